@@ -0,0 +1,137 @@
+// Package apperrors holds the typed errors of the domain. Every error has a kind, which decides the
+// HTTP status, a stable machine code that clients can switch on and a message that is safe to show.
+// Errors without a kind are internal: clients only learn that something went wrong.
+// Clients get the message in their language from the i18n catalog, which is keyed by the code.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+
+	"group1-userservice/app/i18n"
+)
+
+// Kinds; match them with errors.Is, e.g. errors.Is(err, apperrors.ErrNotFound)
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrValidation         = errors.New("validation failed")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrTooManyRequests    = errors.New("too many requests")
+	ErrNotImplemented     = errors.New("not implemented")
+	ErrUnavailable        = errors.New("service unavailable")
+)
+
+// Error is a domain error with a kind, a machine code and a client safe message
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+
+	// Fields maps a field name to what is wrong with it, for validation errors
+	Fields map[string]string
+
+	// detail is appended to the message; reasonKey and reasonArgs translate it when set
+	detail     string
+	reasonKey  string
+	reasonArgs []any
+
+	// parent is the error this one adds detail to
+	parent *Error
+}
+
+func (e *Error) Error() string {
+	if e.detail != "" {
+		return e.Message + ": " + e.detail
+	}
+	return e.Message
+}
+
+// Localize returns the message in l; a detail added with Detailf is not translated
+func (e *Error) Localize(l i18n.Locale) string {
+	msg := i18n.Text(l, e.Code, e.Message)
+	switch {
+	case e.reasonKey != "":
+		return msg + ": " + i18n.T(l, e.reasonKey, e.reasonArgs...)
+	case e.detail != "":
+		return msg + ": " + e.detail
+	}
+	return msg
+}
+
+// Unwrap makes errors.Is match the kind, and the error a detailed copy was made from
+func (e *Error) Unwrap() error {
+	if e.parent != nil {
+		return e.parent
+	}
+	return e.Kind
+}
+
+// Detailf returns a copy of e with more detail appended to the message; errors.Is(copy, e) holds.
+// Use it for details that need no translation, such as field names.
+func (e *Error) Detailf(format string, args ...any) *Error {
+	detailed := *e
+	detailed.detail = fmt.Sprintf(format, args...)
+	detailed.reasonKey, detailed.reasonArgs = "", nil
+	detailed.parent = e
+	return &detailed
+}
+
+// Because returns a copy of e with a reason from the i18n catalog appended to the message;
+// errors.Is(copy, e) holds
+func (e *Error) Because(key string, args ...any) *Error {
+	detailed := *e
+	detailed.detail = i18n.T(i18n.English, key, args...)
+	detailed.reasonKey, detailed.reasonArgs = key, args
+	detailed.parent = e
+	return &detailed
+}
+
+// New creates an error of the given kind
+func New(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func BadRequest(code, message string) *Error {
+	return New(ErrBadRequest, code, message)
+}
+
+// Validation reports invalid fields; message describes the request as a whole
+func Validation(code, message string, fields map[string]string) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message, Fields: fields}
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(ErrUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(ErrForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(ErrNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(ErrConflict, code, message)
+}
+
+func PreconditionFailed(code, message string) *Error {
+	return New(ErrPreconditionFailed, code, message)
+}
+
+func TooManyRequests(code, message string) *Error {
+	return New(ErrTooManyRequests, code, message)
+}
+
+func NotImplemented(code, message string) *Error {
+	return New(ErrNotImplemented, code, message)
+}
+
+func Unavailable(code, message string) *Error {
+	return New(ErrUnavailable, code, message)
+}
@@ -14,6 +14,14 @@ import (
 
 var DB *gorm.DB
 
+// Values of AUTH_CREDENTIAL_MODE. Keycloak checks every password in both; in transitional mode
+// the legacy users.password column is kept and emptied per user on their next login, in keycloak
+// mode it is dropped.
+const (
+	CredentialModeTransitional = "transitional"
+	CredentialModeKeycloak     = "keycloak"
+)
+
 // ConnectDatabase initializes the PostgreSQL connection and runs migrations
 func ConnectDatabase() {
 	host := getEnv("DB_HOST", "localhost")
@@ -44,20 +52,96 @@ func ConnectDatabase() {
 	DB = database
 	log.Println("Connected to PostgreSQL")
 
+	credentialMode := getEnv("AUTH_CREDENTIAL_MODE", CredentialModeTransitional)
+	if credentialMode != CredentialModeTransitional && credentialMode != CredentialModeKeycloak {
+		log.Fatalf("AUTH_CREDENTIAL_MODE must be %q or %q, got %q",
+			CredentialModeTransitional, CredentialModeKeycloak, credentialMode)
+	}
+
+	// Existing settings rows get connection notifications enabled, like new users do
+	addConnectionSettings := !DB.Migrator().HasColumn(&models.NotificationSettings{}, "connection_email")
+
+	// Users that registered before email verification existed count as verified
+	backfillEmailVerified := DB.Migrator().HasTable(&models.User{}) &&
+		!DB.Migrator().HasColumn(&models.User{}, "email_verified_at")
+
 	err = DB.AutoMigrate(
 		&models.User{},
 		&models.NotificationSettings{},
 		&models.Interest{},
+		&models.InterestTranslation{},
 		&models.UserInterest{},
 		&models.DiscoveryPreferences{},
 		&models.PasswordResetToken{},
+		&models.MagicLinkToken{},
 		&models.Badge{},
 		&models.UserBadge{},
+		&models.ProfileVisibility{},
+		&models.HandleReservation{},
+		&models.Connection{},
+		&models.UserBlock{},
+		&models.UserReport{},
+		&models.ModerationAction{},
+		&models.AuditEvent{},
+		&models.ProfileVersion{},
+		&models.Sector{},
+		&models.JobFunction{},
+		&models.VocabularyMapping{},
+		&models.OnboardingState{},
+		&models.OnboardingStepState{},
+		&models.EmailVerificationSend{},
+		&models.TwoFactor{},
+		&models.RecoveryCode{},
+		&models.LoginChallenge{},
 	)
 
 	if err != nil {
 		log.Fatalf("AutoMigrate failed: %v", err)
 	}
+
+	if addConnectionSettings {
+		if err := DB.Exec(
+			"UPDATE notification_settings SET connection_email = true, connection_push = true",
+		).Error; err != nil {
+			log.Fatalf("failed to enable connection notifications: %v", err)
+		}
+	}
+
+	if backfillEmailVerified {
+		if err := DB.Exec("UPDATE users SET email_verified_at = NOW()").Error; err != nil {
+			log.Fatalf("failed to mark existing users verified: %v", err)
+		}
+	}
+
+	if credentialMode == CredentialModeKeycloak && DB.Migrator().HasColumn(&models.User{}, "password") {
+		if err := DB.Migrator().DropColumn(&models.User{}, "password"); err != nil {
+			log.Fatalf("failed to drop legacy password column: %v", err)
+		}
+		log.Println("Dropped legacy users.password column")
+	}
+
+	// Handles are unique regardless of case; users without a handle yet are ignored
+	if err := DB.Exec(
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_handle_lower ON users (LOWER(handle)) WHERE handle <> ''",
+	).Error; err != nil {
+		log.Fatalf("failed to create handle index: %v", err)
+	}
+
+	// The audit log is append-only: rows may be inserted and purged by retention, never changed
+	for _, stmt := range []string{
+		`CREATE OR REPLACE FUNCTION audit_events_prevent_update() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql`,
+		"DROP TRIGGER IF EXISTS audit_events_no_update ON audit_events",
+		`CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events
+	FOR EACH ROW EXECUTE FUNCTION audit_events_prevent_update()`,
+	} {
+		if err := DB.Exec(stmt).Error; err != nil {
+			log.Fatalf("failed to protect audit_events: %v", err)
+		}
+	}
 }
 
 // getEnv returns an environment variable or a fallback value if not set
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/keycloak"
+	"group1-userservice/app/localidp"
+)
+
+// Values of IDENTITY_PROVIDER
+const (
+	IdentityProviderKeycloak = "keycloak"
+	IdentityProviderLocal    = "local"
+)
+
+// IdentityProviderFromEnv builds the identity provider chosen by IDENTITY_PROVIDER (default keycloak)
+func IdentityProviderFromEnv() (interfaces.IdentityProvider, error) {
+	switch kind := getEnv("IDENTITY_PROVIDER", IdentityProviderKeycloak); kind {
+	case IdentityProviderKeycloak:
+		cfg, err := keycloak.ConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return keycloak.NewProvider(cfg), nil
+	case IdentityProviderLocal:
+		cfg, err := localidp.ConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return localidp.New(cfg)
+	default:
+		return nil, fmt.Errorf("IDENTITY_PROVIDER must be %q or %q, got %q", IdentityProviderKeycloak, IdentityProviderLocal, kind)
+	}
+}
@@ -6,26 +6,50 @@ import (
 	"group1-userservice/app/models"
 )
 
+// interestSeed is a default interest. Legacy is the key it was seeded with before interests got slugs,
+// when the Dutch label doubled as the key.
+type interestSeed struct {
+	Key     string
+	Legacy  string
+	LabelNL string
+	LabelEN string
+}
+
+// SeedInterests adds the default interests with their Dutch and English labels. Translations that already
+// exist are left alone, so labels changed by an admin are kept.
 func SeedInterests() {
-	keys := []string{
-		"Gezondheidszorg en Welzijn",
-		"Handel en Dienstverlening",
-		"ICT",
-		"Justitie, Veiligheid en Openbaar Bestuur",
-		"Milieu en Agrarische Sector",
-		"Media en Communicatie",
-		"Onderwijs, Cultuur en Wetenschap",
-		"Techniek, Productie en Bouw",
-		"Toerisme, Recreatie en Horeca",
-		"Transport en Logistiek",
-		"Behoefte aan Investering",
-		"Interesse om te Investeren",
+	// same sector taxonomy as the sectors vocabulary, plus the two investment interests
+	seeds := []interestSeed{
+		{Key: "healthcare_welfare", Legacy: "Gezondheidszorg en Welzijn", LabelNL: "Gezondheidszorg en Welzijn", LabelEN: "Healthcare and Welfare"},
+		{Key: "trade_services", Legacy: "Handel en Dienstverlening", LabelNL: "Handel en Dienstverlening", LabelEN: "Trade and Services"},
+		{Key: "ict", Legacy: "ICT", LabelNL: "ICT", LabelEN: "ICT"},
+		{Key: "justice_security_public_administration", Legacy: "Justitie, Veiligheid en Openbaar Bestuur", LabelNL: "Justitie, Veiligheid en Openbaar Bestuur", LabelEN: "Justice, Security and Public Administration"},
+		{Key: "environment_agriculture", Legacy: "Milieu en Agrarische Sector", LabelNL: "Milieu en Agrarische Sector", LabelEN: "Environment and Agriculture"},
+		{Key: "media_communication", Legacy: "Media en Communicatie", LabelNL: "Media en Communicatie", LabelEN: "Media and Communication"},
+		{Key: "education_culture_science", Legacy: "Onderwijs, Cultuur en Wetenschap", LabelNL: "Onderwijs, Cultuur en Wetenschap", LabelEN: "Education, Culture and Science"},
+		{Key: "engineering_production_construction", Legacy: "Techniek, Productie en Bouw", LabelNL: "Techniek, Productie en Bouw", LabelEN: "Engineering, Production and Construction"},
+		{Key: "tourism_recreation_hospitality", Legacy: "Toerisme, Recreatie en Horeca", LabelNL: "Toerisme, Recreatie en Horeca", LabelEN: "Tourism, Recreation and Hospitality"},
+		{Key: "transport_logistics", Legacy: "Transport en Logistiek", LabelNL: "Transport en Logistiek", LabelEN: "Transport and Logistics"},
+		{Key: "investment_needed", Legacy: "Behoefte aan Investering", LabelNL: "Behoefte aan Investering", LabelEN: "Looking for Investment"},
+		{Key: "investment_offered", Legacy: "Interesse om te Investeren", LabelNL: "Interesse om te Investeren", LabelEN: "Interested in Investing"},
 	}
 
-	for _, key := range keys {
-		interest := models.Interest{Key: key}
-		if err := DB.FirstOrCreate(&interest, models.Interest{Key: key}).Error; err != nil {
-			log.Fatalf("failed to seed interest %s: %v", key, err)
+	for n, s := range seeds {
+		// an interest seeded under its Dutch label gets the slug; it keeps its ID, so the selections of users stay
+		if err := DB.Model(&models.Interest{}).Where("key = ?", s.Legacy).Update("key", s.Key).Error; err != nil {
+			log.Fatalf("failed to migrate interest %s: %v", s.Legacy, err)
+		}
+
+		interest := models.Interest{Key: s.Key}
+		if err := DB.Where(models.Interest{Key: s.Key}).Attrs(models.Interest{Position: n + 1}).FirstOrCreate(&interest).Error; err != nil {
+			log.Fatalf("failed to seed interest %s: %v", s.Key, err)
+		}
+
+		for locale, label := range map[string]string{"nl": s.LabelNL, "en": s.LabelEN} {
+			t := models.InterestTranslation{InterestID: interest.ID, Locale: locale, Label: label}
+			if err := DB.Where(models.InterestTranslation{InterestID: interest.ID, Locale: locale}).FirstOrCreate(&t).Error; err != nil {
+				log.Fatalf("failed to seed %s label of interest %s: %v", locale, s.Key, err)
+			}
 		}
 	}
 }
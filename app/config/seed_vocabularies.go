@@ -0,0 +1,59 @@
+package config
+
+import (
+	"log"
+
+	"group1-userservice/app/models"
+)
+
+// SeedVocabularies adds the default sectors and job functions. Terms that already exist are left alone,
+// so labels changed by an admin are kept.
+func SeedVocabularies() {
+	// same sector taxonomy as the interests catalog
+	sectors := []models.VocabularyTerm{
+		{Key: "healthcare_welfare", LabelNL: "Gezondheidszorg en Welzijn", LabelEN: "Healthcare and Welfare"},
+		{Key: "trade_services", LabelNL: "Handel en Dienstverlening", LabelEN: "Trade and Services"},
+		{Key: "ict", LabelNL: "ICT", LabelEN: "ICT"},
+		{Key: "justice_security_public_administration", LabelNL: "Justitie, Veiligheid en Openbaar Bestuur", LabelEN: "Justice, Security and Public Administration"},
+		{Key: "environment_agriculture", LabelNL: "Milieu en Agrarische Sector", LabelEN: "Environment and Agriculture"},
+		{Key: "media_communication", LabelNL: "Media en Communicatie", LabelEN: "Media and Communication"},
+		{Key: "education_culture_science", LabelNL: "Onderwijs, Cultuur en Wetenschap", LabelEN: "Education, Culture and Science"},
+		{Key: "engineering_production_construction", LabelNL: "Techniek, Productie en Bouw", LabelEN: "Engineering, Production and Construction"},
+		{Key: "tourism_recreation_hospitality", LabelNL: "Toerisme, Recreatie en Horeca", LabelEN: "Tourism, Recreation and Hospitality"},
+		{Key: "transport_logistics", LabelNL: "Transport en Logistiek", LabelEN: "Transport and Logistics"},
+	}
+
+	jobFunctions := []models.VocabularyTerm{
+		{Key: "founder", LabelNL: "Oprichter / Ondernemer", LabelEN: "Founder / Entrepreneur"},
+		{Key: "management", LabelNL: "Directie en Management", LabelEN: "Executive and Management"},
+		{Key: "investor", LabelNL: "Investeerder", LabelEN: "Investor"},
+		{Key: "sales", LabelNL: "Verkoop", LabelEN: "Sales"},
+		{Key: "marketing", LabelNL: "Marketing", LabelEN: "Marketing"},
+		{Key: "finance", LabelNL: "Financiën", LabelEN: "Finance"},
+		{Key: "human_resources", LabelNL: "Personeelszaken (HR)", LabelEN: "Human Resources"},
+		{Key: "software_development", LabelNL: "Softwareontwikkeling", LabelEN: "Software Development"},
+		{Key: "engineering", LabelNL: "Techniek en Engineering", LabelEN: "Engineering"},
+		{Key: "design", LabelNL: "Ontwerp", LabelEN: "Design"},
+		{Key: "consultancy", LabelNL: "Advies", LabelEN: "Consultancy"},
+		{Key: "legal", LabelNL: "Juridisch", LabelEN: "Legal"},
+		{Key: "operations", LabelNL: "Operations", LabelEN: "Operations"},
+		{Key: "customer_service", LabelNL: "Klantenservice", LabelEN: "Customer Service"},
+		{Key: "research", LabelNL: "Onderzoek", LabelEN: "Research"},
+		{Key: "education", LabelNL: "Onderwijs", LabelEN: "Education"},
+		{Key: "healthcare", LabelNL: "Zorg", LabelEN: "Healthcare"},
+		{Key: "student", LabelNL: "Student", LabelEN: "Student"},
+		{Key: "other", LabelNL: "Overig", LabelEN: "Other"},
+	}
+
+	for v, terms := range map[models.Vocabulary][]models.VocabularyTerm{
+		models.VocabularySectors:      sectors,
+		models.VocabularyJobFunctions: jobFunctions,
+	} {
+		for i, t := range terms {
+			t.Position = i + 1
+			if err := DB.Table(string(v)).Where("key = ?", t.Key).FirstOrCreate(&t).Error; err != nil {
+				log.Fatalf("failed to seed %s term %s: %v", v, t.Key, err)
+			}
+		}
+	}
+}
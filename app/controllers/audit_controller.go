@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+)
+
+var errInvalidTime = apperrors.BadRequest("invalid_time", "invalid time")
+
+type AuditController struct {
+	Service interfaces.AuditService
+}
+
+func NewAuditController(s interfaces.AuditService) *AuditController {
+	return &AuditController{Service: s}
+}
+
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, bool) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		c.Error(errInvalidTime.Detailf("%s, expected RFC3339", key))
+		return nil, false
+	}
+	return &t, true
+}
+
+// @Summary Query the audit log
+// @Description Returns audit events, newest first. Requires the admin realm role.
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param actor_type query string false "user, service, admin or anonymous"
+// @Param actor_id query string false "Keycloak sub or service name"
+// @Param action query string false "Action, e.g. user.profile_updated"
+// @Param target_type query string false "Target type, e.g. user or email"
+// @Param target_id query string false "Target ID"
+// @Param from query string false "Start time (RFC3339, inclusive)"
+// @Param to query string false "End time (RFC3339, exclusive)"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} interfaces.AuditPage
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/audit-events [get]
+func (ac *AuditController) Query(c *gin.Context) {
+	from, ok := parseTimeQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseTimeQuery(c, "to")
+	if !ok {
+		return
+	}
+
+	filter := interfaces.AuditFilter{
+		ActorType:  models.AuditActorType(c.Query("actor_type")),
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		From:       from,
+		To:         to,
+	}
+
+	result, err := ac.Service.Query(filter, pagination(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -2,6 +2,8 @@ package controller
 
 import (
 	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -24,21 +26,31 @@ func NewBadgeController(bs interfaces.UserBadgeService, us interfaces.UserServic
 func (bc *BadgeController) Award(c *gin.Context) {
 	var req AwardBadgeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		c.Error(errInvalidInput)
 		return
 	}
 
 	userID, err := uuid.Parse(req.UserID)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid user_id"})
+		c.Error(errInvalidUserID)
 		return
 	}
 
-	_, err = bc.BadgeService.AwardBadge(userID, req.BadgeKey)
+	awarded, err := bc.BadgeService.AwardBadge(userID, req.BadgeKey)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	// the calling service identifies itself with X-Service-Name
+	if awarded {
+		middleware.RecordAudit(c, models.AuditEvent{
+			Action:     models.AuditBadgeAwarded,
+			TargetType: "user",
+			TargetID:   userID.String(),
+			Changes:    models.FieldChanges{"badge_key": {After: req.BadgeKey}},
+		})
+	}
+
 	c.JSON(200, gin.H{"status": "ok"})
 }
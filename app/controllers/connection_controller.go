@@ -0,0 +1,344 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+)
+
+var errInvalidRequestID = apperrors.BadRequest("invalid_request_id", "invalid request id")
+
+type ConnectionController struct {
+	Service     interfaces.ConnectionService
+	UserService interfaces.UserService
+}
+
+func NewConnectionController(
+	s interfaces.ConnectionService,
+	us interfaces.UserService,
+) *ConnectionController {
+	return &ConnectionController{
+		Service:     s,
+		UserService: us,
+	}
+}
+
+// currentUser loads the user of the Bearer token, adding the error to the context when that fails.
+func currentUser(c *gin.Context, us interfaces.UserService) (models.User, bool) {
+	sub, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(errUnauthorized)
+		return models.User{}, false
+	}
+
+	user, err := us.GetByKeycloakID(sub)
+	if err != nil {
+		c.Error(service.ErrUserNotFound)
+		return models.User{}, false
+	}
+
+	return user, true
+}
+
+func targetID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errInvalidUserID)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func pagination(c *gin.Context) interfaces.Pagination {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+	return service.NormalizePagination(page, pageSize)
+}
+
+// @Summary Follow a user
+// @Description Follows another user. Following needs no approval; following twice is a no-op.
+// @Tags Connections
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} models.Connection
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/following/{id} [post]
+func (cc *ConnectionController) Follow(c *gin.Context) {
+	user, ok := currentUser(c, cc.UserService)
+	if !ok {
+		return
+	}
+	id, ok := targetID(c)
+	if !ok {
+		return
+	}
+
+	conn, err := cc.Service.Follow(user, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, conn)
+}
+
+// @Summary Unfollow a user
+// @Tags Connections
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path string true "User ID (UUID)"
+// @Success 204
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/following/{id} [delete]
+func (cc *ConnectionController) Unfollow(c *gin.Context) {
+	user, ok := currentUser(c, cc.UserService)
+	if !ok {
+		return
+	}
+	id, ok := targetID(c)
+	if !ok {
+		return
+	}
+
+	if err := cc.Service.Unfollow(user, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Send a connection request
+// @Description Sends a mutual connection request. When the other user already sent you a request, it is accepted instead.
+// @Tags Connections
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path string true "User ID (UUID)"
+// @Success 201 {object} models.Connection
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Already connected or request pending"
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/connections/{id} [post]
+func (cc *ConnectionController) RequestConnection(c *gin.Context) {
+	user, ok := currentUser(c, cc.UserService)
+	if !ok {
+		return
+	}
+	id, ok := targetID(c)
+	if !ok {
+		return
+	}
+
+	conn, err := cc.Service.RequestConnection(user, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, conn)
+}
+
+// @Summary Remove a connection
+// @Tags Connections
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path string true "User ID (UUID)"
+// @Success 204
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/connections/{id} [delete]
+func (cc *ConnectionController) RemoveConnection(c *gin.Context) {
+	user, ok := currentUser(c, cc.UserService)
+	if !ok {
+		return
+	}
+	id, ok := targetID(c)
+	if !ok {
+		return
+	}
+
+	if err := cc.Service.RemoveConnection(user, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (cc *ConnectionController) respond(c *gin.Context, accept bool) {
+	user, ok := currentUser(c, cc.UserService)
+	if !ok {
+		return
+	}
+
+	requestID, err := strconv.ParseUint(c.Param("requestId"), 10, 64)
+	if err != nil {
+		c.Error(errInvalidRequestID)
+		return
+	}
+
+	conn, err := cc.Service.RespondToRequest(user, uint(requestID), accept)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, conn)
+}
+
+// @Summary Accept a connection request
+// @Tags Connections
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param requestId path int true "Connection request ID"
+// @Success 200 {object} models.Connection
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/connection-requests/{requestId}/accept [post]
+func (cc *ConnectionController) AcceptRequest(c *gin.Context) {
+	cc.respond(c, true)
+}
+
+// @Summary Decline a connection request
+// @Tags Connections
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param requestId path int true "Connection request ID"
+// @Success 200 {object} models.Connection
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/connection-requests/{requestId}/decline [post]
+func (cc *ConnectionController) DeclineRequest(c *gin.Context) {
+	cc.respond(c, false)
+}
+
+func (cc *ConnectionController) list(c *gin.Context, fetch func(uuid.UUID, interfaces.Pagination) (*interfaces.ConnectionPage, error)) {
+	user, ok := currentUser(c, cc.UserService)
+	if !ok {
+		return
+	}
+
+	result, err := fetch(user.ID, pagination(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary List my followers
+// @Tags Connections
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} interfaces.ConnectionPage
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/followers [get]
+func (cc *ConnectionController) ListFollowers(c *gin.Context) {
+	cc.list(c, cc.Service.ListFollowers)
+}
+
+// @Summary List users I follow
+// @Tags Connections
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} interfaces.ConnectionPage
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/following [get]
+func (cc *ConnectionController) ListFollowing(c *gin.Context) {
+	cc.list(c, cc.Service.ListFollowing)
+}
+
+// @Summary List my connections
+// @Tags Connections
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} interfaces.ConnectionPage
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/connections [get]
+func (cc *ConnectionController) ListConnections(c *gin.Context) {
+	cc.list(c, cc.Service.ListConnections)
+}
+
+// @Summary List incoming connection requests
+// @Description Pending requests sent to me. Each item has the request_id to accept or decline.
+// @Tags Connections
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} interfaces.ConnectionPage
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/connection-requests [get]
+func (cc *ConnectionController) ListIncomingRequests(c *gin.Context) {
+	cc.list(c, cc.Service.ListIncomingRequests)
+}
+
+// @Summary Get all connection IDs of a user (internal)
+// @Description Returns follower, following and connection IDs in one call, for the feed service.
+// @Tags Internal
+// @Produce json
+// @Param X-Service-Token header string true "Service token"
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} interfaces.UserConnectionIDs
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /internal/users/id/{id}/connections [get]
+func (cc *ConnectionController) GetConnectionIDsInternal(c *gin.Context) {
+	id, ok := targetID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := cc.UserService.GetByID(id); err != nil {
+		c.Error(service.ErrUserNotFound)
+		return
+	}
+
+	ids, err := cc.Service.ConnectionIDs(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ids)
+}
@@ -5,6 +5,8 @@ import (
 
 	"group1-userservice/app/interfaces"
 	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
 
 	"github.com/gin-gonic/gin"
 )
@@ -35,27 +37,33 @@ func NewDiscoveryPreferencesController(
 // @Tags DiscoveryPreferences
 // @Produce json
 // @Param Authorization header string true "Bearer access token"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} controller.DiscoveryPreferencesResponse
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Success 304
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /users/me/discovery-preferences [get]
 func (dc *DiscoveryPreferencesController) GetForMe(c *gin.Context) {
 	sub, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(errUnauthorized)
 		return
 	}
 
 	user, err := dc.UserService.GetByKeycloakID(sub)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
 	prefs, err := dc.PrefsService.GetForEmail(user.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get discovery preferences"})
+		c.Error(err)
+		return
+	}
+
+	if notModified(c, prefs.Version) {
 		return
 	}
 
@@ -71,38 +79,59 @@ func (dc *DiscoveryPreferencesController) GetForMe(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer access token"
+// @Param If-Match header string false "ETag from GET /users/me/discovery-preferences; 412 when they changed since"
 // @Param request body interfaces.DiscoveryPreferencesInput true "Discovery preferences input"
 // @Success 200 {object} controller.DiscoveryPreferencesResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 412 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /users/me/discovery-preferences [put]
 func (dc *DiscoveryPreferencesController) UpdateForMe(c *gin.Context) {
 	sub, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(errUnauthorized)
 		return
 	}
 
 	user, err := dc.UserService.GetByKeycloakID(sub)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
 	var input interfaces.DiscoveryPreferencesInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		c.Error(errInvalidInput)
 		return
 	}
 
-	updated, err := dc.PrefsService.UpdateForEmail(user.Email, input)
+	before, err := dc.PrefsService.GetForEmail(user.Email)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	version, ok := ifMatchVersion(c, before.Version)
+	if !ok {
 		return
 	}
 
+	updated, err := dc.PrefsService.UpdateForEmailIfVersion(user.Email, input, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditDiscoveryPreferencesSet,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Changes:    service.DiffFields(before, updated),
+	})
+
+	c.Header("ETag", etag(updated.Version))
 	c.JSON(http.StatusOK, DiscoveryPreferencesResponse{
 		Email:    user.Email,
 		RadiusKm: updated.RadiusKm,
@@ -116,27 +145,27 @@ func (dc *DiscoveryPreferencesController) UpdateForMe(c *gin.Context) {
 // @Param X-Service-Token header string true "Service token"
 // @Param email path string true "User email"
 // @Success 200 {object} controller.DiscoveryPreferencesResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /internal/users/{email}/discovery-preferences [get]
 func (dc *DiscoveryPreferencesController) GetByEmailInternal(c *gin.Context) {
 	email := c.Param("email")
 	if email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing email"})
+		c.Error(errMissingEmail)
 		return
 	}
 
 	// Optioneel: check of user bestaat (zoals je al deed)
 	if _, err := dc.UserService.GetByEmail(email); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
 	prefs, err := dc.PrefsService.GetForEmail(email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get discovery preferences"})
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+)
+
+type EmailVerificationController struct {
+	Service     interfaces.EmailVerificationService
+	UserService interfaces.UserService
+}
+
+func NewEmailVerificationController(s interfaces.EmailVerificationService, us interfaces.UserService) *EmailVerificationController {
+	return &EmailVerificationController{Service: s, UserService: us}
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+// @Summary Verify an email address
+// @Description Verifies the email address with the token from the verification email and marks it verified in Keycloak.
+// @Description Using a token again after success is fine. Tokens expire (EMAIL_VERIFICATION_TTL_HOURS) and stop working when the email address changes.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body controller.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /auth/verify-email [post]
+func (vc *EmailVerificationController) Verify(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		c.Error(errTokenRequired)
+		return
+	}
+
+	user, err := vc.Service.Verify(req.Token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		ActorType:  models.AuditActorUser,
+		ActorID:    user.KeycloakID,
+		Action:     models.AuditEmailVerified,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": message(c, "message.email_verified")})
+}
+
+// @Summary Resend the verification email
+// @Description Sends a new verification link if the email belongs to an unverified account.
+// Always returns 200 to prevent user enumeration. Resends are throttled per account.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body controller.ResendVerificationRequest true "Email address"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Router /auth/verify-email/resend [post]
+func (vc *EmailVerificationController) Resend(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Email == "" {
+		c.Error(errEmailRequired)
+		return
+	}
+
+	// Send in the background so the response time does not tell whether the account exists
+	go func(email string) {
+		if err := vc.Service.Resend(email); err != nil && !errors.Is(err, service.ErrVerificationThrottled) {
+			log.Printf("[verification] resend failed: %v\n", err)
+		}
+	}(req.Email)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message(c, "message.verification_resent"),
+	})
+}
+
+// @Summary Resend my verification email
+// @Description Sends a new verification link to the email address of the logged in user.
+// @Tags Auth
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Already verified"
+// @Failure 429 {object} middleware.Problem "Sent too recently"
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/verify-email/resend [post]
+func (vc *EmailVerificationController) ResendForMe(c *gin.Context) {
+	user, ok := currentUser(c, vc.UserService)
+	if !ok {
+		return
+	}
+
+	if err := vc.Service.Send(user); err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditEmailVerificationSent,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": message(c, "message.verification_sent")})
+}
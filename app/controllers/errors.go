@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"group1-userservice/app/apperrors"
+)
+
+// Errors of the HTTP layer itself; the services have their own. Controllers hand errors to c.Error
+// and middleware.ProblemDetails renders them.
+var (
+	errInvalidInput        = apperrors.BadRequest("invalid_input", "invalid request body")
+	errInvalidUserID       = apperrors.BadRequest("invalid_user_id", "invalid user id")
+	errMissingEmail        = apperrors.BadRequest("missing_email", "missing email")
+	errEmailRequired       = apperrors.BadRequest("email_required", "email is required")
+	errTokenRequired       = apperrors.BadRequest("token_required", "token is required")
+	errInvalidStatus       = apperrors.BadRequest("invalid_status", "invalid status")
+	errUnauthorized        = apperrors.Unauthorized("unauthorized", "unauthorized")
+	errForbidden           = apperrors.Forbidden("forbidden", "forbidden")
+	errIdentityUnavailable = apperrors.Unavailable("identity_provider_unavailable", "authentication is unavailable, try again later")
+)
+
+// invalidJSON describes a body that could not be decoded. The decoder's own text names Go types,
+// so only the offending field is passed on.
+func invalidJSON(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return errInvalidInput.Because("invalid_input.field_type", typeErr.Field)
+	}
+
+	// DisallowUnknownFields has no error type of its own
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return errInvalidInput.Because("invalid_input.unknown_field", strings.Trim(field, `"`))
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return errInvalidInput.Because("invalid_input.malformed")
+	}
+
+	return errInvalidInput
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/service"
+)
+
+// etag turns a row version into a strong entity tag
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// etagListMatches reports whether a comma-separated If-Match / If-None-Match value contains tag.
+// Weak tags only match when weak is true.
+func etagListMatches(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the ETag of a GET response and answers 304 when If-None-Match already has it
+func notModified(c *gin.Context, version int64) bool {
+	tag := etag(version)
+	c.Header("ETag", tag)
+
+	if header := c.GetHeader("If-None-Match"); header != "" && etagListMatches(header, tag, true) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// ifMatchVersion checks If-Match against the current version. It returns the version an update must
+// still find (0 without If-Match) or reports a version conflict (412) when the client's copy is stale.
+func ifMatchVersion(c *gin.Context, current int64) (int64, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return 0, true
+	}
+	if !etagListMatches(header, etag(current), false) {
+		c.Error(service.ErrVersionConflict)
+		return 0, false
+	}
+	return current, true
+}
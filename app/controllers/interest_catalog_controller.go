@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+)
+
+var errInvalidInterestID = apperrors.BadRequest("invalid_interest_id", "invalid interest id")
+
+type InterestCatalogController struct {
+	Service interfaces.InterestCatalogService
+}
+
+func NewInterestCatalogController(s interfaces.InterestCatalogService) *InterestCatalogController {
+	return &InterestCatalogController{Service: s}
+}
+
+func interestID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errInvalidInterestID)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary List the interest catalog
+// @Description Returns every interest with all translations, archived ones included, in the order users see them.
+// @Description Requires the admin realm role.
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Success 200 {array} models.Interest
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/interests [get]
+func (ic *InterestCatalogController) List(c *gin.Context) {
+	interests, err := ic.Service.List()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, interests)
+}
+
+// @Summary Add an interest
+// @Description Adds an interest after the existing ones. Keys are lower snake case and cannot be changed later;
+// @Description a label is required for every supported language. Requires the admin realm role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param body body interfaces.InterestInput true "Key and translations per language"
+// @Success 201 {object} models.Interest
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Key already exists"
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/interests [post]
+func (ic *InterestCatalogController) Create(c *gin.Context) {
+	var input interfaces.InterestInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	interest, err := ic.Service.Create(input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditInterestCreated,
+		TargetType: "interest",
+		TargetID:   interest.Key,
+		Changes:    service.DiffFields(gin.H{}, gin.H{"translations": interest.Translations}),
+	})
+
+	c.JSON(http.StatusCreated, interest)
+}
+
+// @Summary Rename an interest
+// @Description Changes the label and description of the languages sent; other languages and the key stay the same.
+// @Description Requires the admin realm role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path int true "Interest ID"
+// @Param body body interfaces.InterestInput true "Translations per language; key is ignored"
+// @Success 200 {object} models.Interest
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/interests/{id} [put]
+func (ic *InterestCatalogController) Update(c *gin.Context) {
+	id, ok := interestID(c)
+	if !ok {
+		return
+	}
+
+	var input interfaces.InterestInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	interest, err := ic.Service.Update(id, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditInterestUpdated,
+		TargetType: "interest",
+		TargetID:   interest.Key,
+		Changes:    service.DiffFields(gin.H{}, gin.H{"translations": interest.Translations}),
+	})
+
+	c.JSON(http.StatusOK, interest)
+}
+
+// @Summary Reorder interests
+// @Description Puts the listed interests first, in the order given; interests that are left out keep their order after them.
+// @Description Requires the admin realm role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param body body interfaces.InterestOrderInput true "Interest IDs in their new order"
+// @Success 200 {array} models.Interest
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/interests/order [put]
+func (ic *InterestCatalogController) Reorder(c *gin.Context) {
+	var input interfaces.InterestOrderInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	interests, err := ic.Service.Reorder(input.IDs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	keys := make([]string, 0, len(interests))
+	for _, i := range interests {
+		keys = append(keys, i.Key)
+	}
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditInterestsReordered,
+		TargetType: "interest",
+		Changes:    models.FieldChanges{"order": {After: keys}},
+	})
+
+	c.JSON(http.StatusOK, interests)
+}
+
+// @Summary Archive an interest
+// @Description Stops offering the interest to users. Selections users made before are kept. Requires the admin realm role.
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path int true "Interest ID"
+// @Success 200 {object} models.Interest
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/interests/{id}/archive [post]
+func (ic *InterestCatalogController) Archive(c *gin.Context) {
+	id, ok := interestID(c)
+	if !ok {
+		return
+	}
+
+	interest, err := ic.Service.Archive(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditInterestArchived,
+		TargetType: "interest",
+		TargetID:   interest.Key,
+	})
+
+	c.JSON(http.StatusOK, interest)
+}
+
+// @Summary Merge an interest into another
+// @Description Moves the selections of users to the interest in the body and archives this one, in one transaction.
+// @Description A user who selected both keeps the remaining interest selected. Requires the admin realm role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path int true "Interest ID to merge away"
+// @Param body body interfaces.InterestMergeInput true "Interest that takes over the selections"
+// @Success 200 {object} interfaces.InterestMergeResult
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Target is archived"
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/interests/{id}/merge [post]
+func (ic *InterestCatalogController) Merge(c *gin.Context) {
+	id, ok := interestID(c)
+	if !ok {
+		return
+	}
+
+	var input interfaces.InterestMergeInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	result, err := ic.Service.Merge(id, input.Into)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditInterestMerged,
+		TargetType: "interest",
+		TargetID:   result.From.Key,
+		Changes:    models.FieldChanges{"interest": {Before: result.From.Key, After: result.Into.Key}},
+	})
+
+	c.JSON(http.StatusOK, result)
+}
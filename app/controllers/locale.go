@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+)
+
+// message returns the catalog text of key in the language of the response
+func message(c *gin.Context, key string) string {
+	return i18n.T(middleware.GetLocale(c), key)
+}
+
+// accountLocale is the preferred language of the account with email, or else the language of the request.
+// Messages sent to an address that may not be the caller's own use it.
+func accountLocale(c *gin.Context, users interfaces.UserService, email string) i18n.Locale {
+	if user, err := users.GetByEmail(email); err == nil {
+		return i18n.Preferred(user.PreferredLanguage, middleware.GetLocale(c))
+	}
+	return middleware.GetLocale(c)
+}
+
+// badgeText translates the name and description of a badge; badges the catalog does not know,
+// such as those added by other services, keep their own text
+func badgeText(l i18n.Locale, key, name, description string) (string, string) {
+	return i18n.Text(l, "badge."+key+".name", name), i18n.Text(l, "badge."+key+".description", description)
+}
+
+func localizeBadges(c *gin.Context, badges []models.UserBadge) {
+	l := middleware.GetLocale(c)
+	for i := range badges {
+		b := &badges[i].Badge
+		b.Name, b.Description = badgeText(l, badges[i].BadgeKey, b.Name, b.Description)
+	}
+}
+
+func localizePublicBadges(c *gin.Context, badges []models.PublicBadge) {
+	l := middleware.GetLocale(c)
+	for i := range badges {
+		badges[i].Name, badges[i].Description = badgeText(l, badges[i].Key, badges[i].Name, badges[i].Description)
+	}
+}
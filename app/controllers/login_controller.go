@@ -1,8 +1,14 @@
 package controller
 
 import (
+	"errors"
+	"log"
+
+	"group1-userservice/app/apperrors"
 	"group1-userservice/app/interfaces"
-	"group1-userservice/app/keycloak"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
 
 	"github.com/gin-gonic/gin"
 
@@ -10,13 +16,22 @@ import (
 	"time"
 )
 
+var (
+	errChallengeRequired    = apperrors.BadRequest("challenge_required", "challenge_token and code are required")
+	errRefreshTokenRequired = apperrors.BadRequest("refresh_token_required", "refresh_token is required")
+)
+
 type LoginController struct {
 	UserService interfaces.UserService
+	TwoFactor   interfaces.TwoFactorService
+	Identity    interfaces.IdentityProvider
 }
 
-func NewLoginController(us interfaces.UserService) *LoginController {
+func NewLoginController(us interfaces.UserService, tf interfaces.TwoFactorService, idp interfaces.IdentityProvider) *LoginController {
 	return &LoginController{
 		UserService: us,
+		TwoFactor:   tf,
+		Identity:    idp,
 	}
 }
 
@@ -25,15 +40,27 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// LoginChallengeResponse is returned by /auth/login instead of tokens when the account has 2FA enabled
+type LoginChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"`
+}
+
 // @Summary User login
-// @Description Authenticate a user and return a Keycloak access token
+// @Description Authenticate a user and return a Keycloak access token.
+// @Description With two-factor authentication enabled a challenge is returned instead; exchange it at /auth/login/2fa.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "Login credentials"
-// @Success 200 {object} keycloak.TokenResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Success 200 {object} interfaces.TokenResponse
+// @Success 202 {object} controller.LoginChallengeResponse "Two-factor code required"
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem "Account blocked, disabled or not verified"
+// @Failure 429 {object} middleware.Problem "Account temporarily locked"
+// @Failure 503 {object} middleware.Problem "Keycloak unavailable"
 // @Router /auth/login [post]
 func (lc *LoginController) Handle(c *gin.Context) {
 	start := time.Now()
@@ -47,7 +74,7 @@ func (lc *LoginController) Handle(c *gin.Context) {
 
 	if err := c.BindJSON(&req); err != nil {
 		metrics.UserRequestOutcomesTotal.WithLabelValues("bad_request").Inc()
-		c.JSON(400, gin.H{"error": "Invalid request body"})
+		c.Error(errInvalidInput)
 		return
 	}
 
@@ -55,29 +82,161 @@ func (lc *LoginController) Handle(c *gin.Context) {
 	user, err := lc.UserService.GetByEmail(req.Email)
 	if err != nil {
 		metrics.UserRequestOutcomesTotal.WithLabelValues("unauthorized").Inc()
-		c.JSON(401, gin.H{"error": "Invalid email or password"})
+		auditLoginFailed(c, req.Email, "unknown_email")
+		c.Error(interfaces.ErrInvalidCredentials)
 		return
 	}
 
-	// Verify password using bcrypt
-	if !lc.UserService.CheckPassword(user.Password, req.Password) {
-		metrics.UserRequestOutcomesTotal.WithLabelValues("unauthorized").Inc()
-		c.JSON(401, gin.H{"error": "Invalid email or password"})
+	// The identity provider is the only credential store: its password grant decides
+	token, err := lc.Identity.PasswordGrant(req.Email, req.Password)
+	if err != nil {
+		writeGrantError(c, req.Email, err)
+		return
+	}
+
+	// Accounts blocked by a moderator may not log in
+	if user.IsBlocked {
+		metrics.UserRequestOutcomesTotal.WithLabelValues("forbidden").Inc()
+		auditLoginFailed(c, req.Email, "account_blocked")
+		c.Error(service.ErrAccountBlocked)
 		return
 	}
 
-	// Ask Keycloak for an access token (auth)
-	token, err := keycloak.GetAccessToken(req.Email, req.Password)
+	// A hash from before Keycloak was authoritative is not needed anymore
+	if err := lc.UserService.RetireLegacyPassword(user, req.Password); err != nil {
+		log.Printf("[login] could not clear legacy password hash: %v\n", err)
+	}
+
+	// With 2FA the tokens are held back until the second step
+	enabled, err := lc.TwoFactor.Enabled(user.ID)
 	if err != nil {
-		metrics.UserRequestOutcomesTotal.WithLabelValues("unauthorized").Inc()
-		c.JSON(401, gin.H{"error": "Authentication failed"})
+		metrics.UserRequestOutcomesTotal.WithLabelValues("error").Inc()
+		c.Error(err)
+		return
+	}
+	if enabled {
+		challenge, expiresIn, err := lc.TwoFactor.StartLogin(user, token.RefreshToken)
+		if err != nil {
+			metrics.UserRequestOutcomesTotal.WithLabelValues("error").Inc()
+			c.Error(err)
+			return
+		}
+
+		metrics.UserRequestOutcomesTotal.WithLabelValues("success").Inc()
+		middleware.RecordAudit(c, models.AuditEvent{
+			ActorType:  models.AuditActorUser,
+			ActorID:    user.KeycloakID,
+			Action:     models.AuditLoginTwoFactorRequired,
+			TargetType: "user",
+			TargetID:   user.ID.String(),
+		})
+
+		c.JSON(202, LoginChallengeResponse{TwoFactorRequired: true, ChallengeToken: challenge, ExpiresIn: expiresIn})
 		return
 	}
 
 	metrics.UserRequestOutcomesTotal.WithLabelValues("success").Inc()
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		ActorType:  models.AuditActorUser,
+		ActorID:    user.KeycloakID,
+		Action:     models.AuditLoginSucceeded,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+	})
+
+	c.JSON(200, token)
+}
+
+// @Summary Finish a login with two-factor authentication
+// @Description Exchanges the challenge token from /auth/login and a TOTP code or recovery code for the Keycloak tokens.
+// @Description A challenge expires after a few minutes and after five invalid codes.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body interfaces.LoginChallengeInput true "Challenge token and code"
+// @Success 200 {object} interfaces.TokenResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem "Account blocked"
+// @Failure 429 {object} middleware.Problem "Too many invalid codes"
+// @Router /auth/login/2fa [post]
+func (lc *LoginController) HandleTwoFactor(c *gin.Context) {
+	var req interfaces.LoginChallengeInput
+	if err := c.ShouldBindJSON(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		c.Error(errChallengeRequired)
+		return
+	}
+
+	user, refreshToken, err := lc.TwoFactor.FinishLogin(req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTwoFactorInvalidCode):
+			auditLoginFailed(c, user.Email, "invalid_2fa_code")
+		case errors.Is(err, service.ErrLoginChallengeLocked):
+			auditLoginFailed(c, user.Email, "too_many_2fa_codes")
+		}
+		c.Error(err)
+		return
+	}
+
+	// The account may have been blocked since the password step
+	if user.IsBlocked {
+		auditLoginFailed(c, user.Email, "account_blocked")
+		c.Error(service.ErrAccountBlocked)
+		return
+	}
+
+	token, err := lc.Identity.Refresh(refreshToken)
+	if err != nil {
+		auditLoginFailed(c, user.Email, "identity_provider_rejected")
+		c.Error(interfaces.ErrInvalidToken)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		ActorType:  models.AuditActorUser,
+		ActorID:    user.KeycloakID,
+		Action:     models.AuditLoginSucceeded,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+	})
+
 	c.JSON(200, token)
 }
 
+// writeGrantError answers a refused password grant with the reason the identity provider gave
+func writeGrantError(c *gin.Context, email string, err error) {
+	outcome, reason := "error", "identity_provider_unavailable"
+	switch {
+	case errors.Is(err, interfaces.ErrInvalidCredentials):
+		outcome, reason = "unauthorized", "invalid_password"
+	case errors.Is(err, interfaces.ErrAccountDisabled):
+		outcome, reason = "forbidden", "account_disabled"
+	case errors.Is(err, interfaces.ErrAccountNotVerified):
+		outcome, reason = "forbidden", "account_not_verified"
+	case errors.Is(err, interfaces.ErrAccountLocked):
+		outcome, reason = "locked", "account_locked"
+	default:
+		log.Printf("[identity] password grant failed: %v\n", err)
+		err = errIdentityUnavailable
+	}
+
+	metrics.UserRequestOutcomesTotal.WithLabelValues(outcome).Inc()
+	auditLoginFailed(c, email, reason)
+	c.Error(err)
+}
+
+// auditLoginFailed records a failed login; the reason is stored as the only change
+func auditLoginFailed(c *gin.Context, email, reason string) {
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditLoginFailed,
+		TargetType: "email",
+		TargetID:   email,
+		Changes:    models.FieldChanges{"reason": {After: reason}},
+	})
+}
+
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
@@ -88,21 +247,21 @@ type RefreshRequest struct {
 // @Accept json
 // @Produce json
 // @Param request body RefreshRequest true "Refresh token"
-// @Success 200 {object} keycloak.TokenResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Success 200 {object} interfaces.TokenResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
 // @Router /auth/refresh [post]
 func (lc *LoginController) Refresh(c *gin.Context) {
 	var req RefreshRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
-		c.JSON(400, gin.H{"error": "refresh_token is required"})
+		c.Error(errRefreshTokenRequired)
 		return
 	}
 
-	token, err := keycloak.RefreshAccessToken(req.RefreshToken)
+	token, err := lc.Identity.Refresh(req.RefreshToken)
 	if err != nil {
-		c.JSON(401, gin.H{"error": "invalid or expired refresh token"})
+		c.Error(interfaces.ErrInvalidToken)
 		return
 	}
 
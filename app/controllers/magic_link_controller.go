@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/metrics"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/notifications"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errImpersonationFailed = apperrors.Unauthorized("login_rejected", "the identity provider refused the login")
+
+type MagicLinkController struct {
+	Service     interfaces.MagicLinkService
+	UserService interfaces.UserService
+	TwoFactor   interfaces.TwoFactorService
+	Identity    interfaces.IdentityProvider
+	Notifier    interfaces.NotificationSender
+}
+
+func NewMagicLinkController(
+	s interfaces.MagicLinkService,
+	us interfaces.UserService,
+	tf interfaces.TwoFactorService,
+	idp interfaces.IdentityProvider,
+	notificationURL string,
+) *MagicLinkController {
+	return &MagicLinkController{
+		Service:     s,
+		UserService: us,
+		TwoFactor:   tf,
+		Identity:    idp,
+		Notifier:    notifications.NewClient(notificationURL),
+	}
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+type RedeemMagicLinkRequest struct {
+	Token string `json:"token"`
+}
+
+func (mc *MagicLinkController) sendMagicLink(email string, token string, l i18n.Locale) {
+	err := mc.Notifier.Send(models.Notification{
+		Email:   email,
+		Message: i18n.T(l, "notification.magic_link.message"),
+		Title:   i18n.T(l, "notification.magic_link.title"),
+		Type:    "system_alert",
+		Token:   token,
+	})
+	if err != nil {
+		log.Printf("[notification] magic link failed: %v\n", err)
+	}
+}
+
+// Request
+// @Summary Request a login link
+// @Description If the email exists, a single-use login link will be sent. It expires after 15 minutes.
+// @Description Always returns 200 to prevent user enumeration.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body controller.MagicLinkRequest true "Magic link request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} middleware.Problem
+// @Failure 429 {object} middleware.Problem
+// @Router /auth/magic-link [post]
+func (mc *MagicLinkController) Request(c *gin.Context) {
+	start := time.Now()
+	defer func() {
+		metrics.UserRequestDuration.Observe(time.Since(start).Seconds())
+	}()
+
+	metrics.UserRequestsTotal.Inc()
+
+	var req MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Email == "" {
+		metrics.UserRequestOutcomesTotal.WithLabelValues("bad_request").Inc()
+		c.Error(errEmailRequired)
+		return
+	}
+
+	metrics.MagicLinkRequestsTotal.Inc()
+
+	token, _ := mc.Service.RequestLink(req.Email)
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditMagicLinkRequested,
+		TargetType: "email",
+		TargetID:   req.Email,
+	})
+
+	// Send notification asynchronously only when a token is created
+	if token != "" {
+		go mc.sendMagicLink(req.Email, token, accountLocale(c, mc.UserService, req.Email))
+	}
+
+	metrics.UserRequestOutcomesTotal.WithLabelValues("success").Inc()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message(c, "message.magic_link_requested"),
+	})
+}
+
+// Redeem
+// @Summary Log in with a login link
+// @Description Exchanges the token from a login link for access and refresh tokens. The token can be used once.
+// @Description With two-factor authentication enabled a challenge is returned instead; exchange it at /auth/login/2fa.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body controller.RedeemMagicLinkRequest true "Login link token"
+// @Success 200 {object} interfaces.TokenResponse
+// @Success 202 {object} controller.LoginChallengeResponse "Two-factor code required"
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem "Account blocked"
+// @Router /auth/magic-link/redeem [post]
+func (mc *MagicLinkController) Redeem(c *gin.Context) {
+	var req RedeemMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		c.Error(errTokenRequired)
+		return
+	}
+
+	user, err := mc.Service.Redeem(req.Token)
+	if err != nil {
+		if errors.Is(err, service.ErrAccountBlocked) {
+			auditLoginFailed(c, user.Email, "account_blocked")
+		}
+		c.Error(err)
+		return
+	}
+
+	token, err := mc.Identity.Impersonate(user.KeycloakID)
+	if err != nil {
+		log.Printf("[identity] magic link impersonation failed: %v\n", err)
+		auditLoginFailed(c, user.Email, "identity_provider_rejected")
+		c.Error(errImpersonationFailed)
+		return
+	}
+
+	// A login link replaces the password, not the second factor
+	enabled, err := mc.TwoFactor.Enabled(user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if enabled {
+		challenge, expiresIn, err := mc.TwoFactor.StartLogin(user, token.RefreshToken)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		middleware.RecordAudit(c, models.AuditEvent{
+			ActorType:  models.AuditActorUser,
+			ActorID:    user.KeycloakID,
+			Action:     models.AuditLoginTwoFactorRequired,
+			TargetType: "user",
+			TargetID:   user.ID.String(),
+			Changes:    models.FieldChanges{"method": {After: "magic_link"}},
+		})
+
+		c.JSON(http.StatusAccepted, LoginChallengeResponse{TwoFactorRequired: true, ChallengeToken: challenge, ExpiresIn: expiresIn})
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		ActorType:  models.AuditActorUser,
+		ActorID:    user.KeycloakID,
+		Action:     models.AuditLoginSucceeded,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Changes:    models.FieldChanges{"method": {After: "magic_link"}},
+	})
+
+	c.JSON(http.StatusOK, token)
+}
@@ -0,0 +1,252 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+)
+
+var errInvalidReportID = apperrors.BadRequest("invalid_report_id", "invalid report id")
+
+type ModerationController struct {
+	Service     interfaces.ModerationService
+	UserService interfaces.UserService
+}
+
+func NewModerationController(
+	s interfaces.ModerationService,
+	us interfaces.UserService,
+) *ModerationController {
+	return &ModerationController{
+		Service:     s,
+		UserService: us,
+	}
+}
+
+// decodeStrict decodes the JSON body into dst, rejecting unknown fields
+func decodeStrict(c *gin.Context, dst interface{}) bool {
+	bodyBytes, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(errInvalidInput)
+		return false
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(bodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		c.Error(invalidJSON(err))
+		return false
+	}
+	return true
+}
+
+func reportID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("reportId"), 10, 64)
+	if err != nil {
+		c.Error(errInvalidReportID)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary Report a user
+// @Description Reports another user's profile to the moderators.
+// @Description Reasons: fake_profile, offensive_bio, inappropriate_photo, spam, harassment, other.
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path string true "User ID (UUID)"
+// @Param body body interfaces.ReportInput true "Reason and optional details (max 1000 characters)"
+// @Success 201 {object} models.UserReport
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Open report for this user already exists"
+// @Failure 500 {object} middleware.Problem
+// @Router /users/id/{id}/report [post]
+func (mc *ModerationController) Report(c *gin.Context) {
+	user, ok := currentUser(c, mc.UserService)
+	if !ok {
+		return
+	}
+	id, ok := targetID(c)
+	if !ok {
+		return
+	}
+
+	var input interfaces.ReportInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	report, err := mc.Service.Report(user, id, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// @Summary List reports
+// @Description Returns the moderation queue, oldest first. Requires the moderator realm role.
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param status query string false "open (default), in_review, resolved, dismissed or all"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} interfaces.ReportPage
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /moderation/reports [get]
+func (mc *ModerationController) List(c *gin.Context) {
+	status := models.ReportStatus(c.DefaultQuery("status", string(models.ReportStatusOpen)))
+	switch status {
+	case models.ReportStatusOpen, models.ReportStatusInReview, models.ReportStatusResolved, models.ReportStatusDismissed:
+	case "all":
+		status = ""
+	default:
+		c.Error(errInvalidStatus)
+		return
+	}
+
+	result, err := mc.Service.List(status, pagination(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Get a report
+// @Description Returns a report with its audit trail. Requires the moderator realm role.
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param reportId path int true "Report ID"
+// @Success 200 {object} interfaces.ReportDetail
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /moderation/reports/{reportId} [get]
+func (mc *ModerationController) Get(c *gin.Context) {
+	id, ok := reportID(c)
+	if !ok {
+		return
+	}
+
+	detail, err := mc.Service.Get(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// @Summary Claim a report
+// @Description Assigns an open report to the calling moderator. Requires the moderator realm role.
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param reportId path int true "Report ID"
+// @Success 200 {object} models.UserReport
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Closed or claimed by another moderator"
+// @Failure 500 {object} middleware.Problem
+// @Router /moderation/reports/{reportId}/claim [post]
+func (mc *ModerationController) Claim(c *gin.Context) {
+	moderatorID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(errUnauthorized)
+		return
+	}
+	id, ok := reportID(c)
+	if !ok {
+		return
+	}
+
+	report, err := mc.Service.Claim(id, moderatorID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Resolve a report
+// @Description Applies the given actions (hide_bio, remove_photo, block_account) and closes the report.
+// @Description Without actions the report is dismissed. Every action is audited. Requires the moderator realm role.
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param reportId path int true "Report ID"
+// @Param body body interfaces.ResolveReportInput true "Actions and note"
+// @Success 200 {object} interfaces.ReportDetail
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Closed or claimed by another moderator"
+// @Failure 500 {object} middleware.Problem
+// @Router /moderation/reports/{reportId}/resolve [post]
+func (mc *ModerationController) Resolve(c *gin.Context) {
+	moderatorID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(errUnauthorized)
+		return
+	}
+	id, ok := reportID(c)
+	if !ok {
+		return
+	}
+
+	var input interfaces.ResolveReportInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	detail, err := mc.Service.Resolve(id, moderatorID, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	actions := make([]string, 0, len(detail.Actions))
+	for _, a := range detail.Actions {
+		if a.Action != models.ModerationActionClaim {
+			actions = append(actions, string(a.Action))
+		}
+	}
+	middleware.RecordAudit(c, models.AuditEvent{
+		ActorType:  models.AuditActorAdmin,
+		ActorID:    moderatorID,
+		Action:     models.AuditModerationReportResolved,
+		TargetType: "user",
+		TargetID:   detail.Report.ReportedID.String(),
+		Changes:    models.FieldChanges{"actions": {After: actions}, "report_id": {After: detail.Report.ID}},
+	})
+
+	c.JSON(http.StatusOK, detail)
+}
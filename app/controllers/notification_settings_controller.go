@@ -9,10 +9,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"group1-userservice/app/apperrors"
 	"group1-userservice/app/interfaces"
 	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
 )
 
+var errSystemAlertsLocked = apperrors.BadRequest("system_alerts_locked", "system_alert settings cannot be modified")
+
 type NotificationSettingsController struct {
 	Service     interfaces.NotificationSettingsService
 	UserService interfaces.UserService
@@ -35,28 +40,28 @@ func NewNotificationSettingsController(
 // @Param X-Service-Token header string true "Service token"
 // @Param email path string true "User email"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /internal/users/{email}/notification-settings [get]
 func (nc *NotificationSettingsController) GetByEmailInternal(c *gin.Context) {
 	email := c.Param("email")
 	if email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing email"})
+		c.Error(errMissingEmail)
 		return
 	}
 
 	// Verify user exists
 	_, err := nc.UserService.GetByEmail(email)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
 	settings, err := nc.Service.GetByEmail(email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load settings"})
+		c.Error(err)
 		return
 	}
 
@@ -65,12 +70,58 @@ func (nc *NotificationSettingsController) GetByEmailInternal(c *gin.Context) {
 			"like":         gin.H{"email": settings.LikeEmail, "push": settings.LikePush},
 			"favorite":     gin.H{"email": settings.FavoriteEmail, "push": settings.FavoritePush},
 			"chat_message": gin.H{"email": settings.ChatEmail, "push": settings.ChatPush},
+			"connection":   gin.H{"email": settings.ConnectionEmail, "push": settings.ConnectionPush},
 			"system_alert": gin.H{"email": settings.SystemEmail, "push": settings.SystemPush},
 		},
 		"expo_push_token": settings.ExpoPushToken,
 	})
 }
 
+// mySettingsResponse is the body of GET and PUT /users/me/notification-settings
+func mySettingsResponse(s *models.NotificationSettings) gin.H {
+	return gin.H{
+		"settings": gin.H{
+			"like":         gin.H{"email": s.LikeEmail, "push": s.LikePush},
+			"favorite":     gin.H{"email": s.FavoriteEmail, "push": s.FavoritePush},
+			"chat_message": gin.H{"email": s.ChatEmail, "push": s.ChatPush},
+			"connection":   gin.H{"email": s.ConnectionEmail, "push": s.ConnectionPush},
+		},
+		"expo_push_token": s.ExpoPushToken,
+	}
+}
+
+// @Summary Get my notification settings
+// @Description Returns the notification settings of the authenticated user, with the settings version as ETag.
+// @Description Send the ETag in If-None-Match to get 304 when nothing changed.
+// @Tags Notifications
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} map[string]interface{}
+// @Success 304
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/notification-settings [get]
+func (nc *NotificationSettingsController) GetForMe(c *gin.Context) {
+	user, ok := currentUser(c, nc.UserService)
+	if !ok {
+		return
+	}
+
+	settings, err := nc.Service.GetByEmail(user.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if notModified(c, settings.Version) {
+		return
+	}
+
+	c.JSON(http.StatusOK, mySettingsResponse(settings))
+}
+
 // @Summary Update notification settings
 // @Description Update notification settings for the authenticated user.
 // System alerts cannot be modified and must not be included in the request body.
@@ -78,30 +129,32 @@ func (nc *NotificationSettingsController) GetByEmailInternal(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer access token"
+// @Param If-Match header string false "ETag from GET /users/me/notification-settings; 412 when the settings changed since"
 // @Param body body interfaces.NotificationSettingsPatchInput true "Updated settings"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 412 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /users/me/notification-settings [put]
 func (nc *NotificationSettingsController) UpdateForMe(c *gin.Context) {
 	sub, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(errUnauthorized)
 		return
 	}
 
 	user, err := nc.UserService.GetByKeycloakID(sub)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
 	// Read raw body once
 	bodyBytes, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		c.Error(errInvalidInput)
 		return
 	}
 
@@ -110,20 +163,26 @@ func (nc *NotificationSettingsController) UpdateForMe(c *gin.Context) {
 	dec := json.NewDecoder(bytes.NewReader(bodyBytes))
 	dec.DisallowUnknownFields()
 	if err := dec.Decode(&patch); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input", "details": err.Error()})
+		c.Error(invalidJSON(err))
 		return
 	}
 
 	// Reject system fields if present
 	if patch.SystemEmail != nil || patch.SystemPush != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "system_alert settings cannot be modified"})
+		c.Error(errSystemAlertsLocked)
 		return
 	}
 
 	// Load current settings
 	current, err := nc.Service.GetByEmail(user.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load settings"})
+		c.Error(err)
+		return
+	}
+	before := *current
+
+	version, ok := ifMatchVersion(c, current.Version)
+	if !ok {
 		return
 	}
 
@@ -146,23 +205,30 @@ func (nc *NotificationSettingsController) UpdateForMe(c *gin.Context) {
 	if patch.ChatPush != nil {
 		current.ChatPush = *patch.ChatPush
 	}
+	if patch.ConnectionEmail != nil {
+		current.ConnectionEmail = *patch.ConnectionEmail
+	}
+	if patch.ConnectionPush != nil {
+		current.ConnectionPush = *patch.ConnectionPush
+	}
 
 	if patch.ExpoPushToken != nil {
 		current.ExpoPushToken = strings.TrimSpace(*patch.ExpoPushToken)
 	}
 
-	updated, err := nc.Service.Upsert(current)
+	updated, err := nc.Service.UpdateIfVersion(current, version)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"settings": gin.H{
-			"like":         gin.H{"email": updated.LikeEmail, "push": updated.LikePush},
-			"favorite":     gin.H{"email": updated.FavoriteEmail, "push": updated.FavoritePush},
-			"chat_message": gin.H{"email": updated.ChatEmail, "push": updated.ChatPush},
-		},
-		"expo_push_token": updated.ExpoPushToken,
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditNotificationSettingsSet,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Changes:    service.DiffFields(before, *updated),
 	})
+
+	c.Header("ETag", etag(updated.Version))
+	c.JSON(http.StatusOK, mySettingsResponse(updated))
 }
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+)
+
+type OnboardingController struct {
+	Service     interfaces.OnboardingService
+	UserService interfaces.UserService
+}
+
+func NewOnboardingController(s interfaces.OnboardingService, us interfaces.UserService) *OnboardingController {
+	return &OnboardingController{Service: s, UserService: us}
+}
+
+// recordOnboardingAudit writes the step event, and the completion event when this step ended onboarding
+func recordOnboardingAudit(c *gin.Context, user models.User, action string, step string, state *models.OnboardingState) {
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     action,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Changes:    models.FieldChanges{"step": {After: step}},
+	})
+
+	if state.CurrentStep == "" && state.CompletedAt != nil {
+		for _, s := range state.Steps {
+			if string(s.Step) == step && s.FinishedAt != nil && s.FinishedAt.Equal(*state.CompletedAt) {
+				middleware.RecordAudit(c, models.AuditEvent{
+					Action:     models.AuditOnboardingCompleted,
+					TargetType: "user",
+					TargetID:   user.ID.String(),
+				})
+			}
+		}
+	}
+}
+
+// @Summary Get my onboarding
+// @Description Returns the onboarding steps in order with their status, and the step to take now (current_step).
+// @Description Starts onboarding on first use. Steps: verify_email, basic_profile, photo, interests, discovery_radius, notification_consent.
+// @Tags Onboarding
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Success 200 {object} models.OnboardingState
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/onboarding [get]
+func (oc *OnboardingController) GetForMe(c *gin.Context) {
+	user, ok := currentUser(c, oc.UserService)
+	if !ok {
+		return
+	}
+
+	state, err := oc.Service.Get(user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// @Summary Complete an onboarding step
+// @Description Completes the current step once it is done: basic_profile needs first name, last name and country,
+// @Description photo a profile photo, interests at least one interest, discovery_radius saved preferences.
+// @Description notification_consent needs {"granted": true|false}. Completing a completed step again is a no-op.
+// @Tags Onboarding
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param step path string true "Step"
+// @Param body body interfaces.OnboardingStepInput false "Only for notification_consent"
+// @Success 200 {object} models.OnboardingState
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Not the current step, or not done yet"
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/onboarding/steps/{step}/complete [post]
+func (oc *OnboardingController) CompleteStep(c *gin.Context) {
+	user, ok := currentUser(c, oc.UserService)
+	if !ok {
+		return
+	}
+
+	var input interfaces.OnboardingStepInput
+	if c.Request.ContentLength != 0 && !decodeStrict(c, &input) {
+		return
+	}
+
+	step := c.Param("step")
+	state, err := oc.Service.Complete(user, models.OnboardingStep(step), input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordOnboardingAudit(c, user, models.AuditOnboardingStepCompleted, step, state)
+	c.JSON(http.StatusOK, state)
+}
+
+// @Summary Skip an onboarding step
+// @Description Skips the current step. Only photo, interests, discovery_radius and notification_consent can be skipped.
+// @Tags Onboarding
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param step path string true "Step"
+// @Success 200 {object} models.OnboardingState
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Not the current step, or not skippable"
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/onboarding/steps/{step}/skip [post]
+func (oc *OnboardingController) SkipStep(c *gin.Context) {
+	user, ok := currentUser(c, oc.UserService)
+	if !ok {
+		return
+	}
+
+	step := c.Param("step")
+	state, err := oc.Service.Skip(user, models.OnboardingStep(step))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordOnboardingAudit(c, user, models.AuditOnboardingStepSkipped, step, state)
+	c.JSON(http.StatusOK, state)
+}
@@ -1,32 +1,41 @@
 package controller
 
 import (
-	"bytes"
-	"encoding/json"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/i18n"
 	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/notifications"
 
 	"github.com/gin-gonic/gin"
 
 	"group1-userservice/app/metrics"
 )
 
+var errResetFieldsRequired = apperrors.BadRequest("reset_fields_required", "token and new_password are required")
+
 type PasswordResetController struct {
 	Service         interfaces.PasswordResetService
+	UserService     interfaces.UserService
 	NotificationURL string
+	Notifier        interfaces.NotificationSender
 }
 
 func NewPasswordResetController(
 	s interfaces.PasswordResetService,
+	us interfaces.UserService,
 	notificationURL string,
 ) *PasswordResetController {
 	return &PasswordResetController{
 		Service:         s,
+		UserService:     us,
 		NotificationURL: notificationURL,
+		Notifier:        notifications.NewClient(notificationURL),
 	}
 }
 
@@ -34,59 +43,17 @@ type ForgotPasswordRequest struct {
 	Email string `json:"email"`
 }
 
-func (pc *PasswordResetController) sendPasswordResetAlert(email string, token string) {
-	if pc.NotificationURL == "" {
-		log.Println("[notification] NOTIFICATION_SERVICE_URL is empty, skipping")
-		return
-	}
-
-	metrics.NotificationCallsTotal.WithLabelValues("attempt").Inc()
-
-	payload := map[string]interface{}{
-		"email":     email,
-		"message":   "A password reset was requested for your account. If this was you, please follow the reset instructions.",
-		"title":     "Password reset requested",
-		"type":      "system_alert",
-		"token":     token,
-		"timestamp": time.Now().Format(time.RFC3339),
-	}
-
-	body, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("[notification] marshal error: %v\n", err)
-		return
-	}
-
-	req, err := http.NewRequest("POST", pc.NotificationURL, bytes.NewBuffer(body))
-	if err != nil {
-		log.Printf("[notification] request error for %s: %v\n", pc.NotificationURL, err)
-		return
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	// Service-to-service authentication
-	serviceToken := os.Getenv("NOTIFICATION_SERVICE_TOKEN")
-	if serviceToken != "" {
-		req.Header.Set("X-Service-Token", serviceToken)
-	}
-
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
+func (pc *PasswordResetController) sendPasswordResetAlert(email string, token string, l i18n.Locale) {
+	err := pc.Notifier.Send(models.Notification{
+		Email:   email,
+		Message: i18n.T(l, "notification.password_reset.message"),
+		Title:   i18n.T(l, "notification.password_reset.title"),
+		Type:    "system_alert",
+		Token:   token,
+	})
 	if err != nil {
-		metrics.NotificationCallsTotal.WithLabelValues("error").Inc()
-		log.Printf("[notification] http error calling %s: %v\n", pc.NotificationURL, err)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		metrics.NotificationCallsTotal.WithLabelValues("success").Inc()
-	} else {
-		metrics.NotificationCallsTotal.WithLabelValues("failed").Inc()
+		log.Printf("[notification] password reset alert failed: %v\n", err)
 	}
-
-	log.Printf("[notification] called %s -> status %d\n", pc.NotificationURL, resp.StatusCode)
 }
 
 // Forgot
@@ -98,7 +65,7 @@ func (pc *PasswordResetController) sendPasswordResetAlert(email string, token st
 // @Produce json
 // @Param request body controller.ForgotPasswordRequest true "Forgot password request"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
 // @Router /auth/forgot-password [post]
 func (pc *PasswordResetController) Forgot(c *gin.Context) {
 	start := time.Now()
@@ -111,7 +78,7 @@ func (pc *PasswordResetController) Forgot(c *gin.Context) {
 	var req ForgotPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil || req.Email == "" {
 		metrics.UserRequestOutcomesTotal.WithLabelValues("bad_request").Inc()
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+		c.Error(errEmailRequired)
 		return
 	}
 
@@ -120,15 +87,21 @@ func (pc *PasswordResetController) Forgot(c *gin.Context) {
 	// Request password reset token from the service layer
 	token, _ := pc.Service.RequestReset(req.Email)
 
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditPasswordResetRequested,
+		TargetType: "email",
+		TargetID:   req.Email,
+	})
+
 	// Send notification asynchronously only when a token is created
 	if token != "" {
-		go pc.sendPasswordResetAlert(req.Email, token)
+		go pc.sendPasswordResetAlert(req.Email, token, accountLocale(c, pc.UserService, req.Email))
 	}
 
 	metrics.UserRequestOutcomesTotal.WithLabelValues("success").Inc()
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "if the email exists, a reset link will be sent",
+		"message": message(c, "message.password_reset_requested"),
 	})
 }
 
@@ -145,20 +118,27 @@ type ResetPasswordRequest struct {
 // @Produce json
 // @Param request body controller.ResetPasswordRequest true "Reset password request"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
 // @Router /auth/reset-password [post]
 func (pc *PasswordResetController) Reset(c *gin.Context) {
 	var req ResetPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" || req.NewPassword == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "token and new_password are required"})
+		c.Error(errResetFieldsRequired)
 		return
 	}
 
-	if err := pc.Service.ResetPassword(req.Token, req.NewPassword); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	email, err := pc.Service.ResetPassword(req.Token, req.NewPassword)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "password updated"})
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditPasswordResetCompleted,
+		TargetType: "email",
+		TargetID:   email,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": message(c, "message.password_updated")})
 }
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+)
+
+type ProfileCompletenessController struct {
+	Service     interfaces.ProfileCompletenessService
+	UserService interfaces.UserService
+}
+
+func NewProfileCompletenessController(s interfaces.ProfileCompletenessService, us interfaces.UserService) *ProfileCompletenessController {
+	return &ProfileCompletenessController{Service: s, UserService: us}
+}
+
+func (pc *ProfileCompletenessController) respond(c *gin.Context, user models.User, l i18n.Locale) {
+	result, err := pc.Service.ForUser(user, l)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Get my profile completeness
+// @Description Returns a weighted percentage, the parts of the profile that are missing or weak, and up to three next steps.
+// @Description Weak parts (e.g. a short biography) count for half. complete is true when every required field is filled in.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Success 200 {object} models.ProfileCompleteness
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/profile/completeness [get]
+func (pc *ProfileCompletenessController) GetForMe(c *gin.Context) {
+	user, ok := currentUser(c, pc.UserService)
+	if !ok {
+		return
+	}
+	pc.respond(c, user, middleware.GetLocale(c))
+}
+
+// @Summary Get the profile completeness of a user (internal)
+// @Description Same result as GET /users/me/profile/completeness, so the feed service can nudge users.
+// @Tags Internal
+// @Produce json
+// @Param X-Service-Token header string true "Service token"
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} models.ProfileCompleteness
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /internal/users/id/{id}/profile-completeness [get]
+func (pc *ProfileCompletenessController) GetByIDInternal(c *gin.Context) {
+	id, ok := targetID(c)
+	if !ok {
+		return
+	}
+
+	user, err := pc.UserService.GetByID(id)
+	if err != nil {
+		c.Error(service.ErrUserNotFound)
+		return
+	}
+	// the texts are shown to the user, not to the calling service
+	pc.respond(c, user, i18n.Preferred(user.PreferredLanguage, middleware.GetLocale(c)))
+}
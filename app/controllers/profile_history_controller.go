@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+)
+
+var errInvalidVersion = apperrors.BadRequest("invalid_version", "invalid version")
+
+type ProfileHistoryController struct {
+	Service      interfaces.ProfileHistoryService
+	UserService  interfaces.UserService
+	BadgeService interfaces.UserBadgeService
+}
+
+func NewProfileHistoryController(
+	s interfaces.ProfileHistoryService,
+	us interfaces.UserService,
+	bs interfaces.UserBadgeService,
+) *ProfileHistoryController {
+	return &ProfileHistoryController{
+		Service:      s,
+		UserService:  us,
+		BadgeService: bs,
+	}
+}
+
+// @Summary Get my profile history
+// @Description Returns the versions of the logged-in user's profile, newest first.
+// @Description Every profile update and photo upload adds a version; `changes` lists the fields that differ from the previous version.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} interfaces.ProfileHistoryPage
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/profile/history [get]
+func (hc *ProfileHistoryController) ListForMe(c *gin.Context) {
+	user, ok := currentUser(c, hc.UserService)
+	if !ok {
+		return
+	}
+
+	page, err := hc.Service.History(user.ID, pagination(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Restore a profile version
+// @Description Writes the profile fields of an earlier version back to the logged-in user's profile.
+// @Description The restore is recorded as a new version, so it can be undone the same way.
+// @Description The old values go through the same validation as a profile update.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param version path int true "Version number"
+// @Success 200 {object} models.User
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/profile/history/{version}/restore [post]
+func (hc *ProfileHistoryController) RestoreForMe(c *gin.Context) {
+	user, ok := currentUser(c, hc.UserService)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.Error(errInvalidVersion)
+		return
+	}
+
+	restored, err := hc.Service.Restore(user, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditProfileRestored,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Changes:    service.DiffFields(user, restored),
+	})
+
+	if err := service.SyncProfileCompleteBadge(hc.BadgeService, restored); err != nil {
+		fmt.Println("WARN: failed to update complete_profile badge:", err)
+	}
+
+	restored.Password = ""
+	c.JSON(http.StatusOK, restored)
+}
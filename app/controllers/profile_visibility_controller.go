@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+)
+
+type ProfileVisibilityController struct {
+	Service     interfaces.ProfileVisibilityService
+	UserService interfaces.UserService
+}
+
+func NewProfileVisibilityController(
+	s interfaces.ProfileVisibilityService,
+	us interfaces.UserService,
+) *ProfileVisibilityController {
+	return &ProfileVisibilityController{
+		Service:     s,
+		UserService: us,
+	}
+}
+
+// @Summary Get my privacy settings
+// @Description Returns who may see each profile field: public, connections or private.
+// @Description Names and the profile photo are always visible.
+// @Tags Privacy
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Success 200 {object} models.ProfileVisibility
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/privacy [get]
+func (pc *ProfileVisibilityController) GetForMe(c *gin.Context) {
+	sub, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	user, err := pc.UserService.GetByKeycloakID(sub)
+	if err != nil {
+		c.Error(service.ErrUserNotFound)
+		return
+	}
+
+	settings, err := pc.Service.GetForUser(user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// @Summary Update my privacy settings
+// @Description Updates the visibility of the given profile fields. Fields that are left out keep their current value.
+// @Description Allowed values: public, connections, private.
+// @Tags Privacy
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param body body interfaces.ProfileVisibilityPatchInput true "Updated privacy settings"
+// @Success 200 {object} models.ProfileVisibility
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/privacy [put]
+func (pc *ProfileVisibilityController) UpdateForMe(c *gin.Context) {
+	sub, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	user, err := pc.UserService.GetByKeycloakID(sub)
+	if err != nil {
+		c.Error(service.ErrUserNotFound)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	// Strict decode: typos / unknown fields / type errors => 400
+	var patch interfaces.ProfileVisibilityPatchInput
+	dec := json.NewDecoder(bytes.NewReader(bodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patch); err != nil {
+		c.Error(invalidJSON(err))
+		return
+	}
+
+	before, _ := pc.Service.GetForUser(user)
+
+	updated, err := pc.Service.UpdateForUser(user, patch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditPrivacySettingsSet,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Changes:    service.DiffFields(before, updated),
+	})
+
+	c.JSON(http.StatusOK, updated)
+}
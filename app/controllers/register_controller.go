@@ -1,8 +1,14 @@
 package controller
 
 import (
+	"errors"
+	"log"
+
+	"group1-userservice/app/apperrors"
 	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
 	"group1-userservice/app/models"
+	"group1-userservice/app/service"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,22 +17,35 @@ import (
 )
 
 type RegisterController struct {
-	UserService interfaces.UserService
+	UserService  interfaces.UserService
+	Verification interfaces.EmailVerificationService
 }
 
-func NewRegisterController(us interfaces.UserService) *RegisterController {
+func NewRegisterController(us interfaces.UserService, verification interfaces.EmailVerificationService) *RegisterController {
 	return &RegisterController{
-		UserService: us,
+		UserService:  us,
+		Verification: verification,
+	}
+}
+
+func (rc *RegisterController) sendVerification(user models.User) {
+	if err := rc.Verification.Send(user); err != nil {
+		log.Printf("[verification] email for new user %s failed: %v\n", user.ID, err)
 	}
 }
 
 // @Summary Register a new user
-// @Description Create a new user in the database and Keycloak
+// @Description Create a new user in the database and Keycloak.
+// @Description An optional `handle` can be chosen; without one a handle is generated from the name.
+// @Description The account starts unverified: a verification link is emailed and most routes stay closed until it is used.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param request body models.User true "User info"
 // @Success 201 {object} models.User
+// @Failure 400 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
 // @Router /users/register [post]
 func (rc *RegisterController) Handle(c *gin.Context) {
 	start := time.Now()
@@ -40,24 +59,38 @@ func (rc *RegisterController) Handle(c *gin.Context) {
 
 	if err := c.BindJSON(&user); err != nil {
 		metrics.UserRequestOutcomesTotal.WithLabelValues("bad_request").Inc()
-		c.JSON(400, gin.H{"error": "Invalid input"})
+		c.Error(errInvalidInput)
 		return
 	}
 
 	if err := rc.UserService.Register(&user); err != nil {
-		if err.Error() == "email already exists" {
-			metrics.UserRequestOutcomesTotal.WithLabelValues("conflict").Inc()
-			c.JSON(409, gin.H{"error": "Email already in use"})
-			return
+		outcome := "error"
+		switch {
+		case errors.Is(err, apperrors.ErrConflict):
+			outcome = "conflict"
+		case errors.Is(err, apperrors.ErrValidation), errors.Is(err, apperrors.ErrBadRequest):
+			outcome = "bad_request"
 		}
-
-		metrics.UserRequestOutcomesTotal.WithLabelValues("error").Inc()
-		c.JSON(500, gin.H{"error": err.Error()})
+		metrics.UserRequestOutcomesTotal.WithLabelValues(outcome).Inc()
+		c.Error(err)
 		return
 	}
 
 	metrics.UserRequestOutcomesTotal.WithLabelValues("success").Inc()
 
 	user.Password = ""
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		ActorType:  models.AuditActorUser,
+		ActorID:    user.KeycloakID,
+		Action:     models.AuditUserRegistered,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Changes:    service.DiffFields(nil, user),
+	})
+
+	// Send the verification email asynchronously; the user can ask for a new one when it fails
+	go rc.sendVerification(user)
+
 	c.JSON(201, user)
 }
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errSocialTokenRequired = apperrors.BadRequest("social_token_required", "provider and id_token are required")
+	errSocialTokenInvalid  = apperrors.Unauthorized("invalid_token", "invalid or expired id_token")
+)
+
+type SocialLoginController struct {
+	Service      interfaces.SocialLoginService
+	TwoFactor    interfaces.TwoFactorService
+	Verification interfaces.EmailVerificationService
+}
+
+func NewSocialLoginController(
+	s interfaces.SocialLoginService,
+	tf interfaces.TwoFactorService,
+	verification interfaces.EmailVerificationService,
+) *SocialLoginController {
+	return &SocialLoginController{Service: s, TwoFactor: tf, Verification: verification}
+}
+
+type SocialLoginRequest struct {
+	// Alias of the identity provider in Keycloak, e.g. "google" or "apple"
+	Provider string `json:"provider"`
+	// ID token the app got from the provider
+	IDToken string `json:"id_token"`
+}
+
+func (sc *SocialLoginController) sendVerification(user models.User) {
+	if err := sc.Verification.Send(user); err != nil {
+		log.Printf("[verification] email for new social user %s failed: %v\n", user.ID, err)
+	}
+}
+
+// Handle
+// @Summary Log in with Google or Apple
+// @Description Exchanges the ID token of a social provider for tokens through Keycloak.
+// @Description The first login creates the account with default settings, or links it to an existing account when the provider verified the same email.
+// @Description With two-factor authentication enabled a challenge is returned instead; exchange it at /auth/login/2fa.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body controller.SocialLoginRequest true "Social login"
+// @Success 200 {object} interfaces.TokenResponse
+// @Success 202 {object} controller.LoginChallengeResponse "Two-factor code required"
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem "Account blocked"
+// @Failure 409 {object} middleware.Problem "Email in use by an account that cannot be linked"
+// @Failure 501 {object} middleware.Problem "Identity provider has no social login"
+// @Failure 503 {object} middleware.Problem
+// @Router /auth/social [post]
+func (sc *SocialLoginController) Handle(c *gin.Context) {
+	var req SocialLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Provider == "" || req.IDToken == "" {
+		c.Error(errSocialTokenRequired)
+		return
+	}
+
+	result, err := sc.Service.Login(req.Provider, req.IDToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSocialProviderUnknown),
+			errors.Is(err, service.ErrSocialEmailMissing),
+			errors.Is(err, service.ErrSocialEmailUnverified),
+			errors.Is(err, service.ErrSocialAccountUnverified),
+			errors.Is(err, interfaces.ErrSocialLoginDisabled):
+			// Rendered as they are
+		case errors.Is(err, interfaces.ErrInvalidToken):
+			err = errSocialTokenInvalid
+		case errors.Is(err, service.ErrAccountBlocked):
+			auditLoginFailed(c, result.User.Email, "account_blocked")
+		case errors.Is(err, interfaces.ErrEmailAlreadyExists):
+			err = service.ErrSocialEmailUnverified
+		default:
+			log.Printf("[social] login with %s failed: %v\n", req.Provider, err)
+			err = errIdentityUnavailable
+		}
+		c.Error(err)
+		return
+	}
+
+	user := result.User
+	method := models.FieldChanges{"method": {After: "social"}, "provider": {After: req.Provider}}
+
+	if result.Created {
+		middleware.RecordAudit(c, models.AuditEvent{
+			ActorType:  models.AuditActorUser,
+			ActorID:    user.KeycloakID,
+			Action:     models.AuditUserRegistered,
+			TargetType: "user",
+			TargetID:   user.ID.String(),
+			Changes:    service.DiffFields(nil, user),
+		})
+
+		// Providers do not always vouch for the email; then it is verified like after a normal registration
+		if user.EmailVerifiedAt == nil {
+			go sc.sendVerification(user)
+		}
+	}
+	if result.Linked {
+		middleware.RecordAudit(c, models.AuditEvent{
+			ActorType:  models.AuditActorUser,
+			ActorID:    user.KeycloakID,
+			Action:     models.AuditSocialIdentityLinked,
+			TargetType: "user",
+			TargetID:   user.ID.String(),
+			Changes:    models.FieldChanges{"provider": {After: req.Provider}},
+		})
+	}
+
+	// A social login replaces the password, not the second factor
+	enabled, err := sc.TwoFactor.Enabled(user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if enabled {
+		challenge, expiresIn, err := sc.TwoFactor.StartLogin(user, result.Token.RefreshToken)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		middleware.RecordAudit(c, models.AuditEvent{
+			ActorType:  models.AuditActorUser,
+			ActorID:    user.KeycloakID,
+			Action:     models.AuditLoginTwoFactorRequired,
+			TargetType: "user",
+			TargetID:   user.ID.String(),
+			Changes:    method,
+		})
+
+		c.JSON(http.StatusAccepted, LoginChallengeResponse{TwoFactorRequired: true, ChallengeToken: challenge, ExpiresIn: expiresIn})
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		ActorType:  models.AuditActorUser,
+		ActorID:    user.KeycloakID,
+		Action:     models.AuditLoginSucceeded,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Changes:    method,
+	})
+
+	c.JSON(http.StatusOK, result.Token)
+}
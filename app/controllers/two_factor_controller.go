@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+)
+
+type TwoFactorController struct {
+	Service     interfaces.TwoFactorService
+	UserService interfaces.UserService
+}
+
+func NewTwoFactorController(s interfaces.TwoFactorService, us interfaces.UserService) *TwoFactorController {
+	return &TwoFactorController{Service: s, UserService: us}
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// @Summary Get my two-factor status
+// @Description Returns whether TOTP two-factor authentication is enabled and how many recovery codes are left.
+// @Tags Two-factor
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Success 200 {object} interfaces.TwoFactorStatus
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/2fa [get]
+func (tc *TwoFactorController) GetForMe(c *gin.Context) {
+	user, ok := currentUser(c, tc.UserService)
+	if !ok {
+		return
+	}
+
+	status, err := tc.Service.Status(user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// @Summary Start two-factor enrollment
+// @Description Creates a new TOTP secret and returns it with an otpauth:// provisioning URI for authenticator apps.
+// @Description Two-factor authentication is enabled once the first code is confirmed. Starting again replaces a pending secret.
+// @Tags Two-factor
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Success 200 {object} interfaces.TwoFactorEnrollment
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Already enabled"
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/2fa/enroll [post]
+func (tc *TwoFactorController) Enroll(c *gin.Context) {
+	user, ok := currentUser(c, tc.UserService)
+	if !ok {
+		return
+	}
+
+	enrollment, err := tc.Service.Enroll(user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// @Summary Confirm two-factor enrollment
+// @Description Enables two-factor authentication with a first code from the authenticator app.
+// @Description Returns ten one-time recovery codes; they are shown only this once.
+// @Tags Two-factor
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param body body interfaces.TwoFactorCodeInput true "TOTP code"
+// @Success 200 {object} controller.RecoveryCodesResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem "Invalid code"
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Not enrolled or already enabled"
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/2fa/confirm [post]
+func (tc *TwoFactorController) Confirm(c *gin.Context) {
+	user, ok := currentUser(c, tc.UserService)
+	if !ok {
+		return
+	}
+
+	var input interfaces.TwoFactorCodeInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	codes, err := tc.Service.Confirm(user, input.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditTwoFactorEnabled,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+	})
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Disable two-factor authentication
+// @Description Requires the password and a TOTP code or recovery code. Removes the secret and all recovery codes.
+// @Tags Two-factor
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param body body interfaces.TwoFactorReauthInput true "Password and code"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem "Invalid password or code"
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Not enabled"
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/2fa/disable [post]
+func (tc *TwoFactorController) Disable(c *gin.Context) {
+	user, ok := currentUser(c, tc.UserService)
+	if !ok {
+		return
+	}
+
+	var input interfaces.TwoFactorReauthInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	if err := tc.Service.Disable(user, input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditTwoFactorDisabled,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": message(c, "message.two_factor_disabled")})
+}
+
+// @Summary Regenerate recovery codes
+// @Description Requires the password and a TOTP code or recovery code. Replaces all recovery codes with ten new ones.
+// @Tags Two-factor
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param body body interfaces.TwoFactorReauthInput true "Password and code"
+// @Success 200 {object} controller.RecoveryCodesResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem "Invalid password or code"
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Not enabled"
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/2fa/recovery-codes [post]
+func (tc *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := currentUser(c, tc.UserService)
+	if !ok {
+		return
+	}
+
+	var input interfaces.TwoFactorReauthInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	codes, err := tc.Service.RegenerateRecoveryCodes(user, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditRecoveryCodesRegenerated,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+	})
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
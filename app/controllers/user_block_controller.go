@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+)
+
+type UserBlockController struct {
+	Service     interfaces.UserBlockService
+	UserService interfaces.UserService
+}
+
+func NewUserBlockController(
+	s interfaces.UserBlockService,
+	us interfaces.UserService,
+) *UserBlockController {
+	return &UserBlockController{
+		Service:     s,
+		UserService: us,
+	}
+}
+
+func (bc *UserBlockController) add(c *gin.Context, kind models.BlockKind) {
+	user, ok := currentUser(c, bc.UserService)
+	if !ok {
+		return
+	}
+	id, ok := targetID(c)
+	if !ok {
+		return
+	}
+
+	block, err := bc.Service.Add(user, id, kind)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, block)
+}
+
+func (bc *UserBlockController) remove(c *gin.Context, kind models.BlockKind) {
+	user, ok := currentUser(c, bc.UserService)
+	if !ok {
+		return
+	}
+	id, ok := targetID(c)
+	if !ok {
+		return
+	}
+
+	if err := bc.Service.Remove(user, id, kind); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (bc *UserBlockController) list(c *gin.Context, kind models.BlockKind) {
+	user, ok := currentUser(c, bc.UserService)
+	if !ok {
+		return
+	}
+
+	result, err := bc.Service.List(user.ID, kind, pagination(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Block a user
+// @Description Blocks another user. Blocked users cannot find or view each other and cannot connect.
+// @Description Existing follows, requests and connections between both users are removed.
+// @Tags Blocks
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} models.UserBlock
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/blocks/{id} [post]
+func (bc *UserBlockController) Block(c *gin.Context) {
+	bc.add(c, models.BlockKindBlock)
+}
+
+// @Summary Unblock a user
+// @Tags Blocks
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path string true "User ID (UUID)"
+// @Success 204
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/blocks/{id} [delete]
+func (bc *UserBlockController) Unblock(c *gin.Context) {
+	bc.remove(c, models.BlockKindBlock)
+}
+
+// @Summary List users I blocked
+// @Tags Blocks
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} interfaces.ConnectionPage
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/blocks [get]
+func (bc *UserBlockController) ListBlocks(c *gin.Context) {
+	bc.list(c, models.BlockKindBlock)
+}
+
+// @Summary Mute a user
+// @Description Mutes another user. Muted users stay visible, but their activity is hidden from you
+// @Description and they do not trigger connection notifications.
+// @Tags Blocks
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} models.UserBlock
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/mutes/{id} [post]
+func (bc *UserBlockController) Mute(c *gin.Context) {
+	bc.add(c, models.BlockKindMute)
+}
+
+// @Summary Unmute a user
+// @Tags Blocks
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path string true "User ID (UUID)"
+// @Success 204
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/mutes/{id} [delete]
+func (bc *UserBlockController) Unmute(c *gin.Context) {
+	bc.remove(c, models.BlockKindMute)
+}
+
+// @Summary List users I muted
+// @Tags Blocks
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} interfaces.ConnectionPage
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/me/mutes [get]
+func (bc *UserBlockController) ListMutes(c *gin.Context) {
+	bc.list(c, models.BlockKindMute)
+}
+
+// @Summary Get the block list of a user (internal)
+// @Description Returns who the user blocked, who blocked the user and who the user muted.
+// @Description Chat and feed services use this to filter content in both directions.
+// @Tags Internal
+// @Produce json
+// @Param X-Service-Token header string true "Service token"
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} interfaces.UserBlockList
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /internal/users/id/{id}/blocks [get]
+func (bc *UserBlockController) GetBlockListInternal(c *gin.Context) {
+	id, ok := targetID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := bc.UserService.GetByID(id); err != nil {
+		c.Error(service.ErrUserNotFound)
+		return
+	}
+
+	list, err := bc.Service.BlockList(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
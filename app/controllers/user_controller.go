@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net/http"
 
+	"group1-userservice/app/apperrors"
 	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
 	"group1-userservice/app/models"
 	"group1-userservice/app/service"
 	"group1-userservice/app/storage"
@@ -15,9 +17,20 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	errMissingName      = apperrors.BadRequest("missing_name", "missing firstname or lastname")
+	errMissingSubject   = apperrors.BadRequest("missing_subject", "missing Keycloak sub")
+	errMissingHandle    = apperrors.BadRequest("missing_handle", "handle is required")
+	errNoProfilePhoto   = apperrors.NotFound("profile_photo_not_found", "no profile photo")
+	errPhotoMissing     = apperrors.BadRequest("photo_missing", "missing file field 'file'")
+	errPhotoTooLarge    = apperrors.BadRequest("photo_too_large", "file too large (max 5MB)")
+	errPhotoContentType = apperrors.BadRequest("photo_content_type", "unsupported content type, use jpeg, png or webp")
+)
+
 type UserController struct {
 	UserService  interfaces.UserService
 	BadgeService interfaces.UserBadgeService
+	ProfileViews interfaces.ProfileViewService
 }
 
 type UserPublicInfoResponse struct {
@@ -35,15 +48,21 @@ type PresignProfilePhotoGetResponse struct {
 	URL string `json:"url"`
 }
 
-func NewUserController(us interfaces.UserService, bs interfaces.UserBadgeService) *UserController {
+func NewUserController(
+	us interfaces.UserService,
+	bs interfaces.UserBadgeService,
+	pv interfaces.ProfileViewService,
+) *UserController {
 	return &UserController{
 		UserService:  us,
 		BadgeService: bs,
+		ProfileViews: pv,
 	}
 }
 
 // @Summary Get user email by firstname-lastname
 // @Description Returns only first_name, last_name and email for the matched user. Requires Bearer access token.
+// @Description The email is omitted when the user does not share it with the caller (see /users/me/privacy).
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -51,27 +70,28 @@ func NewUserController(us interfaces.UserService, bs interfaces.UserBadgeService
 // @Param firstname path string true "First name"
 // @Param lastname path string true "Last name"
 // @Success 200 {object} controller.UserPublicInfoResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /users/{firstname}/{lastname} [get]
 func (uc *UserController) GetByFirstLast(c *gin.Context) {
 	first := c.Param("firstname")
 	last := c.Param("lastname")
 
 	if first == "" || last == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing firstname or lastname"})
+		c.Error(errMissingName)
 		return
 	}
 
-	info, err := uc.UserService.GetPublicInfoByFirstLast(first, last)
+	viewerSub, _ := middleware.GetUserID(c)
+
+	info, err := uc.ProfileViews.PublicInfoByFirstLast(viewerSub, first, last)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, info)
-
 }
 
 // @Summary Get user by email (internal)
@@ -81,21 +101,21 @@ func (uc *UserController) GetByFirstLast(c *gin.Context) {
 // @Param X-Service-Token header string true "Service token"
 // @Param email path string true "User email"
 // @Success 200 {object} models.User
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /users/{email} [get]
 func (uc *UserController) GetByEmail(c *gin.Context) {
 	email := c.Param("email")
 
 	if email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing email"})
+		c.Error(errMissingEmail)
 		return
 	}
 
 	user, err := uc.UserService.GetByEmail(email)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
@@ -104,25 +124,43 @@ func (uc *UserController) GetByEmail(c *gin.Context) {
 }
 
 // @Summary Get user by Keycloak subject
-// @Description Get a single user by their Keycloak subject (sub) ID
+// @Description Get a single user by their Keycloak subject (sub) ID.
+// @Description Requires either X-Service-Token, or a Bearer access token for the same subject.
 // @Tags Users
 // @Produce json
+// @Param Authorization header string false "Bearer access token"
+// @Param X-Service-Token header string false "Service token"
 // @Param sub path string true "Keycloak subject ID"
 // @Success 200 {object} models.User
-// @Failure 400 {object} map[string]string "Missing Keycloak sub"
-// @Failure 404 {object} map[string]string "User not found"
+// @Failure 400 {object} middleware.Problem "Missing Keycloak sub"
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 403 {object} middleware.Problem "Not allowed to read another user"
+// @Failure 404 {object} middleware.Problem "User not found"
 // @Router /users/keycloak/{sub} [get]
 func (uc *UserController) GetByKeycloakSub(c *gin.Context) {
 	sub := c.Param("sub")
 
 	if sub == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing Keycloak sub"})
+		c.Error(errMissingSubject)
 		return
 	}
 
+	// Users may only read their own full record; services may read any
+	if !middleware.IsServiceRequest(c) {
+		callerSub, ok := middleware.GetUserID(c)
+		if !ok || callerSub == "" {
+			c.Error(errUnauthorized)
+			return
+		}
+		if callerSub != sub {
+			c.Error(errForbidden)
+			return
+		}
+	}
+
 	user, err := uc.UserService.GetByKeycloakID(sub)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
@@ -130,53 +168,158 @@ func (uc *UserController) GetByKeycloakSub(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-// @Summary Update my user profile
-// @Description Update logged-in user's profile data
+// @Summary Get public profile by user ID
+// @Description Returns the profile of a user as the caller is allowed to see it.
+// @Description Fields are omitted according to the user's privacy settings (see /users/me/privacy).
+// @Description The phone number is only included when the user enabled phone_number_visible.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} models.UserPublicProfile
+// @Failure 400 {object} middleware.Problem "Invalid user ID"
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 404 {object} middleware.Problem "User not found"
+// @Failure 500 {object} middleware.Problem "Could not load profile"
+// @Router /users/id/{id}/profile [get]
+func (uc *UserController) GetPublicProfileByID(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errInvalidUserID)
+		return
+	}
+
+	viewerSub, _ := middleware.GetUserID(c)
+
+	profile, err := uc.ProfileViews.PublicProfile(viewerSub, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	localizePublicBadges(c, profile.Badges)
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// @Summary Get my user profile
+// @Description Returns the logged-in user's own profile. The ETag header carries the profile version;
+// @Description send it back in If-None-Match to get 304 when nothing changed, or in If-Match on PUT/PATCH.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} models.User
+// @Success 304
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /users/me [get]
+func (uc *UserController) GetMe(c *gin.Context) {
+	user, ok := currentUser(c, uc.UserService)
+	if !ok {
+		return
+	}
+
+	if notModified(c, user.Version) {
+		return
+	}
+
+	user.Password = ""
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Replace my user profile
+// @Description Replaces the logged-in user's profile data. Fields that are omitted are cleared;
+// @Description first_name and last_name are required. Use PATCH to change single fields.
+// @Description Values are trimmed, phone numbers normalized to E.164 and countries to ISO 3166 alpha-2 codes.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer access token"
-// @Param request body models.User true "Updated user fields"
+// @Param If-Match header string false "ETag from GET /users/me; 412 when the profile changed since"
+// @Param request body models.UserUpdateInput true "Complete profile"
 // @Success 200 {object} models.User
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 412 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
 // @Router /users/me [put]
 func (uc *UserController) UpdateMe(c *gin.Context) {
-	userIDAny, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	user, ok := currentUser(c, uc.UserService)
+	if !ok {
 		return
 	}
-
-	userID, ok := userIDAny.(string)
-	if !ok || userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	version, ok := ifMatchVersion(c, user.Version)
+	if !ok {
 		return
 	}
 
 	var input models.UserUpdateInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		c.Error(errInvalidInput)
 		return
 	}
 
-	user, err := uc.UserService.GetByKeycloakID(userID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	updated, err := uc.UserService.ReplaceByEmail(user.Email, &input, version)
+	uc.respondProfileUpdate(c, user, updated, err)
+}
+
+// @Summary Patch my user profile
+// @Description Applies a JSON Merge Patch (RFC 7396) to the logged-in user's profile.
+// @Description Only the fields in the body change; an explicit null clears a field. Unknown fields are rejected.
+// @Description first_name and last_name cannot be cleared.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param If-Match header string false "ETag from GET /users/me; 412 when the profile changed since"
+// @Param request body models.UserProfilePatch true "Fields to change"
+// @Success 200 {object} models.User
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 412 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Router /users/me [patch]
+func (uc *UserController) PatchMe(c *gin.Context) {
+	user, ok := currentUser(c, uc.UserService)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c, user.Version)
+	if !ok {
+		return
+	}
+
+	var patch models.UserProfilePatch
+	if !decodeStrict(c, &patch) {
 		return
 	}
 
-	updated, err := uc.UserService.UpdateByEmail(user.Email, &input)
+	updated, err := uc.UserService.PatchByEmail(user.Email, &patch, version)
+	uc.respondProfileUpdate(c, user, updated, err)
+}
+
+// respondProfileUpdate finishes PUT and PATCH /users/me: audit, badge state and the response
+func (uc *UserController) respondProfileUpdate(c *gin.Context, before, updated models.User, err error) {
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	if service.IsProfileComplete(updated) {
-		_, _ = uc.BadgeService.AwardBadge(updated.ID, service.BadgeKeyProfileComplete)
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditProfileUpdated,
+		TargetType: "user",
+		TargetID:   before.ID.String(),
+		Changes:    service.DiffFields(before, updated),
+	})
+
+	if err := service.SyncProfileCompleteBadge(uc.BadgeService, updated); err != nil {
+		fmt.Println("WARN: failed to update complete_profile badge:", err)
 	}
 
 	updated.Password = ""
+	c.Header("ETag", etag(updated.Version))
 	c.JSON(http.StatusOK, updated)
 }
 
@@ -186,47 +329,45 @@ func (uc *UserController) UpdateMe(c *gin.Context) {
 // @Produce json
 // @Param Authorization header string true "Bearer access token"
 // @Success 200 {object} controller.PresignProfilePhotoGetResponse
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string "User or profile photo not found"
-// @Failure 500 {object} map[string]string "Server error"
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 404 {object} middleware.Problem "User or profile photo not found"
+// @Failure 500 {object} middleware.Problem "Server error"
 // @Router /users/me/profile-photo/url [get]
 func (uc *UserController) PresignProfilePhotoGet(s3 *storage.S3) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userIDAny, exists := c.Get("user_id")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Error(errUnauthorized)
 			return
 		}
 		sub, ok := userIDAny.(string)
 		if !ok || sub == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Error(errUnauthorized)
 			return
 		}
 
 		// get user to find profile photo URL
 		user, err := uc.UserService.GetByKeycloakID(sub)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			c.Error(service.ErrUserNotFound)
 			return
 		}
 
 		if strings.TrimSpace(user.ProfilePhotoURL) == "" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no profile photo"})
+			c.Error(errNoProfilePhoto)
 			return
 		}
 
 		// extract key from URL
-		base := strings.TrimRight(s3.PublicBaseURL, "/") + "/"
-		if !strings.HasPrefix(user.ProfilePhotoURL, base) {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "profile_photo_url not compatible with S3_PUBLIC_BASE_URL"})
+		key, ok := s3.KeyFromPublicURL(user.ProfilePhotoURL)
+		if !ok {
+			c.Error(fmt.Errorf("profile_photo_url %q not compatible with S3_PUBLIC_BASE_URL", user.ProfilePhotoURL))
 			return
 		}
 
-		key := strings.TrimPrefix(user.ProfilePhotoURL, base)
-
 		signed, err := s3.PresignGet(key, 10*time.Minute)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 
@@ -242,42 +383,42 @@ func (uc *UserController) PresignProfilePhotoGet(s3 *storage.S3) gin.HandlerFunc
 // @Param Authorization header string true "Bearer access token"
 // @Param file formData file true "Profile photo file (jpeg/png/webp). Max 5MB."
 // @Success 200 {object} controller.UploadProfilePhotoResponse
-// @Failure 400 {object} map[string]string "Missing file / unsupported type / too large"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 500 {object} map[string]string "Server error"
+// @Failure 400 {object} middleware.Problem "Missing file / unsupported type / too large"
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 500 {object} middleware.Problem "Server error"
 // @Router /users/me/profile-photo [post]
 func (uc *UserController) UploadProfilePhoto(s3 *storage.S3) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// get user sub from context
 		userIDAny, exists := c.Get("user_id")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Error(errUnauthorized)
 			return
 		}
 		sub, ok := userIDAny.(string)
 		if !ok || sub == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Error(errUnauthorized)
 			return
 		}
 
 		// get file from form
 		fh, err := c.FormFile("file")
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "missing file field 'file'"})
+			c.Error(errPhotoMissing)
 			return
 		}
 
 		// basic size check
 		const maxSize = 5 * 1024 * 1024
 		if fh.Size > maxSize {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "file too large (max 5MB)"})
+			c.Error(errPhotoTooLarge)
 			return
 		}
 
 		// open file
 		f, err := fh.Open()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open upload"})
+			c.Error(err)
 			return
 		}
 		defer f.Close()
@@ -290,7 +431,7 @@ func (uc *UserController) UploadProfilePhoto(s3 *storage.S3) gin.HandlerFunc {
 			"image/webp": true,
 		}
 		if !allowedCT[ct] {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported content type"})
+			c.Error(errPhotoContentType)
 			return
 		}
 
@@ -308,14 +449,14 @@ func (uc *UserController) UploadProfilePhoto(s3 *storage.S3) gin.HandlerFunc {
 
 		// upload to minio
 		if err := s3.PutMultipart(key, f, fh.Size, ct); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 
 		// update user profile with public URL
 		publicURL := strings.TrimRight(s3.PublicBaseURL, "/") + "/" + key
 		if err := uc.UserService.UpdateProfilePhotoURLByKeycloakID(sub, publicURL); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 
@@ -324,6 +465,13 @@ func (uc *UserController) UploadProfilePhoto(s3 *storage.S3) gin.HandlerFunc {
 		if err != nil {
 			fmt.Println("WARN: failed to load user after profile photo upload:", err)
 		} else {
+			middleware.RecordAudit(c, models.AuditEvent{
+				Action:     models.AuditProfilePhotoUploaded,
+				TargetType: "user",
+				TargetID:   user.ID.String(),
+				Changes:    models.FieldChanges{"profile_photo_url": {After: publicURL}},
+			})
+
 			_, err := uc.BadgeService.AwardBadge(user.ID, service.BadgeKeyProfilePhotoUploaded)
 			if err != nil {
 				fmt.Println("WARN: failed to award profile_photo_uploaded badge:", err)
@@ -340,7 +488,7 @@ func (uc *UserController) UploadProfilePhoto(s3 *storage.S3) gin.HandlerFunc {
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"message":    "profile picture uploaded",
+			"message":    message(c, "message.profile_photo_uploaded"),
 			"public_url": publicURL,
 		})
 	}
@@ -353,73 +501,197 @@ func (uc *UserController) UploadProfilePhoto(s3 *storage.S3) gin.HandlerFunc {
 // @Produce json
 // @Param Authorization header string true "Bearer access token"
 // @Success 200 {array} models.UserBadge
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /users/me/badges [get]
 func (uc *UserController) GetMyBadges(c *gin.Context) {
 	subAny, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(errUnauthorized)
 		return
 	}
 
 	sub, ok := subAny.(string)
 	if !ok || sub == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(errUnauthorized)
 		return
 	}
 
 	user, err := uc.UserService.GetByKeycloakID(sub)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
 	badges, err := uc.BadgeService.GetBadgesForUser(user.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load badges"})
+		c.Error(err)
 		return
 	}
+	localizeBadges(c, badges)
 
 	c.JSON(http.StatusOK, badges)
 }
 
 // @Summary Get badges for a user by ID
 // @Description Returns all badges earned by the given user. Requires Bearer access token.
+// @Description Returns an empty list when the user does not share badges with the caller.
 // @Description Each item now also includes `badge` metadata (key, name, description) in addition to the UserBadge fields.
 // @Tags Badges
 // @Produce json
 // @Param Authorization header string true "Bearer access token"
 // @Param id path string true "User ID (UUID)"
 // @Success 200 {array} models.UserBadge
-// @Failure 400 {object} map[string]string "Invalid user ID"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string "User not found"
-// @Failure 500 {object} map[string]string "Could not load badges"
+// @Failure 400 {object} middleware.Problem "Invalid user ID"
+// @Failure 401 {object} middleware.Problem "Unauthorized"
+// @Failure 404 {object} middleware.Problem "User not found"
+// @Failure 500 {object} middleware.Problem "Could not load badges"
 // @Router /users/id/{id}/badges [get]
 func (uc *UserController) GetBadgesByUserID(c *gin.Context) {
 	idStr := c.Param("id")
 	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user id"})
+		c.Error(errInvalidUserID)
 		return
 	}
 
 	userID, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		c.Error(errInvalidUserID)
 		return
 	}
 
-	if _, err := uc.UserService.GetByID(userID); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	viewerSub, _ := middleware.GetUserID(c)
+
+	badges, err := uc.ProfileViews.BadgesForUser(viewerSub, userID)
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	localizeBadges(c, badges)
+
+	c.JSON(http.StatusOK, badges)
+}
+
+type UpdateHandleRequest struct {
+	Handle string `json:"handle" example:"jane.doe"`
+}
 
-	badges, err := uc.BadgeService.GetBadgesForUser(userID)
+// @Summary Get public profile by handle
+// @Description Returns the profile of the user with the given handle, as the caller is allowed to see it.
+// @Description Old handles that are still reserved answer with a 301 redirect to the current handle.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param handle path string true "Handle (without @)"
+// @Success 200 {object} models.UserPublicProfile
+// @Success 301 "Redirect to the current handle"
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/@{handle} [get]
+func (uc *UserController) GetByHandle(c *gin.Context) {
+	user, redirected, err := uc.UserService.ResolveHandle(c.Param("handle"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load badges"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
-	c.JSON(http.StatusOK, badges)
+	viewerSub, _ := middleware.GetUserID(c)
+
+	if redirected {
+		// the Location header would give the current handle away to a blocked viewer
+		if err := uc.ProfileViews.CanView(viewerSub, user.ID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Redirect(http.StatusMovedPermanently, "/users/@"+user.Handle)
+		return
+	}
+
+	profile, err := uc.ProfileViews.PublicProfile(viewerSub, user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	localizePublicBadges(c, profile.Badges)
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// @Summary Check handle availability
+// @Description Checks whether a handle is valid and not used or reserved by another user.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param handle query string true "Handle to check"
+// @Success 200 {object} interfaces.HandleAvailability
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /users/handle-availability [get]
+func (uc *UserController) CheckHandleAvailability(c *gin.Context) {
+	handle := c.Query("handle")
+	if strings.TrimSpace(handle) == "" {
+		c.Error(errMissingHandle)
+		return
+	}
+
+	result, err := uc.UserService.CheckHandleAvailability(handle)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Change my handle
+// @Description Changes the handle of the logged-in user. The old handle stays reserved and redirects for a while.
+// @Description A handle can only be changed once per cooldown period.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param request body controller.UpdateHandleRequest true "New handle"
+// @Success 200 {object} models.User
+// @Failure 400 {object} middleware.Problem "Invalid or blocked handle"
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Handle taken"
+// @Failure 429 {object} middleware.Problem "Cooldown active"
+// @Router /users/me/handle [put]
+func (uc *UserController) UpdateMyHandle(c *gin.Context) {
+	sub, ok := middleware.GetUserID(c)
+	if !ok || sub == "" {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	var req UpdateHandleRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Handle) == "" {
+		c.Error(errMissingHandle)
+		return
+	}
+
+	user, err := uc.UserService.GetByKeycloakID(sub)
+	if err != nil {
+		c.Error(service.ErrUserNotFound)
+		return
+	}
+
+	updated, err := uc.UserService.ChangeHandle(user.Email, req.Handle)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditHandleChanged,
+		TargetType: "user",
+		TargetID:   updated.ID.String(),
+		Changes:    models.FieldChanges{"handle": {Before: user.Handle, After: updated.Handle}},
+	})
+
+	updated.Password = ""
+	c.JSON(http.StatusOK, updated)
 }
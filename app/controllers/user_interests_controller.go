@@ -5,6 +5,8 @@ import (
 
 	"group1-userservice/app/interfaces"
 	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,33 +32,33 @@ type UserInterestsUpdateRequest struct {
 }
 
 // @Summary Get user interests
-// @Description Get interests for the authenticated user
+// @Description Get interests for the authenticated user, with labels in the language of the user
 // @Tags Interests
 // @Produce json
 // @Param Authorization header string true "Bearer access token"
 // @Success 200 {object} controller.InterestsResponse
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /users/me/interests [get]
 func (uc *UserInterestsController) GetForMe(c *gin.Context) {
 	sub, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(errUnauthorized)
 		return
 	}
 
 	user, err := uc.UserService.GetByKeycloakID(sub)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
 	email := user.Email
 
-	items, err := uc.Service.GetForUser(email)
+	items, err := uc.Service.GetForUser(email, middleware.GetLocale(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load interests"})
+		c.Error(err)
 		return
 	}
 
@@ -67,45 +69,56 @@ func (uc *UserInterestsController) GetForMe(c *gin.Context) {
 }
 
 // @Summary Update user interests
-// @Description Update interests for the authenticated user (send array with {id,value})
+// @Description Update interests for the authenticated user (send array with {id,value}); archived or unknown IDs are rejected
 // @Tags Interests
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer access token"
 // @Param body body controller.UserInterestsUpdateRequest true "Updated interests"
 // @Success 200 {object} controller.InterestsResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /users/me/interests [put]
 func (uc *UserInterestsController) UpdateForMe(c *gin.Context) {
 	sub, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(errUnauthorized)
 		return
 	}
 
 	user, err := uc.UserService.GetByKeycloakID(sub)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(service.ErrUserNotFound)
 		return
 	}
 
 	var input interfaces.UserInterestsUpdateInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		c.Error(errInvalidInput)
 		return
 	}
 
 	email := user.Email
 
-	items, err := uc.Service.UpdateForUser(email, input)
+	locale := middleware.GetLocale(c)
+	before, _ := uc.Service.GetForUser(email, locale)
+
+	items, err := uc.Service.UpdateForUser(email, input, locale)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		c.Error(err)
 		return
 	}
 
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditInterestsSet,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Changes:    service.DiffFields(gin.H{"interests": before}, gin.H{"interests": items}),
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"email":     email,
 		"interests": items,
@@ -113,26 +126,26 @@ func (uc *UserInterestsController) UpdateForMe(c *gin.Context) {
 }
 
 // @Summary Get user interests (internal)
-// @Description Internal endpoint for other services (requires X-Service-Token)
+// @Description Internal endpoint for other services (requires X-Service-Token); labels follow Accept-Language
 // @Tags Interests
 // @Produce json
 // @Param X-Service-Token header string true "Service token"
 // @Param email path string true "User Email"
 // @Success 200 {object} controller.InterestsResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /internal/users/{email}/interests [get]
 func (uc *UserInterestsController) GetForUserInternal(c *gin.Context) {
 	email := c.Param("email")
 	if email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing email"})
+		c.Error(errMissingEmail)
 		return
 	}
 
-	items, err := uc.Service.GetForUser(email)
+	items, err := uc.Service.GetForUser(email, middleware.GetLocale(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load interests"})
+		c.Error(err)
 		return
 	}
 
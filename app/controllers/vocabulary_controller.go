@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+)
+
+var errInvalidMappingID = apperrors.BadRequest("invalid_mapping_id", "invalid mapping id")
+
+type VocabularyController struct {
+	Service interfaces.VocabularyService
+}
+
+func NewVocabularyController(s interfaces.VocabularyService) *VocabularyController {
+	return &VocabularyController{Service: s}
+}
+
+// vocabularyPaths maps the URL names to the vocabularies
+var vocabularyPaths = map[string]models.Vocabulary{
+	"sectors":       models.VocabularySectors,
+	"job-functions": models.VocabularyJobFunctions,
+}
+
+func vocabulary(c *gin.Context) (models.Vocabulary, bool) {
+	v, ok := vocabularyPaths[c.Param("vocabulary")]
+	if !ok {
+		c.Error(service.ErrVocabularyUnknown)
+		return "", false
+	}
+	return v, true
+}
+
+// @Summary List sectors or job functions
+// @Description Returns the terms profiles can use for sector or job_function, with Dutch and English labels.
+// @Description Profiles store the key.
+// @Tags Vocabularies
+// @Produce json
+// @Param vocabulary path string true "sectors or job-functions"
+// @Success 200 {array} models.VocabularyTerm
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /vocabularies/{vocabulary} [get]
+func (vc *VocabularyController) List(c *gin.Context) {
+	v, ok := vocabulary(c)
+	if !ok {
+		return
+	}
+
+	terms, err := vc.Service.List(v)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, terms)
+}
+
+// @Summary Add a term
+// @Description Adds a sector or job function. Keys are lower snake case and cannot be changed later.
+// @Description Requires the admin realm role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param vocabulary path string true "sectors or job-functions"
+// @Param body body interfaces.VocabularyTermInput true "Key, labels and position"
+// @Success 201 {object} models.VocabularyTerm
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Key already exists"
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/vocabularies/{vocabulary} [post]
+func (vc *VocabularyController) Create(c *gin.Context) {
+	v, ok := vocabulary(c)
+	if !ok {
+		return
+	}
+
+	var input interfaces.VocabularyTermInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	term, err := vc.Service.Create(v, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditVocabularyTermCreated,
+		TargetType: string(v),
+		TargetID:   term.Key,
+		Changes:    models.FieldChanges{"label_nl": {After: term.LabelNL}, "label_en": {After: term.LabelEN}},
+	})
+
+	c.JSON(http.StatusCreated, term)
+}
+
+// @Summary Change a term
+// @Description Changes the labels and position of a sector or job function. Requires the admin realm role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param vocabulary path string true "sectors or job-functions"
+// @Param key path string true "Term key"
+// @Param body body interfaces.VocabularyTermInput true "Labels and position; key is ignored"
+// @Success 200 {object} models.VocabularyTerm
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/vocabularies/{vocabulary}/{key} [put]
+func (vc *VocabularyController) Update(c *gin.Context) {
+	v, ok := vocabulary(c)
+	if !ok {
+		return
+	}
+
+	var input interfaces.VocabularyTermInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	term, err := vc.Service.Update(v, c.Param("key"), input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditVocabularyTermUpdated,
+		TargetType: string(v),
+		TargetID:   term.Key,
+		Changes:    models.FieldChanges{"label_nl": {After: term.LabelNL}, "label_en": {After: term.LabelEN}},
+	})
+
+	c.JSON(http.StatusOK, term)
+}
+
+// @Summary Delete a term
+// @Description Deletes a sector or job function that no profile uses. Requires the admin realm role.
+// @Tags Admin
+// @Param Authorization header string true "Bearer access token"
+// @Param vocabulary path string true "sectors or job-functions"
+// @Param key path string true "Term key"
+// @Success 204
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem "Term is used by profiles"
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/vocabularies/{vocabulary}/{key} [delete]
+func (vc *VocabularyController) Delete(c *gin.Context) {
+	v, ok := vocabulary(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key")
+	if err := vc.Service.Delete(v, key); err != nil {
+		c.Error(err)
+		return
+	}
+
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditVocabularyTermDeleted,
+		TargetType: string(v),
+		TargetID:   key,
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Migration report
+// @Description Lists how the free-text values of existing profiles were mapped to terms, most used first.
+// @Description exact and fuzzy values were applied, unmatched values still need a decision. Requires the admin realm role.
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param vocabulary path string true "sectors or job-functions"
+// @Param status query string false "exact, fuzzy, unmatched, resolved or all (default)"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} interfaces.VocabularyMappingPage
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/vocabularies/{vocabulary}/migration [get]
+func (vc *VocabularyController) MigrationReport(c *gin.Context) {
+	v, ok := vocabulary(c)
+	if !ok {
+		return
+	}
+
+	status := models.VocabularyMappingStatus(c.DefaultQuery("status", "all"))
+	switch status {
+	case models.VocabularyMappingExact, models.VocabularyMappingFuzzy,
+		models.VocabularyMappingUnmatched, models.VocabularyMappingResolved:
+	case "all":
+		status = ""
+	default:
+		c.Error(errInvalidStatus)
+		return
+	}
+
+	report, err := vc.Service.MigrationReport(v, status, pagination(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Apply a migration mapping
+// @Description Sets the chosen term on every profile that still has the reported free-text value.
+// @Description Requires the admin realm role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer access token"
+// @Param vocabulary path string true "sectors or job-functions"
+// @Param mappingId path int true "Mapping ID"
+// @Param body body interfaces.ApplyVocabularyMappingInput true "Term key"
+// @Success 200 {object} models.VocabularyMapping
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /admin/vocabularies/{vocabulary}/migration/{mappingId}/apply [post]
+func (vc *VocabularyController) ApplyMapping(c *gin.Context) {
+	v, ok := vocabulary(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("mappingId"), 10, 64)
+	if err != nil {
+		c.Error(errInvalidMappingID)
+		return
+	}
+
+	var input interfaces.ApplyVocabularyMappingInput
+	if !decodeStrict(c, &input) {
+		return
+	}
+
+	mapping, changed, err := vc.Service.ApplyMapping(v, uint(id), input.Key)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	changes := models.FieldChanges{v.ProfileField(): {Before: mapping.RawValue, After: mapping.AppliedKey}}
+	middleware.RecordAudit(c, models.AuditEvent{
+		Action:     models.AuditVocabularyMappingApplied,
+		TargetType: string(v),
+		TargetID:   mapping.AppliedKey,
+		Changes:    changes,
+	})
+	// each changed profile gets its own event, like an edit by the user
+	for _, userID := range changed {
+		middleware.RecordAudit(c, models.AuditEvent{
+			Action:     models.AuditProfileUpdated,
+			TargetType: "user",
+			TargetID:   userID.String(),
+			Changes:    changes,
+		})
+	}
+
+	c.JSON(http.StatusOK, mapping)
+}
@@ -0,0 +1,132 @@
+// Package i18n translates the texts shown to users. Texts are looked up by key in a catalog per
+// language: error texts by their error code, other texts by a dotted key such as "badge.profile_complete.name".
+// Placeholders use fmt verbs.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a supported language, as an ISO 639-1 code
+type Locale string
+
+const (
+	English Locale = "en"
+	Dutch   Locale = "nl"
+)
+
+var catalogs = map[Locale]map[string]string{
+	English: english,
+	Dutch:   dutch,
+}
+
+// Locales returns the supported locales
+func Locales() []Locale {
+	return []Locale{Dutch, English}
+}
+
+// Parse reads a language tag such as "nl", "NL" or "nl-BE"; only the language part is used
+func Parse(tag string) (Locale, bool) {
+	lang, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	l := Locale(strings.ToLower(lang))
+	if _, ok := catalogs[l]; !ok {
+		return "", false
+	}
+	return l, true
+}
+
+// Default is the locale of DEFAULT_LANGUAGE, or English when it is not set
+func Default() Locale {
+	if l, ok := Parse(os.Getenv("DEFAULT_LANGUAGE")); ok {
+		return l
+	}
+	return English
+}
+
+// Preferred is the locale of a user's preferred_language, or fallback when it is not set or not supported
+func Preferred(language string, fallback Locale) Locale {
+	if l, ok := Parse(language); ok {
+		return l
+	}
+	return fallback
+}
+
+// FromAcceptLanguage picks the supported locale the client prefers most from an Accept-Language header
+func FromAcceptLanguage(header string) (Locale, bool) {
+	type choice struct {
+		locale Locale
+		q      float64
+	}
+
+	choices := []choice{}
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		l, ok := Parse(tag)
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			choices = append(choices, choice{l, q})
+		}
+	}
+	if len(choices) == 0 {
+		return "", false
+	}
+
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	return choices[0].locale, true
+}
+
+// Lookup returns the text of key in l, falling back to English
+func Lookup(l Locale, key string) (string, bool) {
+	if text, ok := catalogs[l][key]; ok {
+		return text, true
+	}
+	text, ok := english[key]
+	return text, ok
+}
+
+// T returns the text of key in l with args filled in. Unknown keys are returned as is.
+func T(l Locale, key string, args ...any) string {
+	text, ok := Lookup(l, key)
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Text returns the text of key in l, or fallback when no catalog has the key
+func Text(l Locale, key, fallback string) string {
+	if text, ok := Lookup(l, key); ok {
+		return text
+	}
+	return fallback
+}
+
+// Missing lists the keys of the English catalog that l has no text for
+func Missing(l Locale) []string {
+	missing := []string{}
+	for key := range english {
+		if _, ok := catalogs[l][key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
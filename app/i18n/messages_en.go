@@ -0,0 +1,210 @@
+package i18n
+
+// english is the complete catalog; other languages fall back to it for keys they miss
+var english = map[string]string{
+	// Errors, by error code
+	"internal_error":                "an unexpected error occurred",
+	"validation_failed":             "validation failed",
+	"unauthorized":                  "unauthorized",
+	"forbidden":                     "forbidden",
+	"invalid_input":                 "invalid request body",
+	"invalid_user_id":               "invalid user id",
+	"invalid_status":                "invalid status",
+	"invalid_time":                  "invalid time",
+	"invalid_version":               "invalid version",
+	"invalid_request_id":            "invalid request id",
+	"invalid_report_id":             "invalid report id",
+	"invalid_mapping_id":            "invalid mapping id",
+	"missing_email":                 "missing email",
+	"missing_name":                  "missing firstname or lastname",
+	"missing_subject":               "missing Keycloak sub",
+	"missing_handle":                "handle is required",
+	"email_required":                "email is required",
+	"token_required":                "token is required",
+	"missing_authorization":         "missing authorization header",
+	"invalid_authorization":         "invalid authorization format",
+	"invalid_service_token":         "invalid service token",
+	"role_required":                 "this route requires a role you do not have",
+	"rate_limited":                  "too many requests, try again later",
+	"identity_provider_unavailable": "authentication is unavailable, try again later",
+	"user_not_found":                "user not found",
+	"email_taken":                   "email already exists",
+	"version_conflict":              "resource was modified, fetch it again and retry",
+
+	"invalid_credentials":     "invalid email or password",
+	"invalid_password":        "invalid password",
+	"invalid_token":           "invalid or expired token",
+	"account_blocked":         "account is blocked",
+	"account_disabled":        "account is disabled",
+	"account_not_verified":    "account is not fully set up",
+	"account_locked":          "too many failed attempts, account is temporarily locked",
+	"identity_not_found":      "user not found at the identity provider",
+	"impersonation_denied":    "identity provider refused to issue tokens for the user",
+	"login_rejected":          "the identity provider refused the login",
+	"challenge_required":      "challenge_token and code are required",
+	"refresh_token_required":  "refresh_token is required",
+	"login_challenge_invalid": "invalid or expired login challenge",
+	"login_challenge_locked":  "too many invalid codes, log in again",
+	"magic_link_invalid":      "invalid or expired login link",
+	"reset_fields_required":   "token and new_password are required",
+	"reset_token_required":    "token is required",
+	"reset_token_invalid":     "invalid or expired token",
+
+	"social_login_disabled":     "social login is not supported by the identity provider",
+	"social_provider_unknown":   "unknown social login provider",
+	"social_token_required":     "provider and id_token are required",
+	"social_email_missing":      "the social account has no email address",
+	"social_email_in_use":       "an account with this email already exists; log in with its password first",
+	"social_account_unverified": "an account with this email exists but its email address is not verified; log in with its password and verify it first",
+
+	"email_not_verified":         "email address is not verified",
+	"email_already_verified":     "email address is already verified",
+	"verification_throttled":     "a verification email was sent too recently",
+	"verification_token_invalid": "invalid verification token",
+	"verification_token_expired": "verification token has expired",
+
+	"two_factor_already_enabled": "two-factor authentication is already enabled",
+	"two_factor_not_enabled":     "two-factor authentication is not enabled",
+	"two_factor_not_enrolled":    "start two-factor enrollment first",
+	"two_factor_code_invalid":    "invalid two-factor code",
+
+	"handle_invalid":  "invalid handle",
+	"handle_reserved": "handle is not allowed",
+	"handle_taken":    "handle already taken",
+	"handle_cooldown": "handle was changed too recently",
+
+	"profile_photo_not_found": "no profile photo",
+	"photo_missing":           "missing file field 'file'",
+	"photo_too_large":         "file too large (max 5MB)",
+	"photo_content_type":      "unsupported content type, use jpeg, png or webp",
+
+	"profile_version_not_found": "profile version not found",
+	"visibility_invalid":        "invalid visibility",
+	"system_alerts_locked":      "system_alert settings cannot be modified",
+	"radius_out_of_range":       "radius_km must be between 1 and 500",
+
+	"onboarding_step_unknown":       "unknown onboarding step",
+	"onboarding_step_not_current":   "finish the current onboarding step first",
+	"onboarding_step_not_skippable": "this onboarding step cannot be skipped",
+	"onboarding_step_automatic":     "this onboarding step is completed automatically",
+	"onboarding_step_incomplete":    "onboarding step is not done yet",
+
+	"connection_self":              "cannot connect with yourself",
+	"connection_exists":            "already connected",
+	"connection_not_found":         "connection not found",
+	"connection_request_pending":   "connection request already pending",
+	"connection_request_not_found": "connection request not found",
+	"block_self":                   "cannot block or mute yourself",
+	"block_kind_invalid":           "kind must be one of block, mute",
+	"block_not_found":              "block not found",
+
+	"report_self":             "cannot report yourself",
+	"report_reason_invalid":   "reason must be one of fake_profile, offensive_bio, inappropriate_photo, spam, harassment, other",
+	"report_details_too_long": "details must be at most 1000 characters",
+	"report_duplicate":        "you already have an open report for this user",
+	"report_not_found":        "report not found",
+	"report_claimed":          "report is claimed by another moderator",
+	"report_closed":           "report is already closed",
+	"report_action_invalid":   "actions must be hide_bio, remove_photo or block_account",
+
+	"vocabulary_unknown":           "unknown vocabulary",
+	"vocabulary_term_not_found":    "term not found",
+	"vocabulary_term_exists":       "term already exists",
+	"vocabulary_term_in_use":       "term is used by profiles",
+	"vocabulary_mapping_not_found": "mapping not found",
+
+	"invalid_interest_id": "invalid interest id",
+	"interest_not_found":  "interest not found",
+	"interest_exists":     "interest already exists",
+	"interest_archived":   "interest is archived",
+	"interest_merge_self": "cannot merge an interest into itself",
+
+	// Reasons added to an error
+	"handle_invalid.length":                           "must be %d to %d characters long",
+	"handle_invalid.characters":                       "only lowercase letters, digits, '.' and '_' are allowed",
+	"handle_invalid.repeated":                         "no repeated '.' or '_'",
+	"invalid_input.field_type":                        "%s has the wrong type",
+	"invalid_input.malformed":                         "the body is not valid JSON",
+	"invalid_input.unknown_field":                     "unknown field %s",
+	"onboarding_step_incomplete.verify_email":         "verify your email address",
+	"onboarding_step_incomplete.fields":               "fill in %s",
+	"onboarding_step_incomplete.photo":                "upload a profile photo",
+	"onboarding_step_incomplete.interests":            "select at least one interest",
+	"onboarding_step_incomplete.discovery_radius":     "save your discovery radius",
+	"onboarding_step_incomplete.notification_consent": `answer with {"granted": true} or {"granted": false}`,
+
+	// Validation of a single field
+	"validation.invalid_utf8":         "must be valid UTF-8",
+	"validation.control_characters":   "must not contain control characters",
+	"validation.too_long":             "must be at most %d characters",
+	"validation.first_name_required":  "First name is required",
+	"validation.last_name_required":   "Last name is required",
+	"validation.email_required":       "Email is required",
+	"validation.email_invalid":        "must be a valid email address",
+	"validation.phone_invalid":        "must be a phone number in international format, e.g. +31612345678",
+	"validation.country_unknown":      "unknown country, use an ISO 3166 code such as NL",
+	"validation.url_invalid":          "must be an http(s) URL",
+	"validation.language_unsupported": "must be one of %s",
+	"validation.password_too_short":   "password must be at least %d characters long",
+	"validation.password_too_long":    "password must be at most %d bytes long",
+	"validation.password_no_digit":    "password must contain at least one number",
+	"validation.term_key_invalid":     "must be lower case letters and digits separated by underscores, e.g. health_care",
+	"validation.label_nl_required":    "Dutch label is required",
+	"validation.label_en_required":    "English label is required",
+	"validation.label_required":       "label is required",
+	"validation.interest_unknown":     "unknown or archived interest, choose one from GET /users/me/interests",
+	"validation.sector_unknown":       "unknown sector, choose one from GET /vocabularies/sectors",
+	"validation.job_function_unknown": "unknown job function, choose one from GET /vocabularies/job-functions",
+
+	// Responses
+	"message.profile_photo_uploaded":   "profile picture uploaded",
+	"message.email_verified":           "email address verified",
+	"message.verification_resent":      "if the email belongs to an unverified account, a new link will be sent",
+	"message.verification_sent":        "verification email sent",
+	"message.two_factor_disabled":      "two-factor authentication disabled",
+	"message.magic_link_requested":     "if the email exists, a login link will be sent",
+	"message.password_reset_requested": "if the email exists, a reset link will be sent",
+	"message.password_updated":         "password updated",
+
+	// Profile completeness: reasons a part is missing or weak, and the next step per field
+	"completeness.reason.missing":                  "not filled in",
+	"completeness.reason.biography_short":          "biography has %d characters, at least %d recommended",
+	"completeness.reason.no_interests":             "no interests selected",
+	"completeness.reason.no_discovery_preferences": "discovery preferences not set",
+	"completeness.step.first_name":                 "Add your first name",
+	"completeness.step.last_name":                  "Add your last name",
+	"completeness.step.profile_photo_url":          "Upload a profile photo",
+	"completeness.step.biography":                  "Tell others about yourself in your biography",
+	"completeness.step.job_function":               "Choose your job function",
+	"completeness.step.sector":                     "Choose the sector you work in",
+	"completeness.step.country":                    "Add your country",
+	"completeness.step.phone_number":               "Add your phone number",
+	"completeness.step.interests":                  "Select the topics you are interested in",
+	"completeness.step.discovery_preferences":      "Set how far away people you discover may be",
+
+	// Notifications; connection messages get the first and last name of the other user
+	"notification.password_reset.title":        "Password reset requested",
+	"notification.password_reset.message":      "A password reset was requested for your account. If this was you, please follow the reset instructions.",
+	"notification.magic_link.title":            "Your login link",
+	"notification.magic_link.message":          "Use this link to log in. It can be used once and expires in 15 minutes. If you did not request it, you can ignore this message.",
+	"notification.email_verification.title":    "Verify your email address",
+	"notification.email_verification.message":  "Please confirm that this is your email address to finish setting up your account.",
+	"notification.connection_follow.title":     "New follower",
+	"notification.connection_follow.message":   "%s %s started following you.",
+	"notification.connection_request.title":    "New connection request",
+	"notification.connection_request.message":  "%s %s wants to connect with you.",
+	"notification.connection_accepted.title":   "Connection accepted",
+	"notification.connection_accepted.message": "%s %s accepted your connection request.",
+
+	// Badges, by badge key; badges that other services add keep the name and description they were created with
+	"badge.profile_photo_uploaded.name":        "Profile picture",
+	"badge.profile_photo_uploaded.description": "User uploaded a profile photo",
+	"badge.profile_complete.name":              "Complete Profile",
+	"badge.profile_complete.description":       "User completed all required profile information",
+	"badge.watch_50_videos.name":               "Watched 50 Videos",
+	"badge.watch_50_videos.description":        "User has watched 50 videos",
+	"badge.share_10_videos.name":               "Shared 10 Videos",
+	"badge.share_10_videos.description":        "User has shared 10 videos",
+	"badge.like_25_videos.name":                "Liked 25 Videos",
+	"badge.like_25_videos.description":         "User has liked 25 videos",
+}
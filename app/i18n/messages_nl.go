@@ -0,0 +1,209 @@
+package i18n
+
+var dutch = map[string]string{
+	// Errors, by error code
+	"internal_error":                "er ging iets mis",
+	"validation_failed":             "niet alle velden zijn geldig",
+	"unauthorized":                  "niet ingelogd",
+	"forbidden":                     "geen toegang",
+	"invalid_input":                 "ongeldige invoer",
+	"invalid_user_id":               "ongeldig gebruikers-id",
+	"invalid_status":                "ongeldige status",
+	"invalid_time":                  "ongeldige tijd",
+	"invalid_version":               "ongeldige versie",
+	"invalid_request_id":            "ongeldig verzoek-id",
+	"invalid_report_id":             "ongeldig meldings-id",
+	"invalid_mapping_id":            "ongeldig koppelings-id",
+	"missing_email":                 "e-mailadres ontbreekt",
+	"missing_name":                  "voornaam of achternaam ontbreekt",
+	"missing_subject":               "Keycloak sub ontbreekt",
+	"missing_handle":                "handle is verplicht",
+	"email_required":                "e-mailadres is verplicht",
+	"token_required":                "token is verplicht",
+	"missing_authorization":         "Authorization header ontbreekt",
+	"invalid_authorization":         "ongeldige Authorization header",
+	"invalid_service_token":         "ongeldig service token",
+	"role_required":                 "voor deze route heb je een rol nodig die je niet hebt",
+	"rate_limited":                  "te veel verzoeken, probeer het later opnieuw",
+	"identity_provider_unavailable": "inloggen is nu niet mogelijk, probeer het later opnieuw",
+	"user_not_found":                "gebruiker niet gevonden",
+	"email_taken":                   "dit e-mailadres is al in gebruik",
+	"version_conflict":              "de gegevens zijn intussen gewijzigd, haal ze opnieuw op en probeer het nog eens",
+
+	"invalid_credentials":     "onjuist e-mailadres of wachtwoord",
+	"invalid_password":        "onjuist wachtwoord",
+	"invalid_token":           "ongeldig of verlopen token",
+	"account_blocked":         "account is geblokkeerd",
+	"account_disabled":        "account is uitgeschakeld",
+	"account_not_verified":    "account is nog niet volledig ingesteld",
+	"account_locked":          "te veel mislukte pogingen, het account is tijdelijk vergrendeld",
+	"identity_not_found":      "gebruiker niet gevonden bij de identity provider",
+	"impersonation_denied":    "de identity provider weigert tokens voor deze gebruiker",
+	"login_rejected":          "de identity provider heeft het inloggen geweigerd",
+	"challenge_required":      "challenge_token en code zijn verplicht",
+	"refresh_token_required":  "refresh_token is verplicht",
+	"login_challenge_invalid": "ongeldige of verlopen inlogpoging",
+	"login_challenge_locked":  "te veel onjuiste codes, log opnieuw in",
+	"magic_link_invalid":      "ongeldige of verlopen inloglink",
+	"reset_fields_required":   "token en new_password zijn verplicht",
+	"reset_token_required":    "token is verplicht",
+	"reset_token_invalid":     "ongeldig of verlopen token",
+
+	"social_login_disabled":     "inloggen met een sociaal account wordt niet ondersteund",
+	"social_provider_unknown":   "onbekende provider",
+	"social_token_required":     "provider en id_token zijn verplicht",
+	"social_email_missing":      "het sociale account heeft geen e-mailadres",
+	"social_email_in_use":       "er bestaat al een account met dit e-mailadres; log eerst in met het wachtwoord",
+	"social_account_unverified": "er bestaat al een account met dit e-mailadres, maar het e-mailadres is nog niet bevestigd; log in met het wachtwoord en bevestig het eerst",
+
+	"email_not_verified":         "e-mailadres is nog niet bevestigd",
+	"email_already_verified":     "e-mailadres is al bevestigd",
+	"verification_throttled":     "er is zojuist al een bevestigingsmail verstuurd",
+	"verification_token_invalid": "ongeldige bevestigingslink",
+	"verification_token_expired": "de bevestigingslink is verlopen",
+
+	"two_factor_already_enabled": "tweestapsverificatie staat al aan",
+	"two_factor_not_enabled":     "tweestapsverificatie staat niet aan",
+	"two_factor_not_enrolled":    "start eerst met het instellen van tweestapsverificatie",
+	"two_factor_code_invalid":    "onjuiste verificatiecode",
+
+	"handle_invalid":  "ongeldige handle",
+	"handle_reserved": "deze handle is niet toegestaan",
+	"handle_taken":    "deze handle is al bezet",
+	"handle_cooldown": "je handle is te kort geleden gewijzigd",
+
+	"profile_photo_not_found": "geen profielfoto",
+	"photo_missing":           "bestandsveld 'file' ontbreekt",
+	"photo_too_large":         "bestand is te groot (max 5MB)",
+	"photo_content_type":      "bestandstype niet ondersteund, gebruik jpeg, png of webp",
+
+	"profile_version_not_found": "profielversie niet gevonden",
+	"visibility_invalid":        "ongeldige zichtbaarheid",
+	"system_alerts_locked":      "instellingen voor system_alert kunnen niet gewijzigd worden",
+	"radius_out_of_range":       "radius_km moet tussen 1 en 500 liggen",
+
+	"onboarding_step_unknown":       "onbekende onboardingstap",
+	"onboarding_step_not_current":   "rond eerst de huidige onboardingstap af",
+	"onboarding_step_not_skippable": "deze onboardingstap kan niet overgeslagen worden",
+	"onboarding_step_automatic":     "deze onboardingstap wordt automatisch afgerond",
+	"onboarding_step_incomplete":    "deze onboardingstap is nog niet klaar",
+
+	"connection_self":              "je kunt geen connectie met jezelf maken",
+	"connection_exists":            "jullie zijn al verbonden",
+	"connection_not_found":         "connectie niet gevonden",
+	"connection_request_pending":   "er staat al een connectieverzoek open",
+	"connection_request_not_found": "connectieverzoek niet gevonden",
+	"block_self":                   "je kunt jezelf niet blokkeren of muten",
+	"block_kind_invalid":           "kind moet block of mute zijn",
+	"block_not_found":              "blokkering niet gevonden",
+
+	"report_self":             "je kunt jezelf niet melden",
+	"report_reason_invalid":   "reason moet fake_profile, offensive_bio, inappropriate_photo, spam, harassment of other zijn",
+	"report_details_too_long": "details mag maximaal 1000 tekens zijn",
+	"report_duplicate":        "je hebt al een openstaande melding voor deze gebruiker",
+	"report_not_found":        "melding niet gevonden",
+	"report_claimed":          "een andere moderator behandelt deze melding al",
+	"report_closed":           "melding is al afgehandeld",
+	"report_action_invalid":   "actions moet hide_bio, remove_photo of block_account zijn",
+
+	"vocabulary_unknown":           "onbekende lijst",
+	"vocabulary_term_not_found":    "term niet gevonden",
+	"vocabulary_term_exists":       "term bestaat al",
+	"vocabulary_term_in_use":       "term wordt gebruikt door profielen",
+	"vocabulary_mapping_not_found": "koppeling niet gevonden",
+
+	"invalid_interest_id": "ongeldig interesse-id",
+	"interest_not_found":  "interesse niet gevonden",
+	"interest_exists":     "interesse bestaat al",
+	"interest_archived":   "interesse is gearchiveerd",
+	"interest_merge_self": "een interesse kan niet met zichzelf samengevoegd worden",
+
+	// Reasons added to an error
+	"handle_invalid.length":                           "moet %d tot %d tekens lang zijn",
+	"handle_invalid.characters":                       "alleen kleine letters, cijfers, '.' en '_' zijn toegestaan",
+	"handle_invalid.repeated":                         "geen herhaalde '.' of '_'",
+	"invalid_input.field_type":                        "%s heeft het verkeerde type",
+	"invalid_input.malformed":                         "de body is geen geldige JSON",
+	"invalid_input.unknown_field":                     "onbekend veld %s",
+	"onboarding_step_incomplete.verify_email":         "bevestig je e-mailadres",
+	"onboarding_step_incomplete.fields":               "vul %s in",
+	"onboarding_step_incomplete.photo":                "upload een profielfoto",
+	"onboarding_step_incomplete.interests":            "kies minstens één interesse",
+	"onboarding_step_incomplete.discovery_radius":     "sla je zoekstraal op",
+	"onboarding_step_incomplete.notification_consent": `antwoord met {"granted": true} of {"granted": false}`,
+
+	// Validation of a single field
+	"validation.invalid_utf8":         "moet geldige UTF-8 zijn",
+	"validation.control_characters":   "mag geen stuurtekens bevatten",
+	"validation.too_long":             "mag maximaal %d tekens zijn",
+	"validation.first_name_required":  "Voornaam is verplicht",
+	"validation.last_name_required":   "Achternaam is verplicht",
+	"validation.email_required":       "E-mailadres is verplicht",
+	"validation.email_invalid":        "moet een geldig e-mailadres zijn",
+	"validation.phone_invalid":        "moet een telefoonnummer in internationaal formaat zijn, bijv. +31612345678",
+	"validation.country_unknown":      "onbekend land, gebruik een ISO 3166 code zoals NL",
+	"validation.url_invalid":          "moet een http(s) URL zijn",
+	"validation.language_unsupported": "moet een van %s zijn",
+	"validation.password_too_short":   "wachtwoord moet minstens %d tekens lang zijn",
+	"validation.password_too_long":    "wachtwoord mag maximaal %d bytes lang zijn",
+	"validation.password_no_digit":    "wachtwoord moet minstens één cijfer bevatten",
+	"validation.term_key_invalid":     "moet uit kleine letters en cijfers bestaan, gescheiden door underscores, bijv. health_care",
+	"validation.label_nl_required":    "Nederlands label is verplicht",
+	"validation.label_en_required":    "Engels label is verplicht",
+	"validation.label_required":       "label is verplicht",
+	"validation.interest_unknown":     "onbekende of gearchiveerde interesse, kies er een uit GET /users/me/interests",
+	"validation.sector_unknown":       "onbekende sector, kies er een uit GET /vocabularies/sectors",
+	"validation.job_function_unknown": "onbekende functie, kies er een uit GET /vocabularies/job-functions",
+
+	// Responses
+	"message.profile_photo_uploaded":   "profielfoto geüpload",
+	"message.email_verified":           "e-mailadres bevestigd",
+	"message.verification_resent":      "als het e-mailadres bij een onbevestigd account hoort, wordt er een nieuwe link verstuurd",
+	"message.verification_sent":        "bevestigingsmail verstuurd",
+	"message.two_factor_disabled":      "tweestapsverificatie uitgeschakeld",
+	"message.magic_link_requested":     "als het e-mailadres bestaat, wordt er een inloglink verstuurd",
+	"message.password_reset_requested": "als het e-mailadres bestaat, wordt er een resetlink verstuurd",
+	"message.password_updated":         "wachtwoord gewijzigd",
+
+	// Profile completeness
+	"completeness.reason.missing":                  "niet ingevuld",
+	"completeness.reason.biography_short":          "biografie heeft %d tekens, minstens %d aanbevolen",
+	"completeness.reason.no_interests":             "geen interesses gekozen",
+	"completeness.reason.no_discovery_preferences": "zoekvoorkeuren niet ingesteld",
+	"completeness.step.first_name":                 "Vul je voornaam in",
+	"completeness.step.last_name":                  "Vul je achternaam in",
+	"completeness.step.profile_photo_url":          "Upload een profielfoto",
+	"completeness.step.biography":                  "Vertel in je biografie iets over jezelf",
+	"completeness.step.job_function":               "Kies je functie",
+	"completeness.step.sector":                     "Kies de sector waarin je werkt",
+	"completeness.step.country":                    "Vul je land in",
+	"completeness.step.phone_number":               "Vul je telefoonnummer in",
+	"completeness.step.interests":                  "Kies de onderwerpen die je interesseren",
+	"completeness.step.discovery_preferences":      "Stel in hoe ver weg de mensen die je ontdekt mogen zijn",
+
+	// Notifications
+	"notification.password_reset.title":        "Wachtwoord resetten aangevraagd",
+	"notification.password_reset.message":      "Er is gevraagd om het wachtwoord van je account te resetten. Was jij dit? Volg dan de instructies om een nieuw wachtwoord in te stellen.",
+	"notification.magic_link.title":            "Je inloglink",
+	"notification.magic_link.message":          "Gebruik deze link om in te loggen. Hij werkt één keer en verloopt na 15 minuten. Heb je hem niet aangevraagd? Dan kun je dit bericht negeren.",
+	"notification.email_verification.title":    "Bevestig je e-mailadres",
+	"notification.email_verification.message":  "Bevestig dat dit jouw e-mailadres is om je account af te maken.",
+	"notification.connection_follow.title":     "Nieuwe volger",
+	"notification.connection_follow.message":   "%s %s volgt je nu.",
+	"notification.connection_request.title":    "Nieuw connectieverzoek",
+	"notification.connection_request.message":  "%s %s wil met je connecten.",
+	"notification.connection_accepted.title":   "Connectie geaccepteerd",
+	"notification.connection_accepted.message": "%s %s heeft je connectieverzoek geaccepteerd.",
+
+	// Badges, by badge key
+	"badge.profile_photo_uploaded.name":        "Profielfoto",
+	"badge.profile_photo_uploaded.description": "Heeft een profielfoto geüpload",
+	"badge.profile_complete.name":              "Volledig profiel",
+	"badge.profile_complete.description":       "Heeft alle verplichte profielgegevens ingevuld",
+	"badge.watch_50_videos.name":               "50 video's bekeken",
+	"badge.watch_50_videos.description":        "Heeft 50 video's bekeken",
+	"badge.share_10_videos.name":               "10 video's gedeeld",
+	"badge.share_10_videos.description":        "Heeft 10 video's gedeeld",
+	"badge.like_25_videos.name":                "25 video's geliket",
+	"badge.like_25_videos.description":         "Heeft 25 video's geliket",
+}
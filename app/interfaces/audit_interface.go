@@ -0,0 +1,43 @@
+package interfaces
+
+import (
+	"time"
+
+	"group1-userservice/app/models"
+)
+
+// AuditFilter narrows down an audit query; empty fields are ignored
+type AuditFilter struct {
+	ActorType  models.AuditActorType
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditPage struct {
+	Items    []models.AuditEvent `json:"items"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	Total    int64               `json:"total"`
+}
+
+type AuditRepository interface {
+	Create(e *models.AuditEvent) error
+	Query(f AuditFilter, p Pagination) ([]models.AuditEvent, int64, error)
+	DeleteBefore(t time.Time) (int64, error)
+}
+
+// AuditRecorder appends events to the audit log
+type AuditRecorder interface {
+	Record(e models.AuditEvent) error
+}
+
+type AuditService interface {
+	AuditRecorder
+
+	Query(f AuditFilter, p Pagination) (*AuditPage, error)
+	Purge(olderThan time.Duration) (int64, error)
+}
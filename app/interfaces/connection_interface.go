@@ -0,0 +1,70 @@
+package interfaces
+
+import (
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+// Pagination is a 1-based page request.
+type Pagination struct {
+	Page     int
+	PageSize int
+}
+
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// ConnectionPage is one page of a connection list.
+type ConnectionPage struct {
+	Items    []models.ConnectionUser `json:"items"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+	Total    int64                   `json:"total"`
+}
+
+// UserConnectionIDs lists all related user IDs of a user, for other services.
+type UserConnectionIDs struct {
+	UserID      uuid.UUID   `json:"user_id"`
+	Followers   []uuid.UUID `json:"followers"`
+	Following   []uuid.UUID `json:"following"`
+	Connections []uuid.UUID `json:"connections"`
+}
+
+type ConnectionRepository interface {
+	Find(requesterID, addresseeID uuid.UUID, t models.ConnectionType) (*models.Connection, error)
+	FindByID(id uint) (*models.Connection, error)
+	Save(c *models.Connection) error
+	Delete(id uint) error
+	DeleteBetween(a, b uuid.UUID) error
+	FindAcceptedMutual(a, b uuid.UUID) (*models.Connection, error)
+	ListFollowers(userID uuid.UUID, p Pagination) ([]models.ConnectionUser, int64, error)
+	ListFollowing(userID uuid.UUID, p Pagination) ([]models.ConnectionUser, int64, error)
+	ListMutual(userID uuid.UUID, p Pagination) ([]models.ConnectionUser, int64, error)
+	ListIncomingRequests(userID uuid.UUID, p Pagination) ([]models.ConnectionUser, int64, error)
+	Counts(userID uuid.UUID) (models.ConnectionCounts, error)
+	IDs(userID uuid.UUID) (UserConnectionIDs, error)
+}
+
+// ConnectionCounter provides the counters shown on a profile.
+type ConnectionCounter interface {
+	Counts(userID uuid.UUID) (models.ConnectionCounts, error)
+}
+
+type ConnectionService interface {
+	RelationshipResolver
+	ConnectionCounter
+
+	Follow(follower models.User, targetID uuid.UUID) (*models.Connection, error)
+	Unfollow(follower models.User, targetID uuid.UUID) error
+	RequestConnection(requester models.User, targetID uuid.UUID) (*models.Connection, error)
+	RespondToRequest(addressee models.User, requestID uint, accept bool) (*models.Connection, error)
+	RemoveConnection(user models.User, otherID uuid.UUID) error
+
+	ListFollowers(userID uuid.UUID, p Pagination) (*ConnectionPage, error)
+	ListFollowing(userID uuid.UUID, p Pagination) (*ConnectionPage, error)
+	ListConnections(userID uuid.UUID, p Pagination) (*ConnectionPage, error)
+	ListIncomingRequests(userID uuid.UUID, p Pagination) (*ConnectionPage, error)
+	ConnectionIDs(userID uuid.UUID) (UserConnectionIDs, error)
+}
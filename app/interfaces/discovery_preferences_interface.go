@@ -9,4 +9,5 @@ type DiscoveryPreferencesInput struct {
 type DiscoveryPreferencesService interface {
 	GetForEmail(email string) (*models.DiscoveryPreferences, error)
 	UpdateForEmail(email string, input DiscoveryPreferencesInput) (*models.DiscoveryPreferences, error)
+	UpdateForEmailIfVersion(email string, input DiscoveryPreferencesInput, version int64) (*models.DiscoveryPreferences, error)
 }
@@ -0,0 +1,37 @@
+package interfaces
+
+import (
+	"time"
+
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+// EmailVerifiedMarker marks the email address of an account as verified at the identity provider
+type EmailVerifiedMarker interface {
+	MarkEmailVerified(keycloakID string) error
+}
+
+type EmailVerificationRepository interface {
+	// MarkVerified sets the verification time unless the user was already verified
+	MarkVerified(userID uuid.UUID, at time.Time) error
+	// FindSend returns the send history of the user, or nil when nothing was sent yet
+	FindSend(userID uuid.UUID) (*models.EmailVerificationSend, error)
+	SaveSend(send *models.EmailVerificationSend) error
+}
+
+// EmailVerificationChecker tells whether the account behind a Keycloak sub has a verified email address
+type EmailVerificationChecker interface {
+	IsEmailVerified(keycloakID string) (bool, error)
+}
+
+type EmailVerificationService interface {
+	EmailVerificationChecker
+	// Send mails a new verification link to the user; resends are throttled
+	Send(user models.User) error
+	// Resend is Send by email address; unknown and verified addresses are ignored
+	Resend(email string) error
+	// Verify checks the token and marks the account it was issued for as verified
+	Verify(token string) (models.User, error)
+}
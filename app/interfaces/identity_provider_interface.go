@@ -0,0 +1,68 @@
+package interfaces
+
+import "group1-userservice/app/apperrors"
+
+// Errors an identity provider returns; a password grant that is refused says why
+var (
+	ErrInvalidCredentials  = apperrors.Unauthorized("invalid_credentials", "invalid email or password")
+	ErrAccountDisabled     = apperrors.Forbidden("account_disabled", "account is disabled")
+	ErrAccountNotVerified  = apperrors.Forbidden("account_not_verified", "account is not fully set up")
+	ErrAccountLocked       = apperrors.TooManyRequests("account_locked", "too many failed attempts, account is temporarily locked")
+	ErrInvalidToken        = apperrors.Unauthorized("invalid_token", "invalid or expired token")
+	ErrEmailAlreadyExists  = apperrors.Conflict("email_taken", "email already exists at the identity provider")
+	ErrIdentityNotFound    = apperrors.NotFound("identity_not_found", "user not found at the identity provider")
+	ErrImpersonationDenied = apperrors.Forbidden("impersonation_denied", "identity provider refused to issue tokens for the user")
+	ErrSocialLoginDisabled = apperrors.NotImplemented("social_login_disabled", "social login is not supported by the identity provider")
+)
+
+// TokenResponse holds the tokens issued at login, in the shape of an OAuth2 token endpoint response
+type TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
+}
+
+// IdentityUser is the account data kept at the identity provider
+type IdentityUser struct {
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+// IdentityClaims are the claims of a verified access token that the service uses
+type IdentityClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	RealmRoles    []string
+}
+
+// IdentityProvider issues and verifies tokens and owns the credentials of every account.
+// Accounts are addressed by their subject, stored as models.User.KeycloakID.
+type IdentityProvider interface {
+	AccountDisabler
+	EmailVerifiedMarker
+
+	// PasswordGrant logs in with email and password
+	PasswordGrant(email, password string) (*TokenResponse, error)
+	// Refresh exchanges a refresh token for new tokens
+	Refresh(refreshToken string) (*TokenResponse, error)
+	// Impersonate issues tokens for an account without its password, after another proof such as a login link
+	Impersonate(subject string) (*TokenResponse, error)
+	// VerifyToken checks the signature and expiry of an access token
+	VerifyToken(accessToken string) (*IdentityClaims, error)
+	// ExchangeSocialToken trades an ID token of a brokered provider such as "google" for tokens of
+	// the account it belongs to; the identity provider creates that account on first use
+	ExchangeSocialToken(provider, idToken string) (*TokenResponse, error)
+	// LinkSocialIdentity moves the brokered logins of subject to the account target and removes subject
+	LinkSocialIdentity(subject, target string) error
+
+	CreateUser(user IdentityUser, password string) (subject string, err error)
+	UpdateUser(subject string, user IdentityUser) error
+	DeleteUser(subject string) error
+	SetPassword(subject string, password string) error
+}
@@ -0,0 +1,41 @@
+package interfaces
+
+import "group1-userservice/app/models"
+
+// InterestLabelInput is the label and description of an interest in one language
+type InterestLabelInput struct {
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+// InterestInput creates or renames an interest. Translations are keyed by language (nl, en); the key
+// cannot be changed once created.
+type InterestInput struct {
+	Key          string                        `json:"key"`
+	Translations map[string]InterestLabelInput `json:"translations"`
+}
+
+// InterestOrderInput lists interest IDs in their new order; interests that are left out follow them
+type InterestOrderInput struct {
+	IDs []uint `json:"ids"`
+}
+
+// InterestMergeInput names the interest that takes over the selections
+type InterestMergeInput struct {
+	Into uint `json:"into"`
+}
+
+type InterestMergeResult struct {
+	From  models.Interest `json:"from"`
+	Into  models.Interest `json:"into"`
+	Moved int64           `json:"moved"`
+}
+
+type InterestCatalogService interface {
+	List() ([]models.Interest, error)
+	Create(input InterestInput) (*models.Interest, error)
+	Update(id uint, input InterestInput) (*models.Interest, error)
+	Reorder(ids []uint) ([]models.Interest, error)
+	Archive(id uint) (*models.Interest, error)
+	Merge(from, into uint) (*InterestMergeResult, error)
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"time"
+
+	"group1-userservice/app/models"
+)
+
+type MagicLinkRepository interface {
+	Create(email string, tokenHash string, expiresAt time.Time) error
+	FindValidByTokenHash(tokenHash string) (*models.MagicLinkToken, error)
+	// MarkUsed reports false when the token was used already, so it is redeemed only once
+	MarkUsed(id uint) (bool, error)
+}
+
+type MagicLinkService interface {
+	// RequestLink returns the raw token to send, or "" when there is no account that may log in
+	RequestLink(email string) (rawToken string, err error)
+	// Redeem uses up the token and returns the account it was issued for
+	Redeem(rawToken string) (models.User, error)
+}
@@ -0,0 +1,63 @@
+package interfaces
+
+import (
+	"time"
+
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+type ReportInput struct {
+	Reason  models.ReportReason `json:"reason"`
+	Details string              `json:"details"`
+}
+
+// ResolveReportInput lists the actions to take. Without actions the report is dismissed.
+type ResolveReportInput struct {
+	Actions []models.ModerationActionType `json:"actions"`
+	Note    string                        `json:"note"`
+}
+
+type ReportPage struct {
+	Items    []models.UserReport `json:"items"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	Total    int64               `json:"total"`
+}
+
+// ReportDetail is a report together with its audit trail
+type ReportDetail struct {
+	Report  models.UserReport         `json:"report"`
+	Actions []models.ModerationAction `json:"actions"`
+}
+
+type UserReportRepository interface {
+	Create(r *models.UserReport) error
+	FindByID(id uint) (*models.UserReport, error)
+	HasOpenReport(reporterID, reportedID uuid.UUID) (bool, error)
+	List(status models.ReportStatus, p Pagination) ([]models.UserReport, int64, error)
+	Claim(id uint, moderatorID string, at time.Time) (bool, error)
+	Resolve(report *models.UserReport, actions []models.ModerationAction, userUpdates map[string]interface{}) (bool, error)
+	AddAction(a *models.ModerationAction) error
+	Actions(reportID uint) ([]models.ModerationAction, error)
+}
+
+// ObjectStorage is the part of the S3 storage moderation needs
+type ObjectStorage interface {
+	KeyFromPublicURL(publicURL string) (string, bool)
+	RemoveObject(key string) error
+}
+
+// AccountDisabler disables login for an account at the identity provider
+type AccountDisabler interface {
+	DisableAccount(keycloakID string) error
+}
+
+type ModerationService interface {
+	Report(reporter models.User, reportedID uuid.UUID, input ReportInput) (*models.UserReport, error)
+	List(status models.ReportStatus, p Pagination) (*ReportPage, error)
+	Get(id uint) (*ReportDetail, error)
+	Claim(id uint, moderatorID string) (*models.UserReport, error)
+	Resolve(id uint, moderatorID string, input ResolveReportInput) (*ReportDetail, error)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "group1-userservice/app/models"
+
+// NotificationSender delivers notifications through the NotificationService.
+type NotificationSender interface {
+	Send(n models.Notification) error
+}
@@ -6,18 +6,21 @@ type NotificationSettingsService interface {
 	GetByEmail(email string) (*models.NotificationSettings, error)
 	UpdateForEmail(email string, input NotificationSettingsInput) (*models.NotificationSettings, error)
 	Upsert(settings *models.NotificationSettings) (*models.NotificationSettings, error)
+	UpdateIfVersion(settings *models.NotificationSettings, version int64) (*models.NotificationSettings, error)
 }
 
 type NotificationSettingsInput struct {
-	LikeEmail     bool   `json:"like_email"`
-	LikePush      bool   `json:"like_push"`
-	FavoriteEmail bool   `json:"favorite_email"`
-	FavoritePush  bool   `json:"favorite_push"`
-	ChatEmail     bool   `json:"chat_email"`
-	ChatPush      bool   `json:"chat_push"`
-	SystemEmail   bool   `json:"system_email"`
-	SystemPush    bool   `json:"system_push"`
-	ExpoPushToken string `json:"expo_push_token"`
+	LikeEmail       bool   `json:"like_email"`
+	LikePush        bool   `json:"like_push"`
+	FavoriteEmail   bool   `json:"favorite_email"`
+	FavoritePush    bool   `json:"favorite_push"`
+	ChatEmail       bool   `json:"chat_email"`
+	ChatPush        bool   `json:"chat_push"`
+	ConnectionEmail bool   `json:"connection_email"`
+	ConnectionPush  bool   `json:"connection_push"`
+	SystemEmail     bool   `json:"system_email"`
+	SystemPush      bool   `json:"system_push"`
+	ExpoPushToken   string `json:"expo_push_token"`
 }
 
 type NotificationSettingsPatchInput struct {
@@ -28,6 +31,9 @@ type NotificationSettingsPatchInput struct {
 	ChatEmail     *bool `json:"chat_email,omitempty"`
 	ChatPush      *bool `json:"chat_push,omitempty"`
 
+	ConnectionEmail *bool `json:"connection_email,omitempty"`
+	ConnectionPush  *bool `json:"connection_push,omitempty"`
+
 	SystemEmail *bool `json:"system_email,omitempty"`
 	SystemPush  *bool `json:"system_push,omitempty"`
 
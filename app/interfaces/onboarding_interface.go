@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+// OnboardingStepInput is the optional body when completing a step
+type OnboardingStepInput struct {
+	// Granted answers the notification_consent step
+	Granted *bool `json:"granted"`
+}
+
+type OnboardingRepository interface {
+	// FindOrCreate loads the state of the user with its steps in order. When there is none yet, fresh is
+	// stored and returned; created reports which of the two happened.
+	FindOrCreate(userID uuid.UUID, fresh *models.OnboardingState) (state *models.OnboardingState, created bool, err error)
+	// Update saves the state and all of its steps
+	Update(state *models.OnboardingState) error
+}
+
+type OnboardingService interface {
+	Get(user models.User) (*models.OnboardingState, error)
+	Complete(user models.User, step models.OnboardingStep, input OnboardingStepInput) (*models.OnboardingState, error)
+	Skip(user models.User, step models.OnboardingStep) (*models.OnboardingState, error)
+}
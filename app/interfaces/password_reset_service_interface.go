@@ -2,5 +2,5 @@ package interfaces
 
 type PasswordResetService interface {
 	RequestReset(email string) (rawToken string, err error)
-	ResetPassword(rawToken string, newPassword string) error
+	ResetPassword(rawToken string, newPassword string) (email string, err error)
 }
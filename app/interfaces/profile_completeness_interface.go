@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/models"
+)
+
+// ProfileCompletenessRepository reads the parts of a profile that are stored outside the users table
+type ProfileCompletenessRepository interface {
+	CountSelectedInterests(email string) (int64, error)
+	// HasDiscoveryPreferences reports whether preferences were stored; reading them does not create any
+	HasDiscoveryPreferences(email string) (bool, error)
+}
+
+type ProfileCompletenessService interface {
+	ForUser(u models.User, l i18n.Locale) (*models.ProfileCompleteness, error)
+}
@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+// ProfileHistoryPage is one page of a user's profile history, newest version first.
+type ProfileHistoryPage struct {
+	Items    []models.ProfileHistoryEntry `json:"items"`
+	Page     int                          `json:"page"`
+	PageSize int                          `json:"page_size"`
+	Total    int64                        `json:"total"`
+}
+
+// ProfileHistoryRepository reads the versions written by the user repository on every profile change.
+type ProfileHistoryRepository interface {
+	VocabularyLookup
+	// List returns versions newest first; limit may exceed the page size to include the predecessor.
+	List(userID uuid.UUID, offset, limit int) ([]models.ProfileVersion, int64, error)
+	Find(userID uuid.UUID, version int) (*models.ProfileVersion, error)
+	// Restore writes the snapshot of v back to the user and records it as a new version.
+	Restore(userID uuid.UUID, v models.ProfileVersion) (models.User, error)
+}
+
+type ProfileHistoryService interface {
+	History(userID uuid.UUID, p Pagination) (*ProfileHistoryPage, error)
+	Restore(user models.User, version int) (models.User, error)
+}
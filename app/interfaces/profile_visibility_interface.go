@@ -0,0 +1,43 @@
+package interfaces
+
+import (
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+// ProfileVisibilityPatchInput only changes the fields that are present in the request.
+type ProfileVisibilityPatchInput struct {
+	Email       *models.Visibility `json:"email,omitempty"`
+	PhoneNumber *models.Visibility `json:"phone_number,omitempty"`
+	Country     *models.Visibility `json:"country,omitempty"`
+	JobFunction *models.Visibility `json:"job_function,omitempty"`
+	Sector      *models.Visibility `json:"sector,omitempty"`
+	Biography   *models.Visibility `json:"biography,omitempty"`
+	Badges      *models.Visibility `json:"badges,omitempty"`
+}
+
+type ProfileVisibilityRepository interface {
+	FindByUserID(userID uuid.UUID) (*models.ProfileVisibility, error)
+	Upsert(v *models.ProfileVisibility) (*models.ProfileVisibility, error)
+}
+
+type ProfileVisibilityService interface {
+	GetForUser(user models.User) (*models.ProfileVisibility, error)
+	UpdateForUser(user models.User, input ProfileVisibilityPatchInput) (*models.ProfileVisibility, error)
+}
+
+// RelationshipResolver tells how a viewer relates to the owner of a profile.
+type RelationshipResolver interface {
+	Relationship(viewerID, targetID uuid.UUID) (models.Relationship, error)
+}
+
+// ProfileViewService is the single entry point for reading another user's data.
+// Every method applies the target's visibility settings for the viewer (identified by Keycloak sub).
+type ProfileViewService interface {
+	// CanView returns an error when the viewer may not know the target exists, e.g. because of a block.
+	CanView(viewerSub string, targetID uuid.UUID) error
+	PublicProfile(viewerSub string, targetID uuid.UUID) (*models.UserPublicProfile, error)
+	PublicInfoByFirstLast(viewerSub string, first, last string) (*models.UserPublicInfo, error)
+	BadgesForUser(viewerSub string, targetID uuid.UUID) ([]models.UserBadge, error)
+}
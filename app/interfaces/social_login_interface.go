@@ -0,0 +1,19 @@
+package interfaces
+
+import "group1-userservice/app/models"
+
+// SocialLoginResult is the outcome of a login with a social account
+type SocialLoginResult struct {
+	User  models.User
+	Token *TokenResponse
+	// Created is set when this login made the local user
+	Created bool
+	// Linked is set when the social account was linked to an existing account with the same verified email
+	Linked bool
+}
+
+type SocialLoginService interface {
+	// Login exchanges an ID token of the provider ("google", "apple") for tokens of the account it
+	// belongs to, creating or linking the local user on first use
+	Login(provider, idToken string) (SocialLoginResult, error)
+}
@@ -0,0 +1,77 @@
+package interfaces
+
+import (
+	"time"
+
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactorStatus is the 2FA state shown to the user
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorEnrollment is returned once when enrolling; the secret is shown to the user to add to an authenticator app
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeInput confirms enrollment
+type TwoFactorCodeInput struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorReauthInput re-authenticates the user before 2FA is disabled or recovery codes are replaced.
+// Code is a TOTP code or a recovery code.
+type TwoFactorReauthInput struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// LoginChallengeInput finishes a login with 2FA
+type LoginChallengeInput struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type TwoFactorRepository interface {
+	// Find returns the 2FA setup of the user, or nil when there is none
+	Find(userID uuid.UUID) (*models.TwoFactor, error)
+	Save(tf *models.TwoFactor) error
+	// Delete removes the setup, its recovery codes and pending login challenges
+	Delete(userID uuid.UUID) error
+	// UseStep stores step as the last used step unless a later or equal step was used already
+	UseStep(userID uuid.UUID, step int64) (bool, error)
+
+	ReplaceRecoveryCodes(userID uuid.UUID, hashes []string) error
+	CountRecoveryCodes(userID uuid.UUID) (int64, error)
+	// UseRecoveryCode marks an unused code as used and reports whether there was one
+	UseRecoveryCode(userID uuid.UUID, hash string) (bool, error)
+
+	CreateChallenge(ch *models.LoginChallenge) error
+	// FindChallenge returns an unexpired challenge
+	FindChallenge(tokenHash string) (*models.LoginChallenge, error)
+	AddChallengeAttempt(id uint) error
+	DeleteChallenge(id uint) error
+}
+
+type TwoFactorService interface {
+	Status(user models.User) (TwoFactorStatus, error)
+	Enroll(user models.User) (*TwoFactorEnrollment, error)
+	// Confirm enables 2FA with a first code and returns the recovery codes
+	Confirm(user models.User, code string) ([]string, error)
+	Disable(user models.User, input TwoFactorReauthInput) error
+	RegenerateRecoveryCodes(user models.User, input TwoFactorReauthInput) ([]string, error)
+
+	// Enabled reports whether logging in needs a second factor
+	Enabled(userID uuid.UUID) (bool, error)
+	// StartLogin keeps the refresh token of a password login and returns the challenge token for the second step
+	StartLogin(user models.User, refreshToken string) (challengeToken string, expiresIn int, err error)
+	// FinishLogin checks the code and returns the user and refresh token of the challenge
+	FinishLogin(input LoginChallengeInput) (models.User, string, error)
+}
@@ -9,4 +9,5 @@ import (
 type UserBadgeRepository interface {
 	CreateIfNotExists(userID uuid.UUID, badgeKey string) (models.UserBadge, bool, error)
 	FindByUserID(userID uuid.UUID) ([]models.UserBadge, error)
+	Delete(userID uuid.UUID, badgeKey string) (bool, error)
 }
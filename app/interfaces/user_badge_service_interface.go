@@ -8,5 +8,6 @@ import (
 
 type UserBadgeService interface {
 	AwardBadge(userID uuid.UUID, badgeKey string) (bool, error)
+	RevokeBadge(userID uuid.UUID, badgeKey string) (bool, error)
 	GetBadgesForUser(userID uuid.UUID) ([]models.UserBadge, error)
 }
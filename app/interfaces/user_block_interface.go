@@ -0,0 +1,40 @@
+package interfaces
+
+import (
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+// UserBlockList is what other services need to filter content for a user.
+type UserBlockList struct {
+	UserID    uuid.UUID   `json:"user_id"`
+	Blocked   []uuid.UUID `json:"blocked"`
+	BlockedBy []uuid.UUID `json:"blocked_by"`
+	Muted     []uuid.UUID `json:"muted"`
+}
+
+type UserBlockRepository interface {
+	Create(b *models.UserBlock) error
+	Delete(blockerID, blockedID uuid.UUID, kind models.BlockKind) (bool, error)
+	Exists(blockerID, blockedID uuid.UUID, kind models.BlockKind) (bool, error)
+	BlockedBetween(a, b uuid.UUID) (bool, error)
+	List(blockerID uuid.UUID, kind models.BlockKind, p Pagination) ([]models.ConnectionUser, int64, error)
+	TargetIDs(blockerID uuid.UUID, kind models.BlockKind) ([]uuid.UUID, error)
+	BlockerIDs(blockedID uuid.UUID, kind models.BlockKind) ([]uuid.UUID, error)
+}
+
+// BlockChecker answers block and mute questions for other services and controllers.
+type BlockChecker interface {
+	IsBlockedBetween(a, b uuid.UUID) (bool, error)
+	HasMuted(userID, otherID uuid.UUID) (bool, error)
+}
+
+type UserBlockService interface {
+	BlockChecker
+
+	Add(user models.User, targetID uuid.UUID, kind models.BlockKind) (*models.UserBlock, error)
+	Remove(user models.User, targetID uuid.UUID, kind models.BlockKind) error
+	List(userID uuid.UUID, kind models.BlockKind, p Pagination) (*ConnectionPage, error)
+	BlockList(userID uuid.UUID) (UserBlockList, error)
+}
@@ -1,9 +1,14 @@
 package interfaces
 
+import "group1-userservice/app/i18n"
+
+// UserInterestResponseItem is an interest with its label in the language of the request
 type UserInterestResponseItem struct {
-	ID    uint   `json:"id"`
-	Key   string `json:"key"`
-	Value bool   `json:"value"`
+	ID          uint   `json:"id"`
+	Key         string `json:"key"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+	Value       bool   `json:"value"`
 }
 
 type UserInterestItemInput struct {
@@ -16,6 +21,6 @@ type UserInterestsUpdateInput struct {
 }
 
 type UserInterestsService interface {
-	GetForUser(email string) ([]UserInterestResponseItem, error)
-	UpdateForUser(email string, input UserInterestsUpdateInput) ([]UserInterestResponseItem, error)
+	GetForUser(email string, locale i18n.Locale) ([]UserInterestResponseItem, error)
+	UpdateForUser(email string, input UserInterestsUpdateInput, locale i18n.Locale) ([]UserInterestResponseItem, error)
 }
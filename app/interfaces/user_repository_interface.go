@@ -1,12 +1,15 @@
 package interfaces
 
 import (
+	"time"
+
 	"group1-userservice/app/models"
 
 	"github.com/google/uuid"
 )
 
 type UserRepository interface {
+	VocabularyLookup
 	Create(user *models.User) error
 	FindByEmail(email string) (models.User, error)
 	FindAll() ([]models.User, error)
@@ -14,8 +17,14 @@ type UserRepository interface {
 	FindByKeycloakID(sub string) (models.User, error)
 	FindPublicInfoByFirstLast(firstName, lastName string) (*models.UserPublicInfo, error)
 	FindByFirstLastInsensitive(first, last string) (models.User, error)
-	UpdatePasswordHashByEmail(email string, passwordHash string) error
+	ClearLegacyPasswordHash(id uuid.UUID) error
 	UpdateFieldsByEmail(email string, fields map[string]any) (models.User, error)
+	UpdateFieldsByEmailIfVersion(email string, fields map[string]any, version int64) (models.User, error)
 	UpdateProfilePhotoURLByKeycloakID(keycloakID, url string) error
 	GetByID(id uuid.UUID) (models.User, error)
+	FindByHandle(handle string) (models.User, error)
+	HandleInUse(handle string) (bool, error)
+	FindActiveHandleReservation(handle string) (*models.HandleReservation, error)
+	ChangeHandle(userID uuid.UUID, newHandle string, reservedUntil time.Time) (models.User, error)
+	FindWithoutHandle() ([]models.User, error)
 }
@@ -6,14 +6,37 @@ import (
 	"github.com/google/uuid"
 )
 
+// UserLookup finds the user behind a Keycloak sub
+type UserLookup interface {
+	GetByKeycloakID(sub string) (models.User, error)
+}
+
 type UserService interface {
 	Register(user *models.User) error
+	// RegisterFromIdentity creates the local user for an account the identity provider already has
+	RegisterFromIdentity(user *models.User) error
 	GetByEmail(email string) (models.User, error)
-	CheckPassword(hash string, raw string) bool
+	// VerifyPassword asks the identity provider, the only credential store, whether the password is right
+	VerifyPassword(email string, password string) error
+	// RetireLegacyPassword clears the local bcrypt hash left from before the identity provider was authoritative;
+	// plainPassword is the password it just accepted, or "" when it was replaced
+	RetireLegacyPassword(user models.User, plainPassword string) error
 	GetByKeycloakID(sub string) (models.User, error)
 	GetPublicInfoByFirstLast(first, last string) (*models.UserPublicInfo, error)
-	UpdatePasswordByEmail(email string, newPlainPassword string) error
 	UpdateByEmail(email string, input *models.UserUpdateInput) (models.User, error)
+	ReplaceByEmail(email string, input *models.UserUpdateInput, ifMatch int64) (models.User, error)
+	PatchByEmail(email string, patch *models.UserProfilePatch, ifMatch int64) (models.User, error)
 	UpdateProfilePhotoURLByKeycloakID(keycloakID, url string) error
 	GetByID(id uuid.UUID) (models.User, error)
+	ResolveHandle(handle string) (user models.User, redirected bool, err error)
+	CheckHandleAvailability(handle string) (HandleAvailability, error)
+	ChangeHandle(email string, handle string) (models.User, error)
+	BackfillHandles() error
+}
+
+// HandleAvailability is the result of checking whether a handle can be claimed.
+type HandleAvailability struct {
+	Handle    string `json:"handle"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
 }
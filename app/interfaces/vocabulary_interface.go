@@ -0,0 +1,64 @@
+package interfaces
+
+import (
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+// VocabularyTermInput creates or changes a term. The key cannot be changed once created.
+type VocabularyTermInput struct {
+	Key      string `json:"key"`
+	LabelNL  string `json:"label_nl"`
+	LabelEN  string `json:"label_en"`
+	Position int    `json:"position"`
+}
+
+// ApplyVocabularyMappingInput picks the term key for a free-text value from the migration report
+type ApplyVocabularyMappingInput struct {
+	Key string `json:"key"`
+}
+
+type VocabularyMappingPage struct {
+	Items    []models.VocabularyMapping `json:"items"`
+	Page     int                        `json:"page"`
+	PageSize int                        `json:"page_size"`
+	Total    int64                      `json:"total"`
+}
+
+// VocabularyValueCount is a profile value that is not a term key, with the number of users that have it
+type VocabularyValueCount struct {
+	Value string
+	Users int64
+}
+
+// VocabularyLookup resolves a key or a Dutch or English label (case-insensitive) to its term
+type VocabularyLookup interface {
+	FindVocabularyTerm(v models.Vocabulary, value string) (*models.VocabularyTerm, error)
+}
+
+type VocabularyRepository interface {
+	VocabularyLookup
+	List(v models.Vocabulary) ([]models.VocabularyTerm, error)
+	Find(v models.Vocabulary, key string) (*models.VocabularyTerm, error)
+	Create(v models.Vocabulary, t *models.VocabularyTerm) error
+	Update(v models.Vocabulary, t *models.VocabularyTerm) error
+	Delete(v models.Vocabulary, key string) (bool, error)
+	UsersWithTerm(v models.Vocabulary, key string) (int64, error)
+	UnknownProfileValues(v models.Vocabulary) ([]VocabularyValueCount, error)
+	ReplaceProfileValue(v models.Vocabulary, raw, key string) ([]uuid.UUID, error)
+	SaveMapping(m *models.VocabularyMapping) error
+	ListMappings(v models.Vocabulary, status models.VocabularyMappingStatus, p Pagination) ([]models.VocabularyMapping, int64, error)
+	FindMapping(v models.Vocabulary, id uint) (*models.VocabularyMapping, error)
+}
+
+type VocabularyService interface {
+	List(v models.Vocabulary) ([]models.VocabularyTerm, error)
+	Create(v models.Vocabulary, input VocabularyTermInput) (*models.VocabularyTerm, error)
+	Update(v models.Vocabulary, key string, input VocabularyTermInput) (*models.VocabularyTerm, error)
+	Delete(v models.Vocabulary, key string) error
+	MigrateProfileValues() error
+	MigrationReport(v models.Vocabulary, status models.VocabularyMappingStatus, p Pagination) (*VocabularyMappingPage, error)
+	// ApplyMapping also returns the IDs of the users whose profile was changed
+	ApplyMapping(v models.Vocabulary, id uint, key string) (*models.VocabularyMapping, []uuid.UUID, error)
+}
@@ -0,0 +1,231 @@
+package keycloak
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"group1-userservice/app/interfaces"
+)
+
+// adminToken logs in to the master realm with the admin-cli client and returns the access token
+func (p *Provider) adminToken() (string, error) {
+	adminTokenURL := fmt.Sprintf("%s/realms/master/protocol/openid-connect/token", p.config.URL)
+
+	form := url.Values{
+		"client_id":  {"admin-cli"},
+		"grant_type": {"password"},
+		"username":   {p.config.AdminUser},
+		"password":   {p.config.AdminPassword},
+	}
+
+	req, _ := http.NewRequest("POST", adminTokenURL, bytes.NewBufferString(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get admin token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to get admin token, status: %d", resp.StatusCode)
+	}
+
+	var adminToken interfaces.TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&adminToken); err != nil {
+		return "", fmt.Errorf("failed to decode admin token response: %w", err)
+	}
+
+	return adminToken.AccessToken, nil
+}
+
+// adminURL builds an Admin API URL for the configured realm
+func (p *Provider) adminURL(path string) string {
+	return fmt.Sprintf("%s/admin/realms/%s%s", p.config.URL, p.config.Realm, path)
+}
+
+// adminRequest sends a JSON request to the Admin API and fails unless Keycloak answers 204 No Content
+func (p *Provider) adminRequest(adminToken, method, path string, payload interface{}) error {
+	var body io.Reader = http.NoBody
+	if payload != nil {
+		raw, _ := json.Marshal(payload)
+		body = bytes.NewReader(raw)
+	}
+
+	req, _ := http.NewRequest(method, p.adminURL(path), body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call keycloak %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return interfaces.ErrIdentityNotFound
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("keycloak %s %s failed, status: %d", method, path, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// findUserID searches a user by email and returns the Keycloak ID
+func (p *Provider) findUserID(adminToken, email string) (string, error) {
+	req, _ := http.NewRequest("GET", p.adminURL("/users?exact=true&email="+url.QueryEscape(email)), nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to query user by email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to query user, status: %d", resp.StatusCode)
+	}
+
+	// Keycloak returns an array of users
+	var found []struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return "", fmt.Errorf("failed to decode user search response: %w", err)
+	}
+	if len(found) == 0 || found[0].ID == "" {
+		return "", interfaces.ErrIdentityNotFound
+	}
+
+	return found[0].ID, nil
+}
+
+// resetPassword sets a permanent password with the reset-password endpoint
+func (p *Provider) resetPassword(adminToken, keycloakID, password string) error {
+	return p.adminRequest(adminToken, "PUT", "/users/"+keycloakID+"/reset-password", map[string]interface{}{
+		"type":      "password",
+		"value":     password,
+		"temporary": false,
+	})
+}
+
+// updateUser changes the given attributes of a Keycloak user
+func (p *Provider) updateUser(keycloakID string, fields map[string]interface{}) error {
+	if keycloakID == "" {
+		return nil
+	}
+
+	adminToken, err := p.adminToken()
+	if err != nil {
+		return err
+	}
+	return p.adminRequest(adminToken, "PUT", "/users/"+keycloakID, fields)
+}
+
+// DisableAccount disables the Keycloak user so no new tokens can be issued
+func (p *Provider) DisableAccount(keycloakID string) error {
+	return p.updateUser(keycloakID, map[string]interface{}{"enabled": false})
+}
+
+// MarkEmailVerified sets emailVerified on the Keycloak user
+func (p *Provider) MarkEmailVerified(keycloakID string) error {
+	return p.updateUser(keycloakID, map[string]interface{}{"emailVerified": true})
+}
+
+// UpdateUser changes the email address and name of the Keycloak user
+func (p *Provider) UpdateUser(keycloakID string, user interfaces.IdentityUser) error {
+	return p.updateUser(keycloakID, map[string]interface{}{
+		"email":     user.Email,
+		"username":  user.Email,
+		"firstName": user.FirstName,
+		"lastName":  user.LastName,
+	})
+}
+
+// DeleteUser removes the Keycloak user; a user that is already gone is not an error
+func (p *Provider) DeleteUser(keycloakID string) error {
+	adminToken, err := p.adminToken()
+	if err != nil {
+		return err
+	}
+
+	err = p.adminRequest(adminToken, "DELETE", "/users/"+keycloakID, nil)
+	if errors.Is(err, interfaces.ErrIdentityNotFound) {
+		return nil
+	}
+	return err
+}
+
+// SetPassword replaces the password of the Keycloak user
+func (p *Provider) SetPassword(keycloakID string, password string) error {
+	adminToken, err := p.adminToken()
+	if err != nil {
+		return err
+	}
+	return p.resetPassword(adminToken, keycloakID, password)
+}
+
+// federatedIdentity is the link of a Keycloak user to an account at a brokered identity provider
+type federatedIdentity struct {
+	IdentityProvider string `json:"identityProvider"`
+	UserID           string `json:"userId"`
+	UserName         string `json:"userName"`
+}
+
+// federatedIdentities lists the brokered logins of a user
+func (p *Provider) federatedIdentities(adminToken, keycloakID string) ([]federatedIdentity, error) {
+	req, _ := http.NewRequest("GET", p.adminURL("/users/"+keycloakID+"/federated-identity"), nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query federated identities: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, interfaces.ErrIdentityNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to query federated identities, status: %d", resp.StatusCode)
+	}
+
+	var identities []federatedIdentity
+	if err := json.NewDecoder(resp.Body).Decode(&identities); err != nil {
+		return nil, fmt.Errorf("failed to decode federated identities: %w", err)
+	}
+	return identities, nil
+}
+
+// LinkSocialIdentity moves the brokered logins of keycloakID to target. The user keycloakID was
+// created by the first broker login and is deleted first, because a brokered account can be linked
+// to one Keycloak user only.
+func (p *Provider) LinkSocialIdentity(keycloakID, target string) error {
+	adminToken, err := p.adminToken()
+	if err != nil {
+		return err
+	}
+
+	identities, err := p.federatedIdentities(adminToken, keycloakID)
+	if err != nil {
+		return err
+	}
+
+	if err := p.adminRequest(adminToken, "DELETE", "/users/"+keycloakID, nil); err != nil {
+		return err
+	}
+
+	for _, identity := range identities {
+		path := "/users/" + target + "/federated-identity/" + identity.IdentityProvider
+		if err := p.adminRequest(adminToken, "POST", path, identity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package keycloak
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"group1-userservice/app/interfaces"
+)
+
+// Impersonate gets tokens for a user without their password, using a token exchange with
+// requested_subject ("direct naked impersonation"). Keycloak needs the token-exchange and
+// admin-fine-grained-authz features, and the user-service client needs the impersonation permission.
+func (p *Provider) Impersonate(keycloakID string) (*interfaces.TokenResponse, error) {
+	resp, err := p.requestToken(url.Values{
+		"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"requested_subject":    {keycloakID},
+		"requested_token_type": {"urn:ietf:params:oauth:token-type:refresh_token"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, interfaces.ErrImpersonationDenied
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to exchange token, status: %d", resp.StatusCode)
+	}
+
+	var token interfaces.TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// ExchangeSocialToken trades the ID token of a brokered identity provider for tokens of the realm
+// ("external to internal" token exchange). The provider is the alias of the identity provider in the
+// realm; on first use Keycloak creates the brokered user through the first broker login flow.
+func (p *Provider) ExchangeSocialToken(provider, idToken string) (*interfaces.TokenResponse, error) {
+	resp, err := p.requestToken(url.Values{
+		"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"subject_token":        {idToken},
+		"subject_issuer":       {provider},
+		"subject_token_type":   {"urn:ietf:params:oauth:token-type:jwt"},
+		"requested_token_type": {"urn:ietf:params:oauth:token-type:refresh_token"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Keycloak answers 400 for an ID token it cannot validate with the provider, and also when it
+	// would have to create a user for an email it already has and the first broker login flow does
+	// not link them itself
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		var body struct {
+			Description string `json:"error_description"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		if strings.Contains(strings.ToLower(body.Description), "already exists") {
+			return nil, interfaces.ErrEmailAlreadyExists
+		}
+		return nil, interfaces.ErrInvalidToken
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to exchange social token, status: %d", resp.StatusCode)
+	}
+
+	var token interfaces.TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
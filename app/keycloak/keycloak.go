@@ -6,44 +6,98 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
-	"group1-userservice/app/models"
+	"group1-userservice/app/interfaces"
+
+	"github.com/Nerzal/gocloak/v13"
 )
 
-// Returned when Keycloak reports a duplicate email (HTTP 409 Conflict)
-var ErrEmailAlreadyExists = errors.New("email already exists in keycloak")
+// Config says where the realm is and how the service logs in to it
+type Config struct {
+	URL          string
+	Realm        string
+	ClientID     string
+	ClientSecret string
+
+	// Admin account in the master realm, used for the Admin API
+	AdminUser     string
+	AdminPassword string
+
+	// Timeout of every call to Keycloak; ten seconds when zero
+	Timeout time.Duration
+}
+
+// ConfigFromEnv reads KEYCLOAK_URL, KEYCLOAK_REALM, KEYCLOAK_CLIENT_ID, KEYCLOAK_CLIENT_SECRET,
+// KEYCLOAK_ADMIN_USER and KEYCLOAK_ADMIN_PASS. URL and realm are required.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		URL:           os.Getenv("KEYCLOAK_URL"),
+		Realm:         os.Getenv("KEYCLOAK_REALM"),
+		ClientID:      os.Getenv("KEYCLOAK_CLIENT_ID"),
+		ClientSecret:  os.Getenv("KEYCLOAK_CLIENT_SECRET"),
+		AdminUser:     os.Getenv("KEYCLOAK_ADMIN_USER"),
+		AdminPassword: os.Getenv("KEYCLOAK_ADMIN_PASS"),
+	}
+
+	// Both URL and realm are required to validate tokens
+	if cfg.URL == "" || cfg.Realm == "" {
+		return Config{}, errors.New("KEYCLOAK_URL and KEYCLOAK_REALM must be set")
+	}
+
+	return cfg, nil
+}
+
+// Provider is the Keycloak implementation of interfaces.IdentityProvider
+type Provider struct {
+	config Config
+	http   *http.Client
+	jwt    *gocloak.GoCloak
+}
+
+var _ interfaces.IdentityProvider = (*Provider)(nil)
+
+// NewProvider creates a Keycloak provider
+func NewProvider(cfg Config) *Provider {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
 
-// TokenResponse matches the JSON structure returned by Keycloak's token endpoint
-type TokenResponse struct {
-	AccessToken      string `json:"access_token"`
-	TokenType        string `json:"token_type"`
-	ExpiresIn        int    `json:"expires_in"`
-	RefreshToken     string `json:"refresh_token"`
-	RefreshExpiresIn int    `json:"refresh_expires_in"`
+	return &Provider{
+		config: cfg,
+		http:   &http.Client{Timeout: cfg.Timeout},
+		jwt:    gocloak.NewClient(cfg.URL),
+	}
 }
 
-// GetAccessToken exchanges user credentials for an access token using OAuth2 password grant
-func GetAccessToken(email, password string) (*TokenResponse, error) {
-	// Token endpoint for the configured realm
-	url := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token",
-		os.Getenv("KEYCLOAK_URL"),
-		os.Getenv("KEYCLOAK_REALM"),
-	)
-
-	// Form-encoded OAuth2 password grant payload
-	data := fmt.Sprintf(
-		"client_id=%s&client_secret=%s&grant_type=password&username=%s&password=%s",
-		os.Getenv("KEYCLOAK_CLIENT_ID"),
-		os.Getenv("KEYCLOAK_CLIENT_SECRET"),
-		email, password,
-	)
-
-	// Create HTTP POST request with a body stream (io.Reader) from the form data
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer([]byte(data)))
+// tokenURL is the token endpoint of the configured realm
+func (p *Provider) tokenURL() string {
+	return fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token", p.config.URL, p.config.Realm)
+}
+
+// requestToken posts a grant to the token endpoint as the user-service client
+func (p *Provider) requestToken(form url.Values) (*http.Response, error) {
+	form.Set("client_id", p.config.ClientID)
+	form.Set("client_secret", p.config.ClientSecret)
+
+	req, _ := http.NewRequest("POST", p.tokenURL(), strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := http.DefaultClient.Do(req)
+	return p.http.Do(req)
+}
+
+// PasswordGrant exchanges user credentials for an access token using OAuth2 password grant.
+// A rejection is returned as one of the interfaces.Err* reasons when Keycloak says why.
+func (p *Provider) PasswordGrant(email, password string) (*interfaces.TokenResponse, error) {
+	// Form-encoded, so a password may contain & or =
+	resp, err := p.requestToken(url.Values{
+		"grant_type": {"password"},
+		"username":   {email},
+		"password":   {password},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -51,11 +105,11 @@ func GetAccessToken(email, password string) (*TokenResponse, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("failed to get access token, status: %d", resp.StatusCode)
+		return nil, grantError(resp)
 	}
 
 	// Decode JSON response body into the TokenResponse struct (stream decoding)
-	var token TokenResponse
+	var token interfaces.TokenResponse
 	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
 		return nil, err
 	}
@@ -63,47 +117,39 @@ func GetAccessToken(email, password string) (*TokenResponse, error) {
 	return &token, nil
 }
 
-// CreateUserInKeycloakWithPassword creates a user via Keycloak Admin API and sets an initial password
-func CreateUserInKeycloakWithPassword(user models.User, plainPassword string) (string, error) {
-	// Step 1: Get an admin access token from the master realm (needed for admin endpoints)
-	adminTokenURL := fmt.Sprintf(
-		"%s/realms/master/protocol/openid-connect/token",
-		os.Getenv("KEYCLOAK_URL"),
-	)
-
-	// Admin login using password grant against the built-in "admin-cli" client
-	data := fmt.Sprintf(
-		"client_id=admin-cli&grant_type=password&username=%s&password=%s",
-		os.Getenv("KEYCLOAK_ADMIN_USER"),
-		os.Getenv("KEYCLOAK_ADMIN_PASS"),
-	)
-
-	req, _ := http.NewRequest("POST", adminTokenURL, bytes.NewBuffer([]byte(data)))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+// grantError maps the error_description of a rejected grant to a reason. Keycloak answers
+// invalid_grant for all of them, so the description is the only thing that tells them apart.
+func grantError(resp *http.Response) error {
+	var body struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+
+	if body.Error == "invalid_grant" {
+		description := strings.ToLower(body.Description)
+		switch {
+		case strings.Contains(description, "temporarily disabled"), strings.Contains(description, "locked"):
+			return interfaces.ErrAccountLocked
+		case strings.Contains(description, "disabled"):
+			return interfaces.ErrAccountDisabled
+		case strings.Contains(description, "not fully set up"), strings.Contains(description, "not verified"):
+			return interfaces.ErrAccountNotVerified
+		case strings.Contains(description, "invalid user credentials"):
+			return interfaces.ErrInvalidCredentials
+		}
+	}
+
+	return fmt.Errorf("failed to get access token, status: %d, error: %s", resp.StatusCode, body.Error)
+}
 
-	resp, err := http.DefaultClient.Do(req)
+// CreateUser creates a user via the Admin API, sets the initial password and returns the Keycloak ID
+func (p *Provider) CreateUser(user interfaces.IdentityUser, password string) (string, error) {
+	adminToken, err := p.adminToken()
 	if err != nil {
-		return "", fmt.Errorf("failed to get admin token: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", fmt.Errorf("failed to get admin token, status: %d", resp.StatusCode)
+		return "", err
 	}
 
-	// Parse admin access token from JSON response
-	var adminToken TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&adminToken); err != nil {
-		return "", fmt.Errorf("failed to decode admin token response: %w", err)
-	}
-
-	// Step 2: Create the user in the configured realm using the Admin API
-	createURL := fmt.Sprintf(
-		"%s/admin/realms/%s/users",
-		os.Getenv("KEYCLOAK_URL"),
-		os.Getenv("KEYCLOAK_REALM"),
-	)
-
 	// Payload for the "create user" call (Keycloak expects JSON)
 	newUser := map[string]interface{}{
 		"email":     user.Email,
@@ -111,16 +157,16 @@ func CreateUserInKeycloakWithPassword(user models.User, plainPassword string) (s
 		"firstName": user.FirstName,
 		"lastName":  user.LastName,
 		"enabled":   true,
+		// set once the user follows the verification link (see MarkEmailVerified)
+		"emailVerified": false,
 	}
-
-	// Convert Go map to JSON bytes for the request body
 	body, _ := json.Marshal(newUser)
 
-	req, _ = http.NewRequest("POST", createURL, bytes.NewBuffer(body))
+	req, _ := http.NewRequest("POST", p.adminURL("/users"), bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+adminToken.AccessToken)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
 
-	resp, err = http.DefaultClient.Do(req)
+	resp, err := p.http.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to call keycloak create user: %w", err)
 	}
@@ -128,192 +174,21 @@ func CreateUserInKeycloakWithPassword(user models.User, plainPassword string) (s
 
 	// 409 Conflict with duplicate email in Keycloak
 	if resp.StatusCode == http.StatusConflict {
-		return "", ErrEmailAlreadyExists
+		return "", interfaces.ErrEmailAlreadyExists
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return "", fmt.Errorf("failed to create user in keycloak, status: %d", resp.StatusCode)
 	}
 
-	// Step 3: Query the user to retrieve the generated Keycloak user ID (UUID)
-	findURL := fmt.Sprintf(
-		"%s/admin/realms/%s/users?email=%s",
-		os.Getenv("KEYCLOAK_URL"),
-		os.Getenv("KEYCLOAK_REALM"),
-		user.Email,
-	)
-
-	getReq, _ := http.NewRequest("GET", findURL, nil)
-	getReq.Header.Set("Authorization", "Bearer "+adminToken.AccessToken)
-
-	findResp, err := http.DefaultClient.Do(getReq)
+	// Query the user to retrieve the generated Keycloak user ID (UUID)
+	kcID, err := p.findUserID(adminToken, user.Email)
 	if err != nil {
-		return "", fmt.Errorf("failed to query created user: %w", err)
+		return "", err
 	}
-	defer findResp.Body.Close()
 
-	if findResp.StatusCode < 200 || findResp.StatusCode >= 300 {
-		return "", fmt.Errorf("failed to query created user, status: %d", findResp.StatusCode)
-	}
-
-	// Decode JSON array of users returned by Keycloak search
-	var found []map[string]interface{}
-	if err := json.NewDecoder(findResp.Body).Decode(&found); err != nil {
-		return "", fmt.Errorf("failed to decode user search response: %w", err)
-	}
-
-	// Keycloak search returned no user, something went wrong after creation
-	if len(found) == 0 {
-		return "", fmt.Errorf("user not found after creation")
-	}
-
-	// Extract the id field from the first result
-	idRaw, ok := found[0]["id"]
-	if !ok {
-		return "", fmt.Errorf("user id missing in keycloak response")
-	}
-
-	// Type assertion: JSON decoding uses interface so we assert it's a string UUID
-	kcID, ok := idRaw.(string)
-	if !ok {
-		return "", fmt.Errorf("invalid keycloak id type")
-	}
-
-	// Step 4: Set initial password using the reset-password admin endpoint
-	passURL := fmt.Sprintf(
-		"%s/admin/realms/%s/users/%s/reset-password",
-		os.Getenv("KEYCLOAK_URL"),
-		os.Getenv("KEYCLOAK_REALM"),
-		kcID,
-	)
-
-	passPayload := map[string]interface{}{
-		"type":      "password",
-		"value":     plainPassword,
-		"temporary": false,
-	}
-
-	// Convert password payload to JSON
-	passJSON, _ := json.Marshal(passPayload)
-
-	reqPass, _ := http.NewRequest("PUT", passURL, bytes.NewBuffer(passJSON))
-	reqPass.Header.Set("Content-Type", "application/json")
-	reqPass.Header.Set("Authorization", "Bearer "+adminToken.AccessToken)
-
-	passResp, err := http.DefaultClient.Do(reqPass)
-	if err != nil {
-		return "", fmt.Errorf("failed to set password in keycloak: %w", err)
-	}
-	defer passResp.Body.Close()
-
-	// Keycloak returns 204 No Content when password update succeeds
-	if passResp.StatusCode != http.StatusNoContent {
-		return "", fmt.Errorf("failed to set password, status: %d", passResp.StatusCode)
+	if err := p.resetPassword(adminToken, kcID, password); err != nil {
+		return "", err
 	}
 
 	return kcID, nil
 }
-
-// ResetKeycloakPasswordByEmail finds a user by email and updates their password via Admin API
-func ResetKeycloakPasswordByEmail(email, newPassword string) error {
-	// Get admin access token (same flow as in user creation)
-	adminTokenURL := fmt.Sprintf("%s/realms/master/protocol/openid-connect/token", os.Getenv("KEYCLOAK_URL"))
-
-	data := fmt.Sprintf(
-		"client_id=admin-cli&grant_type=password&username=%s&password=%s",
-		os.Getenv("KEYCLOAK_ADMIN_USER"),
-		os.Getenv("KEYCLOAK_ADMIN_PASS"),
-	)
-
-	req, _ := http.NewRequest("POST", adminTokenURL, bytes.NewBuffer([]byte(data)))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to get admin token: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to get admin token, status: %d", resp.StatusCode)
-	}
-
-	// Decode admin token from JSON response
-	var adminToken TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&adminToken); err != nil {
-		return fmt.Errorf("failed to decode admin token response: %w", err)
-	}
-
-	// Search for the user by email to get their Keycloak user ID
-	findURL := fmt.Sprintf(
-		"%s/admin/realms/%s/users?email=%s",
-		os.Getenv("KEYCLOAK_URL"),
-		os.Getenv("KEYCLOAK_REALM"),
-		email,
-	)
-
-	getReq, _ := http.NewRequest("GET", findURL, nil)
-	getReq.Header.Set("Authorization", "Bearer "+adminToken.AccessToken)
-
-	findResp, err := http.DefaultClient.Do(getReq)
-	if err != nil {
-		return fmt.Errorf("failed to query user by email: %w", err)
-	}
-	defer findResp.Body.Close()
-
-	if findResp.StatusCode < 200 || findResp.StatusCode >= 300 {
-		return fmt.Errorf("failed to query user, status: %d", findResp.StatusCode)
-	}
-
-	// Decode search results (Keycloak returns an array)
-	var found []map[string]interface{}
-	if err := json.NewDecoder(findResp.Body).Decode(&found); err != nil {
-		return fmt.Errorf("failed to decode user search response: %w", err)
-	}
-	if len(found) == 0 {
-		return fmt.Errorf("user not found in keycloak")
-	}
-
-	// Extract and type-assert the Keycloak user ID (UUID string)
-	idRaw, ok := found[0]["id"]
-	if !ok {
-		return fmt.Errorf("user id missing in keycloak response")
-	}
-	kcID, ok := idRaw.(string)
-	if !ok {
-		return fmt.Errorf("invalid keycloak id type")
-	}
-
-	// Build reset-password endpoint and send new password payload
-	passURL := fmt.Sprintf("%s/admin/realms/%s/users/%s/reset-password",
-		os.Getenv("KEYCLOAK_URL"),
-		os.Getenv("KEYCLOAK_REALM"),
-		kcID,
-	)
-
-	passPayload := map[string]interface{}{
-		"type":      "password",
-		"value":     newPassword,
-		"temporary": false,
-	}
-	passJSON, _ := json.Marshal(passPayload)
-
-	reqPass, _ := http.NewRequest("PUT", passURL, bytes.NewBuffer(passJSON))
-	reqPass.Header.Set("Content-Type", "application/json")
-	reqPass.Header.Set("Authorization", "Bearer "+adminToken.AccessToken)
-
-	passResp, err := http.DefaultClient.Do(reqPass)
-	if err != nil {
-		return fmt.Errorf("failed to set password in keycloak: %w", err)
-	}
-	defer passResp.Body.Close()
-
-	// 204 No Content indicates success
-	if passResp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("failed to set password, status: %d", passResp.StatusCode)
-	}
-
-	return nil
-}
-
-// Function variable so tests can replace it with a mock implementation
-var ResetPasswordByEmail = ResetKeycloakPasswordByEmail
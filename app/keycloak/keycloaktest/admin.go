@@ -0,0 +1,270 @@
+package keycloaktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// representation is the user JSON of the Admin API
+type representation struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	FirstName     string `json:"firstName"`
+	LastName      string `json:"lastName"`
+	Enabled       bool   `json:"enabled"`
+	EmailVerified bool   `json:"emailVerified"`
+}
+
+func toRepresentation(u *User) representation {
+	return representation{
+		ID:            u.ID,
+		Username:      u.Username,
+		Email:         u.Email,
+		FirstName:     u.FirstName,
+		LastName:      u.LastName,
+		Enabled:       u.Enabled,
+		EmailVerified: u.EmailVerified,
+	}
+}
+
+// admin only lets requests with an admin token of the master realm through to the realm
+func (s *Server) admin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "HTTP 401 Unauthorized"})
+			return
+		}
+		if _, err := s.parse(raw, "Bearer", "master"); err != nil {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "HTTP 401 Unauthorized"})
+			return
+		}
+		if r.PathValue("realm") != s.Realm {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Realm not found."})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// taken reports whether another user already has the username or email; callers hold the lock
+func (s *Server) taken(id, username, email string) bool {
+	for _, u := range s.users {
+		if u.ID == id {
+			continue
+		}
+		if (username != "" && strings.EqualFold(u.Username, username)) || (email != "" && strings.EqualFold(u.Email, email)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var rep representation
+	if err := json.NewDecoder(r.Body).Decode(&rep); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"errorMessage": "Invalid user representation"})
+		return
+	}
+	if rep.Username == "" {
+		rep.Username = rep.Email
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.taken("", rep.Username, rep.Email) {
+		writeJSON(w, http.StatusConflict, map[string]string{"errorMessage": "User exists with same username or email"})
+		return
+	}
+
+	u := &User{
+		ID:            uuid.NewString(),
+		Username:      strings.ToLower(rep.Username),
+		Email:         rep.Email,
+		FirstName:     rep.FirstName,
+		LastName:      rep.LastName,
+		Enabled:       rep.Enabled,
+		EmailVerified: rep.EmailVerified,
+	}
+	s.users[u.ID] = u
+
+	w.Header().Set("Location", s.URL+"/admin/realms/"+s.Realm+"/users/"+u.ID)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// searchUsers filters on email and username; without exact=true they match on a substring
+func (s *Server) searchUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	exact := query.Get("exact") == "true"
+
+	matches := func(value, filter string) bool {
+		if filter == "" {
+			return true
+		}
+		if exact {
+			return strings.EqualFold(value, filter)
+		}
+		return strings.Contains(strings.ToLower(value), strings.ToLower(filter))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := []representation{}
+	for _, u := range s.users {
+		if matches(u.Email, query.Get("email")) && matches(u.Username, query.Get("username")) {
+			found = append(found, toRepresentation(u))
+		}
+	}
+	writeJSON(w, http.StatusOK, found)
+}
+
+// updateUser changes only the attributes present in the body
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request) {
+	var rep struct {
+		Username      *string `json:"username"`
+		Email         *string `json:"email"`
+		FirstName     *string `json:"firstName"`
+		LastName      *string `json:"lastName"`
+		Enabled       *bool   `json:"enabled"`
+		EmailVerified *bool   `json:"emailVerified"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&rep); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"errorMessage": "Invalid user representation"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[r.PathValue("id")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+		return
+	}
+
+	var username, email string
+	if rep.Username != nil {
+		username = *rep.Username
+	}
+	if rep.Email != nil {
+		email = *rep.Email
+	}
+	if s.taken(u.ID, username, email) {
+		writeJSON(w, http.StatusConflict, map[string]string{"errorMessage": "User exists with same username or email"})
+		return
+	}
+
+	if rep.Username != nil {
+		u.Username = strings.ToLower(*rep.Username)
+	}
+	if rep.Email != nil {
+		u.Email = *rep.Email
+	}
+	if rep.FirstName != nil {
+		u.FirstName = *rep.FirstName
+	}
+	if rep.LastName != nil {
+		u.LastName = *rep.LastName
+	}
+	if rep.Enabled != nil {
+		u.Enabled = *rep.Enabled
+	}
+	if rep.EmailVerified != nil {
+		u.EmailVerified = *rep.EmailVerified
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteUser removes the user and ends its sessions
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if _, ok := s.users[id]; !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+		return
+	}
+
+	delete(s.users, id)
+	for sessionID, userID := range s.sessions {
+		if userID == id {
+			delete(s.sessions, sessionID)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) resetPassword(w http.ResponseWriter, r *http.Request) {
+	var credential struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&credential); err != nil || credential.Type != "password" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"errorMessage": "Invalid credential"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[r.PathValue("id")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+		return
+	}
+
+	u.Password = credential.Value
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) federatedIdentities(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[r.PathValue("id")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+		return
+	}
+
+	identities := append([]FederatedIdentity{}, u.FederatedIdentities...)
+	writeJSON(w, http.StatusOK, identities)
+}
+
+// addFederatedIdentity links a brokered account to the user; an account is linked to one user only
+func (s *Server) addFederatedIdentity(w http.ResponseWriter, r *http.Request) {
+	var identity FederatedIdentity
+	if err := json.NewDecoder(r.Body).Decode(&identity); err != nil || identity.UserID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"errorMessage": "Invalid federated identity"})
+		return
+	}
+	identity.Provider = r.PathValue("provider")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[r.PathValue("id")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+		return
+	}
+
+	for _, other := range s.users {
+		for _, linked := range other.FederatedIdentities {
+			if linked.Provider == identity.Provider && linked.UserID == identity.UserID {
+				writeJSON(w, http.StatusConflict, map[string]string{"errorMessage": "User is already linked with provider"})
+				return
+			}
+		}
+	}
+
+	u.FederatedIdentities = append(u.FederatedIdentities, identity)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,282 @@
+// Package keycloaktest runs a fake Keycloak on an httptest server, so the HTTP code of the keycloak
+// package can be tested without a container. It knows one realm with one confidential client, the
+// admin-cli client of the master realm, and the endpoints the user service calls: the token endpoint
+// (password and refresh grants, social token exchange), logout, certs (JWKS) and the users part of
+// the Admin API.
+package keycloaktest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"group1-userservice/app/keycloak"
+
+	"github.com/google/uuid"
+)
+
+// User is a user of the fake realm. Password is kept in plain text; this is a test double.
+type User struct {
+	ID            string
+	Username      string
+	Email         string
+	FirstName     string
+	LastName      string
+	Password      string
+	Enabled       bool
+	EmailVerified bool
+	RealmRoles    []string
+
+	// FederatedIdentities are the brokered logins linked to the user
+	FederatedIdentities []FederatedIdentity
+}
+
+// FederatedIdentity links a user to an account at a brokered identity provider
+type FederatedIdentity struct {
+	Provider string `json:"identityProvider"`
+	UserID   string `json:"userId"`
+	UserName string `json:"userName"`
+}
+
+// SocialAccount is the account at a brokered provider that an ID token belongs to
+type SocialAccount struct {
+	UserID        string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+type socialToken struct {
+	provider string
+	account  SocialAccount
+}
+
+// Fault replaces the answer to matching requests. A request matches when the method is equal
+// (or Method is empty) and the path ends with Path, e.g. "/users" or "/openid-connect/token".
+type Fault struct {
+	Method string
+	Path   string
+
+	// Status is answered instead of handling the request; zero handles it normally after Delay
+	Status int
+
+	// Delay is waited before answering; longer than the client timeout simulates a hanging Keycloak
+	Delay time.Duration
+
+	// Times the fault fires; zero keeps it until ClearFaults
+	Times int
+}
+
+// Server is a fake Keycloak. Create it with NewServer and Close it when done.
+type Server struct {
+	*httptest.Server
+
+	Realm         string
+	ClientID      string
+	ClientSecret  string
+	AdminUser     string
+	AdminPassword string
+
+	// RequireVerifiedEmail rejects password grants of unverified users, like the
+	// "Verify Email" required action does
+	RequireVerifiedEmail bool
+
+	// LinkSocialByEmail makes the first broker login link a social account to the user with the same
+	// verified email; without it the exchange is refused with "User already exists"
+	LinkSocialByEmail bool
+
+	key *rsa.PrivateKey
+	kid string
+
+	mu       sync.Mutex
+	users    map[string]*User  // by ID
+	sessions map[string]string // session ID → user ID
+	social   map[string]socialToken
+	faults   []*Fault
+}
+
+// NewServer starts a fake Keycloak with realm "test", client "user-service" with secret "secret"
+// and admin account admin/admin
+func NewServer() *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic("keycloaktest: " + err.Error())
+	}
+
+	s := &Server{
+		Realm:         "test",
+		ClientID:      "user-service",
+		ClientSecret:  "secret",
+		AdminUser:     "admin",
+		AdminPassword: "admin",
+		key:           key,
+		kid:           uuid.NewString(),
+		users:         map[string]*User{},
+		sessions:      map[string]string{},
+		social:        map[string]socialToken{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /realms/{realm}/protocol/openid-connect/token", s.token)
+	mux.HandleFunc("POST /realms/{realm}/protocol/openid-connect/logout", s.logout)
+	mux.HandleFunc("GET /realms/{realm}/protocol/openid-connect/certs", s.certs)
+	mux.HandleFunc("POST /admin/realms/{realm}/users", s.admin(s.createUser))
+	mux.HandleFunc("GET /admin/realms/{realm}/users", s.admin(s.searchUsers))
+	mux.HandleFunc("PUT /admin/realms/{realm}/users/{id}", s.admin(s.updateUser))
+	mux.HandleFunc("DELETE /admin/realms/{realm}/users/{id}", s.admin(s.deleteUser))
+	mux.HandleFunc("PUT /admin/realms/{realm}/users/{id}/reset-password", s.admin(s.resetPassword))
+	mux.HandleFunc("GET /admin/realms/{realm}/users/{id}/federated-identity", s.admin(s.federatedIdentities))
+	mux.HandleFunc("POST /admin/realms/{realm}/users/{id}/federated-identity/{provider}", s.admin(s.addFederatedIdentity))
+
+	s.Server = httptest.NewServer(s.inject(mux))
+	return s
+}
+
+// Config is a keycloak.Config pointing at this server
+func (s *Server) Config() keycloak.Config {
+	return keycloak.Config{
+		URL:           s.URL,
+		Realm:         s.Realm,
+		ClientID:      s.ClientID,
+		ClientSecret:  s.ClientSecret,
+		AdminUser:     s.AdminUser,
+		AdminPassword: s.AdminPassword,
+	}
+}
+
+// AddUser puts a user in the realm and returns its ID; an empty ID or username is filled in
+func (s *Server) AddUser(u User) string {
+	if u.ID == "" {
+		u.ID = uuid.NewString()
+	}
+	if u.Username == "" {
+		u.Username = strings.ToLower(u.Email)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[u.ID] = &u
+	return u.ID
+}
+
+// User returns a copy of the user with the given email
+func (s *Server) User(email string) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u := s.byEmail(email); u != nil {
+		return *u, true
+	}
+	return User{}, false
+}
+
+// AddSocialToken makes idToken a valid ID token of the brokered provider for the account
+func (s *Server) AddSocialToken(provider, idToken string, account SocialAccount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.social[idToken] = socialToken{provider: provider, account: account}
+}
+
+// Inject adds a fault; faults are checked in the order they were added
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// inject answers a request with the first matching fault, if any
+func (s *Server) inject(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := s.takeFault(r)
+		if f == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if f.Delay > 0 {
+			select {
+			case <-time.After(f.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if f.Status == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		writeJSON(w, f.Status, map[string]string{"error": http.StatusText(f.Status)})
+	})
+}
+
+// takeFault returns the first fault matching the request and uses up one of its times
+func (s *Server) takeFault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasSuffix(r.URL.Path, f.Path) {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// byEmail finds a user regardless of case; callers hold the lock
+func (s *Server) byEmail(email string) *User {
+	for _, u := range s.users {
+		if strings.EqualFold(u.Email, email) {
+			return u
+		}
+	}
+	return nil
+}
+
+// byUsername finds a user by username or email, like the password grant does; callers hold the lock
+func (s *Server) byUsername(username string) *User {
+	for _, u := range s.users {
+		if strings.EqualFold(u.Username, username) || strings.EqualFold(u.Email, username) {
+			return u
+		}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeOAuthError answers like Keycloak does for a rejected grant
+func writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
@@ -0,0 +1,287 @@
+package keycloaktest
+
+import (
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"group1-userservice/app/interfaces"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	accessTTL  = 5 * time.Minute
+	refreshTTL = 30 * time.Minute
+)
+
+// issuer is the "iss" claim of tokens of a realm
+func (s *Server) issuer(realm string) string {
+	return s.URL + "/realms/" + realm
+}
+
+// sign signs claims with the realm key, with the key ID in the header so JWKS lookups work
+func (s *Server) sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.kid
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		panic("keycloaktest: " + err.Error())
+	}
+	return signed
+}
+
+// parse verifies a token of the given type issued for the realm and returns its claims
+func (s *Server) parse(raw, typ, realm string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(*jwt.Token) (interface{}, error) {
+		return &s.key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(s.issuer(realm)))
+	if err != nil {
+		return nil, err
+	}
+	if claims["typ"] != typ {
+		return nil, errors.New("wrong token type")
+	}
+	return claims, nil
+}
+
+// issue creates an access and refresh token for a user session of the realm; callers hold the lock
+func (s *Server) issue(u *User, sessionID string) interfaces.TokenResponse {
+	now := time.Now()
+
+	claims := func(typ string, ttl time.Duration) jwt.MapClaims {
+		return jwt.MapClaims{
+			"jti":                uuid.NewString(),
+			"iss":                s.issuer(s.Realm),
+			"sub":                u.ID,
+			"typ":                typ,
+			"azp":                s.ClientID,
+			"sid":                sessionID,
+			"iat":                now.Unix(),
+			"exp":                now.Add(ttl).Unix(),
+			"email":              u.Email,
+			"email_verified":     u.EmailVerified,
+			"preferred_username": u.Username,
+			"given_name":         u.FirstName,
+			"family_name":        u.LastName,
+			"realm_access":       map[string]interface{}{"roles": u.RealmRoles},
+		}
+	}
+
+	return interfaces.TokenResponse{
+		AccessToken:      s.sign(claims("Bearer", accessTTL)),
+		TokenType:        "Bearer",
+		ExpiresIn:        int(accessTTL.Seconds()),
+		RefreshToken:     s.sign(claims("Refresh", refreshTTL)),
+		RefreshExpiresIn: int(refreshTTL.Seconds()),
+	}
+}
+
+// adminLogin handles the token endpoint of the master realm, where only the admin account logs in
+func (s *Server) adminLogin(w http.ResponseWriter, r *http.Request) {
+	if r.PostForm.Get("client_id") != "admin-cli" || r.PostForm.Get("grant_type") != "password" ||
+		r.PostForm.Get("username") != s.AdminUser || r.PostForm.Get("password") != s.AdminPassword {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_grant", "Invalid user credentials")
+		return
+	}
+
+	now := time.Now()
+	writeJSON(w, http.StatusOK, interfaces.TokenResponse{
+		AccessToken: s.sign(jwt.MapClaims{
+			"iss": s.issuer("master"),
+			"sub": "admin",
+			"typ": "Bearer",
+			"azp": "admin-cli",
+			"iat": now.Unix(),
+			"exp": now.Add(accessTTL).Unix(),
+		}),
+		TokenType: "Bearer",
+		ExpiresIn: int(accessTTL.Seconds()),
+	})
+}
+
+// client checks the realm and client credentials of a form post and answers when they are wrong
+func (s *Server) client(w http.ResponseWriter, r *http.Request) bool {
+	if r.PathValue("realm") != s.Realm {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Realm does not exist"})
+		return false
+	}
+	if r.PostForm.Get("client_id") != s.ClientID || r.PostForm.Get("client_secret") != s.ClientSecret {
+		writeOAuthError(w, http.StatusUnauthorized, "unauthorized_client", "Invalid client or Invalid client credentials")
+		return false
+	}
+	return true
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Invalid form")
+		return
+	}
+	if r.PathValue("realm") == "master" {
+		s.adminLogin(w, r)
+		return
+	}
+	if !s.client(w, r) {
+		return
+	}
+
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+		s.passwordGrant(w, r)
+	case "refresh_token":
+		s.refreshGrant(w, r)
+	case "urn:ietf:params:oauth:grant-type:token-exchange":
+		s.socialExchange(w, r)
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "Unsupported grant_type")
+	}
+}
+
+func (s *Server) passwordGrant(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.byUsername(r.PostForm.Get("username"))
+	switch {
+	case u == nil || u.Password != r.PostForm.Get("password"):
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_grant", "Invalid user credentials")
+	case !u.Enabled:
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Account disabled")
+	case s.RequireVerifiedEmail && !u.EmailVerified:
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Account is not fully set up")
+	default:
+		sessionID := uuid.NewString()
+		s.sessions[sessionID] = u.ID
+		writeJSON(w, http.StatusOK, s.issue(u, sessionID))
+	}
+}
+
+func (s *Server) refreshGrant(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.parse(r.PostForm.Get("refresh_token"), "Refresh", s.Realm)
+	if err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Invalid refresh token")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionID, _ := claims["sid"].(string)
+	userID, ok := s.sessions[sessionID]
+	if !ok {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Session not active")
+		return
+	}
+
+	u, ok := s.users[userID]
+	if !ok || !u.Enabled {
+		delete(s.sessions, sessionID)
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Token is not active")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.issue(u, sessionID))
+}
+
+// socialExchange handles an external to internal token exchange, running a first broker login
+// for social accounts that are not linked to a user yet
+func (s *Server) socialExchange(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	social, ok := s.social[r.PostForm.Get("subject_token")]
+	if !ok || social.provider != r.PostForm.Get("subject_issuer") {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_token", "Invalid token")
+		return
+	}
+	account := social.account
+
+	var user *User
+	for _, u := range s.users {
+		for _, identity := range u.FederatedIdentities {
+			if identity.Provider == social.provider && identity.UserID == account.UserID {
+				user = u
+			}
+		}
+	}
+
+	link := FederatedIdentity{Provider: social.provider, UserID: account.UserID, UserName: account.Email}
+	if user == nil {
+		user = s.byEmail(account.Email)
+		switch {
+		case user != nil && s.LinkSocialByEmail && account.EmailVerified:
+			user.FederatedIdentities = append(user.FederatedIdentities, link)
+		case user != nil:
+			writeOAuthError(w, http.StatusBadRequest, "invalid_token", "User already exists")
+			return
+		default:
+			user = &User{
+				ID:                  uuid.NewString(),
+				Username:            strings.ToLower(account.Email),
+				Email:               account.Email,
+				FirstName:           account.FirstName,
+				LastName:            account.LastName,
+				Enabled:             true,
+				EmailVerified:       account.EmailVerified,
+				FederatedIdentities: []FederatedIdentity{link},
+			}
+			s.users[user.ID] = user
+		}
+	}
+
+	if !user.Enabled {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Account disabled")
+		return
+	}
+
+	sessionID := uuid.NewString()
+	s.sessions[sessionID] = user.ID
+	writeJSON(w, http.StatusOK, s.issue(user, sessionID))
+}
+
+// logout ends the session of a refresh token, after which it can no longer be refreshed
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Invalid form")
+		return
+	}
+	if !s.client(w, r) {
+		return
+	}
+
+	claims, err := s.parse(r.PostForm.Get("refresh_token"), "Refresh", s.Realm)
+	if err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Invalid refresh token")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionID, _ := claims["sid"].(string)
+	delete(s.sessions, sessionID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// certs publishes the public key of the realm as a JWKS
+func (s *Server) certs(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": s.kid,
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
@@ -0,0 +1,51 @@
+package keycloak
+
+import (
+	"context"
+
+	"group1-userservice/app/interfaces"
+)
+
+// VerifyToken checks an access token against the public keys of the realm
+func (p *Provider) VerifyToken(accessToken string) (*interfaces.IdentityClaims, error) {
+	_, claims, err := p.jwt.DecodeAccessToken(context.Background(), accessToken, p.config.Realm)
+	if err != nil {
+		return nil, interfaces.ErrInvalidToken
+	}
+
+	sub, _ := (*claims)["sub"].(string)
+	email, _ := (*claims)["email"].(string)
+	verified, _ := (*claims)["email_verified"].(bool)
+	firstName, _ := (*claims)["given_name"].(string)
+	lastName, _ := (*claims)["family_name"].(string)
+
+	return &interfaces.IdentityClaims{
+		Subject:       sub,
+		Email:         email,
+		EmailVerified: verified,
+		FirstName:     firstName,
+		LastName:      lastName,
+		RealmRoles:    realmRoles(*claims),
+	}, nil
+}
+
+// realmRoles reads the realm roles from the "realm_access.roles" claim of a Keycloak access token
+func realmRoles(claims map[string]interface{}) []string {
+	access, ok := claims["realm_access"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	raw, ok := access["roles"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	roles := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if s, ok := r.(string); ok {
+			roles = append(roles, s)
+		}
+	}
+	return roles
+}
@@ -0,0 +1,343 @@
+// Package localidp is an identity provider that runs inside the service and signs its own JWTs.
+// It is meant for development and tests without a Keycloak container, not for production.
+package localidp
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"group1-userservice/app/interfaces"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Config for the local identity provider
+type Config struct {
+	// Secret signs the tokens (HS256)
+	Secret     []byte
+	Issuer     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+
+	// StorePath is a JSON file the accounts are kept in; empty keeps them in memory only
+	StorePath string
+
+	// Roles are the realm roles given to accounts, by email
+	Roles map[string][]string
+}
+
+// ConfigFromEnv reads LOCAL_IDP_SECRET, LOCAL_IDP_STORE and LOCAL_IDP_ROLES.
+// Without a secret a random one is used, so tokens stop working after a restart.
+// LOCAL_IDP_ROLES looks like "alice@example.com=admin;moderator,bob@example.com=moderator".
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Secret:     []byte(os.Getenv("LOCAL_IDP_SECRET")),
+		Issuer:     "group1-userservice-local",
+		AccessTTL:  5 * time.Minute,
+		RefreshTTL: 30 * time.Minute,
+		StorePath:  os.Getenv("LOCAL_IDP_STORE"),
+		Roles:      map[string][]string{},
+	}
+
+	if len(cfg.Secret) == 0 {
+		cfg.Secret = make([]byte, 32)
+		if _, err := rand.Read(cfg.Secret); err != nil {
+			return Config{}, err
+		}
+		log.Println("LOCAL_IDP_SECRET not set, tokens will not survive a restart")
+	}
+
+	for _, entry := range strings.Split(os.Getenv("LOCAL_IDP_ROLES"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		email, roles, ok := strings.Cut(entry, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("invalid LOCAL_IDP_ROLES entry %q", entry)
+		}
+		cfg.Roles[strings.ToLower(strings.TrimSpace(email))] = strings.Split(strings.TrimSpace(roles), ";")
+	}
+
+	return cfg, nil
+}
+
+type account struct {
+	Subject       string `json:"subject"`
+	Email         string `json:"email"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	PasswordHash  string `json:"password_hash"`
+	Enabled       bool   `json:"enabled"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// claims of the issued tokens, shaped like those of Keycloak so clients see no difference
+type claims struct {
+	jwt.RegisteredClaims
+	Type          string `json:"typ"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name,omitempty"`
+	FamilyName    string `json:"family_name,omitempty"`
+	RealmAccess   struct {
+		Roles []string `json:"roles"`
+	} `json:"realm_access"`
+}
+
+// Provider is the local implementation of interfaces.IdentityProvider
+type Provider struct {
+	config Config
+
+	mu       sync.Mutex
+	accounts map[string]*account // by subject
+}
+
+var _ interfaces.IdentityProvider = (*Provider)(nil)
+
+// New creates the provider and loads the accounts from the store file if there is one
+func New(cfg Config) (*Provider, error) {
+	p := &Provider{config: cfg, accounts: map[string]*account{}}
+
+	if cfg.StorePath == "" {
+		return p, nil
+	}
+
+	raw, err := os.ReadFile(cfg.StorePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &p.accounts); err != nil {
+		return nil, fmt.Errorf("invalid local identity store %s: %w", cfg.StorePath, err)
+	}
+	return p, nil
+}
+
+// save writes the accounts to the store file; callers hold the lock
+func (p *Provider) save() error {
+	if p.config.StorePath == "" {
+		return nil
+	}
+
+	raw, err := json.MarshalIndent(p.accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p.config.StorePath, raw, 0o600)
+}
+
+// byEmail finds an account regardless of case; callers hold the lock
+func (p *Provider) byEmail(email string) *account {
+	for _, a := range p.accounts {
+		if strings.EqualFold(a.Email, email) {
+			return a
+		}
+	}
+	return nil
+}
+
+// issue signs a new access and refresh token for the account; callers hold the lock
+func (p *Provider) issue(a *account) (*interfaces.TokenResponse, error) {
+	now := time.Now()
+
+	sign := func(typ string, ttl time.Duration) (string, error) {
+		c := claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        uuid.NewString(),
+				Issuer:    p.config.Issuer,
+				Subject:   a.Subject,
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			},
+			Type:          typ,
+			Email:         a.Email,
+			EmailVerified: a.EmailVerified,
+			GivenName:     a.FirstName,
+			FamilyName:    a.LastName,
+		}
+		c.RealmAccess.Roles = p.config.Roles[strings.ToLower(a.Email)]
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(p.config.Secret)
+	}
+
+	access, err := sign("Bearer", p.config.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := sign("Refresh", p.config.RefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &interfaces.TokenResponse{
+		AccessToken:      access,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(p.config.AccessTTL.Seconds()),
+		RefreshToken:     refresh,
+		RefreshExpiresIn: int(p.config.RefreshTTL.Seconds()),
+	}, nil
+}
+
+// parse verifies a token of the given type and returns its claims
+func (p *Provider) parse(token, typ string) (*claims, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (interface{}, error) {
+		return p.config.Secret, nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithIssuer(p.config.Issuer))
+	if err != nil || c.Type != typ {
+		return nil, interfaces.ErrInvalidToken
+	}
+	return &c, nil
+}
+
+func (p *Provider) PasswordGrant(email, password string) (*interfaces.TokenResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a := p.byEmail(email)
+	if a == nil || bcrypt.CompareHashAndPassword([]byte(a.PasswordHash), []byte(password)) != nil {
+		return nil, interfaces.ErrInvalidCredentials
+	}
+	if !a.Enabled {
+		return nil, interfaces.ErrAccountDisabled
+	}
+	return p.issue(a)
+}
+
+func (p *Provider) Refresh(refreshToken string) (*interfaces.TokenResponse, error) {
+	c, err := p.parse(refreshToken, "Refresh")
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, ok := p.accounts[c.Subject]
+	if !ok || !a.Enabled {
+		return nil, interfaces.ErrInvalidToken
+	}
+	return p.issue(a)
+}
+
+func (p *Provider) Impersonate(subject string) (*interfaces.TokenResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, ok := p.accounts[subject]
+	if !ok {
+		return nil, interfaces.ErrIdentityNotFound
+	}
+	if !a.Enabled {
+		return nil, interfaces.ErrAccountDisabled
+	}
+	return p.issue(a)
+}
+
+func (p *Provider) VerifyToken(accessToken string) (*interfaces.IdentityClaims, error) {
+	c, err := p.parse(accessToken, "Bearer")
+	if err != nil {
+		return nil, err
+	}
+
+	return &interfaces.IdentityClaims{
+		Subject:       c.Subject,
+		Email:         c.Email,
+		EmailVerified: c.EmailVerified,
+		FirstName:     c.GivenName,
+		LastName:      c.FamilyName,
+		RealmRoles:    c.RealmAccess.Roles,
+	}, nil
+}
+
+// ExchangeSocialToken is not supported: the local provider cannot validate tokens of Google or Apple
+func (p *Provider) ExchangeSocialToken(provider, idToken string) (*interfaces.TokenResponse, error) {
+	return nil, interfaces.ErrSocialLoginDisabled
+}
+
+func (p *Provider) LinkSocialIdentity(subject, target string) error {
+	return interfaces.ErrSocialLoginDisabled
+}
+
+func (p *Provider) CreateUser(user interfaces.IdentityUser, password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.byEmail(user.Email) != nil {
+		return "", interfaces.ErrEmailAlreadyExists
+	}
+
+	a := &account{
+		Subject:      uuid.NewString(),
+		Email:        user.Email,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		PasswordHash: string(hash),
+		Enabled:      true,
+	}
+	p.accounts[a.Subject] = a
+	return a.Subject, p.save()
+}
+
+// change runs fn on the account with the lock held and saves the store afterwards
+func (p *Provider) change(subject string, fn func(a *account)) error {
+	// Local users without an identity, like seeded ones, have nothing to change
+	if subject == "" {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, ok := p.accounts[subject]
+	if !ok {
+		return interfaces.ErrIdentityNotFound
+	}
+	fn(a)
+	return p.save()
+}
+
+func (p *Provider) UpdateUser(subject string, user interfaces.IdentityUser) error {
+	return p.change(subject, func(a *account) {
+		a.Email, a.FirstName, a.LastName = user.Email, user.FirstName, user.LastName
+	})
+}
+
+func (p *Provider) DisableAccount(subject string) error {
+	return p.change(subject, func(a *account) { a.Enabled = false })
+}
+
+func (p *Provider) MarkEmailVerified(subject string) error {
+	return p.change(subject, func(a *account) { a.EmailVerified = true })
+}
+
+func (p *Provider) SetPassword(subject string, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return p.change(subject, func(a *account) { a.PasswordHash = string(hash) })
+}
+
+func (p *Provider) DeleteUser(subject string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.accounts, subject)
+	return p.save()
+}
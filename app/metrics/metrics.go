@@ -47,6 +47,24 @@ var (
 		Help: "Total number of password reset requests",
 	})
 
+	MagicLinkRequestsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "userservice_magic_link_requests_total",
+		Help: "Total number of magic-link login requests",
+	})
+
+	LegacyPasswordsRetiredTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "userservice_legacy_passwords_retired_total",
+		Help: "Local password hashes cleared, by whether they still matched the Keycloak password (matched, drifted, replaced)",
+	}, []string{"outcome"})
+
+	EmailVerificationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "userservice_email_verifications_total",
+			Help: "Email verification events: sent, throttled, verified, invalid or expired",
+		},
+		[]string{"event"},
+	)
+
 	NotificationCallsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "userservice_notification_calls_total",
@@ -54,4 +72,31 @@ var (
 		},
 		[]string{"status"},
 	)
+
+	OnboardingStartedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "userservice_onboarding_started_total",
+		Help: "Total number of users that started onboarding",
+	})
+
+	OnboardingStepsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "userservice_onboarding_steps_total",
+			Help: "Total number of onboarding steps finished, per step and outcome (completed or skipped)",
+		},
+		[]string{"step", "outcome"},
+	)
+
+	OnboardingStepDurationSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "userservice_onboarding_step_duration_seconds",
+			Help:    "Time between a step becoming current and being finished",
+			Buckets: []float64{5, 15, 30, 60, 300, 900, 3600, 21600, 86400, 604800},
+		},
+		[]string{"step"},
+	)
+
+	OnboardingCompletedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "userservice_onboarding_completed_total",
+		Help: "Total number of users that finished onboarding",
+	})
 )
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+)
+
+// AuditContext makes the audit recorder available to handlers through RecordAudit.
+// Users with adminRole are recorded as admin actors.
+func AuditContext(recorder interfaces.AuditRecorder, adminRole string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("audit_recorder", recorder)
+		c.Set("audit_admin_role", adminRole)
+		c.Next()
+	}
+}
+
+// RecordAudit appends an event to the audit log. The actor is taken from the request unless set,
+// and IP and user agent are always taken from the request. Without AuditContext it does nothing.
+// Failures are logged and never fail the request.
+func RecordAudit(c *gin.Context, e models.AuditEvent) {
+	recorderAny, exists := c.Get("audit_recorder")
+	if !exists {
+		return
+	}
+	recorder, ok := recorderAny.(interfaces.AuditRecorder)
+	if !ok || recorder == nil {
+		return
+	}
+
+	if e.ActorType == "" {
+		e.ActorType, e.ActorID = auditActor(c)
+	}
+	e.IP = c.ClientIP()
+	e.UserAgent = c.Request.UserAgent()
+
+	if err := recorder.Record(e); err != nil {
+		log.Printf("[audit] failed to record %s: %v\n", e.Action, err)
+	}
+}
+
+// auditActor identifies who made the request: a service, an admin, a user or nobody
+func auditActor(c *gin.Context) (models.AuditActorType, string) {
+	if IsServiceRequest(c) {
+		name := c.GetHeader("X-Service-Name")
+		if name == "" {
+			name = "unknown"
+		}
+		return models.AuditActorService, name
+	}
+
+	if sub, ok := GetUserID(c); ok && sub != "" {
+		if role := c.GetString("audit_admin_role"); role != "" && HasRealmRole(c, role) {
+			return models.AuditActorAdmin, sub
+		}
+		return models.AuditActorUser, sub
+	}
+
+	return models.AuditActorAnonymous, ""
+}
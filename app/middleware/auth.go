@@ -1,74 +1,71 @@
 package middleware
 
 import (
-	"context"
-	"log"
-	"net/http"
-	"os"
 	"strings"
 
-	"github.com/Nerzal/gocloak/v13"
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+
 	"github.com/gin-gonic/gin"
 )
 
+var identityProvider interfaces.IdentityProvider
+
 var (
-	keycloakClient *gocloak.GoCloak
-	realm          string
+	errMissingAuthorization = apperrors.Unauthorized("missing_authorization", "missing authorization header")
+	errInvalidAuthorization = apperrors.Unauthorized("invalid_authorization", "invalid authorization format")
 )
 
-// InitKeycloak initializes the Keycloak client and loads configuration
-func InitKeycloak() {
-	keycloakURL := os.Getenv("KEYCLOAK_URL")
-	realm = os.Getenv("KEYCLOAK_REALM")
-
-	// Both URL and realm are required to validate tokens
-	if keycloakURL == "" || realm == "" {
-		log.Fatal("KEYCLOAK_URL and KEYCLOAK_REALM must be set")
-	}
-
-	// Create Keycloak client
-	keycloakClient = gocloak.NewClient(keycloakURL)
+// InitIdentityProvider sets the identity provider that verifies access tokens
+func InitIdentityProvider(idp interfaces.IdentityProvider) {
+	identityProvider = idp
 }
 
-// AuthMiddleware validates JWT access tokens issued by Keycloak
+// AuthMiddleware validates JWT access tokens issued by the identity provider
 func AuthMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// Read Authorization header
-		authHeader := ctx.GetHeader("Authorization")
-		if authHeader == "" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
-			ctx.Abort()
+		if !authenticate(ctx) {
 			return
 		}
 
-		// Remove "Bearer prefix
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token == authHeader {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization format"})
-			ctx.Abort()
-			return
-		}
-
-		// Decode and validate the JWT access token
-		_, claims, err := keycloakClient.DecodeAccessToken(
-			context.Background(),
-			token,
-			realm,
-		)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
-			ctx.Abort()
-			return
-		}
+		ctx.Next()
+	}
+}
 
-		// Extract the user ID from the "sub" (subject) claim
-		sub, _ := (*claims)["sub"].(string)
+// authenticate validates the bearer token and stores the user ID in the Gin context.
+// On failure it aborts the request with a 401 problem and returns false.
+func authenticate(ctx *gin.Context) bool {
+	// Read Authorization header
+	authHeader := ctx.GetHeader("Authorization")
+	if authHeader == "" {
+		AbortWithError(ctx, errMissingAuthorization)
+		return false
+	}
 
-		// Store user ID in Gin context for later handlers
-		ctx.Set("user_id", sub)
+	// Remove "Bearer prefix
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if token == authHeader {
+		AbortWithError(ctx, errInvalidAuthorization)
+		return false
+	}
 
-		ctx.Next()
+	// Decode and validate the JWT access token
+	claims, err := identityProvider.VerifyToken(token)
+	if err != nil {
+		AbortWithError(ctx, interfaces.ErrInvalidToken)
+		return false
 	}
+
+	// Store the user ID from the "sub" (subject) claim for later handlers
+	ctx.Set("user_id", claims.Subject)
+
+	// Store realm roles for role-gated routes
+	ctx.Set("realm_roles", claims.RealmRoles)
+
+	// Tokens issued after verification say so; older tokens are checked by RequireVerifiedEmail
+	ctx.Set("email_verified", claims.EmailVerified)
+
+	return true
 }
 
 // GetUserID retrieves the Keycloak user ID from the Gin context
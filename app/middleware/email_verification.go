@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+)
+
+var errEmailNotVerified = apperrors.Forbidden("email_not_verified", "email address is not verified")
+
+// defaultUnverifiedRoutes are open to accounts that did not verify their email address yet
+const defaultUnverifiedRoutes = "GET /users/me,GET /users/me/onboarding,POST /users/me/verify-email/resend"
+
+// UnverifiedAllowedRoutes reads UNVERIFIED_ALLOWED_ROUTES: comma separated "METHOD /path" entries, where
+// METHOD may be * and a path ending in /* also matches everything below it. Paths are route patterns,
+// e.g. "GET /users/id/:id/profile".
+func UnverifiedAllowedRoutes() []string {
+	raw := os.Getenv("UNVERIFIED_ALLOWED_ROUTES")
+	if raw == "" {
+		raw = defaultUnverifiedRoutes
+	}
+
+	routes := []string{}
+	for _, r := range strings.Split(raw, ",") {
+		if r = strings.Join(strings.Fields(r), " "); r != "" {
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// routeAllowed reports whether method and route pattern match one of the allowed entries
+func routeAllowed(allowed []string, method, route string) bool {
+	for _, entry := range allowed {
+		m, path, ok := strings.Cut(entry, " ")
+		if !ok || (m != "*" && !strings.EqualFold(m, method)) {
+			continue
+		}
+		if path == route {
+			return true
+		}
+		if prefix, wildcard := strings.CutSuffix(path, "/*"); wildcard &&
+			(route == prefix || strings.HasPrefix(route, prefix+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireVerifiedEmail restricts accounts without a verified email address to the allowed routes.
+// It must run after AuthMiddleware. The email_verified claim is trusted when set; otherwise the checker
+// decides, so users that verified after their token was issued are let through as well.
+func RequireVerifiedEmail(checker interfaces.EmailVerificationChecker, allowed []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("email_verified") || routeAllowed(allowed, c.Request.Method, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		sub, _ := GetUserID(c)
+		verified, err := checker.IsEmailVerified(sub)
+		if err != nil || !verified {
+			AbortWithError(c, errEmailNotVerified)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/interfaces"
+)
+
+const (
+	localeKey     = "locale"
+	localeUserKey = "locale_users"
+)
+
+// Locale lets GetLocale use the preferred_language of the signed in user. The user is only looked up
+// when a response needs a translated text.
+func Locale(users interfaces.UserLookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(localeUserKey, users)
+		c.Next()
+	}
+}
+
+// GetLocale returns the language of the response: the preferred_language of the signed in user, then
+// the Accept-Language header, then DEFAULT_LANGUAGE. It must be called after the auth middleware.
+func GetLocale(c *gin.Context) i18n.Locale {
+	if l, ok := c.Get(localeKey); ok {
+		return l.(i18n.Locale)
+	}
+
+	l := resolveLocale(c)
+	c.Set(localeKey, l)
+	return l
+}
+
+func resolveLocale(c *gin.Context) i18n.Locale {
+	if users, ok := c.Get(localeUserKey); ok {
+		if sub, ok := GetUserID(c); ok {
+			if user, err := users.(interfaces.UserLookup).GetByKeycloakID(sub); err == nil {
+				if l, ok := i18n.Parse(user.PreferredLanguage); ok {
+					return l
+				}
+			}
+		}
+	}
+
+	if l, ok := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language")); ok {
+		return l
+	}
+	return i18n.Default()
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of error responses (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of every error response. Code is stable and meant for clients to switch on;
+// Detail is a human readable explanation and may change.
+type Problem struct {
+	Type     string            `json:"type" example:"about:blank"`
+	Title    string            `json:"title" example:"Not Found"`
+	Status   int               `json:"status" example:"404"`
+	Detail   string            `json:"detail,omitempty" example:"user not found"`
+	Instance string            `json:"instance,omitempty" example:"/users/me"`
+	Code     string            `json:"code" example:"user_not_found"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+// statusByKind maps the error kinds to HTTP statuses
+var statusByKind = []struct {
+	kind   error
+	status int
+}{
+	{apperrors.ErrBadRequest, http.StatusBadRequest},
+	{apperrors.ErrValidation, http.StatusUnprocessableEntity},
+	{apperrors.ErrUnauthorized, http.StatusUnauthorized},
+	{apperrors.ErrForbidden, http.StatusForbidden},
+	{apperrors.ErrNotFound, http.StatusNotFound},
+	{apperrors.ErrConflict, http.StatusConflict},
+	{apperrors.ErrPreconditionFailed, http.StatusPreconditionFailed},
+	{apperrors.ErrTooManyRequests, http.StatusTooManyRequests},
+	{apperrors.ErrNotImplemented, http.StatusNotImplemented},
+	{apperrors.ErrUnavailable, http.StatusServiceUnavailable},
+}
+
+// AbortWithError stops the request; ProblemDetails renders err once the handlers are done
+func AbortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// ProblemDetails renders the last error added to the context with c.Error as application/problem+json,
+// in the language of GetLocale. It must be the first middleware, so it also sees errors of the middleware
+// after it. Errors that are not typed are logged and answered with a generic 500; their text never
+// reaches the client.
+func ProblemDetails() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+
+		locale := GetLocale(c)
+		problem := NewProblem(err, locale)
+		if problem.Status == http.StatusInternalServerError {
+			log.Printf("[error] %s %s: %v\n", c.Request.Method, c.Request.URL.Path, err)
+		}
+		problem.Instance = c.Request.URL.Path
+
+		c.Header("Content-Type", ProblemContentType)
+		c.Header("Content-Language", string(locale))
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// NewProblem describes err in locale l as a problem without an instance
+func NewProblem(err error, l i18n.Locale) Problem {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return problem(http.StatusUnprocessableEntity, "validation_failed", i18n.T(l, "validation_failed"), fields.Messages(l))
+	}
+
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		for _, s := range statusByKind {
+			if errors.Is(appErr.Kind, s.kind) {
+				return problem(s.status, appErr.Code, appErr.Localize(l), appErr.Fields)
+			}
+		}
+	}
+
+	return problem(http.StatusInternalServerError, "internal_error", i18n.T(l, "internal_error"), nil)
+}
+
+func problem(status int, code, detail string, fields map[string]string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Fields: fields,
+	}
+}
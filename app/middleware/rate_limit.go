@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"group1-userservice/app/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errRateLimited = apperrors.TooManyRequests("rate_limited", "too many requests, try again later")
+
+// RateLimit allows limit requests per client IP in each window. State is kept in memory,
+// so with several instances each one counts on its own.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	type counter struct {
+		start time.Time
+		count int
+	}
+
+	var mu sync.Mutex
+	counters := map[string]*counter{}
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		// Forget clients whose window has passed, so the map does not grow forever
+		for key, ctr := range counters {
+			if now.Sub(ctr.start) >= window {
+				delete(counters, key)
+			}
+		}
+
+		ctr, ok := counters[ip]
+		if !ok {
+			ctr = &counter{start: now}
+			counters[ip] = ctr
+		}
+		ctr.count++
+		over := ctr.count > limit
+		retryAfter := window - now.Sub(ctr.start)
+		mu.Unlock()
+
+		if over {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			AbortWithError(c, errRateLimited)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AuthEmailRateLimit limits the endpoints that email a login or reset link: AUTH_EMAIL_RATE_LIMIT requests
+// (default 5) per AUTH_EMAIL_RATE_WINDOW_MINUTES (default 15) per client IP. Every call has its own counters.
+func AuthEmailRateLimit() gin.HandlerFunc {
+	limit := 5
+	if v, err := strconv.Atoi(os.Getenv("AUTH_EMAIL_RATE_LIMIT")); err == nil && v > 0 {
+		limit = v
+	}
+
+	window := 15 * time.Minute
+	if v, err := strconv.Atoi(os.Getenv("AUTH_EMAIL_RATE_WINDOW_MINUTES")); err == nil && v > 0 {
+		window = time.Duration(v) * time.Minute
+	}
+
+	return RateLimit(limit, window)
+}
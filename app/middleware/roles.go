@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"group1-userservice/app/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errRoleRequired = apperrors.Forbidden("role_required", "this route requires a role you do not have")
+
+// HasRealmRole reports whether the authenticated user has the given Keycloak realm role
+func HasRealmRole(c *gin.Context, role string) bool {
+	rolesAny, exists := c.Get("realm_roles")
+	if !exists {
+		return false
+	}
+
+	roles, _ := rolesAny.([]string)
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRealmRole only lets users with the given realm role through.
+// It must run after AuthMiddleware.
+func RequireRealmRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasRealmRole(c, role) {
+			AbortWithError(c, errRoleRequired)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,12 +1,15 @@
 package middleware
 
 import (
-	"net/http"
 	"os"
 
+	"group1-userservice/app/apperrors"
+
 	"github.com/gin-gonic/gin"
 )
 
+var errInvalidServiceToken = apperrors.Unauthorized("invalid_service_token", "invalid service token")
+
 // ServiceAuthMiddleware protects internal service-to-service endpoints
 func ServiceAuthMiddleware() gin.HandlerFunc {
 	// Token shared between trusted services
@@ -18,13 +21,42 @@ func ServiceAuthMiddleware() gin.HandlerFunc {
 
 		// Reject request if token is missing or does not match
 		if requiredToken == "" || provided == "" || provided != requiredToken {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "invalid service token",
-			})
+			AbortWithError(c, errInvalidServiceToken)
 			return
 		}
 
 		// Token is valid, continue request
+		c.Set("service_auth", true)
+		c.Next()
+	}
+}
+
+// AuthOrServiceMiddleware accepts either a valid X-Service-Token or a Keycloak bearer token.
+// A request that sends a service token is only authenticated as a service.
+func AuthOrServiceMiddleware() gin.HandlerFunc {
+	requiredToken := os.Getenv("USER_SERVICE_TOKEN")
+
+	return func(c *gin.Context) {
+		if provided := c.GetHeader("X-Service-Token"); provided != "" {
+			if requiredToken == "" || provided != requiredToken {
+				AbortWithError(c, errInvalidServiceToken)
+				return
+			}
+
+			c.Set("service_auth", true)
+			c.Next()
+			return
+		}
+
+		if !authenticate(c) {
+			return
+		}
+
 		c.Next()
 	}
 }
+
+// IsServiceRequest reports whether the request was authenticated with a service token
+func IsServiceRequest(c *gin.Context) bool {
+	return c.GetBool("service_auth")
+}
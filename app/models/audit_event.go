@@ -0,0 +1,108 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type AuditActorType string
+
+const (
+	AuditActorUser      AuditActorType = "user"
+	AuditActorService   AuditActorType = "service"
+	AuditActorAdmin     AuditActorType = "admin"
+	AuditActorAnonymous AuditActorType = "anonymous"
+)
+
+// Audit actions
+const (
+	AuditUserRegistered           = "user.registered"
+	AuditLoginSucceeded           = "auth.login_succeeded"
+	AuditLoginFailed              = "auth.login_failed"
+	AuditPasswordResetRequested   = "auth.password_reset_requested"
+	AuditPasswordResetCompleted   = "auth.password_reset_completed"
+	AuditMagicLinkRequested       = "auth.magic_link_requested"
+	AuditSocialIdentityLinked     = "auth.social_identity_linked"
+	AuditEmailVerificationSent    = "auth.email_verification_sent"
+	AuditEmailVerified            = "auth.email_verified"
+	AuditLoginTwoFactorRequired   = "auth.login_2fa_required"
+	AuditTwoFactorEnabled         = "auth.2fa_enabled"
+	AuditTwoFactorDisabled        = "auth.2fa_disabled"
+	AuditRecoveryCodesRegenerated = "auth.2fa_recovery_codes_regenerated"
+	AuditProfileUpdated           = "user.profile_updated"
+	AuditProfilePhotoUploaded     = "user.profile_photo_uploaded"
+	AuditProfileRestored          = "user.profile_restored"
+	AuditHandleChanged            = "user.handle_changed"
+	AuditBadgeAwarded             = "badge.awarded"
+	AuditNotificationSettingsSet  = "settings.notifications_updated"
+	AuditPrivacySettingsSet       = "settings.privacy_updated"
+	AuditInterestsSet             = "settings.interests_updated"
+	AuditDiscoveryPreferencesSet  = "settings.discovery_updated"
+	AuditModerationReportResolved = "moderation.report_resolved"
+	AuditVocabularyTermCreated    = "vocabulary.term_created"
+	AuditVocabularyTermUpdated    = "vocabulary.term_updated"
+	AuditVocabularyTermDeleted    = "vocabulary.term_deleted"
+	AuditVocabularyMappingApplied = "vocabulary.mapping_applied"
+	AuditInterestCreated          = "interest.created"
+	AuditInterestUpdated          = "interest.updated"
+	AuditInterestsReordered       = "interest.reordered"
+	AuditInterestArchived         = "interest.archived"
+	AuditInterestMerged           = "interest.merged"
+	AuditOnboardingStepCompleted  = "onboarding.step_completed"
+	AuditOnboardingStepSkipped    = "onboarding.step_skipped"
+	AuditOnboardingCompleted      = "onboarding.completed"
+)
+
+// FieldChange is the before and after value of one changed field
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// FieldChanges maps field names to their change and is stored as JSON
+type FieldChanges map[string]FieldChange
+
+func (f FieldChanges) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (f *FieldChanges) Scan(value interface{}) error {
+	if value == nil {
+		*f = nil
+		return nil
+	}
+
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("unsupported type for FieldChanges")
+	}
+	return json.Unmarshal(raw, f)
+}
+
+// AuditEvent is one row of the append-only audit log.
+type AuditEvent struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"index"`
+	ActorType  AuditActorType `json:"actor_type" gorm:"size:20;not null;index:idx_audit_actor"`
+	ActorID    string         `json:"actor_id" gorm:"index:idx_audit_actor"`
+	Action     string         `json:"action" gorm:"size:60;not null;index"`
+	TargetType string         `json:"target_type" gorm:"size:30;index:idx_audit_target"`
+	TargetID   string         `json:"target_id" gorm:"index:idx_audit_target"`
+	Changes    FieldChanges   `json:"changes,omitempty" gorm:"type:jsonb"`
+	IP         string         `json:"ip"`
+	UserAgent  string         `json:"user_agent"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ConnectionType string
+
+const (
+	// ConnectionTypeFollow is a one-way follow that needs no approval
+	ConnectionTypeFollow ConnectionType = "follow"
+	// ConnectionTypeMutual is a two-way connection that the addressee has to accept
+	ConnectionTypeMutual ConnectionType = "connection"
+)
+
+type ConnectionStatus string
+
+const (
+	ConnectionStatusPending  ConnectionStatus = "pending"
+	ConnectionStatusAccepted ConnectionStatus = "accepted"
+	ConnectionStatusDeclined ConnectionStatus = "declined"
+)
+
+// Connection is a directed edge from requester to addressee.
+// Mutual connections are stored once, in the direction they were requested.
+type Connection struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	RequesterID uuid.UUID        `json:"requester_id" gorm:"type:uuid;not null;uniqueIndex:idx_connection_pair"`
+	AddresseeID uuid.UUID        `json:"addressee_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_connection_pair"`
+	Type        ConnectionType   `json:"type" gorm:"size:20;not null;uniqueIndex:idx_connection_pair"`
+	Status      ConnectionStatus `json:"status" gorm:"size:20;not null;index"`
+	CreatedAt   time.Time        `json:"created_at"`
+	RespondedAt *time.Time       `json:"responded_at,omitempty"`
+}
+
+// ConnectionUser is the summary of a user shown in follower/following/connection lists.
+type ConnectionUser struct {
+	ID              uuid.UUID `json:"id"`
+	Handle          string    `json:"handle"`
+	FirstName       string    `json:"first_name"`
+	LastName        string    `json:"last_name"`
+	ProfilePhotoURL string    `json:"profile_photo_url"`
+	Since           time.Time `json:"since"`
+	RequestID       uint      `json:"request_id,omitempty"`
+}
+
+// ConnectionCounts are the relationship counters shown on a profile.
+type ConnectionCounts struct {
+	Followers   int64 `json:"followers"`
+	Following   int64 `json:"following"`
+	Connections int64 `json:"connections"`
+}
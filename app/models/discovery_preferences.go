@@ -3,4 +3,7 @@ package models
 type DiscoveryPreferences struct {
 	Email    string `json:"email" gorm:"type:varchar(255);not null;primaryKey"`
 	RadiusKm int    `json:"radius_km" gorm:"not null;default:50"`
+
+	// Version is bumped on every change and sent as the ETag
+	Version int64 `json:"version" gorm:"not null;default:1"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailVerificationSend tracks the verification emails sent to a user, to throttle resends
+type EmailVerificationSend struct {
+	UserID     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LastSentAt time.Time `gorm:"not null"`
+
+	// WindowStartedAt starts the day in which SendCount emails were sent
+	WindowStartedAt time.Time `gorm:"not null"`
+	SendCount       int       `gorm:"not null;default:0"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HandleReservation keeps an old handle reserved for its previous owner
+// so that links to it keep redirecting and nobody else can claim it.
+type HandleReservation struct {
+	Handle        string    `json:"handle" gorm:"primaryKey;size:30"`
+	UserID        uuid.UUID `json:"user_id" gorm:"type:uuid;index;not null"`
+	ReservedUntil time.Time `json:"reserved_until" gorm:"index;not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
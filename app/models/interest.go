@@ -1,6 +1,35 @@
 package models
 
+import "time"
+
+// Interest is a topic users can select. Key is a stable slug; what users see is in the translations,
+// so a label can be changed without changing the interest. Archived interests are no longer offered to
+// users, but the selections made before stay.
 type Interest struct {
-	ID  uint   `json:"id" gorm:"primaryKey"`
-	Key string `json:"key" gorm:"uniqueIndex;not null"`
+	ID           uint                  `json:"id" gorm:"primaryKey"`
+	Key          string                `json:"key" gorm:"uniqueIndex;not null"`
+	Position     int                   `json:"position" gorm:"not null;default:0"`
+	ArchivedAt   *time.Time            `json:"archived_at,omitempty" gorm:"index"`
+	Translations []InterestTranslation `json:"translations,omitempty" gorm:"foreignKey:InterestID;constraint:OnDelete:CASCADE"`
+}
+
+// InterestTranslation is the label and description of an interest in one language
+type InterestTranslation struct {
+	InterestID  uint   `json:"-" gorm:"primaryKey"`
+	Locale      string `json:"locale" gorm:"primaryKey;size:2"`
+	Label       string `json:"label" gorm:"size:200;not null"`
+	Description string `json:"description" gorm:"size:1000;not null;default:''"`
+}
+
+// Translation returns the translation in locale, else the English or Dutch one. Without any
+// translation the key is used as label.
+func (i Interest) Translation(locale string) InterestTranslation {
+	for _, l := range []string{locale, "en", "nl"} {
+		for _, t := range i.Translations {
+			if t.Locale == l {
+				return t
+			}
+		}
+	}
+	return InterestTranslation{InterestID: i.ID, Locale: locale, Label: i.Key}
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// MagicLinkToken is a single-use login link; like PasswordResetToken only the hash of the token is stored
+type MagicLinkToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	Email     string    `gorm:"index;not null"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	Used      bool      `gorm:"not null;default:false"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,13 @@
+package models
+
+// Notification is the payload sent to the NotificationService.
+type Notification struct {
+	Email     string         `json:"email"`
+	Title     string         `json:"title"`
+	Message   string         `json:"message"`
+	Type      string         `json:"type"`
+	Token     string         `json:"token,omitempty"`
+	Channels  map[string]any `json:"channels,omitempty"`
+	Data      map[string]any `json:"data,omitempty"`
+	Timestamp string         `json:"timestamp"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OnboardingStep string
+
+// Onboarding steps in the order they are taken
+const (
+	OnboardingVerifyEmail         OnboardingStep = "verify_email"
+	OnboardingBasicProfile        OnboardingStep = "basic_profile"
+	OnboardingPhoto               OnboardingStep = "photo"
+	OnboardingInterests           OnboardingStep = "interests"
+	OnboardingDiscoveryRadius     OnboardingStep = "discovery_radius"
+	OnboardingNotificationConsent OnboardingStep = "notification_consent"
+)
+
+// OnboardingSteps lists all steps in order
+var OnboardingSteps = []OnboardingStep{
+	OnboardingVerifyEmail,
+	OnboardingBasicProfile,
+	OnboardingPhoto,
+	OnboardingInterests,
+	OnboardingDiscoveryRadius,
+	OnboardingNotificationConsent,
+}
+
+type OnboardingStepStatus string
+
+const (
+	OnboardingStepPending   OnboardingStepStatus = "pending"
+	OnboardingStepCompleted OnboardingStepStatus = "completed"
+	OnboardingStepSkipped   OnboardingStepStatus = "skipped"
+)
+
+// OnboardingState is the onboarding progress of one user
+type OnboardingState struct {
+	UserID      uuid.UUID  `json:"-" gorm:"type:uuid;primaryKey"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+
+	// NotificationConsent is the answer given in the notification_consent step, nil when not answered
+	NotificationConsent *bool `json:"notification_consent"`
+
+	Steps []OnboardingStepState `json:"steps" gorm:"foreignKey:UserID;references:UserID"`
+
+	// CurrentStep is the first pending step, empty once onboarding is done
+	CurrentStep OnboardingStep `json:"current_step,omitempty" gorm:"-"`
+}
+
+// OnboardingStepState is the status of one step; FinishedAt is set when it was completed or skipped
+type OnboardingStepState struct {
+	UserID     uuid.UUID            `json:"-" gorm:"type:uuid;primaryKey"`
+	Step       OnboardingStep       `json:"step" gorm:"size:30;primaryKey"`
+	Position   int                  `json:"position" gorm:"not null"`
+	Status     OnboardingStepStatus `json:"status" gorm:"size:20;not null;index"`
+	Skippable  bool                 `json:"skippable" gorm:"-"`
+	FinishedAt *time.Time           `json:"finished_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "github.com/google/uuid"
+
+type CompletenessStatus string
+
+const (
+	CompletenessMissing CompletenessStatus = "missing"
+	CompletenessWeak    CompletenessStatus = "weak" // filled in, but counts for half
+)
+
+// CompletenessItem is a part of the profile that is missing or could be better
+type CompletenessItem struct {
+	Field    string             `json:"field" example:"biography"`
+	Status   CompletenessStatus `json:"status" example:"weak"`
+	Weight   int                `json:"weight" example:"15"`
+	Required bool               `json:"required"`
+	Reason   string             `json:"reason" example:"biography has 12 characters, at least 50 recommended"`
+}
+
+// CompletenessStep suggests what to do next and where
+type CompletenessStep struct {
+	Field    string `json:"field" example:"profile_photo_url"`
+	Message  string `json:"message" example:"Upload a profile photo"`
+	Endpoint string `json:"endpoint" example:"POST /users/me/profile-photo"`
+}
+
+// ProfileCompleteness is a weighted score of how complete a profile is
+type ProfileCompleteness struct {
+	UserID     uuid.UUID          `json:"user_id"`
+	Percentage int                `json:"percentage" example:"70"`
+	Complete   bool               `json:"complete"` // every required field is filled in
+	Missing    []CompletenessItem `json:"missing"`
+	NextSteps  []CompletenessStep `json:"next_steps"`
+}
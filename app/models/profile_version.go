@@ -0,0 +1,107 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProfileVersionReason string
+
+const (
+	// ProfileVersionInitial is the state before the first recorded change
+	ProfileVersionInitial ProfileVersionReason = "initial"
+	ProfileVersionUpdate  ProfileVersionReason = "update"
+	ProfileVersionPhoto   ProfileVersionReason = "photo"
+	ProfileVersionRestore ProfileVersionReason = "restore"
+	// ProfileVersionModeration is a change made by a moderator, e.g. a removed photo
+	ProfileVersionModeration ProfileVersionReason = "moderation"
+	// ProfileVersionVocabulary is a free-text sector or job function replaced by its managed term
+	ProfileVersionVocabulary ProfileVersionReason = "vocabulary"
+)
+
+// ProfileSnapshot holds the user-editable profile fields at one point in time
+type ProfileSnapshot struct {
+	FirstName          string `json:"first_name"`
+	LastName           string `json:"last_name"`
+	PhoneNumber        string `json:"phone_number"`
+	PhoneNumberVisible bool   `json:"phone_number_visible"`
+	Country            string `json:"country"`
+	JobFunction        string `json:"job_function"`
+	Sector             string `json:"sector"`
+	Biography          string `json:"biography"`
+	ProfilePhotoURL    string `json:"profile_photo_url"`
+	PreferredLanguage  string `json:"preferred_language"`
+}
+
+func SnapshotOf(u User) ProfileSnapshot {
+	return ProfileSnapshot{
+		FirstName:          u.FirstName,
+		LastName:           u.LastName,
+		PhoneNumber:        u.PhoneNumber,
+		PhoneNumberVisible: u.PhoneNumberVisible,
+		Country:            u.Country,
+		JobFunction:        u.JobFunction,
+		Sector:             u.Sector,
+		Biography:          u.Biography,
+		ProfilePhotoURL:    u.ProfilePhotoURL,
+		PreferredLanguage:  u.PreferredLanguage,
+	}
+}
+
+// Fields returns every snapshot field as a column update, including empty values
+func (s ProfileSnapshot) Fields() map[string]any {
+	return map[string]any{
+		"first_name":           s.FirstName,
+		"last_name":            s.LastName,
+		"phone_number":         s.PhoneNumber,
+		"phone_number_visible": s.PhoneNumberVisible,
+		"country":              s.Country,
+		"job_function":         s.JobFunction,
+		"sector":               s.Sector,
+		"biography":            s.Biography,
+		"profile_photo_url":    s.ProfilePhotoURL,
+		"preferred_language":   s.PreferredLanguage,
+	}
+}
+
+func (s ProfileSnapshot) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (s *ProfileSnapshot) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("unsupported type for ProfileSnapshot")
+	}
+	return json.Unmarshal(raw, s)
+}
+
+// ProfileVersion is one numbered snapshot in a user's profile history
+type ProfileVersion struct {
+	ID           uint                 `json:"-" gorm:"primaryKey"`
+	UserID       uuid.UUID            `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_profile_version"`
+	Version      int                  `json:"version" gorm:"not null;uniqueIndex:idx_profile_version"`
+	Reason       ProfileVersionReason `json:"reason" gorm:"size:20;not null"`
+	RestoredFrom *int                 `json:"restored_from,omitempty"`
+	Snapshot     ProfileSnapshot      `json:"snapshot" gorm:"type:jsonb;not null"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+// ProfileHistoryEntry is a version together with the fields it changed compared to the previous one
+type ProfileHistoryEntry struct {
+	ProfileVersion
+	Changes FieldChanges `json:"changes"`
+}
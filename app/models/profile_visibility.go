@@ -0,0 +1,75 @@
+package models
+
+import "github.com/google/uuid"
+
+// Visibility controls who may see a profile field.
+type Visibility string
+
+const (
+	VisibilityPublic      Visibility = "public"
+	VisibilityConnections Visibility = "connections"
+	VisibilityPrivate     Visibility = "private"
+)
+
+// Valid reports whether v is one of the known visibility levels.
+func (v Visibility) Valid() bool {
+	return v == VisibilityPublic || v == VisibilityConnections || v == VisibilityPrivate
+}
+
+// Relationship describes how the viewer of a profile relates to its owner.
+type Relationship string
+
+const (
+	RelationshipSelf       Relationship = "self"
+	RelationshipConnection Relationship = "connection"
+	RelationshipPublic     Relationship = "public"
+	// RelationshipBlocked means one of the two users blocked the other; nothing is visible
+	RelationshipBlocked Relationship = "blocked"
+)
+
+// AllowedFor reports whether a field with visibility v may be shown to a viewer with relationship rel.
+func (v Visibility) AllowedFor(rel Relationship) bool {
+	switch rel {
+	case RelationshipSelf:
+		return true
+	case RelationshipConnection:
+		return v == VisibilityPublic || v == VisibilityConnections
+	case RelationshipPublic:
+		return v == VisibilityPublic
+	}
+	return false
+}
+
+// ProfileVisibility stores per-field privacy settings of a user.
+// Names and the profile photo are always visible.
+type ProfileVisibility struct {
+	UserID      uuid.UUID  `json:"-" gorm:"type:uuid;primaryKey"`
+	Email       Visibility `json:"email" gorm:"size:20;not null;default:public"`
+	PhoneNumber Visibility `json:"phone_number" gorm:"size:20;not null;default:private"`
+	Country     Visibility `json:"country" gorm:"size:20;not null;default:public"`
+	JobFunction Visibility `json:"job_function" gorm:"size:20;not null;default:public"`
+	Sector      Visibility `json:"sector" gorm:"size:20;not null;default:public"`
+	Biography   Visibility `json:"biography" gorm:"size:20;not null;default:public"`
+	Badges      Visibility `json:"badges" gorm:"size:20;not null;default:public"`
+}
+
+// ForField returns the visibility configured for field f.
+func (p ProfileVisibility) ForField(f ProfileField) Visibility {
+	switch f {
+	case ProfileFieldEmail:
+		return p.Email
+	case ProfileFieldPhoneNumber:
+		return p.PhoneNumber
+	case ProfileFieldCountry:
+		return p.Country
+	case ProfileFieldJobFunction:
+		return p.JobFunction
+	case ProfileFieldSector:
+		return p.Sector
+	case ProfileFieldBiography:
+		return p.Biography
+	case ProfileFieldBadges:
+		return p.Badges
+	}
+	return VisibilityPrivate
+}
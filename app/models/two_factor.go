@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactor is the TOTP setup of a user. It is pending until the first code is confirmed.
+type TwoFactor struct {
+	UserID uuid.UUID `gorm:"type:uuid;primaryKey"`
+
+	// SecretEnc is the base32 TOTP secret, encrypted with the two-factor key
+	SecretEnc string `gorm:"not null"`
+
+	Enabled     bool `gorm:"not null;default:false"`
+	ConfirmedAt *time.Time
+
+	// LastUsedStep is the time step of the last accepted code, so a code cannot be used twice
+	LastUsedStep int64 `gorm:"not null;default:0"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// RecoveryCode is a one-time code that replaces a TOTP code; only its hash is stored
+type RecoveryCode struct {
+	ID       uint      `gorm:"primaryKey"`
+	UserID   uuid.UUID `gorm:"type:uuid;index;not null"`
+	CodeHash string    `gorm:"uniqueIndex;not null"`
+	UsedAt   *time.Time
+}
+
+// LoginChallenge is the first half of a login with 2FA: the password was correct and the Keycloak
+// refresh token is kept until the second factor is given
+type LoginChallenge struct {
+	ID              uint      `gorm:"primaryKey"`
+	TokenHash       string    `gorm:"uniqueIndex;not null"`
+	UserID          uuid.UUID `gorm:"type:uuid;index;not null"`
+	RefreshTokenEnc string    `gorm:"not null"`
+	Attempts        int       `gorm:"not null;default:0"`
+	ExpiresAt       time.Time `gorm:"index;not null"`
+	CreatedAt       time.Time
+}
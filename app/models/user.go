@@ -1,12 +1,17 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
 	ID                 uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	KeycloakID         string    `json:"keycloak_id" gorm:"uniqueIndex"`
 	Email              string    `json:"email"`
-	Password           string    `json:"password"`
+	Password           string    `json:"password,omitempty" gorm:"-"`
 	FirstName          string    `json:"first_name"`
 	LastName           string    `json:"last_name"`
 	PhoneNumber        string    `json:"phone_number"`
@@ -17,6 +22,28 @@ type User struct {
 	Biography          string    `json:"biography"`
 	IsBlocked          bool      `json:"is_blocked"`
 	ProfilePhotoURL    string    `json:"profile_photo_url" gorm:"default:''"`
+
+	// PreferredLanguage ("nl" or "en") is used for responses and notifications; when it is empty
+	// the Accept-Language header or DEFAULT_LANGUAGE decides
+	PreferredLanguage string `json:"preferred_language" gorm:"size:2;not null;default:''"`
+
+	// BiographyHidden is set by a moderator; the biography is then only visible to its owner
+	BiographyHidden bool `json:"biography_hidden" gorm:"not null;default:false"`
+
+	// Handle is the unique, lowercase vanity name used in /users/@{handle}
+	Handle          string     `json:"handle" gorm:"size:30;default:''"`
+	HandleChangedAt *time.Time `json:"handle_changed_at,omitempty"`
+
+	// Version is bumped on every profile change and sent as the ETag of /users/me
+	Version int64 `json:"version" gorm:"not null;default:1"`
+
+	// EmailVerifiedAt is set when the user followed the verification link; nil means unverified
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// LegacyPasswordHash is the bcrypt hash from before Keycloak was the only credential store.
+	// It is never written and not migrated: it is cleared on the next login and the column is
+	// dropped in AUTH_CREDENTIAL_MODE=keycloak. Password above is only the plain input at registration.
+	LegacyPasswordHash string `json:"-" gorm:"column:password;->;-:migration"`
 }
 
 type UserUpdateInput struct {
@@ -29,4 +56,51 @@ type UserUpdateInput struct {
 	Sector             string `json:"sector"`
 	Biography          string `json:"biography"`
 	ProfilePhotoURL    string `json:"profile_photo_url"`
+	PreferredLanguage  string `json:"preferred_language"`
+}
+
+// UserProfilePatch is a JSON Merge Patch (RFC 7396) of the profile: absent keys are left alone,
+// an explicit null clears the field.
+type UserProfilePatch struct {
+	FirstName          PatchString `json:"first_name" swaggertype:"string"`
+	LastName           PatchString `json:"last_name" swaggertype:"string"`
+	PhoneNumber        PatchString `json:"phone_number" swaggertype:"string"`
+	PhoneNumberVisible PatchBool   `json:"phone_number_visible" swaggertype:"boolean"`
+	Country            PatchString `json:"country" swaggertype:"string"`
+	JobFunction        PatchString `json:"job_function" swaggertype:"string"`
+	Sector             PatchString `json:"sector" swaggertype:"string"`
+	Biography          PatchString `json:"biography" swaggertype:"string"`
+	ProfilePhotoURL    PatchString `json:"profile_photo_url" swaggertype:"string"`
+	PreferredLanguage  PatchString `json:"preferred_language" swaggertype:"string"`
+}
+
+// PatchString is a string field of a merge patch. Set reports whether the key was present;
+// a null value is Set with an empty Value.
+type PatchString struct {
+	Set   bool
+	Value string
+}
+
+func (p *PatchString) UnmarshalJSON(data []byte) error {
+	p.Set = true
+	if string(data) == "null" {
+		p.Value = ""
+		return nil
+	}
+	return json.Unmarshal(data, &p.Value)
+}
+
+// PatchBool is a boolean field of a merge patch; null resets it to false.
+type PatchBool struct {
+	Set   bool
+	Value bool
+}
+
+func (p *PatchBool) UnmarshalJSON(data []byte) error {
+	p.Set = true
+	if string(data) == "null" {
+		p.Value = false
+		return nil
+	}
+	return json.Unmarshal(data, &p.Value)
 }
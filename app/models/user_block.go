@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BlockKind string
+
+const (
+	// BlockKindBlock hides both users from each other and prevents connecting
+	BlockKindBlock BlockKind = "block"
+	// BlockKindMute only hides the muted user's activity from the muter
+	BlockKindMute BlockKind = "mute"
+)
+
+// UserBlock is a block or mute placed by one user on another.
+// This is separate from User.IsBlocked, which is an admin action on the account itself.
+type UserBlock struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BlockerID uuid.UUID `json:"blocker_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_block_pair"`
+	BlockedID uuid.UUID `json:"blocked_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_user_block_pair"`
+	Kind      BlockKind `json:"kind" gorm:"size:10;not null;uniqueIndex:idx_user_block_pair"`
+	CreatedAt time.Time `json:"created_at"`
+}
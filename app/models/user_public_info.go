@@ -3,5 +3,5 @@ package models
 type UserPublicInfo struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
-	Email     string `json:"email"`
+	Email     string `json:"email,omitempty"`
 }
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProfileField identifies a profile field that may be shown to other users.
+type ProfileField string
+
+const (
+	ProfileFieldEmail       ProfileField = "email"
+	ProfileFieldPhoneNumber ProfileField = "phone_number"
+	ProfileFieldCountry     ProfileField = "country"
+	ProfileFieldJobFunction ProfileField = "job_function"
+	ProfileFieldSector      ProfileField = "sector"
+	ProfileFieldBiography   ProfileField = "biography"
+	ProfileFieldBadges      ProfileField = "badges"
+)
+
+// PublicBadge is the badge information other users are allowed to see.
+type PublicBadge struct {
+	Key         string    `json:"key"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	EarnedAt    time.Time `json:"earned_at"`
+}
+
+// UserPublicProfile is the projection of a user that is returned to other users.
+// Fields that are not visible to the viewer are left empty and omitted from JSON.
+type UserPublicProfile struct {
+	ID              uuid.UUID     `json:"id"`
+	Handle          string        `json:"handle,omitempty"`
+	FirstName       string        `json:"first_name"`
+	LastName        string        `json:"last_name"`
+	Email           string        `json:"email,omitempty"`
+	PhoneNumber     string        `json:"phone_number,omitempty"`
+	Country         string        `json:"country,omitempty"`
+	JobFunction     string        `json:"job_function,omitempty"`
+	Sector          string        `json:"sector,omitempty"`
+	Biography       string        `json:"biography,omitempty"`
+	ProfilePhotoURL string        `json:"profile_photo_url,omitempty"`
+	Badges          []PublicBadge `json:"badges"`
+
+	Counts *ConnectionCounts `json:"counts,omitempty"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReportReason string
+
+const (
+	ReportReasonFakeProfile        ReportReason = "fake_profile"
+	ReportReasonOffensiveBio       ReportReason = "offensive_bio"
+	ReportReasonInappropriatePhoto ReportReason = "inappropriate_photo"
+	ReportReasonSpam               ReportReason = "spam"
+	ReportReasonHarassment         ReportReason = "harassment"
+	ReportReasonOther              ReportReason = "other"
+)
+
+// ReportReasons lists the reasons a user can pick when reporting a profile
+var ReportReasons = []ReportReason{
+	ReportReasonFakeProfile,
+	ReportReasonOffensiveBio,
+	ReportReasonInappropriatePhoto,
+	ReportReasonSpam,
+	ReportReasonHarassment,
+	ReportReasonOther,
+}
+
+func (r ReportReason) Valid() bool {
+	for _, v := range ReportReasons {
+		if r == v {
+			return true
+		}
+	}
+	return false
+}
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusInReview  ReportStatus = "in_review"
+	ReportStatusResolved  ReportStatus = "resolved"
+	ReportStatusDismissed ReportStatus = "dismissed"
+)
+
+// UserReport is a report about another user's profile, waiting in the moderation queue.
+// Moderators are identified by their Keycloak sub, they do not need a local user row.
+type UserReport struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	ReporterID uuid.UUID    `json:"reporter_id" gorm:"type:uuid;not null;index"`
+	ReportedID uuid.UUID    `json:"reported_id" gorm:"type:uuid;not null;index"`
+	Reason     ReportReason `json:"reason" gorm:"size:30;not null"`
+	Details    string       `json:"details" gorm:"type:text"`
+	Status     ReportStatus `json:"status" gorm:"size:20;not null;index"`
+
+	ClaimedBy  string     `json:"claimed_by,omitempty"`
+	ClaimedAt  *time.Time `json:"claimed_at,omitempty"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	Resolution string     `json:"resolution,omitempty" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ModerationActionType string
+
+const (
+	ModerationActionClaim        ModerationActionType = "claim"
+	ModerationActionDismiss      ModerationActionType = "dismiss"
+	ModerationActionHideBio      ModerationActionType = "hide_bio"
+	ModerationActionRemovePhoto  ModerationActionType = "remove_photo"
+	ModerationActionBlockAccount ModerationActionType = "block_account"
+)
+
+// ModerationAction is the audit trail of everything a moderator did with a report.
+// Rows are only ever inserted.
+type ModerationAction struct {
+	ID           uint                 `json:"id" gorm:"primaryKey"`
+	ReportID     uint                 `json:"report_id" gorm:"not null;index"`
+	ModeratorID  string               `json:"moderator_id" gorm:"not null"`
+	Action       ModerationActionType `json:"action" gorm:"size:30;not null"`
+	TargetUserID uuid.UUID            `json:"target_user_id" gorm:"type:uuid;not null;index"`
+	Note         string               `json:"note,omitempty" gorm:"type:text"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Vocabulary names a managed list of profile values; the value is also its table name
+type Vocabulary string
+
+const (
+	VocabularySectors      Vocabulary = "sectors"
+	VocabularyJobFunctions Vocabulary = "job_functions"
+)
+
+// ProfileField is the users column that holds a key of this vocabulary, or "" for an unknown vocabulary
+func (v Vocabulary) ProfileField() string {
+	switch v {
+	case VocabularySectors:
+		return "sector"
+	case VocabularyJobFunctions:
+		return "job_function"
+	}
+	return ""
+}
+
+// VocabularyTerm is one entry of a managed list: a stable key with Dutch and English labels.
+// Profiles store the key, clients show the label.
+type VocabularyTerm struct {
+	Key       string    `json:"key" gorm:"primaryKey;size:100"`
+	LabelNL   string    `json:"label_nl" gorm:"size:200;not null"`
+	LabelEN   string    `json:"label_en" gorm:"size:200;not null"`
+	Position  int       `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// Sector is a term of the sectors table
+type Sector struct {
+	VocabularyTerm
+}
+
+// JobFunction is a term of the job_functions table
+type JobFunction struct {
+	VocabularyTerm
+}
+
+type VocabularyMappingStatus string
+
+const (
+	VocabularyMappingExact     VocabularyMappingStatus = "exact"     // matched a key or label and was applied
+	VocabularyMappingFuzzy     VocabularyMappingStatus = "fuzzy"     // close enough to apply, worth a check
+	VocabularyMappingUnmatched VocabularyMappingStatus = "unmatched" // left unchanged, needs a manual decision
+	VocabularyMappingResolved  VocabularyMappingStatus = "resolved"  // applied by an admin
+)
+
+// VocabularyMapping records how one free-text profile value was migrated to a term key
+type VocabularyMapping struct {
+	ID           uint                    `json:"id" gorm:"primaryKey"`
+	Vocabulary   Vocabulary              `json:"vocabulary" gorm:"size:30;not null;uniqueIndex:idx_vocabulary_mapping"`
+	RawValue     string                  `json:"raw_value" gorm:"not null;uniqueIndex:idx_vocabulary_mapping"`
+	SuggestedKey string                  `json:"suggested_key"`
+	Score        float64                 `json:"score"`
+	Status       VocabularyMappingStatus `json:"status" gorm:"size:20;not null;index"`
+	AppliedKey   string                  `json:"applied_key"`
+	UserCount    int64                   `json:"user_count"`
+	CreatedAt    time.Time               `json:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+}
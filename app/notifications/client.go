@@ -0,0 +1,80 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/metrics"
+	"group1-userservice/app/models"
+)
+
+// Client posts notifications to the NotificationService
+type Client struct {
+	URL          string
+	ServiceToken string
+	HTTP         *http.Client
+}
+
+// NewClient creates a client for the given NotificationService URL.
+// The service token is read from NOTIFICATION_SERVICE_TOKEN.
+func NewClient(url string) interfaces.NotificationSender {
+	return &Client{
+		URL:          url,
+		ServiceToken: os.Getenv("NOTIFICATION_SERVICE_TOKEN"),
+		HTTP:         &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Send posts a notification. Without a configured URL the notification is skipped.
+func (c *Client) Send(n models.Notification) error {
+	if c.URL == "" {
+		log.Println("[notification] NOTIFICATION_SERVICE_URL is empty, skipping")
+		return nil
+	}
+
+	metrics.NotificationCallsTotal.WithLabelValues("attempt").Inc()
+
+	if n.Timestamp == "" {
+		n.Timestamp = time.Now().Format(time.RFC3339)
+	}
+
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.URL, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("build notification request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Service-to-service authentication
+	if c.ServiceToken != "" {
+		req.Header.Set("X-Service-Token", c.ServiceToken)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		metrics.NotificationCallsTotal.WithLabelValues("error").Inc()
+		return fmt.Errorf("call %s: %w", c.URL, err)
+	}
+	defer resp.Body.Close()
+
+	log.Printf("[notification] called %s -> status %d\n", c.URL, resp.StatusCode)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		metrics.NotificationCallsTotal.WithLabelValues("failed").Inc()
+		return fmt.Errorf("notification service returned status %d", resp.StatusCode)
+	}
+
+	metrics.NotificationCallsTotal.WithLabelValues("success").Inc()
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"gorm.io/gorm"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) interfaces.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(e *models.AuditEvent) error {
+	return r.db.Create(e).Error
+}
+
+// Query returns matching events, newest first
+func (r *auditRepository) Query(f interfaces.AuditFilter, p interfaces.Pagination) ([]models.AuditEvent, int64, error) {
+	q := r.db.Model(&models.AuditEvent{})
+	if f.ActorType != "" {
+		q = q.Where("actor_type = ?", f.ActorType)
+	}
+	if f.ActorID != "" {
+		q = q.Where("actor_id = ?", f.ActorID)
+	}
+	if f.Action != "" {
+		q = q.Where("action = ?", f.Action)
+	}
+	if f.TargetType != "" {
+		q = q.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != "" {
+		q = q.Where("target_id = ?", f.TargetID)
+	}
+	if f.From != nil {
+		q = q.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("created_at < ?", *f.To)
+	}
+
+	var total int64
+	if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := []models.AuditEvent{}
+	err := q.Session(&gorm.Session{}).
+		Order("created_at DESC, id DESC").
+		Limit(p.PageSize).
+		Offset(p.Offset()).
+		Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// DeleteBefore is only used by the retention job; events are never updated
+func (r *auditRepository) DeleteBefore(t time.Time) (int64, error) {
+	res := r.db.Where("created_at < ?", t).Delete(&models.AuditEvent{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type connectionRepository struct {
+	db *gorm.DB
+}
+
+func NewConnectionRepository(db *gorm.DB) interfaces.ConnectionRepository {
+	return &connectionRepository{db: db}
+}
+
+const connectionUserColumns = "users.id, users.handle, users.first_name, users.last_name, users.profile_photo_url"
+
+func (r *connectionRepository) Find(requesterID, addresseeID uuid.UUID, t models.ConnectionType) (*models.Connection, error) {
+	var c models.Connection
+	err := r.db.
+		Where("requester_id = ? AND addressee_id = ? AND type = ?", requesterID, addresseeID, t).
+		First(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *connectionRepository) FindByID(id uint) (*models.Connection, error) {
+	var c models.Connection
+	if err := r.db.First(&c, id).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *connectionRepository) Save(c *models.Connection) error {
+	return r.db.Save(c).Error
+}
+
+func (r *connectionRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Connection{}, id).Error
+}
+
+func (r *connectionRepository) FindAcceptedMutual(a, b uuid.UUID) (*models.Connection, error) {
+	var c models.Connection
+	err := r.db.
+		Where("type = ? AND status = ?", models.ConnectionTypeMutual, models.ConnectionStatusAccepted).
+		Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)", a, b, b, a).
+		First(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// list runs a paged query over connections joined with the user on the other side of the edge.
+func (r *connectionRepository) list(base *gorm.DB, p interfaces.Pagination, selectCols string) ([]models.ConnectionUser, int64, error) {
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := []models.ConnectionUser{}
+	err := base.Session(&gorm.Session{}).
+		Select(selectCols).
+		Order("connections.created_at DESC, connections.id DESC").
+		Limit(p.PageSize).
+		Offset(p.Offset()).
+		Scan(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *connectionRepository) ListFollowers(userID uuid.UUID, p interfaces.Pagination) ([]models.ConnectionUser, int64, error) {
+	base := r.db.Table("connections").
+		Joins("JOIN users ON users.id = connections.requester_id").
+		Where("connections.addressee_id = ? AND connections.type = ? AND connections.status = ?",
+			userID, models.ConnectionTypeFollow, models.ConnectionStatusAccepted)
+	return r.list(base, p, connectionUserColumns+", connections.created_at AS since")
+}
+
+func (r *connectionRepository) ListFollowing(userID uuid.UUID, p interfaces.Pagination) ([]models.ConnectionUser, int64, error) {
+	base := r.db.Table("connections").
+		Joins("JOIN users ON users.id = connections.addressee_id").
+		Where("connections.requester_id = ? AND connections.type = ? AND connections.status = ?",
+			userID, models.ConnectionTypeFollow, models.ConnectionStatusAccepted)
+	return r.list(base, p, connectionUserColumns+", connections.created_at AS since")
+}
+
+func (r *connectionRepository) ListMutual(userID uuid.UUID, p interfaces.Pagination) ([]models.ConnectionUser, int64, error) {
+	base := r.db.Table("connections").
+		Joins(`JOIN users ON users.id = CASE WHEN connections.requester_id = ? THEN connections.addressee_id ELSE connections.requester_id END`, userID).
+		Where("(connections.requester_id = ? OR connections.addressee_id = ?) AND connections.type = ? AND connections.status = ?",
+			userID, userID, models.ConnectionTypeMutual, models.ConnectionStatusAccepted)
+	return r.list(base, p, connectionUserColumns+", COALESCE(connections.responded_at, connections.created_at) AS since")
+}
+
+func (r *connectionRepository) ListIncomingRequests(userID uuid.UUID, p interfaces.Pagination) ([]models.ConnectionUser, int64, error) {
+	base := r.db.Table("connections").
+		Joins("JOIN users ON users.id = connections.requester_id").
+		Where("connections.addressee_id = ? AND connections.type = ? AND connections.status = ?",
+			userID, models.ConnectionTypeMutual, models.ConnectionStatusPending)
+	return r.list(base, p, connectionUserColumns+", connections.created_at AS since, connections.id AS request_id")
+}
+
+func (r *connectionRepository) Counts(userID uuid.UUID) (models.ConnectionCounts, error) {
+	var counts models.ConnectionCounts
+
+	err := r.db.Model(&models.Connection{}).
+		Where("addressee_id = ? AND type = ? AND status = ?", userID, models.ConnectionTypeFollow, models.ConnectionStatusAccepted).
+		Count(&counts.Followers).Error
+	if err != nil {
+		return counts, err
+	}
+
+	err = r.db.Model(&models.Connection{}).
+		Where("requester_id = ? AND type = ? AND status = ?", userID, models.ConnectionTypeFollow, models.ConnectionStatusAccepted).
+		Count(&counts.Following).Error
+	if err != nil {
+		return counts, err
+	}
+
+	err = r.db.Model(&models.Connection{}).
+		Where("(requester_id = ? OR addressee_id = ?) AND type = ? AND status = ?",
+			userID, userID, models.ConnectionTypeMutual, models.ConnectionStatusAccepted).
+		Count(&counts.Connections).Error
+	return counts, err
+}
+
+func (r *connectionRepository) IDs(userID uuid.UUID) (interfaces.UserConnectionIDs, error) {
+	out := interfaces.UserConnectionIDs{
+		UserID:      userID,
+		Followers:   []uuid.UUID{},
+		Following:   []uuid.UUID{},
+		Connections: []uuid.UUID{},
+	}
+
+	err := r.db.Model(&models.Connection{}).
+		Where("addressee_id = ? AND type = ? AND status = ?", userID, models.ConnectionTypeFollow, models.ConnectionStatusAccepted).
+		Pluck("requester_id", &out.Followers).Error
+	if err != nil {
+		return out, err
+	}
+
+	err = r.db.Model(&models.Connection{}).
+		Where("requester_id = ? AND type = ? AND status = ?", userID, models.ConnectionTypeFollow, models.ConnectionStatusAccepted).
+		Pluck("addressee_id", &out.Following).Error
+	if err != nil {
+		return out, err
+	}
+
+	err = r.db.Model(&models.Connection{}).
+		Select("CASE WHEN requester_id = ? THEN addressee_id ELSE requester_id END", userID).
+		Where("(requester_id = ? OR addressee_id = ?) AND type = ? AND status = ?",
+			userID, userID, models.ConnectionTypeMutual, models.ConnectionStatusAccepted).
+		Scan(&out.Connections).Error
+	return out, err
+}
+
+// DeleteBetween removes every follow, request and connection between a and b, in both directions.
+func (r *connectionRepository) DeleteBetween(a, b uuid.UUID) error {
+	return r.db.
+		Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)", a, b, b, a).
+		Delete(&models.Connection{}).Error
+}
@@ -23,12 +23,32 @@ func (r *DiscoveryPreferencesRepository) GetByEmail(email string) (*models.Disco
 
 func (r *DiscoveryPreferencesRepository) Upsert(p *models.DiscoveryPreferences) (*models.DiscoveryPreferences, error) {
 	err := r.db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "email"}},
-		DoUpdates: clause.AssignmentColumns([]string{"radius_km"}),
+		Columns: []clause.Column{{Name: "email"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"radius_km": gorm.Expr("excluded.radius_km"),
+			"version":   gorm.Expr("discovery_preferences.version + 1"),
+		}),
 	}).Create(p).Error
 
 	if err != nil {
 		return nil, err
 	}
-	return p, nil
+	return r.GetByEmail(p.Email)
+}
+
+// UpdateIfVersion changes existing preferences only while they still have version; ErrVersionConflict otherwise
+func (r *DiscoveryPreferencesRepository) UpdateIfVersion(p *models.DiscoveryPreferences, version int64) (*models.DiscoveryPreferences, error) {
+	res := r.db.Model(&models.DiscoveryPreferences{}).
+		Where("email = ? AND version = ?", p.Email, version).
+		Updates(map[string]any{
+			"radius_km": p.RadiusKm,
+			"version":   nextVersion(),
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrVersionConflict
+	}
+	return r.GetByEmail(p.Email)
 }
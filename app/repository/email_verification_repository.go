@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type emailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) interfaces.EmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+// MarkVerified bumps the profile version, so cached ETags of the unverified profile no longer match
+func (r *emailVerificationRepository) MarkVerified(userID uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", userID).
+		Updates(map[string]any{"email_verified_at": at, "version": nextVersion()}).Error
+}
+
+func (r *emailVerificationRepository) FindSend(userID uuid.UUID) (*models.EmailVerificationSend, error) {
+	var send models.EmailVerificationSend
+	err := r.db.Where("user_id = ?", userID).First(&send).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &send, nil
+}
+
+func (r *emailVerificationRepository) SaveSend(send *models.EmailVerificationSend) error {
+	return r.db.Save(send).Error
+}
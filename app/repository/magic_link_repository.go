@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"time"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"gorm.io/gorm"
+)
+
+type MagicLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewMagicLinkRepository(db *gorm.DB) interfaces.MagicLinkRepository {
+	return &MagicLinkRepository{db: db}
+}
+
+func (r *MagicLinkRepository) Create(email string, tokenHash string, expiresAt time.Time) error {
+	row := &models.MagicLinkToken{
+		Email:     email,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		Used:      false,
+	}
+	return r.db.Create(row).Error
+}
+
+func (r *MagicLinkRepository) FindValidByTokenHash(tokenHash string) (*models.MagicLinkToken, error) {
+	var row models.MagicLinkToken
+	err := r.db.
+		Where("token_hash = ? AND used = false AND expires_at > NOW()", tokenHash).
+		First(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+func (r *MagicLinkRepository) MarkUsed(id uint) (bool, error) {
+	res := r.db.Model(&models.MagicLinkToken{}).
+		Where("id = ? AND used = false", id).
+		Update("used", true)
+	return res.RowsAffected > 0, res.Error
+}
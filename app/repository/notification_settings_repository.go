@@ -31,9 +31,12 @@ func (r *NotificationSettingsRepository) Upsert(s *models.NotificationSettings)
 		existing.FavoritePush = s.FavoritePush
 		existing.ChatEmail = s.ChatEmail
 		existing.ChatPush = s.ChatPush
+		existing.ConnectionEmail = s.ConnectionEmail
+		existing.ConnectionPush = s.ConnectionPush
 		existing.SystemEmail = s.SystemEmail
 		existing.SystemPush = s.SystemPush
 		existing.ExpoPushToken = s.ExpoPushToken
+		existing.Version++
 
 		config.DB.Save(&existing)
 		return &existing, nil
@@ -42,3 +45,30 @@ func (r *NotificationSettingsRepository) Upsert(s *models.NotificationSettings)
 	config.DB.Create(s)
 	return s, nil
 }
+
+// UpdateIfVersion saves s only while the stored settings still have version; ErrVersionConflict otherwise
+func (r *NotificationSettingsRepository) UpdateIfVersion(s *models.NotificationSettings, version int64) (*models.NotificationSettings, error) {
+	res := config.DB.Model(&models.NotificationSettings{}).
+		Where("user_email = ? AND version = ?", s.UserEmail, version).
+		Updates(map[string]any{
+			"like_email":       s.LikeEmail,
+			"like_push":        s.LikePush,
+			"favorite_email":   s.FavoriteEmail,
+			"favorite_push":    s.FavoritePush,
+			"chat_email":       s.ChatEmail,
+			"chat_push":        s.ChatPush,
+			"connection_email": s.ConnectionEmail,
+			"connection_push":  s.ConnectionPush,
+			"system_email":     s.SystemEmail,
+			"system_push":      s.SystemPush,
+			"expo_push_token":  s.ExpoPushToken,
+			"version":          nextVersion(),
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrVersionConflict
+	}
+	return r.GetByEmail(s.UserEmail)
+}
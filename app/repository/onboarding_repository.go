@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type onboardingRepository struct {
+	db *gorm.DB
+}
+
+func NewOnboardingRepository(db *gorm.DB) interfaces.OnboardingRepository {
+	return &onboardingRepository{db: db}
+}
+
+func (r *onboardingRepository) find(userID uuid.UUID) (*models.OnboardingState, error) {
+	var state models.OnboardingState
+	err := r.db.
+		Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("user_id = ?", userID).
+		First(&state).Error
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// FindOrCreate inserts fresh unless the user already has a state; concurrent first requests end up
+// with the same state
+func (r *onboardingRepository) FindOrCreate(userID uuid.UUID, fresh *models.OnboardingState) (*models.OnboardingState, bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Steps").Create(fresh)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		created = true
+		return tx.Create(&fresh.Steps).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	state, err := r.find(userID)
+	return state, created, err
+}
+
+func (r *onboardingRepository) Update(state *models.OnboardingState) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.OnboardingState{}).
+			Where("user_id = ?", state.UserID).
+			Updates(map[string]any{
+				"completed_at":         state.CompletedAt,
+				"notification_consent": state.NotificationConsent,
+			}).Error; err != nil {
+			return err
+		}
+
+		for _, s := range state.Steps {
+			if err := tx.Model(&models.OnboardingStepState{}).
+				Where("user_id = ? AND step = ?", state.UserID, s.Step).
+				Updates(map[string]any{"status": s.Status, "finished_at": s.FinishedAt}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
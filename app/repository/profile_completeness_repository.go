@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"gorm.io/gorm"
+)
+
+type profileCompletenessRepository struct {
+	db *gorm.DB
+}
+
+func NewProfileCompletenessRepository(db *gorm.DB) interfaces.ProfileCompletenessRepository {
+	return &profileCompletenessRepository{db: db}
+}
+
+func (r *profileCompletenessRepository) CountSelectedInterests(email string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserInterest{}).
+		Where("user_email = ? AND value = ?", email, true).
+		// selections of archived interests are kept, but users cannot see them anymore
+		Where("interest_id IN (?)", r.db.Model(&models.Interest{}).Select("id").Where("archived_at IS NULL")).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *profileCompletenessRepository) HasDiscoveryPreferences(email string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.DiscoveryPreferences{}).
+		Where("email = ?", email).
+		Count(&count).Error
+	return count > 0, err
+}
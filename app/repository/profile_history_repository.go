@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"errors"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type profileHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewProfileHistoryRepository(db *gorm.DB) interfaces.ProfileHistoryRepository {
+	return &profileHistoryRepository{db: db}
+}
+
+func (r *profileHistoryRepository) List(userID uuid.UUID, offset, limit int) ([]models.ProfileVersion, int64, error) {
+	var total int64
+	if err := r.db.Model(&models.ProfileVersion{}).
+		Where("user_id = ?", userID).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := []models.ProfileVersion{}
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("version DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&items).Error
+	return items, total, err
+}
+
+// FindVocabularyTerm also knows the free-text values that were mapped to a term, so a version from
+// before the vocabulary migration can still be restored
+func (r *profileHistoryRepository) FindVocabularyTerm(v models.Vocabulary, value string) (*models.VocabularyTerm, error) {
+	term, err := findVocabularyTerm(r.db, v, value)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return term, err
+	}
+
+	var mapping models.VocabularyMapping
+	if err := r.db.Where("vocabulary = ? AND raw_value = ? AND applied_key <> ''", v, value).
+		First(&mapping).Error; err != nil {
+		return nil, err
+	}
+	return findVocabularyTerm(r.db, v, mapping.AppliedKey)
+}
+
+func (r *profileHistoryRepository) Find(userID uuid.UUID, version int) (*models.ProfileVersion, error) {
+	var v models.ProfileVersion
+	if err := r.db.Where("user_id = ? AND version = ?", userID, version).First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Restore writes the snapshot back as a new version. A photo removed by a moderator after the snapshot
+// is not brought back: its object is gone from storage and it was removed for a reason.
+func (r *profileHistoryRepository) Restore(userID uuid.UUID, v models.ProfileVersion) (models.User, error) {
+	fields := v.Snapshot.Fields()
+
+	var moderated int64
+	if err := r.db.Model(&models.ProfileVersion{}).
+		Where("user_id = ? AND version > ? AND reason = ?", userID, v.Version, models.ProfileVersionModeration).
+		Count(&moderated).Error; err != nil {
+		return models.User{}, err
+	}
+	if moderated > 0 {
+		delete(fields, "profile_photo_url")
+	}
+
+	restoredFrom := v.Version
+	return updateProfile(r.db, "id = ?", userID, fields, 0, models.ProfileVersionRestore, &restoredFrom)
+}
+
+// updateProfile applies fields to the user matching where and records the new profile version,
+// all in one transaction. The user row is locked so concurrent edits get consecutive versions.
+// A non-zero expectedVersion makes the update conditional: ErrVersionConflict when it no longer matches.
+func updateProfile(
+	db *gorm.DB,
+	where string,
+	arg any,
+	fields map[string]any,
+	expectedVersion int64,
+	reason models.ProfileVersionReason,
+	restoredFrom *int,
+) (models.User, error) {
+	var updated models.User
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var before models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(where, arg).
+			First(&before).Error; err != nil {
+			return err
+		}
+
+		if expectedVersion == 0 {
+			expectedVersion = before.Version
+		}
+
+		if len(fields) > 0 {
+			changes := map[string]any{"version": nextVersion()}
+			for k, v := range fields {
+				changes[k] = v
+			}
+
+			res := tx.Model(&models.User{}).
+				Where("id = ? AND version = ?", before.ID, expectedVersion).
+				Updates(changes)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return ErrVersionConflict
+			}
+		} else if before.Version != expectedVersion {
+			return ErrVersionConflict
+		}
+
+		if err := tx.Where("id = ?", before.ID).First(&updated).Error; err != nil {
+			return err
+		}
+
+		return appendProfileVersion(tx, before, updated, reason, restoredFrom)
+	})
+
+	return updated, err
+}
+
+// appendProfileVersion stores the snapshot of after when it differs from before.
+// The first change of a user also stores the state before it, so it can be restored.
+func appendProfileVersion(
+	tx *gorm.DB,
+	before, after models.User,
+	reason models.ProfileVersionReason,
+	restoredFrom *int,
+) error {
+	prev := models.SnapshotOf(before)
+	next := models.SnapshotOf(after)
+	if prev == next {
+		return nil
+	}
+
+	var latest int
+	if err := tx.Model(&models.ProfileVersion{}).
+		Where("user_id = ?", after.ID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	if latest == 0 {
+		latest++
+		initial := models.ProfileVersion{
+			UserID:   after.ID,
+			Version:  latest,
+			Reason:   models.ProfileVersionInitial,
+			Snapshot: prev,
+		}
+		if err := tx.Create(&initial).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Create(&models.ProfileVersion{
+		UserID:       after.ID,
+		Version:      latest + 1,
+		Reason:       reason,
+		RestoredFrom: restoredFrom,
+		Snapshot:     next,
+	}).Error
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type profileVisibilityRepository struct {
+	db *gorm.DB
+}
+
+func NewProfileVisibilityRepository(db *gorm.DB) interfaces.ProfileVisibilityRepository {
+	return &profileVisibilityRepository{db: db}
+}
+
+func (r *profileVisibilityRepository) FindByUserID(userID uuid.UUID) (*models.ProfileVisibility, error) {
+	var v models.ProfileVisibility
+	if err := r.db.Where("user_id = ?", userID).First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *profileVisibilityRepository) Upsert(v *models.ProfileVisibility) (*models.ProfileVisibility, error) {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"email", "phone_number", "country", "job_function", "sector", "biography", "badges",
+		}),
+	}).Create(v).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
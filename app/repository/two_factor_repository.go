@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) interfaces.TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) Find(userID uuid.UUID) (*models.TwoFactor, error) {
+	var tf models.TwoFactor
+	err := r.db.Where("user_id = ?", userID).First(&tf).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+func (r *twoFactorRepository) Save(tf *models.TwoFactor) error {
+	return r.db.Save(tf).Error
+}
+
+func (r *twoFactorRepository) Delete(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []any{&models.RecoveryCode{}, &models.LoginChallenge{}, &models.TwoFactor{}} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *twoFactorRepository) UseStep(userID uuid.UUID, step int64) (bool, error) {
+	res := r.db.Model(&models.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID uuid.UUID, hashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, 0, len(hashes))
+		for _, h := range hashes {
+			codes = append(codes, models.RecoveryCode{UserID: userID, CodeHash: h})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) CountRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(userID uuid.UUID, hash string) (bool, error) {
+	res := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *twoFactorRepository) CreateChallenge(ch *models.LoginChallenge) error {
+	return r.db.Create(ch).Error
+}
+
+func (r *twoFactorRepository) FindChallenge(tokenHash string) (*models.LoginChallenge, error) {
+	var ch models.LoginChallenge
+	err := r.db.
+		Where("token_hash = ? AND expires_at > NOW()", tokenHash).
+		First(&ch).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
+func (r *twoFactorRepository) AddChallengeAttempt(id uint) error {
+	return r.db.Model(&models.LoginChallenge{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+func (r *twoFactorRepository) DeleteChallenge(id uint) error {
+	return r.db.Delete(&models.LoginChallenge{}, id).Error
+}
@@ -54,3 +54,8 @@ func (r *userBadgeRepository) FindByUserID(userID uuid.UUID) ([]models.UserBadge
 
 	return badges, err
 }
+
+func (r *userBadgeRepository) Delete(userID uuid.UUID, badgeKey string) (bool, error) {
+	tx := r.db.Where("user_id = ? AND badge_key = ?", userID, badgeKey).Delete(&models.UserBadge{})
+	return tx.RowsAffected > 0, tx.Error
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type userBlockRepository struct {
+	db *gorm.DB
+}
+
+func NewUserBlockRepository(db *gorm.DB) interfaces.UserBlockRepository {
+	return &userBlockRepository{db: db}
+}
+
+// Create stores the block; blocking someone twice keeps the original row.
+func (r *userBlockRepository) Create(b *models.UserBlock) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(b).Error
+}
+
+func (r *userBlockRepository) Delete(blockerID, blockedID uuid.UUID, kind models.BlockKind) (bool, error) {
+	res := r.db.
+		Where("blocker_id = ? AND blocked_id = ? AND kind = ?", blockerID, blockedID, kind).
+		Delete(&models.UserBlock{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *userBlockRepository) Exists(blockerID, blockedID uuid.UUID, kind models.BlockKind) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserBlock{}).
+		Where("blocker_id = ? AND blocked_id = ? AND kind = ?", blockerID, blockedID, kind).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *userBlockRepository) BlockedBetween(a, b uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserBlock{}).
+		Where("kind = ?", models.BlockKindBlock).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *userBlockRepository) List(blockerID uuid.UUID, kind models.BlockKind, p interfaces.Pagination) ([]models.ConnectionUser, int64, error) {
+	base := r.db.Table("user_blocks").
+		Joins("JOIN users ON users.id = user_blocks.blocked_id").
+		Where("user_blocks.blocker_id = ? AND user_blocks.kind = ?", blockerID, kind)
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := []models.ConnectionUser{}
+	err := base.Session(&gorm.Session{}).
+		Select(connectionUserColumns + ", user_blocks.created_at AS since").
+		Order("user_blocks.created_at DESC, user_blocks.id DESC").
+		Limit(p.PageSize).
+		Offset(p.Offset()).
+		Scan(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *userBlockRepository) TargetIDs(blockerID uuid.UUID, kind models.BlockKind) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	err := r.db.Model(&models.UserBlock{}).
+		Where("blocker_id = ? AND kind = ?", blockerID, kind).
+		Pluck("blocked_id", &ids).Error
+	return ids, err
+}
+
+func (r *userBlockRepository) BlockerIDs(blockedID uuid.UUID, kind models.BlockKind) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	err := r.db.Model(&models.UserBlock{}).
+		Where("blocked_id = ? AND kind = ?", blockedID, kind).
+		Pluck("blocker_id", &ids).Error
+	return ids, err
+}
@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"time"
+
 	"group1-userservice/app/config"
 	"group1-userservice/app/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserInterestsRepository struct {
@@ -33,14 +36,105 @@ func (r *UserInterestsRepository) CreateInterests(keys []string) error {
 	return nil
 }
 
+// ListAllInterests returns every interest, archived ones included, in the order users see them
 func (r *UserInterestsRepository) ListAllInterests() ([]models.Interest, error) {
 	var interests []models.Interest
-	if err := r.db.Order("id asc").Find(&interests).Error; err != nil {
+	if err := r.db.Preload("Translations").Order("position asc, id asc").Find(&interests).Error; err != nil {
+		return nil, err
+	}
+	return interests, nil
+}
+
+// ListActiveInterests returns the interests users can select
+func (r *UserInterestsRepository) ListActiveInterests() ([]models.Interest, error) {
+	var interests []models.Interest
+	if err := r.db.Preload("Translations").Where("archived_at IS NULL").Order("position asc, id asc").Find(&interests).Error; err != nil {
 		return nil, err
 	}
 	return interests, nil
 }
 
+func (r *UserInterestsRepository) FindInterest(id uint) (*models.Interest, error) {
+	var interest models.Interest
+	if err := r.db.Preload("Translations").First(&interest, id).Error; err != nil {
+		return nil, err
+	}
+	return &interest, nil
+}
+
+func (r *UserInterestsRepository) FindInterestByKey(key string) (*models.Interest, error) {
+	var interest models.Interest
+	if err := r.db.Where("key = ?", key).First(&interest).Error; err != nil {
+		return nil, err
+	}
+	return &interest, nil
+}
+
+// CreateInterest adds the interest with its translations after the last interest
+func (r *UserInterestsRepository) CreateInterest(interest *models.Interest) error {
+	var last int
+	if err := r.db.Model(&models.Interest{}).Select("COALESCE(MAX(position), 0)").Scan(&last).Error; err != nil {
+		return err
+	}
+	interest.Position = last + 1
+	return r.db.Create(interest).Error
+}
+
+// SaveTranslations adds or replaces the label and description of an interest per locale
+func (r *UserInterestsRepository) SaveTranslations(translations []models.InterestTranslation) error {
+	if len(translations) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "interest_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"label", "description"}),
+	}).Create(&translations).Error
+}
+
+// SetPositions numbers the interests from 1 in the order of ids
+func (r *UserInterestsRepository) SetPositions(ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for n, id := range ids {
+			if err := tx.Model(&models.Interest{}).Where("id = ?", id).Update("position", n+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *UserInterestsRepository) ArchiveInterest(id uint, at time.Time) error {
+	return r.db.Model(&models.Interest{}).Where("id = ? AND archived_at IS NULL", id).Update("archived_at", at).Error
+}
+
+// MergeInterests moves the selections of from to into and archives from, in one transaction. A user who
+// had both keeps into selected when either was. It returns the number of selections moved.
+func (r *UserInterestsRepository) MergeInterests(from, into uint, at time.Time) (int64, error) {
+	var moved int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserInterest{}).Where("interest_id = ?", from).Count(&moved).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`UPDATE user_interests AS t SET value = t.value OR s.value
+			FROM user_interests AS s
+			WHERE s.interest_id = ? AND t.interest_id = ? AND t.user_email = s.user_email`, from, into).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`UPDATE user_interests SET interest_id = ?
+			WHERE interest_id = ? AND user_email NOT IN (SELECT user_email FROM user_interests WHERE interest_id = ?)`,
+			into, from, into).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("interest_id = ?", from).Delete(&models.UserInterest{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Interest{}).Where("id = ? AND archived_at IS NULL", from).Update("archived_at", at).Error
+	})
+	return moved, err
+}
+
 func (r *UserInterestsRepository) GetUserInterests(email string) ([]models.UserInterest, error) {
 	var rows []models.UserInterest
 	if err := r.db.
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"time"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type userReportRepository struct {
+	db *gorm.DB
+}
+
+func NewUserReportRepository(db *gorm.DB) interfaces.UserReportRepository {
+	return &userReportRepository{db: db}
+}
+
+func (r *userReportRepository) Create(report *models.UserReport) error {
+	return r.db.Create(report).Error
+}
+
+func (r *userReportRepository) FindByID(id uint) (*models.UserReport, error) {
+	var report models.UserReport
+	if err := r.db.First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *userReportRepository) HasOpenReport(reporterID, reportedID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserReport{}).
+		Where("reporter_id = ? AND reported_id = ? AND status IN ?", reporterID, reportedID,
+			[]models.ReportStatus{models.ReportStatusOpen, models.ReportStatusInReview}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List returns reports oldest first, so the queue is worked in order. An empty status lists all reports.
+func (r *userReportRepository) List(status models.ReportStatus, p interfaces.Pagination) ([]models.UserReport, int64, error) {
+	q := r.db.Model(&models.UserReport{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := []models.UserReport{}
+	err := q.Session(&gorm.Session{}).
+		Order("created_at ASC, id ASC").
+		Limit(p.PageSize).
+		Offset(p.Offset()).
+		Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Claim assigns an open report to the moderator. It also succeeds when the moderator already holds the claim.
+// It returns false when the report does not exist, is closed or is claimed by someone else.
+func (r *userReportRepository) Claim(id uint, moderatorID string, at time.Time) (bool, error) {
+	res := r.db.Model(&models.UserReport{}).
+		Where("id = ?", id).
+		Where("status = ? OR (status = ? AND claimed_by = ?)",
+			models.ReportStatusOpen, models.ReportStatusInReview, moderatorID).
+		Updates(map[string]interface{}{
+			"status":     models.ReportStatusInReview,
+			"claimed_by": moderatorID,
+			"claimed_at": at,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// Resolve closes the report, records the actions and applies userUpdates to the reported user in one transaction.
+// The report is only closed while it is open or claimed by report.ResolvedBy. Otherwise nothing is written and
+// it returns false, so of two concurrent resolves only the first applies its actions.
+func (r *userReportRepository) Resolve(
+	report *models.UserReport,
+	actions []models.ModerationAction,
+	userUpdates map[string]interface{},
+) (bool, error) {
+	resolved := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.UserReport{}).
+			Where("id = ?", report.ID).
+			Where("status IN ? AND (claimed_by = '' OR claimed_by = ?)",
+				[]models.ReportStatus{models.ReportStatusOpen, models.ReportStatusInReview}, report.ResolvedBy).
+			Updates(map[string]interface{}{
+				"status":      report.Status,
+				"claimed_by":  report.ClaimedBy,
+				"claimed_at":  report.ClaimedAt,
+				"resolved_by": report.ResolvedBy,
+				"resolved_at": report.ResolvedAt,
+				"resolution":  report.Resolution,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		resolved = true
+
+		if len(actions) > 0 {
+			if err := tx.Create(&actions).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(userUpdates) > 0 {
+			_, err := updateProfile(tx, "id = ?", report.ReportedID, userUpdates, 0, models.ProfileVersionModeration, nil)
+			return err
+		}
+
+		return nil
+	})
+	return resolved && err == nil, err
+}
+
+func (r *userReportRepository) AddAction(a *models.ModerationAction) error {
+	return r.db.Create(a).Error
+}
+
+func (r *userReportRepository) Actions(reportID uint) ([]models.ModerationAction, error) {
+	actions := []models.ModerationAction{}
+	err := r.db.Where("report_id = ?", reportID).Order("created_at ASC, id ASC").Find(&actions).Error
+	return actions, err
+}
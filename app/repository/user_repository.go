@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"group1-userservice/app/interfaces"
 	"group1-userservice/app/models"
 
@@ -71,34 +73,27 @@ func (r *userRepository) FindByFirstLastInsensitive(first, last string) (models.
 	return user, err
 }
 
-func (r *userRepository) UpdatePasswordHashByEmail(email string, passwordHash string) error {
-	return r.db.Model(&models.User{}).
-		Where("email = ?", email).
-		Update("password", passwordHash).Error
+// ClearLegacyPasswordHash empties the legacy password column; the model never writes it
+func (r *userRepository) ClearLegacyPasswordHash(id uuid.UUID) error {
+	return r.db.Table("users").
+		Where("id = ?", id).
+		Update("password", nil).Error
 }
 
+// UpdateFieldsByEmail applies fields and records a profile version when the profile changed
 func (r *userRepository) UpdateFieldsByEmail(email string, fields map[string]any) (models.User, error) {
-	if err := r.db.Model(&models.User{}).
-		Where("email = ?", email).
-		Updates(fields).Error; err != nil {
-		return models.User{}, err
-	}
+	return updateProfile(r.db, "email = ?", email, fields, 0, models.ProfileVersionUpdate, nil)
+}
 
-	return r.FindByEmail(email)
+// UpdateFieldsByEmailIfVersion is UpdateFieldsByEmail that only applies while the user still has version
+func (r *userRepository) UpdateFieldsByEmailIfVersion(email string, fields map[string]any, version int64) (models.User, error) {
+	return updateProfile(r.db, "email = ?", email, fields, version, models.ProfileVersionUpdate, nil)
 }
 
 func (r *userRepository) UpdateProfilePhotoURLByKeycloakID(keycloakID, url string) error {
-	tx := r.db.Model(&models.User{}).
-		Where("keycloak_id = ?", keycloakID).
-		Update("profile_photo_url", url)
-
-	if tx.Error != nil {
-		return tx.Error
-	}
-	if tx.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return nil
+	_, err := updateProfile(r.db, "keycloak_id = ?", keycloakID,
+		map[string]any{"profile_photo_url": url}, 0, models.ProfileVersionPhoto, nil)
+	return err
 }
 
 func (r *userRepository) GetByID(id uuid.UUID) (models.User, error) {
@@ -106,3 +101,93 @@ func (r *userRepository) GetByID(id uuid.UUID) (models.User, error) {
 	err := r.db.Where("id = ?", id).First(&user).Error
 	return user, err
 }
+
+func (r *userRepository) FindVocabularyTerm(v models.Vocabulary, value string) (*models.VocabularyTerm, error) {
+	return findVocabularyTerm(r.db, v, value)
+}
+
+func (r *userRepository) FindByHandle(handle string) (models.User, error) {
+	var user models.User
+	err := r.db.Where("LOWER(handle) = LOWER(?)", handle).First(&user).Error
+	return user, err
+}
+
+// HandleInUse reports whether a handle belongs to a user or is still reserved after a rename
+func (r *userRepository) HandleInUse(handle string) (bool, error) {
+	var users int64
+	if err := r.db.Model(&models.User{}).
+		Where("LOWER(handle) = LOWER(?)", handle).
+		Count(&users).Error; err != nil {
+		return false, err
+	}
+	if users > 0 {
+		return true, nil
+	}
+
+	var reservations int64
+	if err := r.db.Model(&models.HandleReservation{}).
+		Where("LOWER(handle) = LOWER(?) AND reserved_until > ?", handle, time.Now()).
+		Count(&reservations).Error; err != nil {
+		return false, err
+	}
+	return reservations > 0, nil
+}
+
+func (r *userRepository) FindActiveHandleReservation(handle string) (*models.HandleReservation, error) {
+	var row models.HandleReservation
+	err := r.db.
+		Where("LOWER(handle) = LOWER(?) AND reserved_until > ?", handle, time.Now()).
+		First(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// ChangeHandle sets a new handle and reserves the old one in a single transaction
+func (r *userRepository) ChangeHandle(userID uuid.UUID, newHandle string, reservedUntil time.Time) (models.User, error) {
+	var updated models.User
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+
+		if user.Handle != "" {
+			reservation := models.HandleReservation{
+				Handle:        user.Handle,
+				UserID:        user.ID,
+				ReservedUntil: reservedUntil,
+			}
+			if err := tx.Save(&reservation).Error; err != nil {
+				return err
+			}
+		}
+
+		// Reclaiming one of your own reserved handles releases the reservation
+		if err := tx.Where("LOWER(handle) = LOWER(?) AND user_id = ?", newHandle, user.ID).
+			Delete(&models.HandleReservation{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&user).Updates(map[string]any{
+			"handle":            newHandle,
+			"handle_changed_at": now,
+			"version":           nextVersion(),
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", userID).First(&updated).Error
+	})
+
+	return updated, err
+}
+
+func (r *userRepository) FindWithoutHandle() ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("handle = '' OR handle IS NULL").Find(&users).Error
+	return users, err
+}
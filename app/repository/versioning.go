@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"group1-userservice/app/apperrors"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned by conditional updates when the row no longer has the expected version
+var ErrVersionConflict = apperrors.PreconditionFailed("version_conflict", "resource was modified, fetch it again and retry")
+
+// nextVersion increments the version column of the updated row
+func nextVersion() any {
+	return gorm.Expr("version + 1")
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"strings"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type vocabularyRepository struct {
+	db *gorm.DB
+}
+
+func NewVocabularyRepository(db *gorm.DB) interfaces.VocabularyRepository {
+	return &vocabularyRepository{db: db}
+}
+
+// findVocabularyTerm matches value against the key and both labels, ignoring case
+func findVocabularyTerm(db *gorm.DB, v models.Vocabulary, value string) (*models.VocabularyTerm, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	var t models.VocabularyTerm
+	err := db.Table(string(v)).
+		Where("LOWER(key) = ? OR LOWER(label_nl) = ? OR LOWER(label_en) = ?", value, value, value).
+		// a key wins over a label of another term
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "LOWER(key) = ? DESC", Vars: []any{value}}}).
+		First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *vocabularyRepository) FindVocabularyTerm(v models.Vocabulary, value string) (*models.VocabularyTerm, error) {
+	return findVocabularyTerm(r.db, v, value)
+}
+
+func (r *vocabularyRepository) List(v models.Vocabulary) ([]models.VocabularyTerm, error) {
+	terms := []models.VocabularyTerm{}
+	err := r.db.Table(string(v)).Order("position ASC, key ASC").Find(&terms).Error
+	return terms, err
+}
+
+func (r *vocabularyRepository) Find(v models.Vocabulary, key string) (*models.VocabularyTerm, error) {
+	var t models.VocabularyTerm
+	if err := r.db.Table(string(v)).Where("key = ?", key).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *vocabularyRepository) Create(v models.Vocabulary, t *models.VocabularyTerm) error {
+	return r.db.Table(string(v)).Create(t).Error
+}
+
+func (r *vocabularyRepository) Update(v models.Vocabulary, t *models.VocabularyTerm) error {
+	res := r.db.Table(string(v)).
+		Where("key = ?", t.Key).
+		Updates(map[string]any{"label_nl": t.LabelNL, "label_en": t.LabelEN, "position": t.Position})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *vocabularyRepository) Delete(v models.Vocabulary, key string) (bool, error) {
+	res := r.db.Table(string(v)).Where("key = ?", key).Delete(&models.VocabularyTerm{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *vocabularyRepository) UsersWithTerm(v models.Vocabulary, key string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where(v.ProfileField()+" = ?", key).Count(&count).Error
+	return count, err
+}
+
+// UnknownProfileValues groups the non-empty profile values that are not a key of the vocabulary
+func (r *vocabularyRepository) UnknownProfileValues(v models.Vocabulary) ([]interfaces.VocabularyValueCount, error) {
+	column := v.ProfileField()
+
+	values := []interfaces.VocabularyValueCount{}
+	err := r.db.Model(&models.User{}).
+		Select(column+" AS value, COUNT(*) AS users").
+		Where(column+" <> ''").
+		Where(column+" NOT IN (?)", r.db.Table(string(v)).Select("key")).
+		Group(column).
+		Order("users DESC").
+		Scan(&values).Error
+	return values, err
+}
+
+// ReplaceProfileValue sets the term key on every profile that has raw and returns the IDs of those users.
+// Each profile is updated on its own and gets a history version, so the change can be seen and restored.
+func (r *vocabularyRepository) ReplaceProfileValue(v models.Vocabulary, raw, key string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&models.User{}).
+		Where(v.ProfileField()+" = ?", raw).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		_, err := updateProfile(r.db, "id = ?", id, map[string]any{v.ProfileField(): key}, 0, models.ProfileVersionVocabulary, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// SaveMapping stores the mapping, replacing an earlier mapping of the same value
+func (r *vocabularyRepository) SaveMapping(m *models.VocabularyMapping) error {
+	if m.ID != 0 {
+		return r.db.Save(m).Error
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "vocabulary"}, {Name: "raw_value"}},
+		DoUpdates: clause.AssignmentColumns([]string{"suggested_key", "score", "status", "applied_key", "user_count", "updated_at"}),
+	}).Create(m).Error
+}
+
+func (r *vocabularyRepository) ListMappings(v models.Vocabulary, status models.VocabularyMappingStatus, p interfaces.Pagination) ([]models.VocabularyMapping, int64, error) {
+	q := r.db.Model(&models.VocabularyMapping{}).Where("vocabulary = ?", v)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := []models.VocabularyMapping{}
+	err := q.Session(&gorm.Session{}).
+		Order("user_count DESC, id ASC").
+		Limit(p.PageSize).
+		Offset(p.Offset()).
+		Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *vocabularyRepository) FindMapping(v models.Vocabulary, id uint) (*models.VocabularyMapping, error) {
+	var m models.VocabularyMapping
+	if err := r.db.Where("vocabulary = ?", v).First(&m, id).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
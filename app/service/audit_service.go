@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+	"time"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+)
+
+type auditService struct {
+	repo interfaces.AuditRepository
+}
+
+func NewAuditService(repo interfaces.AuditRepository) interfaces.AuditService {
+	return &auditService{repo: repo}
+}
+
+func (s *auditService) Record(e models.AuditEvent) error {
+	e.ID = 0
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if e.ActorType == "" {
+		e.ActorType = models.AuditActorAnonymous
+	}
+	return s.repo.Create(&e)
+}
+
+func (s *auditService) Query(f interfaces.AuditFilter, p interfaces.Pagination) (*interfaces.AuditPage, error) {
+	items, total, err := s.repo.Query(f, p)
+	if err != nil {
+		return nil, err
+	}
+	return &interfaces.AuditPage{Items: items, Page: p.Page, PageSize: p.PageSize, Total: total}, nil
+}
+
+func (s *auditService) Purge(olderThan time.Duration) (int64, error) {
+	return s.repo.DeleteBefore(time.Now().Add(-olderThan))
+}
+
+// StartAuditRetention deletes audit events older than AUDIT_RETENTION_DAYS (default 365) once a day.
+// A value of 0 keeps events forever.
+func StartAuditRetention(s interfaces.AuditService) {
+	retention := envDays("AUDIT_RETENTION_DAYS", 365)
+	if retention <= 0 {
+		log.Println("[audit] retention disabled")
+		return
+	}
+
+	go func() {
+		for {
+			deleted, err := s.Purge(retention)
+			if err != nil {
+				log.Printf("[audit] retention run failed: %v\n", err)
+			} else if deleted > 0 {
+				log.Printf("[audit] retention removed %d events\n", deleted)
+			}
+			time.Sleep(24 * time.Hour)
+		}
+	}()
+}
+
+// skippedDiffFields are never written to a diff: secrets and bookkeeping fields
+var skippedDiffFields = map[string]bool{
+	"password":        true,
+	"expo_push_token": true,
+	"created_at":      true,
+	"updated_at":      true,
+	"version":         true,
+}
+
+// DiffFields compares the JSON representation of before and after and returns the changed fields.
+// Both values should be of the same type; secrets and timestamps are skipped.
+func DiffFields(before, after interface{}) models.FieldChanges {
+	b := toFieldMap(before)
+	a := toFieldMap(after)
+
+	changes := models.FieldChanges{}
+	for k, av := range a {
+		if skippedDiffFields[k] {
+			continue
+		}
+		if bv, ok := b[k]; !ok || !reflect.DeepEqual(bv, av) {
+			changes[k] = models.FieldChange{Before: b[k], After: av}
+		}
+	}
+	for k, bv := range b {
+		if skippedDiffFields[k] {
+			continue
+		}
+		if _, ok := a[k]; !ok {
+			changes[k] = models.FieldChange{Before: bv, After: nil}
+		}
+	}
+	return changes
+}
+
+func toFieldMap(v interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	if v == nil {
+		return out
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return out
+	}
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrConnectionSelf            = apperrors.BadRequest("connection_self", "cannot connect with yourself")
+	ErrConnectionTargetNotFound  = apperrors.NotFound("user_not_found", "user not found")
+	ErrConnectionExists          = apperrors.Conflict("connection_exists", "already connected")
+	ErrConnectionRequestPending  = apperrors.Conflict("connection_request_pending", "connection request already pending")
+	ErrConnectionNotFound        = apperrors.NotFound("connection_not_found", "connection not found")
+	ErrConnectionRequestNotFound = apperrors.NotFound("connection_request_not_found", "connection request not found")
+)
+
+const (
+	DefaultConnectionPageSize = 20
+	MaxConnectionPageSize     = 100
+)
+
+type connectionService struct {
+	repo        interfaces.ConnectionRepository
+	userSvc     interfaces.UserService
+	settingsSvc interfaces.NotificationSettingsService
+	notifier    interfaces.NotificationSender
+	blocks      interfaces.BlockChecker
+}
+
+func NewConnectionService(
+	repo interfaces.ConnectionRepository,
+	userSvc interfaces.UserService,
+	settingsSvc interfaces.NotificationSettingsService,
+	notifier interfaces.NotificationSender,
+	blocks interfaces.BlockChecker,
+) interfaces.ConnectionService {
+	return &connectionService{
+		repo:        repo,
+		userSvc:     userSvc,
+		settingsSvc: settingsSvc,
+		notifier:    notifier,
+		blocks:      blocks,
+	}
+}
+
+// NormalizePagination applies the default and maximum page size.
+func NormalizePagination(page, pageSize int) interfaces.Pagination {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultConnectionPageSize
+	}
+	if pageSize > MaxConnectionPageSize {
+		pageSize = MaxConnectionPageSize
+	}
+	return interfaces.Pagination{Page: page, PageSize: pageSize}
+}
+
+func (s *connectionService) target(from models.User, targetID uuid.UUID) (models.User, error) {
+	if from.ID == targetID {
+		return models.User{}, ErrConnectionSelf
+	}
+	target, err := s.userSvc.GetByID(targetID)
+	if err != nil {
+		return models.User{}, ErrConnectionTargetNotFound
+	}
+
+	// a blocked user is reported as not found, so blocking is not revealed
+	blocked, err := s.blocks.IsBlockedBetween(from.ID, target.ID)
+	if err != nil {
+		return models.User{}, err
+	}
+	if blocked {
+		return models.User{}, ErrConnectionTargetNotFound
+	}
+
+	return target, nil
+}
+
+func (s *connectionService) Follow(follower models.User, targetID uuid.UUID) (*models.Connection, error) {
+	target, err := s.target(follower, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// following twice is a no-op
+	if existing, err := s.repo.Find(follower.ID, target.ID, models.ConnectionTypeFollow); err == nil {
+		return existing, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	c := &models.Connection{
+		RequesterID: follower.ID,
+		AddresseeID: target.ID,
+		Type:        models.ConnectionTypeFollow,
+		Status:      models.ConnectionStatusAccepted,
+	}
+	if err := s.repo.Save(c); err != nil {
+		return nil, err
+	}
+
+	s.notify(target, "connection_follow", follower)
+
+	return c, nil
+}
+
+func (s *connectionService) Unfollow(follower models.User, targetID uuid.UUID) error {
+	existing, err := s.repo.Find(follower.ID, targetID, models.ConnectionTypeFollow)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrConnectionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(existing.ID)
+}
+
+// RequestConnection sends a connection request. When the target already sent a
+// pending request to the requester, that request is accepted instead.
+func (s *connectionService) RequestConnection(requester models.User, targetID uuid.UUID) (*models.Connection, error) {
+	target, err := s.target(requester, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reverse, err := s.repo.Find(target.ID, requester.ID, models.ConnectionTypeMutual); err == nil {
+		switch reverse.Status {
+		case models.ConnectionStatusAccepted:
+			return nil, ErrConnectionExists
+		case models.ConnectionStatusPending:
+			return s.accept(reverse, requester, target)
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	existing, err := s.repo.Find(requester.ID, target.ID, models.ConnectionTypeMutual)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if existing != nil {
+		switch existing.Status {
+		case models.ConnectionStatusAccepted:
+			return nil, ErrConnectionExists
+		case models.ConnectionStatusPending:
+			return nil, ErrConnectionRequestPending
+		}
+		// a declined request may be sent again
+		existing.Status = models.ConnectionStatusPending
+		existing.CreatedAt = time.Now()
+		existing.RespondedAt = nil
+	} else {
+		existing = &models.Connection{
+			RequesterID: requester.ID,
+			AddresseeID: target.ID,
+			Type:        models.ConnectionTypeMutual,
+			Status:      models.ConnectionStatusPending,
+		}
+	}
+
+	if err := s.repo.Save(existing); err != nil {
+		return nil, err
+	}
+
+	s.notify(target, "connection_request", requester)
+
+	return existing, nil
+}
+
+func (s *connectionService) RespondToRequest(addressee models.User, requestID uint, accept bool) (*models.Connection, error) {
+	c, err := s.repo.FindByID(requestID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrConnectionRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.AddresseeID != addressee.ID || c.Type != models.ConnectionTypeMutual || c.Status != models.ConnectionStatusPending {
+		return nil, ErrConnectionRequestNotFound
+	}
+
+	if !accept {
+		now := time.Now()
+		c.Status = models.ConnectionStatusDeclined
+		c.RespondedAt = &now
+		if err := s.repo.Save(c); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	requester, err := s.userSvc.GetByID(c.RequesterID)
+	if err != nil {
+		return nil, ErrConnectionRequestNotFound
+	}
+	return s.accept(c, addressee, requester)
+}
+
+// accept marks c as accepted by addressee and notifies the requester.
+func (s *connectionService) accept(c *models.Connection, addressee, requester models.User) (*models.Connection, error) {
+	now := time.Now()
+	c.Status = models.ConnectionStatusAccepted
+	c.RespondedAt = &now
+	if err := s.repo.Save(c); err != nil {
+		return nil, err
+	}
+
+	s.notify(requester, "connection_accepted", addressee)
+
+	return c, nil
+}
+
+func (s *connectionService) RemoveConnection(user models.User, otherID uuid.UUID) error {
+	c, err := s.repo.FindAcceptedMutual(user.ID, otherID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrConnectionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(c.ID)
+}
+
+func page(items []models.ConnectionUser, total int64, p interfaces.Pagination) *interfaces.ConnectionPage {
+	return &interfaces.ConnectionPage{Items: items, Page: p.Page, PageSize: p.PageSize, Total: total}
+}
+
+func (s *connectionService) ListFollowers(userID uuid.UUID, p interfaces.Pagination) (*interfaces.ConnectionPage, error) {
+	items, total, err := s.repo.ListFollowers(userID, p)
+	if err != nil {
+		return nil, err
+	}
+	return page(items, total, p), nil
+}
+
+func (s *connectionService) ListFollowing(userID uuid.UUID, p interfaces.Pagination) (*interfaces.ConnectionPage, error) {
+	items, total, err := s.repo.ListFollowing(userID, p)
+	if err != nil {
+		return nil, err
+	}
+	return page(items, total, p), nil
+}
+
+func (s *connectionService) ListConnections(userID uuid.UUID, p interfaces.Pagination) (*interfaces.ConnectionPage, error) {
+	items, total, err := s.repo.ListMutual(userID, p)
+	if err != nil {
+		return nil, err
+	}
+	return page(items, total, p), nil
+}
+
+func (s *connectionService) ListIncomingRequests(userID uuid.UUID, p interfaces.Pagination) (*interfaces.ConnectionPage, error) {
+	items, total, err := s.repo.ListIncomingRequests(userID, p)
+	if err != nil {
+		return nil, err
+	}
+	return page(items, total, p), nil
+}
+
+func (s *connectionService) Counts(userID uuid.UUID) (models.ConnectionCounts, error) {
+	return s.repo.Counts(userID)
+}
+
+func (s *connectionService) ConnectionIDs(userID uuid.UUID) (interfaces.UserConnectionIDs, error) {
+	return s.repo.IDs(userID)
+}
+
+// Relationship implements interfaces.RelationshipResolver on top of accepted mutual connections.
+func (s *connectionService) Relationship(viewerID, targetID uuid.UUID) (models.Relationship, error) {
+	if viewerID == uuid.Nil {
+		return models.RelationshipPublic, nil
+	}
+	if viewerID == targetID {
+		return models.RelationshipSelf, nil
+	}
+
+	_, err := s.repo.FindAcceptedMutual(viewerID, targetID)
+	if err == nil {
+		return models.RelationshipConnection, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.RelationshipPublic, nil
+	}
+	return "", err
+}
+
+// notify sends a connection event to recipient on the channels they enabled,
+// unless recipient muted actor, in the recipient's preferred language. Failures are logged; they never
+// fail the connection action itself.
+func (s *connectionService) notify(recipient models.User, eventType string, actor models.User) {
+	if s.notifier == nil {
+		return
+	}
+
+	if muted, err := s.blocks.HasMuted(recipient.ID, actor.ID); err != nil || muted {
+		return
+	}
+
+	settings, err := s.settingsSvc.GetByEmail(recipient.Email)
+	if err != nil {
+		log.Printf("[connections] loading notification settings for %s failed: %v\n", recipient.Email, err)
+		return
+	}
+
+	if !settings.ConnectionEmail && !settings.ConnectionPush {
+		return
+	}
+
+	l := i18n.Preferred(recipient.PreferredLanguage, i18n.Default())
+	err = s.notifier.Send(models.Notification{
+		Email:   recipient.Email,
+		Title:   i18n.T(l, "notification."+eventType+".title"),
+		Message: i18n.T(l, "notification."+eventType+".message", actor.FirstName, actor.LastName),
+		Type:    eventType,
+		Channels: map[string]any{
+			"email": settings.ConnectionEmail,
+			"push":  settings.ConnectionPush,
+		},
+		Data: map[string]any{
+			"actor_id":     actor.ID.String(),
+			"actor_handle": actor.Handle,
+		},
+	})
+	if err != nil {
+		log.Printf("[connections] %s notification failed: %v\n", eventType, err)
+	}
+}
@@ -1,13 +1,14 @@
 package service
 
 import (
-	"errors"
-
+	"group1-userservice/app/apperrors"
 	"group1-userservice/app/interfaces"
 	"group1-userservice/app/models"
 	"group1-userservice/app/repository"
 )
 
+var ErrDiscoveryRadius = apperrors.BadRequest("radius_out_of_range", "radius_km must be between 1 and 500")
+
 type discoveryPreferencesService struct {
 	repo *repository.DiscoveryPreferencesRepository
 }
@@ -30,13 +31,21 @@ func (s *discoveryPreferencesService) GetForEmail(email string) (*models.Discove
 }
 
 func (s *discoveryPreferencesService) UpdateForEmail(email string, input interfaces.DiscoveryPreferencesInput) (*models.DiscoveryPreferences, error) {
+	return s.UpdateForEmailIfVersion(email, input, 0)
+}
+
+// UpdateForEmailIfVersion updates while the stored preferences still have version; 0 updates unconditionally
+func (s *discoveryPreferencesService) UpdateForEmailIfVersion(email string, input interfaces.DiscoveryPreferencesInput, version int64) (*models.DiscoveryPreferences, error) {
 	if input.RadiusKm < 1 || input.RadiusKm > 500 {
-		return nil, errors.New("radius_km must be between 1 and 500")
+		return nil, ErrDiscoveryRadius
 	}
 
 	prefs := &models.DiscoveryPreferences{
 		Email:    email,
 		RadiusKm: input.RadiusKm,
 	}
-	return s.repo.Upsert(prefs)
+	if version == 0 {
+		return s.repo.Upsert(prefs)
+	}
+	return s.repo.UpdateIfVersion(prefs, version)
 }
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/metrics"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmailAlreadyVerified       = apperrors.Conflict("email_already_verified", "email address is already verified")
+	ErrVerificationThrottled      = apperrors.TooManyRequests("verification_throttled", "a verification email was sent too recently")
+	ErrVerificationTokenInvalid   = apperrors.Unauthorized("verification_token_invalid", "invalid verification token")
+	ErrVerificationTokenExpired   = apperrors.Unauthorized("verification_token_expired", "verification token has expired")
+	ErrVerificationSecretRequired = errors.New("EMAIL_VERIFICATION_SECRET is required")
+)
+
+// EmailVerificationConfig holds the signing secret, how long a link stays valid and the resend limits
+type EmailVerificationConfig struct {
+	Secret         []byte
+	TTL            time.Duration
+	ResendCooldown time.Duration
+	MaxSendsPerDay int
+}
+
+// EmailVerificationConfigFromEnv reads EMAIL_VERIFICATION_SECRET (required), EMAIL_VERIFICATION_TTL_HOURS (24),
+// EMAIL_VERIFICATION_RESEND_COOLDOWN_SECONDS (60) and EMAIL_VERIFICATION_MAX_SENDS_PER_DAY (5)
+func EmailVerificationConfigFromEnv() (EmailVerificationConfig, error) {
+	cfg := EmailVerificationConfig{
+		Secret:         []byte(os.Getenv("EMAIL_VERIFICATION_SECRET")),
+		TTL:            24 * time.Hour,
+		ResendCooldown: 60 * time.Second,
+		MaxSendsPerDay: 5,
+	}
+	if len(cfg.Secret) == 0 {
+		return cfg, ErrVerificationSecretRequired
+	}
+
+	for _, setting := range []struct {
+		key  string
+		unit time.Duration
+		dst  *time.Duration
+	}{
+		{"EMAIL_VERIFICATION_TTL_HOURS", time.Hour, &cfg.TTL},
+		{"EMAIL_VERIFICATION_RESEND_COOLDOWN_SECONDS", time.Second, &cfg.ResendCooldown},
+	} {
+		if raw := os.Getenv(setting.key); raw != "" {
+			v, err := strconv.Atoi(raw)
+			if err != nil || v < 0 {
+				return cfg, fmt.Errorf("%s must be a non-negative number", setting.key)
+			}
+			*setting.dst = time.Duration(v) * setting.unit
+		}
+	}
+
+	if raw := os.Getenv("EMAIL_VERIFICATION_MAX_SENDS_PER_DAY"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return cfg, errors.New("EMAIL_VERIFICATION_MAX_SENDS_PER_DAY must be at least 1")
+		}
+		cfg.MaxSendsPerDay = v
+	}
+
+	return cfg, nil
+}
+
+type emailVerificationService struct {
+	repo     interfaces.EmailVerificationRepository
+	users    interfaces.UserService
+	notifier interfaces.NotificationSender
+	accounts interfaces.EmailVerifiedMarker
+	cfg      EmailVerificationConfig
+}
+
+func NewEmailVerificationService(
+	repo interfaces.EmailVerificationRepository,
+	users interfaces.UserService,
+	notifier interfaces.NotificationSender,
+	accounts interfaces.EmailVerifiedMarker,
+	cfg EmailVerificationConfig,
+) interfaces.EmailVerificationService {
+	return &emailVerificationService{repo: repo, users: users, notifier: notifier, accounts: accounts, cfg: cfg}
+}
+
+// The token is base64url(user id | expiry) "." base64url(HMAC-SHA256 over the payload and the email address),
+// so it stops working once it expires or the email address of the account changes
+func (s *emailVerificationService) sign(payload []byte, email string) []byte {
+	mac := hmac.New(sha256.New, s.cfg.Secret)
+	mac.Write(payload)
+	mac.Write([]byte(strings.ToLower(email)))
+	return mac.Sum(nil)
+}
+
+func (s *emailVerificationService) token(user models.User, expires time.Time) string {
+	payload := make([]byte, 24)
+	copy(payload, user.ID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expires.Unix()))
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(s.sign(payload, user.Email))
+}
+
+// throttle returns the updated send history, or ErrVerificationThrottled when no email may be sent now
+func (s *emailVerificationService) throttle(userID uuid.UUID, now time.Time) (*models.EmailVerificationSend, error) {
+	send, err := s.repo.FindSend(userID)
+	if err != nil {
+		return nil, err
+	}
+	if send == nil {
+		send = &models.EmailVerificationSend{UserID: userID, WindowStartedAt: now}
+	}
+
+	if now.Sub(send.LastSentAt) < s.cfg.ResendCooldown {
+		return nil, ErrVerificationThrottled
+	}
+	if now.Sub(send.WindowStartedAt) >= 24*time.Hour {
+		send.WindowStartedAt = now
+		send.SendCount = 0
+	}
+	if send.SendCount >= s.cfg.MaxSendsPerDay {
+		return nil, ErrVerificationThrottled
+	}
+
+	send.LastSentAt = now
+	send.SendCount++
+	return send, nil
+}
+
+func (s *emailVerificationService) Send(user models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	now := time.Now()
+	send, err := s.throttle(user.ID, now)
+	if err != nil {
+		if errors.Is(err, ErrVerificationThrottled) {
+			metrics.EmailVerificationsTotal.WithLabelValues("throttled").Inc()
+		}
+		return err
+	}
+
+	l := i18n.Preferred(user.PreferredLanguage, i18n.Default())
+	if err := s.notifier.Send(models.Notification{
+		Email:   user.Email,
+		Title:   i18n.T(l, "notification.email_verification.title"),
+		Message: i18n.T(l, "notification.email_verification.message"),
+		Type:    "system_alert",
+		Token:   s.token(user, now.Add(s.cfg.TTL)),
+	}); err != nil {
+		return err
+	}
+
+	metrics.EmailVerificationsTotal.WithLabelValues("sent").Inc()
+	return s.repo.SaveSend(send)
+}
+
+func (s *emailVerificationService) Resend(email string) error {
+	user, err := s.users.GetByEmail(email)
+	if err != nil || user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.Send(user)
+}
+
+func (s *emailVerificationService) Verify(token string) (models.User, error) {
+	user, err := s.verify(token)
+	if err != nil {
+		metrics.EmailVerificationsTotal.WithLabelValues("invalid").Inc()
+		return models.User{}, err
+	}
+
+	// following the link twice is fine
+	if user.EmailVerifiedAt != nil {
+		return user, nil
+	}
+
+	if err := s.accounts.MarkEmailVerified(user.KeycloakID); err != nil {
+		return models.User{}, err
+	}
+
+	now := time.Now()
+	if err := s.repo.MarkVerified(user.ID, now); err != nil {
+		return models.User{}, err
+	}
+	user.EmailVerifiedAt = &now
+
+	metrics.EmailVerificationsTotal.WithLabelValues("verified").Inc()
+	return user, nil
+}
+
+// verify decodes the token and returns the user it was issued for
+func (s *emailVerificationService) verify(token string) (models.User, error) {
+	encPayload, encSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return models.User{}, ErrVerificationTokenInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil || len(payload) != 24 {
+		return models.User{}, ErrVerificationTokenInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encSig)
+	if err != nil {
+		return models.User{}, ErrVerificationTokenInvalid
+	}
+
+	userID, err := uuid.FromBytes(payload[:16])
+	if err != nil {
+		return models.User{}, ErrVerificationTokenInvalid
+	}
+	user, err := s.users.GetByID(userID)
+	if err != nil || !hmac.Equal(sig, s.sign(payload, user.Email)) {
+		return models.User{}, ErrVerificationTokenInvalid
+	}
+
+	expires := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if time.Now().After(expires) {
+		return models.User{}, ErrVerificationTokenExpired
+	}
+
+	return user, nil
+}
+
+func (s *emailVerificationService) IsEmailVerified(keycloakID string) (bool, error) {
+	user, err := s.users.GetByKeycloakID(keycloakID)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerifiedAt != nil, nil
+}
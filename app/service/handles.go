@@ -0,0 +1,133 @@
+package service
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"group1-userservice/app/apperrors"
+)
+
+const (
+	handleMinLength = 3
+	handleMaxLength = 30
+)
+
+var (
+	ErrHandleInvalid  = apperrors.BadRequest("handle_invalid", "invalid handle")
+	ErrHandleReserved = apperrors.BadRequest("handle_reserved", "handle is not allowed")
+	ErrHandleTaken    = apperrors.Conflict("handle_taken", "handle already taken")
+	ErrHandleCooldown = apperrors.TooManyRequests("handle_cooldown", "handle was changed too recently")
+)
+
+// Lowercase letters, digits, dots and underscores; must start and end with a letter or digit
+var handlePattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9._]*[a-z0-9])?$`)
+
+// Handles that collide with routes, roles or that could be used to impersonate the platform
+var reservedHandles = map[string]bool{
+	"admin": true, "administrator": true, "api": true, "auth": true, "badges": true,
+	"help": true, "id": true, "internal": true, "keycloak": true, "me": true,
+	"metrics": true, "moderator": true, "null": true, "official": true, "register": true,
+	"root": true, "security": true, "settings": true, "staff": true, "support": true,
+	"swagger": true, "system": true, "undefined": true, "user": true, "users": true,
+}
+
+// Words that may not be a part of a handle (English and Dutch). They are matched against whole parts
+// between dots and underscores, so names such as "lulu", "kutcher" and "scunthorpe" stay allowed.
+var blockedHandleWords = map[string]bool{
+	"fuck": true, "shit": true, "bitch": true, "cunt": true, "nigger": true, "nigga": true,
+	"whore": true, "slut": true, "porn": true,
+	"kanker": true, "kankerlijer": true, "tering": true, "teringlijer": true, "tyfus": true,
+	"hoer": true, "kut": true, "lul": true, "klootzak": true, "mongool": true,
+}
+
+var diacritics = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ò", "o", "ó", "o", "ô", "o", "ö", "o", "õ", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u",
+	"ý", "y", "ÿ", "y", "ç", "c", "ñ", "n", "ß", "ss",
+)
+
+// NormalizeHandle trims whitespace and a leading "@" and lowercases the handle
+func NormalizeHandle(raw string) string {
+	h := strings.TrimSpace(raw)
+	h = strings.TrimPrefix(h, "@")
+	return strings.ToLower(h)
+}
+
+// ValidateHandle checks the format of a normalized handle and the reserved/blocked word lists
+func ValidateHandle(h string) error {
+	if len(h) < handleMinLength || len(h) > handleMaxLength {
+		return ErrHandleInvalid.Because("handle_invalid.length", handleMinLength, handleMaxLength)
+	}
+	if !handlePattern.MatchString(h) {
+		return ErrHandleInvalid.Because("handle_invalid.characters")
+	}
+	if strings.Contains(h, "..") || strings.Contains(h, "__") {
+		return ErrHandleInvalid.Because("handle_invalid.repeated")
+	}
+	if reservedHandles[h] {
+		return ErrHandleReserved
+	}
+	for _, part := range strings.FieldsFunc(h, func(r rune) bool { return r == '.' || r == '_' }) {
+		// "kut123" is still the word with a number after it
+		if blockedHandleWords[strings.TrimRight(part, "0123456789")] {
+			return ErrHandleReserved
+		}
+	}
+	return nil
+}
+
+// handleBaseFromName turns "Jöhn", "van Doe" into "john.vandoe"
+func handleBaseFromName(first, last string) string {
+	clean := func(s string) string {
+		s = diacritics.Replace(strings.ToLower(strings.TrimSpace(s)))
+		var b strings.Builder
+		for _, r := range s {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	}
+
+	parts := []string{}
+	if f := clean(first); f != "" {
+		parts = append(parts, f)
+	}
+	if l := clean(last); l != "" {
+		parts = append(parts, l)
+	}
+
+	base := strings.Join(parts, ".")
+	if len(base) < handleMinLength {
+		base = "user" + base
+	}
+	// leave room for a numeric collision suffix
+	if len(base) > handleMaxLength-4 {
+		base = strings.TrimRight(base[:handleMaxLength-4], ".")
+	}
+	return base
+}
+
+// handleChangeCooldown is the minimum time between two handle changes (HANDLE_CHANGE_COOLDOWN_DAYS, default 30)
+func handleChangeCooldown() time.Duration {
+	return envDays("HANDLE_CHANGE_COOLDOWN_DAYS", 30)
+}
+
+// handleReservationPeriod is how long an old handle stays reserved (HANDLE_RESERVATION_DAYS, default 90)
+func handleReservationPeriod() time.Duration {
+	return envDays("HANDLE_RESERVATION_DAYS", 90)
+}
+
+func envDays(key string, fallback int) time.Duration {
+	days := fallback
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v >= 0 {
+		days = v
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
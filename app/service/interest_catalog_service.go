@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+	"group1-userservice/app/validation"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInterestNotFound  = apperrors.NotFound("interest_not_found", "interest not found")
+	ErrInterestExists    = apperrors.Conflict("interest_exists", "interest already exists")
+	ErrInterestArchived  = apperrors.Conflict("interest_archived", "interest is archived")
+	ErrInterestMergeSelf = apperrors.BadRequest("interest_merge_self", "cannot merge an interest into itself")
+)
+
+type interestCatalogService struct {
+	repo *repository.UserInterestsRepository
+}
+
+func NewInterestCatalogService(repo *repository.UserInterestsRepository) interfaces.InterestCatalogService {
+	return &interestCatalogService{repo: repo}
+}
+
+// translationsOf turns the translations of a request into rows, sorted by locale
+func translationsOf(id uint, input map[string]interfaces.InterestLabelInput) []models.InterestTranslation {
+	out := make([]models.InterestTranslation, 0, len(input))
+	for locale, t := range input {
+		out = append(out, models.InterestTranslation{InterestID: id, Locale: locale, Label: t.Label, Description: t.Description})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Locale < out[j].Locale })
+	return out
+}
+
+func (s *interestCatalogService) find(id uint) (*models.Interest, error) {
+	interest, err := s.repo.FindInterest(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInterestNotFound
+	}
+	return interest, err
+}
+
+func (s *interestCatalogService) List() ([]models.Interest, error) {
+	return s.repo.ListAllInterests()
+}
+
+// Create adds an interest after the existing ones; it needs a label in every supported language
+func (s *interestCatalogService) Create(input interfaces.InterestInput) (*models.Interest, error) {
+	interest := models.Interest{Key: input.Key, Translations: translationsOf(0, input.Translations)}
+	if err := validation.Interest(&interest, true); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.FindInterestByKey(interest.Key); err == nil {
+		return nil, ErrInterestExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.repo.CreateInterest(&interest); err != nil {
+		return nil, err
+	}
+	return s.find(interest.ID)
+}
+
+// Update changes the labels and descriptions of the languages sent; the key stays the same because
+// clients refer to it
+func (s *interestCatalogService) Update(id uint, input interfaces.InterestInput) (*models.Interest, error) {
+	current, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := models.Interest{ID: id, Key: current.Key, Translations: translationsOf(id, input.Translations)}
+	if err := validation.Interest(&changed, false); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveTranslations(changed.Translations); err != nil {
+		return nil, err
+	}
+	return s.find(id)
+}
+
+// Reorder puts the interests of ids first, in that order; the others keep their order after them
+func (s *interestCatalogService) Reorder(ids []uint) ([]models.Interest, error) {
+	all, err := s.repo.ListAllInterests()
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[uint]bool{}
+	for _, i := range all {
+		known[i.ID] = true
+	}
+
+	order := make([]uint, 0, len(all))
+	listed := map[uint]bool{}
+	for _, id := range ids {
+		if !known[id] {
+			return nil, ErrInterestNotFound.Detailf("id %d", id)
+		}
+		if !listed[id] {
+			listed[id] = true
+			order = append(order, id)
+		}
+	}
+	for _, i := range all {
+		if !listed[i.ID] {
+			order = append(order, i.ID)
+		}
+	}
+
+	if err := s.repo.SetPositions(order); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAllInterests()
+}
+
+// Archive stops offering an interest to users; their selections are kept. Archiving twice is a no-op.
+func (s *interestCatalogService) Archive(id uint) (*models.Interest, error) {
+	if _, err := s.find(id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ArchiveInterest(id, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.find(id)
+}
+
+// Merge moves the selections of from to into and archives from. into must not be archived.
+func (s *interestCatalogService) Merge(from, into uint) (*interfaces.InterestMergeResult, error) {
+	if from == into {
+		return nil, ErrInterestMergeSelf
+	}
+	if _, err := s.find(from); err != nil {
+		return nil, err
+	}
+	target, err := s.find(into)
+	if err != nil {
+		return nil, err
+	}
+	if target.ArchivedAt != nil {
+		return nil, ErrInterestArchived.Detailf("id %d", into)
+	}
+
+	moved, err := s.repo.MergeInterests(from, into, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.find(from)
+	if err != nil {
+		return nil, err
+	}
+	return &interfaces.InterestMergeResult{From: *source, Into: *target, Moved: moved}, nil
+}
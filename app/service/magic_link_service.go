@@ -0,0 +1,75 @@
+package service
+
+import (
+	"time"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+)
+
+var (
+	ErrMagicLinkInvalid = apperrors.Unauthorized("magic_link_invalid", "invalid or expired login link")
+	ErrAccountBlocked   = apperrors.Forbidden("account_blocked", "account is blocked")
+)
+
+// magicLinkTTL is short: the link logs in without a password
+const magicLinkTTL = 15 * time.Minute
+
+type magicLinkService struct {
+	repo    interfaces.MagicLinkRepository
+	userSvc interfaces.UserService
+}
+
+func NewMagicLinkService(r interfaces.MagicLinkRepository, userSvc interfaces.UserService) interfaces.MagicLinkService {
+	return &magicLinkService{repo: r, userSvc: userSvc}
+}
+
+func (s *magicLinkService) RequestLink(email string) (string, error) {
+	user, err := s.userSvc.GetByEmail(email)
+	if err != nil || user.IsBlocked {
+		return "", nil
+	}
+
+	rawToken, err := generateToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.Create(user.Email, hashToken(rawToken), time.Now().Add(magicLinkTTL)); err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+func (s *magicLinkService) Redeem(rawToken string) (models.User, error) {
+	if rawToken == "" {
+		return models.User{}, ErrMagicLinkInvalid
+	}
+
+	row, err := s.repo.FindValidByTokenHash(hashToken(rawToken))
+	if err != nil {
+		return models.User{}, ErrMagicLinkInvalid
+	}
+
+	// mark used before anything else, so two concurrent redeems cannot both log in
+	ok, err := s.repo.MarkUsed(row.ID)
+	if err != nil {
+		return models.User{}, err
+	}
+	if !ok {
+		return models.User{}, ErrMagicLinkInvalid
+	}
+
+	user, err := s.userSvc.GetByEmail(row.Email)
+	if err != nil {
+		return models.User{}, ErrMagicLinkInvalid
+	}
+	// the user is returned with the error so the failed login can be audited
+	if user.IsBlocked {
+		return user, ErrAccountBlocked
+	}
+
+	return user, nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportSelf      = apperrors.BadRequest("report_self", "cannot report yourself")
+	ErrReportReason    = apperrors.BadRequest("report_reason_invalid", "reason must be one of fake_profile, offensive_bio, inappropriate_photo, spam, harassment, other")
+	ErrReportDetails   = apperrors.BadRequest("report_details_too_long", "details must be at most 1000 characters")
+	ErrReportDuplicate = apperrors.Conflict("report_duplicate", "you already have an open report for this user")
+	ErrReportNotFound  = apperrors.NotFound("report_not_found", "report not found")
+	ErrReportClosed    = apperrors.Conflict("report_closed", "report is already closed")
+	ErrReportClaimed   = apperrors.Conflict("report_claimed", "report is claimed by another moderator")
+	ErrReportAction    = apperrors.BadRequest("report_action_invalid", "actions must be hide_bio, remove_photo or block_account")
+)
+
+const maxReportDetailsLength = 1000
+
+type moderationService struct {
+	repo     interfaces.UserReportRepository
+	userSvc  interfaces.UserService
+	storage  interfaces.ObjectStorage
+	accounts interfaces.AccountDisabler
+}
+
+func NewModerationService(
+	repo interfaces.UserReportRepository,
+	userSvc interfaces.UserService,
+	storage interfaces.ObjectStorage,
+	accounts interfaces.AccountDisabler,
+) interfaces.ModerationService {
+	return &moderationService{
+		repo:     repo,
+		userSvc:  userSvc,
+		storage:  storage,
+		accounts: accounts,
+	}
+}
+
+func (s *moderationService) Report(reporter models.User, reportedID uuid.UUID, input interfaces.ReportInput) (*models.UserReport, error) {
+	if reporter.ID == reportedID {
+		return nil, ErrReportSelf
+	}
+	if !input.Reason.Valid() {
+		return nil, ErrReportReason
+	}
+
+	details := strings.TrimSpace(input.Details)
+	if len([]rune(details)) > maxReportDetailsLength {
+		return nil, ErrReportDetails
+	}
+
+	if _, err := s.userSvc.GetByID(reportedID); err != nil {
+		return nil, ErrProfileNotFound
+	}
+
+	open, err := s.repo.HasOpenReport(reporter.ID, reportedID)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, ErrReportDuplicate
+	}
+
+	report := &models.UserReport{
+		ReporterID: reporter.ID,
+		ReportedID: reportedID,
+		Reason:     input.Reason,
+		Details:    details,
+		Status:     models.ReportStatusOpen,
+	}
+	if err := s.repo.Create(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *moderationService) List(status models.ReportStatus, p interfaces.Pagination) (*interfaces.ReportPage, error) {
+	items, total, err := s.repo.List(status, p)
+	if err != nil {
+		return nil, err
+	}
+	return &interfaces.ReportPage{Items: items, Page: p.Page, PageSize: p.PageSize, Total: total}, nil
+}
+
+func (s *moderationService) find(id uint) (*models.UserReport, error) {
+	report, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	return report, err
+}
+
+func (s *moderationService) Get(id uint) (*interfaces.ReportDetail, error) {
+	report, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := s.repo.Actions(id)
+	if err != nil {
+		return nil, err
+	}
+	return &interfaces.ReportDetail{Report: *report, Actions: actions}, nil
+}
+
+func closed(r *models.UserReport) bool {
+	return r.Status == models.ReportStatusResolved || r.Status == models.ReportStatusDismissed
+}
+
+func (s *moderationService) Claim(id uint, moderatorID string) (*models.UserReport, error) {
+	claimed, err := s.repo.Claim(id, moderatorID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !claimed {
+		if closed(report) {
+			return nil, ErrReportClosed
+		}
+		return nil, ErrReportClaimed
+	}
+
+	err = s.repo.AddAction(&models.ModerationAction{
+		ReportID:     report.ID,
+		ModeratorID:  moderatorID,
+		Action:       models.ModerationActionClaim,
+		TargetUserID: report.ReportedID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Resolve applies the requested actions to the reported user and closes the report.
+// Keycloak and S3 are changed before the database, so a failure there leaves the report open to retry.
+func (s *moderationService) Resolve(id uint, moderatorID string, input interfaces.ResolveReportInput) (*interfaces.ReportDetail, error) {
+	report, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if closed(report) {
+		return nil, ErrReportClosed
+	}
+	if report.Status == models.ReportStatusInReview && report.ClaimedBy != moderatorID {
+		return nil, ErrReportClaimed
+	}
+
+	requested := map[models.ModerationActionType]bool{}
+	for _, a := range input.Actions {
+		switch a {
+		case models.ModerationActionHideBio, models.ModerationActionRemovePhoto, models.ModerationActionBlockAccount:
+			requested[a] = true
+		default:
+			return nil, ErrReportAction
+		}
+	}
+
+	target, err := s.userSvc.GetByID(report.ReportedID)
+	if err != nil {
+		return nil, ErrProfileNotFound
+	}
+
+	note := strings.TrimSpace(input.Note)
+	userUpdates := map[string]interface{}{}
+	var actions []models.ModerationAction
+
+	record := func(a models.ModerationActionType) {
+		actions = append(actions, models.ModerationAction{
+			ReportID:     report.ID,
+			ModeratorID:  moderatorID,
+			Action:       a,
+			TargetUserID: target.ID,
+			Note:         note,
+		})
+	}
+
+	if requested[models.ModerationActionBlockAccount] {
+		if err := s.accounts.DisableAccount(target.KeycloakID); err != nil {
+			return nil, fmt.Errorf("disable account: %w", err)
+		}
+		userUpdates["is_blocked"] = true
+		record(models.ModerationActionBlockAccount)
+	}
+
+	if requested[models.ModerationActionRemovePhoto] {
+		if target.ProfilePhotoURL != "" {
+			if key, ok := s.storage.KeyFromPublicURL(target.ProfilePhotoURL); ok {
+				if err := s.storage.RemoveObject(key); err != nil {
+					return nil, fmt.Errorf("remove profile photo: %w", err)
+				}
+			}
+		}
+		userUpdates["profile_photo_url"] = ""
+		record(models.ModerationActionRemovePhoto)
+	}
+
+	if requested[models.ModerationActionHideBio] {
+		userUpdates["biography_hidden"] = true
+		record(models.ModerationActionHideBio)
+	}
+
+	now := time.Now()
+	report.Status = models.ReportStatusResolved
+	if len(actions) == 0 {
+		report.Status = models.ReportStatusDismissed
+		record(models.ModerationActionDismiss)
+	}
+	if report.ClaimedBy == "" {
+		report.ClaimedBy = moderatorID
+		report.ClaimedAt = &now
+	}
+	report.ResolvedBy = moderatorID
+	report.ResolvedAt = &now
+	report.Resolution = note
+
+	resolved, err := s.repo.Resolve(report, actions, userUpdates)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return nil, ErrReportClosed
+	}
+
+	return s.Get(report.ID)
+}
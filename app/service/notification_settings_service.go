@@ -31,6 +31,9 @@ func (s *notificationSettingsService) GetByEmail(email string) (*models.Notifica
 		ChatPush:      true,
 		SystemEmail:   true,
 		SystemPush:    false,
+
+		ConnectionEmail: true,
+		ConnectionPush:  true,
 	}
 
 	return s.repo.Upsert(defaults)
@@ -46,8 +49,11 @@ func (s *notificationSettingsService) UpdateForEmail(email string, input interfa
 		ChatEmail:     input.ChatEmail,
 		ChatPush:      input.ChatPush,
 		SystemEmail:   input.SystemEmail,
-		SystemPush:    input.SystemPush,
-		ExpoPushToken: input.ExpoPushToken,
+
+		ConnectionEmail: input.ConnectionEmail,
+		ConnectionPush:  input.ConnectionPush,
+		SystemPush:      input.SystemPush,
+		ExpoPushToken:   input.ExpoPushToken,
 	}
 
 	return s.repo.Upsert(settings)
@@ -56,3 +62,11 @@ func (s *notificationSettingsService) UpdateForEmail(email string, input interfa
 func (s *notificationSettingsService) Upsert(settings *models.NotificationSettings) (*models.NotificationSettings, error) {
 	return s.repo.Upsert(settings)
 }
+
+// UpdateIfVersion saves settings while the stored row still has version; 0 saves unconditionally
+func (s *notificationSettingsService) UpdateIfVersion(settings *models.NotificationSettings, version int64) (*models.NotificationSettings, error) {
+	if version == 0 {
+		return s.repo.Upsert(settings)
+	}
+	return s.repo.UpdateIfVersion(settings, version)
+}
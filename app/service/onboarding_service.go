@@ -0,0 +1,336 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/metrics"
+	"group1-userservice/app/models"
+)
+
+var (
+	ErrOnboardingUnknownStep      = apperrors.NotFound("onboarding_step_unknown", "unknown onboarding step")
+	ErrOnboardingStepNotCurrent   = apperrors.Conflict("onboarding_step_not_current", "finish the current onboarding step first")
+	ErrOnboardingStepNotSkippable = apperrors.Conflict("onboarding_step_not_skippable", "this onboarding step cannot be skipped")
+	ErrOnboardingSystemStep       = apperrors.Conflict("onboarding_step_automatic", "this onboarding step is completed automatically")
+	ErrOnboardingStepIncomplete   = apperrors.Conflict("onboarding_step_incomplete", "onboarding step is not done yet")
+)
+
+// onboardingRule says how a step can be finished. requirement returns ErrOnboardingStepIncomplete with
+// what is still missing, or nil.
+type onboardingRule struct {
+	skippable   bool
+	system      bool // completed by the service as soon as the requirement is met
+	requirement func(s *onboardingService, u models.User, input interfaces.OnboardingStepInput) (*apperrors.Error, error)
+}
+
+// stepIncomplete says what a step still needs, as a text from the i18n catalog
+func stepIncomplete(key string, args ...any) *apperrors.Error {
+	return ErrOnboardingStepIncomplete.Because(key, args...)
+}
+
+var onboardingRules = map[models.OnboardingStep]onboardingRule{
+	models.OnboardingVerifyEmail: {
+		system: true,
+		requirement: func(s *onboardingService, u models.User, _ interfaces.OnboardingStepInput) (*apperrors.Error, error) {
+			if u.EmailVerifiedAt == nil {
+				return stepIncomplete("onboarding_step_incomplete.verify_email"), nil
+			}
+			return nil, nil
+		},
+	},
+	models.OnboardingBasicProfile: {
+		requirement: func(s *onboardingService, u models.User, _ interfaces.OnboardingStepInput) (*apperrors.Error, error) {
+			missing := []string{}
+			for _, f := range []struct{ name, value string }{
+				{"first_name", u.FirstName},
+				{"last_name", u.LastName},
+				{"country", u.Country},
+			} {
+				if strings.TrimSpace(f.value) == "" {
+					missing = append(missing, f.name)
+				}
+			}
+			if len(missing) > 0 {
+				return stepIncomplete("onboarding_step_incomplete.fields", strings.Join(missing, ", ")), nil
+			}
+			return nil, nil
+		},
+	},
+	models.OnboardingPhoto: {
+		skippable: true,
+		requirement: func(s *onboardingService, u models.User, _ interfaces.OnboardingStepInput) (*apperrors.Error, error) {
+			if u.ProfilePhotoURL == "" {
+				return stepIncomplete("onboarding_step_incomplete.photo"), nil
+			}
+			return nil, nil
+		},
+	},
+	models.OnboardingInterests: {
+		skippable: true,
+		requirement: func(s *onboardingService, u models.User, _ interfaces.OnboardingStepInput) (*apperrors.Error, error) {
+			n, err := s.profile.CountSelectedInterests(u.Email)
+			if err != nil || n > 0 {
+				return nil, err
+			}
+			return stepIncomplete("onboarding_step_incomplete.interests"), nil
+		},
+	},
+	models.OnboardingDiscoveryRadius: {
+		skippable: true,
+		requirement: func(s *onboardingService, u models.User, _ interfaces.OnboardingStepInput) (*apperrors.Error, error) {
+			ok, err := s.profile.HasDiscoveryPreferences(u.Email)
+			if err != nil || ok {
+				return nil, err
+			}
+			return stepIncomplete("onboarding_step_incomplete.discovery_radius"), nil
+		},
+	},
+	models.OnboardingNotificationConsent: {
+		skippable: true,
+		requirement: func(s *onboardingService, u models.User, input interfaces.OnboardingStepInput) (*apperrors.Error, error) {
+			if input.Granted == nil {
+				return stepIncomplete("onboarding_step_incomplete.notification_consent"), nil
+			}
+			return nil, nil
+		},
+	},
+}
+
+type onboardingService struct {
+	repo          interfaces.OnboardingRepository
+	profile       interfaces.ProfileCompletenessRepository
+	notifications interfaces.NotificationSettingsService
+}
+
+func NewOnboardingService(
+	repo interfaces.OnboardingRepository,
+	profile interfaces.ProfileCompletenessRepository,
+	notifications interfaces.NotificationSettingsService,
+) interfaces.OnboardingService {
+	return &onboardingService{repo: repo, profile: profile, notifications: notifications}
+}
+
+// load returns the state of the user, starting onboarding on first use, with system steps brought up to date
+func (s *onboardingService) load(user models.User) (*models.OnboardingState, error) {
+	now := time.Now()
+	fresh := &models.OnboardingState{UserID: user.ID, StartedAt: now}
+	for i, step := range models.OnboardingSteps {
+		fresh.Steps = append(fresh.Steps, models.OnboardingStepState{
+			UserID:   user.ID,
+			Step:     step,
+			Position: i + 1,
+			Status:   models.OnboardingStepPending,
+		})
+	}
+
+	state, created, err := s.repo.FindOrCreate(user.ID, fresh)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		metrics.OnboardingStartedTotal.Inc()
+	}
+
+	changed, err := s.advance(state, user)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if err := s.repo.Update(state); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// advance completes system steps that are current and met
+func (s *onboardingService) advance(state *models.OnboardingState, user models.User) (bool, error) {
+	changed := false
+	for {
+		current := currentOnboardingStep(state)
+		if current == nil {
+			return changed, nil
+		}
+		rule := onboardingRules[current.Step]
+		if !rule.system {
+			return changed, nil
+		}
+
+		missing, err := rule.requirement(s, user, interfaces.OnboardingStepInput{})
+		if err != nil || missing != nil {
+			return changed, err
+		}
+		finishOnboardingStep(state, current, models.OnboardingStepCompleted)
+		changed = true
+	}
+}
+
+func currentOnboardingStep(state *models.OnboardingState) *models.OnboardingStepState {
+	for i := range state.Steps {
+		if state.Steps[i].Status == models.OnboardingStepPending {
+			return &state.Steps[i]
+		}
+	}
+	return nil
+}
+
+// finishOnboardingStep marks the step and records the funnel metrics; finishing the last step ends onboarding
+func finishOnboardingStep(state *models.OnboardingState, step *models.OnboardingStepState, status models.OnboardingStepStatus) {
+	now := time.Now()
+
+	// the step became current when the step before it was finished
+	since := state.StartedAt
+	for _, s := range state.Steps {
+		if s.Position < step.Position && s.FinishedAt != nil && s.FinishedAt.After(since) {
+			since = *s.FinishedAt
+		}
+	}
+
+	step.Status = status
+	step.FinishedAt = &now
+	metrics.OnboardingStepsTotal.WithLabelValues(string(step.Step), string(status)).Inc()
+	metrics.OnboardingStepDurationSeconds.WithLabelValues(string(step.Step)).Observe(now.Sub(since).Seconds())
+
+	if currentOnboardingStep(state) == nil {
+		state.CompletedAt = &now
+		metrics.OnboardingCompletedTotal.Inc()
+	}
+}
+
+// decorateOnboarding fills in the fields that are not stored
+func decorateOnboarding(state *models.OnboardingState) *models.OnboardingState {
+	state.CurrentStep = ""
+	if current := currentOnboardingStep(state); current != nil {
+		state.CurrentStep = current.Step
+	}
+	for i := range state.Steps {
+		state.Steps[i].Skippable = onboardingRules[state.Steps[i].Step].skippable
+	}
+	return state
+}
+
+func (s *onboardingService) Get(user models.User) (*models.OnboardingState, error) {
+	state, err := s.load(user)
+	if err != nil {
+		return nil, err
+	}
+	return decorateOnboarding(state), nil
+}
+
+// step loads the state and checks that step is the one to finish now. A step that already has the
+// requested status returns done, so retries are harmless.
+func (s *onboardingService) step(user models.User, step models.OnboardingStep, status models.OnboardingStepStatus) (*models.OnboardingState, *models.OnboardingStepState, bool, error) {
+	rule, ok := onboardingRules[step]
+	if !ok {
+		return nil, nil, false, ErrOnboardingUnknownStep
+	}
+	if rule.system {
+		return nil, nil, false, ErrOnboardingSystemStep
+	}
+
+	state, err := s.load(user)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	current := currentOnboardingStep(state)
+	for i := range state.Steps {
+		st := &state.Steps[i]
+		if st.Step != step {
+			continue
+		}
+		if st.Status == status {
+			return state, st, true, nil
+		}
+		if current == nil || current.Step != step {
+			return nil, nil, false, ErrOnboardingStepNotCurrent
+		}
+		return state, st, false, nil
+	}
+	return nil, nil, false, ErrOnboardingUnknownStep
+}
+
+// Complete finishes the current step once its requirement is met
+func (s *onboardingService) Complete(user models.User, step models.OnboardingStep, input interfaces.OnboardingStepInput) (*models.OnboardingState, error) {
+	state, st, done, err := s.step(user, step, models.OnboardingStepCompleted)
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		return decorateOnboarding(state), nil
+	}
+
+	missing, err := onboardingRules[step].requirement(s, user, input)
+	if err != nil {
+		return nil, err
+	}
+	if missing != nil {
+		return nil, missing
+	}
+
+	if step == models.OnboardingNotificationConsent {
+		if err := s.applyNotificationConsent(user, *input.Granted); err != nil {
+			return nil, err
+		}
+		state.NotificationConsent = input.Granted
+	}
+	finishOnboardingStep(state, st, models.OnboardingStepCompleted)
+	if _, err := s.advance(state, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(state); err != nil {
+		return nil, err
+	}
+	return decorateOnboarding(state), nil
+}
+
+// applyNotificationConsent turns off every optional notification when the user declines them.
+// Consent keeps the settings as they are; those already default to on. System emails are always sent.
+func (s *onboardingService) applyNotificationConsent(user models.User, granted bool) error {
+	if granted {
+		return nil
+	}
+
+	settings, err := s.notifications.GetByEmail(user.Email)
+	if err != nil {
+		return err
+	}
+
+	settings.LikeEmail, settings.LikePush = false, false
+	settings.FavoriteEmail, settings.FavoritePush = false, false
+	settings.ChatEmail, settings.ChatPush = false, false
+	settings.ConnectionEmail, settings.ConnectionPush = false, false
+	settings.SystemPush = false
+
+	_, err = s.notifications.Upsert(settings)
+	return err
+}
+
+// Skip passes over the current step when it is optional
+func (s *onboardingService) Skip(user models.User, step models.OnboardingStep) (*models.OnboardingState, error) {
+	if rule, ok := onboardingRules[step]; ok && !rule.system && !rule.skippable {
+		return nil, ErrOnboardingStepNotSkippable
+	}
+
+	state, st, done, err := s.step(user, step, models.OnboardingStepSkipped)
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		return decorateOnboarding(state), nil
+	}
+
+	finishOnboardingStep(state, st, models.OnboardingStepSkipped)
+	if _, err := s.advance(state, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(state); err != nil {
+		return nil, err
+	}
+	return decorateOnboarding(state), nil
+}
@@ -5,20 +5,30 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
-	"errors"
 	"time"
 
+	"group1-userservice/app/apperrors"
 	"group1-userservice/app/interfaces"
-	"group1-userservice/app/keycloak"
+	"group1-userservice/app/validation"
+)
+
+var (
+	ErrResetTokenRequired = apperrors.BadRequest("reset_token_required", "token is required")
+	ErrResetTokenInvalid  = apperrors.Unauthorized("reset_token_invalid", "invalid or expired token")
 )
 
 type passwordResetService struct {
 	resetRepo interfaces.PasswordResetRepository
 	userSvc   interfaces.UserService
+	idp       interfaces.IdentityProvider
 }
 
-func NewPasswordResetService(r interfaces.PasswordResetRepository, userSvc interfaces.UserService) interfaces.PasswordResetService {
-	return &passwordResetService{resetRepo: r, userSvc: userSvc}
+func NewPasswordResetService(
+	r interfaces.PasswordResetRepository,
+	userSvc interfaces.UserService,
+	idp interfaces.IdentityProvider,
+) interfaces.PasswordResetService {
+	return &passwordResetService{resetRepo: r, userSvc: userSvc, idp: idp}
 }
 
 func (s *passwordResetService) RequestReset(email string) (string, error) {
@@ -39,33 +49,43 @@ func (s *passwordResetService) RequestReset(email string) (string, error) {
 	return rawToken, nil
 }
 
-func (s *passwordResetService) ResetPassword(rawToken string, newPassword string) error {
+// ResetPassword sets the new password and returns the email of the account it belongs to
+func (s *passwordResetService) ResetPassword(rawToken string, newPassword string) (string, error) {
 	if rawToken == "" {
-		return errors.New("token is required")
+		return "", ErrResetTokenRequired
 	}
 
 	tokenHash := hashToken(rawToken)
 	row, err := s.resetRepo.FindValidByTokenHash(tokenHash)
 	if err != nil {
-		return errors.New("invalid or expired token")
+		return "", ErrResetTokenInvalid
 	}
 
-	// Keycloak reset
-	if err := keycloak.ResetPasswordByEmail(row.Email, newPassword); err != nil {
-		return err
+	if err := validation.Password(newPassword); err != nil {
+		return "", err
 	}
 
-	// Postgres update (bcrypt)
-	if err := s.userSvc.UpdatePasswordByEmail(row.Email, newPassword); err != nil {
-		return err
+	user, err := s.userSvc.GetByEmail(row.Email)
+	if err != nil {
+		return "", ErrResetTokenInvalid
+	}
+
+	// The identity provider is the only credential store
+	if err := s.idp.SetPassword(user.KeycloakID, newPassword); err != nil {
+		return "", err
+	}
+
+	// A legacy local hash would be stale now
+	if err := s.userSvc.RetireLegacyPassword(user, ""); err != nil {
+		return "", err
 	}
 
 	// mark token used
 	if err := s.resetRepo.MarkUsed(row.ID); err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return row.Email, nil
 }
 
 func generateToken(n int) (string, error) {
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+)
+
+// maxCompletenessSteps is the number of next steps suggested at once
+const maxCompletenessSteps = 3
+
+// CompletenessConfig sets how much each part of a profile counts and which parts are required.
+// A weight of 0 leaves a part out of the score.
+type CompletenessConfig struct {
+	Weights            map[string]int
+	Required           map[string]bool
+	MinBiographyLength int
+}
+
+// DefaultCompletenessConfig weighs to 100 and requires the same fields as the profile_complete badge
+func DefaultCompletenessConfig() CompletenessConfig {
+	return CompletenessConfig{
+		Weights: map[string]int{
+			"first_name":            10,
+			"last_name":             10,
+			"profile_photo_url":     15,
+			"biography":             15,
+			"job_function":          10,
+			"sector":                10,
+			"country":               5,
+			"phone_number":          5,
+			"interests":             15,
+			"discovery_preferences": 5,
+		},
+		Required: map[string]bool{
+			"first_name":        true,
+			"last_name":         true,
+			"phone_number":      true,
+			"country":           true,
+			"job_function":      true,
+			"sector":            true,
+			"biography":         true,
+			"profile_photo_url": true,
+		},
+		MinBiographyLength: 50,
+	}
+}
+
+// CompletenessConfigFromEnv starts from the defaults and applies
+// PROFILE_COMPLETENESS_WEIGHTS ("biography=20,interests=10"), PROFILE_REQUIRED_FIELDS ("first_name,last_name")
+// and PROFILE_MIN_BIOGRAPHY_LENGTH.
+func CompletenessConfigFromEnv() (CompletenessConfig, error) {
+	cfg := DefaultCompletenessConfig()
+
+	if raw := os.Getenv("PROFILE_COMPLETENESS_WEIGHTS"); raw != "" {
+		for _, pair := range strings.Split(raw, ",") {
+			field, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			weight, err := strconv.Atoi(strings.TrimSpace(value))
+			if !ok || err != nil || weight < 0 {
+				return cfg, fmt.Errorf("PROFILE_COMPLETENESS_WEIGHTS: invalid entry %q, expected field=weight", pair)
+			}
+			field = strings.TrimSpace(field)
+			if _, known := cfg.Weights[field]; !known {
+				return cfg, fmt.Errorf("PROFILE_COMPLETENESS_WEIGHTS: unknown field %q", field)
+			}
+			cfg.Weights[field] = weight
+		}
+	}
+
+	if raw, set := os.LookupEnv("PROFILE_REQUIRED_FIELDS"); set {
+		cfg.Required = map[string]bool{}
+		for _, field := range strings.Split(raw, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if _, known := cfg.Weights[field]; !known {
+				return cfg, fmt.Errorf("PROFILE_REQUIRED_FIELDS: unknown field %q", field)
+			}
+			cfg.Required[field] = true
+		}
+	}
+
+	if raw := os.Getenv("PROFILE_MIN_BIOGRAPHY_LENGTH"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("PROFILE_MIN_BIOGRAPHY_LENGTH: invalid value %q", raw)
+		}
+		cfg.MinBiographyLength = n
+	}
+
+	return cfg, nil
+}
+
+// completenessInput is everything the checks look at
+type completenessInput struct {
+	locale             i18n.Locale
+	user               models.User
+	selectedInterests  int64
+	hasDiscoveryPrefs  bool
+	minBiographyLength int
+}
+
+// completenessCheck scores one part of the profile. check returns "" when the part is fine.
+// The next step that fixes the part is the catalog text "completeness.step.<field>".
+type completenessCheck struct {
+	field    string
+	check    func(in completenessInput) (models.CompletenessStatus, string)
+	endpoint string
+}
+
+func filledIn(value func(u models.User) string) func(in completenessInput) (models.CompletenessStatus, string) {
+	return func(in completenessInput) (models.CompletenessStatus, string) {
+		if strings.TrimSpace(value(in.user)) == "" {
+			return models.CompletenessMissing, i18n.T(in.locale, "completeness.reason.missing")
+		}
+		return "", ""
+	}
+}
+
+var completenessChecks = []completenessCheck{
+	{field: "first_name", check: filledIn(func(u models.User) string { return u.FirstName }), endpoint: "PATCH /users/me"},
+	{field: "last_name", check: filledIn(func(u models.User) string { return u.LastName }), endpoint: "PATCH /users/me"},
+	{field: "profile_photo_url", check: filledIn(func(u models.User) string { return u.ProfilePhotoURL }), endpoint: "POST /users/me/profile-photo"},
+	{field: "biography", check: func(in completenessInput) (models.CompletenessStatus, string) {
+		length := utf8.RuneCountInString(strings.TrimSpace(in.user.Biography))
+		switch {
+		case length == 0:
+			return models.CompletenessMissing, i18n.T(in.locale, "completeness.reason.missing")
+		case length < in.minBiographyLength:
+			return models.CompletenessWeak, i18n.T(in.locale, "completeness.reason.biography_short", length, in.minBiographyLength)
+		}
+		return "", ""
+	}, endpoint: "PATCH /users/me"},
+	{field: "job_function", check: filledIn(func(u models.User) string { return u.JobFunction }), endpoint: "PATCH /users/me"},
+	{field: "sector", check: filledIn(func(u models.User) string { return u.Sector }), endpoint: "PATCH /users/me"},
+	{field: "country", check: filledIn(func(u models.User) string { return u.Country }), endpoint: "PATCH /users/me"},
+	{field: "phone_number", check: filledIn(func(u models.User) string { return u.PhoneNumber }), endpoint: "PATCH /users/me"},
+	{field: "interests", check: func(in completenessInput) (models.CompletenessStatus, string) {
+		if in.selectedInterests == 0 {
+			return models.CompletenessMissing, i18n.T(in.locale, "completeness.reason.no_interests")
+		}
+		return "", ""
+	}, endpoint: "PUT /users/me/interests"},
+	{field: "discovery_preferences", check: func(in completenessInput) (models.CompletenessStatus, string) {
+		if !in.hasDiscoveryPrefs {
+			return models.CompletenessMissing, i18n.T(in.locale, "completeness.reason.no_discovery_preferences")
+		}
+		return "", ""
+	}, endpoint: "PUT /users/me/discovery-preferences"},
+}
+
+type profileCompletenessService struct {
+	repo interfaces.ProfileCompletenessRepository
+	cfg  CompletenessConfig
+}
+
+func NewProfileCompletenessService(repo interfaces.ProfileCompletenessRepository, cfg CompletenessConfig) interfaces.ProfileCompletenessService {
+	return &profileCompletenessService{repo: repo, cfg: cfg}
+}
+
+// ForUser scores the profile, with the texts in l. Weak parts count for half their weight. Next steps
+// start with required parts, then the parts that add the most.
+func (s *profileCompletenessService) ForUser(u models.User, l i18n.Locale) (*models.ProfileCompleteness, error) {
+	interests, err := s.repo.CountSelectedInterests(u.Email)
+	if err != nil {
+		return nil, err
+	}
+	hasPrefs, err := s.repo.HasDiscoveryPreferences(u.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	in := completenessInput{
+		locale:             l,
+		user:               u,
+		selectedInterests:  interests,
+		hasDiscoveryPrefs:  hasPrefs,
+		minBiographyLength: s.cfg.MinBiographyLength,
+	}
+
+	result := &models.ProfileCompleteness{
+		UserID:    u.ID,
+		Complete:  true,
+		Missing:   []models.CompletenessItem{},
+		NextSteps: []models.CompletenessStep{},
+	}
+
+	total, earned := 0.0, 0.0
+	steps := map[string]completenessCheck{}
+	for _, c := range completenessChecks {
+		weight := s.cfg.Weights[c.field]
+		required := s.cfg.Required[c.field]
+		if weight == 0 && !required {
+			continue
+		}
+		total += float64(weight)
+
+		status, reason := c.check(in)
+		switch status {
+		case "":
+			earned += float64(weight)
+			continue
+		case models.CompletenessWeak:
+			earned += float64(weight) / 2
+		case models.CompletenessMissing:
+			if required {
+				result.Complete = false
+			}
+		}
+
+		result.Missing = append(result.Missing, models.CompletenessItem{
+			Field:    c.field,
+			Status:   status,
+			Weight:   weight,
+			Required: required,
+			Reason:   reason,
+		})
+		steps[c.field] = c
+	}
+
+	if total > 0 {
+		result.Percentage = int(math.Round(100 * earned / total))
+	} else {
+		result.Percentage = 100
+	}
+
+	// required before optional, missing before weak, then by weight; ties keep the check order
+	order := append([]models.CompletenessItem(nil), result.Missing...)
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if a.Required != b.Required {
+			return a.Required
+		}
+		if a.Status != b.Status {
+			return a.Status == models.CompletenessMissing
+		}
+		return a.Weight > b.Weight
+	})
+	for _, item := range order {
+		if len(result.NextSteps) == maxCompletenessSteps {
+			break
+		}
+		c := steps[item.Field]
+		result.NextSteps = append(result.NextSteps, models.CompletenessStep{
+			Field:    c.field,
+			Message:  i18n.T(l, "completeness.step."+c.field),
+			Endpoint: c.endpoint,
+		})
+	}
+
+	return result, nil
+}
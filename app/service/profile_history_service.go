@@ -0,0 +1,70 @@
+package service
+
+import (
+	"errors"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+	"group1-userservice/app/validation"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrProfileVersionNotFound = apperrors.NotFound("profile_version_not_found", "profile version not found")
+
+type profileHistoryService struct {
+	repo interfaces.ProfileHistoryRepository
+}
+
+func NewProfileHistoryService(repo interfaces.ProfileHistoryRepository) interfaces.ProfileHistoryService {
+	return &profileHistoryService{repo: repo}
+}
+
+// History returns a page of versions, each with the fields it changed compared to the version before it
+func (s *profileHistoryService) History(userID uuid.UUID, p interfaces.Pagination) (*interfaces.ProfileHistoryPage, error) {
+	// one extra row gives the predecessor of the oldest version on this page
+	versions, total, err := s.repo.List(userID, p.Offset(), p.PageSize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.ProfileHistoryEntry, 0, p.PageSize)
+	for i, v := range versions {
+		if i == p.PageSize {
+			break
+		}
+
+		var changes models.FieldChanges
+		if i+1 < len(versions) {
+			changes = DiffFields(versions[i+1].Snapshot, v.Snapshot)
+		} else {
+			changes = DiffFields(models.ProfileSnapshot{}, v.Snapshot)
+		}
+
+		items = append(items, models.ProfileHistoryEntry{ProfileVersion: v, Changes: changes})
+	}
+
+	return &interfaces.ProfileHistoryPage{Items: items, Page: p.Page, PageSize: p.PageSize, Total: total}, nil
+}
+
+// Restore writes an earlier snapshot back to the profile. The result is recorded as a new version,
+// so a restore can itself be undone.
+func (s *profileHistoryService) Restore(user models.User, version int) (models.User, error) {
+	v, err := s.repo.Find(user.ID, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, ErrProfileVersionNotFound
+		}
+		return models.User{}, err
+	}
+
+	// old versions may predate the current rules, so they are validated like any other update
+	err = validation.Profile(&v.Snapshot)
+	if err := resolveTerms(s.repo, err, &v.Snapshot.Sector, &v.Snapshot.JobFunction); err != nil {
+		return models.User{}, err
+	}
+
+	return s.repo.Restore(user.ID, *v)
+}
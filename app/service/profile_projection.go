@@ -0,0 +1,106 @@
+package service
+
+import (
+	"group1-userservice/app/models"
+)
+
+var projectedFields = []models.ProfileField{
+	models.ProfileFieldEmail,
+	models.ProfileFieldPhoneNumber,
+	models.ProfileFieldCountry,
+	models.ProfileFieldJobFunction,
+	models.ProfileFieldSector,
+	models.ProfileFieldBiography,
+	models.ProfileFieldBadges,
+}
+
+// DefaultProfileVisibility returns the settings of a user that never changed their privacy settings.
+// The phone number follows the legacy PhoneNumberVisible flag.
+func DefaultProfileVisibility(u models.User) models.ProfileVisibility {
+	phone := models.VisibilityPrivate
+	if u.PhoneNumberVisible {
+		phone = models.VisibilityPublic
+	}
+
+	return models.ProfileVisibility{
+		UserID:      u.ID,
+		Email:       models.VisibilityPublic,
+		PhoneNumber: phone,
+		Country:     models.VisibilityPublic,
+		JobFunction: models.VisibilityPublic,
+		Sector:      models.VisibilityPublic,
+		Biography:   models.VisibilityPublic,
+		Badges:      models.VisibilityPublic,
+	}
+}
+
+// VisibleFields decides per profile field whether a viewer with relationship rel may see it.
+// PhoneNumberVisible acts as a master switch: when it is off, only the owner sees the phone number.
+// The same goes for BiographyHidden and the biography.
+func VisibleFields(u models.User, settings models.ProfileVisibility, rel models.Relationship) map[models.ProfileField]bool {
+	visible := make(map[models.ProfileField]bool, len(projectedFields))
+	for _, f := range projectedFields {
+		visible[f] = settings.ForField(f).AllowedFor(rel)
+	}
+
+	if rel != models.RelationshipSelf && !u.PhoneNumberVisible {
+		visible[models.ProfileFieldPhoneNumber] = false
+	}
+
+	// a biography hidden by a moderator is only shown to its owner
+	if rel != models.RelationshipSelf && u.BiographyHidden {
+		visible[models.ProfileFieldBiography] = false
+	}
+
+	return visible
+}
+
+// ProjectProfile builds the profile of u as a viewer with relationship rel is allowed to see it.
+func ProjectProfile(
+	u models.User,
+	settings models.ProfileVisibility,
+	rel models.Relationship,
+	badges []models.UserBadge,
+) models.UserPublicProfile {
+	visible := VisibleFields(u, settings, rel)
+
+	out := models.UserPublicProfile{
+		ID:              u.ID,
+		Handle:          u.Handle,
+		FirstName:       u.FirstName,
+		LastName:        u.LastName,
+		ProfilePhotoURL: u.ProfilePhotoURL,
+		Badges:          []models.PublicBadge{},
+	}
+
+	if visible[models.ProfileFieldEmail] {
+		out.Email = u.Email
+	}
+	if visible[models.ProfileFieldPhoneNumber] {
+		out.PhoneNumber = u.PhoneNumber
+	}
+	if visible[models.ProfileFieldCountry] {
+		out.Country = u.Country
+	}
+	if visible[models.ProfileFieldJobFunction] {
+		out.JobFunction = u.JobFunction
+	}
+	if visible[models.ProfileFieldSector] {
+		out.Sector = u.Sector
+	}
+	if visible[models.ProfileFieldBiography] {
+		out.Biography = u.Biography
+	}
+	if visible[models.ProfileFieldBadges] {
+		for _, b := range badges {
+			out.Badges = append(out.Badges, models.PublicBadge{
+				Key:         b.BadgeKey,
+				Name:        b.Badge.Name,
+				Description: b.Badge.Description,
+				EarnedAt:    b.EarnedAt,
+			})
+		}
+	}
+
+	return out
+}
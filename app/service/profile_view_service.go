@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrProfileNotFound is returned when the requested user does not exist.
+var ErrProfileNotFound = apperrors.NotFound("user_not_found", "user not found")
+
+type profileViewService struct {
+	userSvc       interfaces.UserService
+	badgeSvc      interfaces.UserBadgeService
+	visibilitySvc interfaces.ProfileVisibilityService
+	relationships interfaces.RelationshipResolver
+	counter       interfaces.ConnectionCounter
+}
+
+func NewProfileViewService(
+	userSvc interfaces.UserService,
+	badgeSvc interfaces.UserBadgeService,
+	visibilitySvc interfaces.ProfileVisibilityService,
+	relationships interfaces.RelationshipResolver,
+	counter interfaces.ConnectionCounter,
+) interfaces.ProfileViewService {
+	return &profileViewService{
+		userSvc:       userSvc,
+		badgeSvc:      badgeSvc,
+		visibilitySvc: visibilitySvc,
+		relationships: relationships,
+		counter:       counter,
+	}
+}
+
+// relationship tells how the viewer relates to the target.
+// A viewer without a local user row is treated as an anonymous member of the public.
+// When either user blocked the other, ErrProfileNotFound is returned.
+func (s *profileViewService) relationship(viewerSub string, targetID uuid.UUID) (models.Relationship, error) {
+	viewerID := uuid.Nil
+	if viewerSub != "" {
+		viewer, err := s.userSvc.GetByKeycloakID(viewerSub)
+		switch {
+		case err == nil:
+			viewerID = viewer.ID
+		case !errors.Is(err, ErrUserNotFound):
+			// falling back to the public would skip the block check
+			return "", err
+		}
+	}
+
+	rel, err := s.relationships.Relationship(viewerID, targetID)
+	if err != nil {
+		return "", err
+	}
+
+	// blocked users do not exist for each other
+	if rel == models.RelationshipBlocked {
+		return "", ErrProfileNotFound
+	}
+
+	return rel, nil
+}
+
+// view loads the target's settings and how the viewer relates to the target.
+func (s *profileViewService) view(viewerSub string, target models.User) (models.ProfileVisibility, models.Relationship, error) {
+	rel, err := s.relationship(viewerSub, target.ID)
+	if err != nil {
+		return models.ProfileVisibility{}, "", err
+	}
+
+	settings, err := s.visibilitySvc.GetForUser(target)
+	if err != nil {
+		return models.ProfileVisibility{}, "", err
+	}
+
+	return *settings, rel, nil
+}
+
+func (s *profileViewService) CanView(viewerSub string, targetID uuid.UUID) error {
+	_, err := s.relationship(viewerSub, targetID)
+	return err
+}
+
+func (s *profileViewService) PublicProfile(viewerSub string, targetID uuid.UUID) (*models.UserPublicProfile, error) {
+	target, err := s.userSvc.GetByID(targetID)
+	if err != nil {
+		return nil, ErrProfileNotFound
+	}
+
+	settings, rel, err := s.view(viewerSub, target)
+	if err != nil {
+		return nil, err
+	}
+
+	badges, err := s.badgeSvc.GetBadgesForUser(target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := ProjectProfile(target, settings, rel, badges)
+
+	// counters are optional: without a counter the profile is returned without them
+	if s.counter != nil {
+		counts, err := s.counter.Counts(target.ID)
+		if err != nil {
+			return nil, err
+		}
+		profile.Counts = &counts
+	}
+
+	return &profile, nil
+}
+
+func (s *profileViewService) PublicInfoByFirstLast(viewerSub string, first, last string) (*models.UserPublicInfo, error) {
+	info, err := s.userSvc.GetPublicInfoByFirstLast(first, last)
+	if err != nil {
+		return nil, ErrProfileNotFound
+	}
+
+	target, err := s.userSvc.GetByEmail(info.Email)
+	if err != nil {
+		return nil, ErrProfileNotFound
+	}
+
+	settings, rel, err := s.view(viewerSub, target)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := ProjectProfile(target, settings, rel, nil)
+	return &models.UserPublicInfo{
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		Email:     profile.Email,
+	}, nil
+}
+
+func (s *profileViewService) BadgesForUser(viewerSub string, targetID uuid.UUID) ([]models.UserBadge, error) {
+	target, err := s.userSvc.GetByID(targetID)
+	if err != nil {
+		return nil, ErrProfileNotFound
+	}
+
+	settings, rel, err := s.view(viewerSub, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if !VisibleFields(target, settings, rel)[models.ProfileFieldBadges] {
+		return []models.UserBadge{}, nil
+	}
+
+	return s.badgeSvc.GetBadgesForUser(target.ID)
+}
+
+type selfRelationshipResolver struct{}
+
+// NewSelfRelationshipResolver only distinguishes between the owner and everybody else.
+func NewSelfRelationshipResolver() interfaces.RelationshipResolver {
+	return selfRelationshipResolver{}
+}
+
+func (selfRelationshipResolver) Relationship(viewerID, targetID uuid.UUID) (models.Relationship, error) {
+	if viewerID != uuid.Nil && viewerID == targetID {
+		return models.RelationshipSelf, nil
+	}
+	return models.RelationshipPublic, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrVisibilityInvalid = apperrors.BadRequest("visibility_invalid", "invalid visibility")
+
+type profileVisibilityService struct {
+	repo    interfaces.ProfileVisibilityRepository
+	userSvc interfaces.UserService
+}
+
+func NewProfileVisibilityService(repo interfaces.ProfileVisibilityRepository, userSvc interfaces.UserService) interfaces.ProfileVisibilityService {
+	return &profileVisibilityService{repo: repo, userSvc: userSvc}
+}
+
+func (s *profileVisibilityService) GetForUser(user models.User) (*models.ProfileVisibility, error) {
+	settings, err := s.repo.FindByUserID(user.ID)
+	if err == nil {
+		return settings, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// a user who never saved settings gets the defaults
+	defaults := DefaultProfileVisibility(user)
+	return &defaults, nil
+}
+
+func (s *profileVisibilityService) UpdateForUser(user models.User, input interfaces.ProfileVisibilityPatchInput) (*models.ProfileVisibility, error) {
+	current, err := s.GetForUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	apply := func(field models.ProfileField, in *models.Visibility, dst *models.Visibility) error {
+		if in == nil {
+			return nil
+		}
+		if !in.Valid() {
+			return fmt.Errorf("%s must be one of public, connections, private", field)
+		}
+		*dst = *in
+		return nil
+	}
+
+	if err := errors.Join(
+		apply(models.ProfileFieldEmail, input.Email, &current.Email),
+		apply(models.ProfileFieldPhoneNumber, input.PhoneNumber, &current.PhoneNumber),
+		apply(models.ProfileFieldCountry, input.Country, &current.Country),
+		apply(models.ProfileFieldJobFunction, input.JobFunction, &current.JobFunction),
+		apply(models.ProfileFieldSector, input.Sector, &current.Sector),
+		apply(models.ProfileFieldBiography, input.Biography, &current.Biography),
+		apply(models.ProfileFieldBadges, input.Badges, &current.Badges),
+	); err != nil {
+		return nil, ErrVisibilityInvalid.Detailf("%s", strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+
+	current.UserID = user.ID
+	updated, err := s.repo.Upsert(current)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the legacy phone_number_visible flag in line with the new setting
+	if input.PhoneNumber != nil {
+		phoneVisible := *input.PhoneNumber != models.VisibilityPrivate
+		if phoneVisible != user.PhoneNumberVisible {
+			if _, err := s.userSvc.UpdateByEmail(user.Email, &models.UserUpdateInput{PhoneNumberVisible: &phoneVisible}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return updated, nil
+}
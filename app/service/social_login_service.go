@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+)
+
+var (
+	ErrSocialProviderUnknown   = apperrors.BadRequest("social_provider_unknown", "unknown social login provider")
+	ErrSocialEmailMissing      = apperrors.BadRequest("social_email_missing", "the social account has no email address")
+	ErrSocialEmailUnverified   = apperrors.Conflict("social_email_in_use", "an account with this email already exists; log in with its password first")
+	ErrSocialAccountUnverified = apperrors.Conflict("social_account_unverified", "an account with this email exists but its email address is not verified; log in with its password and verify it first")
+)
+
+// SocialLoginConfig lists the aliases of the identity providers brokered by Keycloak that may be used
+type SocialLoginConfig struct {
+	Providers []string
+}
+
+var providerAlias = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// SocialLoginConfigFromEnv reads SOCIAL_LOGIN_PROVIDERS, a comma-separated list of provider aliases ("google,apple")
+func SocialLoginConfigFromEnv() (SocialLoginConfig, error) {
+	raw := os.Getenv("SOCIAL_LOGIN_PROVIDERS")
+	if raw == "" {
+		raw = "google,apple"
+	}
+
+	cfg := SocialLoginConfig{}
+	for _, alias := range strings.Split(raw, ",") {
+		alias = strings.TrimSpace(alias)
+		if !providerAlias.MatchString(alias) {
+			return cfg, fmt.Errorf("invalid SOCIAL_LOGIN_PROVIDERS entry %q", alias)
+		}
+		cfg.Providers = append(cfg.Providers, alias)
+	}
+	return cfg, nil
+}
+
+type socialLoginService struct {
+	idp           interfaces.IdentityProvider
+	userSvc       interfaces.UserService
+	notifications interfaces.NotificationSettingsService
+	discovery     interfaces.DiscoveryPreferencesService
+	providers     map[string]bool
+}
+
+func NewSocialLoginService(
+	idp interfaces.IdentityProvider,
+	userSvc interfaces.UserService,
+	notifications interfaces.NotificationSettingsService,
+	discovery interfaces.DiscoveryPreferencesService,
+	cfg SocialLoginConfig,
+) interfaces.SocialLoginService {
+	providers := map[string]bool{}
+	for _, p := range cfg.Providers {
+		providers[p] = true
+	}
+
+	return &socialLoginService{
+		idp:           idp,
+		userSvc:       userSvc,
+		notifications: notifications,
+		discovery:     discovery,
+		providers:     providers,
+	}
+}
+
+func (s *socialLoginService) Login(provider, idToken string) (interfaces.SocialLoginResult, error) {
+	if !s.providers[provider] {
+		return interfaces.SocialLoginResult{}, ErrSocialProviderUnknown
+	}
+
+	token, err := s.idp.ExchangeSocialToken(provider, idToken)
+	if err != nil {
+		return interfaces.SocialLoginResult{}, err
+	}
+	claims, err := s.idp.VerifyToken(token.AccessToken)
+	if err != nil {
+		return interfaces.SocialLoginResult{}, err
+	}
+
+	// Returning user, or Keycloak linked the account itself in its first broker login flow
+	user, err := s.userSvc.GetByKeycloakID(claims.Subject)
+	if err == nil {
+		return s.result(user, token, false, false)
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return interfaces.SocialLoginResult{}, err
+	}
+
+	if claims.Email == "" {
+		return interfaces.SocialLoginResult{}, ErrSocialEmailMissing
+	}
+
+	existing, err := s.userSvc.GetByEmail(claims.Email)
+	if errors.Is(err, ErrUserNotFound) {
+		return s.create(claims, token)
+	}
+	if err != nil {
+		return interfaces.SocialLoginResult{}, err
+	}
+
+	// Linking on an email the provider did not verify would hand the account to whoever typed it in
+	if !claims.EmailVerified || existing.KeycloakID == "" {
+		return interfaces.SocialLoginResult{}, ErrSocialEmailUnverified
+	}
+	// Anyone can register an email they do not own; only a verified account may take over the social login
+	if existing.EmailVerifiedAt == nil {
+		return interfaces.SocialLoginResult{}, ErrSocialAccountUnverified
+	}
+
+	if err := s.idp.LinkSocialIdentity(claims.Subject, existing.KeycloakID); err != nil {
+		return interfaces.SocialLoginResult{}, err
+	}
+
+	// The tokens were issued to the account that was just removed; the same ID token now logs in to the linked one
+	token, err = s.idp.ExchangeSocialToken(provider, idToken)
+	if err != nil {
+		return interfaces.SocialLoginResult{}, err
+	}
+	claims, err = s.idp.VerifyToken(token.AccessToken)
+	if err != nil {
+		return interfaces.SocialLoginResult{}, err
+	}
+	if claims.Subject != existing.KeycloakID {
+		return interfaces.SocialLoginResult{}, fmt.Errorf("social account linked to %s but logged in as %s", existing.KeycloakID, claims.Subject)
+	}
+
+	return s.result(existing, token, false, true)
+}
+
+// create makes the local user for a new social account, with default settings
+func (s *socialLoginService) create(claims *interfaces.IdentityClaims, token *interfaces.TokenResponse) (interfaces.SocialLoginResult, error) {
+	user := models.User{
+		KeycloakID: claims.Subject,
+		Email:      claims.Email,
+		FirstName:  claims.FirstName,
+		LastName:   claims.LastName,
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userSvc.RegisterFromIdentity(&user); err != nil {
+		return interfaces.SocialLoginResult{}, err
+	}
+
+	// Both are created with their defaults when read the first time
+	if _, err := s.notifications.GetByEmail(user.Email); err != nil {
+		return interfaces.SocialLoginResult{}, err
+	}
+	if _, err := s.discovery.GetForEmail(user.Email); err != nil {
+		return interfaces.SocialLoginResult{}, err
+	}
+
+	return s.result(user, token, true, false)
+}
+
+func (s *socialLoginService) result(user models.User, token *interfaces.TokenResponse, created, linked bool) (interfaces.SocialLoginResult, error) {
+	result := interfaces.SocialLoginResult{User: user, Token: token, Created: created, Linked: linked}
+	if user.IsBlocked {
+		return result, ErrAccountBlocked
+	}
+	return result, nil
+}
@@ -0,0 +1,416 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTwoFactorKeyRequired     = errors.New("TWO_FACTOR_ENCRYPTION_KEY is required")
+	ErrTwoFactorAlreadyEnabled  = apperrors.Conflict("two_factor_already_enabled", "two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled     = apperrors.Conflict("two_factor_not_enrolled", "start two-factor enrollment first")
+	ErrTwoFactorNotEnabled      = apperrors.Conflict("two_factor_not_enabled", "two-factor authentication is not enabled")
+	ErrTwoFactorInvalidCode     = apperrors.Unauthorized("two_factor_code_invalid", "invalid two-factor code")
+	ErrTwoFactorInvalidPassword = apperrors.Unauthorized("invalid_password", "invalid password")
+	ErrLoginChallengeInvalid    = apperrors.Unauthorized("login_challenge_invalid", "invalid or expired login challenge")
+	ErrLoginChallengeLocked     = apperrors.TooManyRequests("login_challenge_locked", "too many invalid codes, log in again")
+)
+
+const (
+	totpPeriod         = 30
+	totpDigits         = 6
+	recoveryCodeCount  = 10
+	maxChallengeTrials = 5
+)
+
+// TwoFactorConfig holds the key that encrypts TOTP secrets and pending refresh tokens,
+// the issuer shown in authenticator apps and how long a login challenge lasts
+type TwoFactorConfig struct {
+	Key          []byte
+	Issuer       string
+	ChallengeTTL time.Duration
+}
+
+// TwoFactorConfigFromEnv reads TWO_FACTOR_ENCRYPTION_KEY (required), TOTP_ISSUER ("Group1")
+// and TWO_FACTOR_CHALLENGE_TTL_SECONDS (300)
+func TwoFactorConfigFromEnv() (TwoFactorConfig, error) {
+	cfg := TwoFactorConfig{Issuer: "Group1", ChallengeTTL: 5 * time.Minute}
+
+	raw := os.Getenv("TWO_FACTOR_ENCRYPTION_KEY")
+	if raw == "" {
+		return cfg, ErrTwoFactorKeyRequired
+	}
+	// AES-256 needs 32 bytes; any passphrase is stretched to that
+	key := sha256.Sum256([]byte(raw))
+	cfg.Key = key[:]
+
+	if issuer := strings.TrimSpace(os.Getenv("TOTP_ISSUER")); issuer != "" {
+		cfg.Issuer = issuer
+	}
+	if v := os.Getenv("TWO_FACTOR_CHALLENGE_TTL_SECONDS"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 30 {
+			return cfg, errors.New("TWO_FACTOR_CHALLENGE_TTL_SECONDS must be at least 30")
+		}
+		cfg.ChallengeTTL = time.Duration(seconds) * time.Second
+	}
+
+	return cfg, nil
+}
+
+// TOTPCode returns the RFC 6238 code (SHA-1, 6 digits, 30 seconds) of a base32 secret at time t
+func TOTPCode(secret string, t time.Time) (string, error) {
+	return totpCodeAt(secret, t.Unix()/totpPeriod)
+}
+
+func totpCodeAt(secret string, step int64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil
+}
+
+// matchTOTP returns the time step the code belongs to; one step of clock drift either way is accepted
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	current := now.Unix() / totpPeriod
+	for _, step := range []int64{current, current - 1, current + 1} {
+		expected, err := totpCodeAt(secret, step)
+		if err == nil && subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// normalizeRecoveryCode accepts codes with or without dash, spaces and in any case
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
+
+type twoFactorService struct {
+	repo  interfaces.TwoFactorRepository
+	users interfaces.UserService
+	cfg   TwoFactorConfig
+}
+
+func NewTwoFactorService(repo interfaces.TwoFactorRepository, users interfaces.UserService, cfg TwoFactorConfig) interfaces.TwoFactorService {
+	return &twoFactorService{repo: repo, users: users, cfg: cfg}
+}
+
+func (s *twoFactorService) encrypt(plain string) (string, error) {
+	block, err := aes.NewCipher(s.cfg.Key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plain), nil)), nil
+}
+
+func (s *twoFactorService) decrypt(enc string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(s.cfg.Key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return "", errors.New("encrypted value too short")
+	}
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func (s *twoFactorService) Status(user models.User) (interfaces.TwoFactorStatus, error) {
+	tf, err := s.repo.Find(user.ID)
+	if err != nil || tf == nil || !tf.Enabled {
+		return interfaces.TwoFactorStatus{}, err
+	}
+
+	remaining, err := s.repo.CountRecoveryCodes(user.ID)
+	if err != nil {
+		return interfaces.TwoFactorStatus{}, err
+	}
+	return interfaces.TwoFactorStatus{Enabled: true, ConfirmedAt: tf.ConfirmedAt, RecoveryCodesRemaining: remaining}, nil
+}
+
+// Enroll creates a new secret; a pending enrollment is replaced
+func (s *twoFactorService) Enroll(user models.User) (*interfaces.TwoFactorEnrollment, error) {
+	tf, err := s.repo.Find(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tf != nil && tf.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw)
+
+	enc, err := s.encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(&models.TwoFactor{UserID: user.ID, SecretEnc: enc}); err != nil {
+		return nil, err
+	}
+
+	label := url.PathEscape(s.cfg.Issuer + ":" + user.Email)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", s.cfg.Issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(totpDigits))
+	query.Set("period", strconv.Itoa(totpPeriod))
+
+	return &interfaces.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: "otpauth://totp/" + label + "?" + query.Encode(),
+	}, nil
+}
+
+func (s *twoFactorService) Confirm(user models.User, code string) ([]string, error) {
+	tf, err := s.repo.Find(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if tf.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := s.decrypt(tf.SecretEnc)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := matchTOTP(secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, ErrTwoFactorInvalidCode
+	}
+
+	now := time.Now()
+	tf.Enabled = true
+	tf.ConfirmedAt = &now
+	tf.LastUsedStep = step
+	if err := s.repo.Save(tf); err != nil {
+		return nil, err
+	}
+
+	return s.newRecoveryCodes(user.ID)
+}
+
+func (s *twoFactorService) newRecoveryCodes(userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashToken(code))
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkCode accepts a TOTP code that was not used before, or an unused recovery code
+func (s *twoFactorService) checkCode(tf *models.TwoFactor, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return false, nil
+	}
+
+	if len(code) == totpDigits {
+		secret, err := s.decrypt(tf.SecretEnc)
+		if err != nil {
+			return false, err
+		}
+		step, ok := matchTOTP(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return s.repo.UseStep(tf.UserID, step)
+	}
+
+	return s.repo.UseRecoveryCode(tf.UserID, hashToken(normalizeRecoveryCode(code)))
+}
+
+// reauthenticate checks the password and a second factor before 2FA settings change
+func (s *twoFactorService) reauthenticate(user models.User, input interfaces.TwoFactorReauthInput) (*models.TwoFactor, error) {
+	tf, err := s.repo.Find(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil || !tf.Enabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	if input.Password == "" {
+		return nil, ErrTwoFactorInvalidPassword
+	}
+	if err := s.users.VerifyPassword(user.Email, input.Password); err != nil {
+		if errors.Is(err, interfaces.ErrInvalidCredentials) {
+			return nil, ErrTwoFactorInvalidPassword
+		}
+		return nil, err
+	}
+
+	ok, err := s.checkCode(tf, input.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrTwoFactorInvalidCode
+	}
+	return tf, nil
+}
+
+func (s *twoFactorService) Disable(user models.User, input interfaces.TwoFactorReauthInput) error {
+	if _, err := s.reauthenticate(user, input); err != nil {
+		return err
+	}
+	return s.repo.Delete(user.ID)
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(user models.User, input interfaces.TwoFactorReauthInput) ([]string, error) {
+	if _, err := s.reauthenticate(user, input); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(user.ID)
+}
+
+func (s *twoFactorService) Enabled(userID uuid.UUID) (bool, error) {
+	tf, err := s.repo.Find(userID)
+	if err != nil {
+		return false, err
+	}
+	return tf != nil && tf.Enabled, nil
+}
+
+func (s *twoFactorService) StartLogin(user models.User, refreshToken string) (string, int, error) {
+	raw, err := generateToken(32)
+	if err != nil {
+		return "", 0, err
+	}
+	enc, err := s.encrypt(refreshToken)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := s.repo.CreateChallenge(&models.LoginChallenge{
+		TokenHash:       hashToken(raw),
+		UserID:          user.ID,
+		RefreshTokenEnc: enc,
+		ExpiresAt:       time.Now().Add(s.cfg.ChallengeTTL),
+	}); err != nil {
+		return "", 0, err
+	}
+
+	return raw, int(s.cfg.ChallengeTTL.Seconds()), nil
+}
+
+// FinishLogin also returns the user when the code is wrong, so the failure can be attributed
+func (s *twoFactorService) FinishLogin(input interfaces.LoginChallengeInput) (models.User, string, error) {
+	ch, err := s.repo.FindChallenge(hashToken(input.ChallengeToken))
+	if err != nil {
+		return models.User{}, "", ErrLoginChallengeInvalid
+	}
+	user, err := s.users.GetByID(ch.UserID)
+	if err != nil {
+		return models.User{}, "", ErrLoginChallengeInvalid
+	}
+	if ch.Attempts >= maxChallengeTrials {
+		_ = s.repo.DeleteChallenge(ch.ID)
+		return user, "", ErrLoginChallengeLocked
+	}
+
+	tf, err := s.repo.Find(ch.UserID)
+	if err != nil {
+		return models.User{}, "", err
+	}
+	if tf == nil || !tf.Enabled {
+		return models.User{}, "", ErrLoginChallengeInvalid
+	}
+
+	ok, err := s.checkCode(tf, input.Code)
+	if err != nil {
+		return models.User{}, "", err
+	}
+	if !ok {
+		if ch.Attempts+1 >= maxChallengeTrials {
+			_ = s.repo.DeleteChallenge(ch.ID)
+			return user, "", ErrLoginChallengeLocked
+		}
+		if err := s.repo.AddChallengeAttempt(ch.ID); err != nil {
+			return models.User{}, "", err
+		}
+		return user, "", ErrTwoFactorInvalidCode
+	}
+
+	// the challenge is used up from here
+	if err := s.repo.DeleteChallenge(ch.ID); err != nil {
+		return models.User{}, "", err
+	}
+
+	refreshToken, err := s.decrypt(ch.RefreshTokenEnc)
+	if err != nil {
+		return models.User{}, "", err
+	}
+	return user, refreshToken, nil
+}
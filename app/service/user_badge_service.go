@@ -27,6 +27,21 @@ func (s *userBadgeService) AwardBadge(userID uuid.UUID, badgeKey string) (bool,
 	return created, err
 }
 
+func (s *userBadgeService) RevokeBadge(userID uuid.UUID, badgeKey string) (bool, error) {
+	return s.repo.Delete(userID, badgeKey)
+}
+
+// SyncProfileCompleteBadge awards the profile_complete badge to a complete profile and
+// takes it back once a field has been cleared again
+func SyncProfileCompleteBadge(badges interfaces.UserBadgeService, u models.User) error {
+	if IsProfileComplete(u) {
+		_, err := badges.AwardBadge(u.ID, BadgeKeyProfileComplete)
+		return err
+	}
+	_, err := badges.RevokeBadge(u.ID, BadgeKeyProfileComplete)
+	return err
+}
+
 func (s *userBadgeService) GetBadgesForUser(userID uuid.UUID) ([]models.UserBadge, error) {
 	return s.repo.FindByUserID(userID)
 }
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBlockSelf     = apperrors.BadRequest("block_self", "cannot block or mute yourself")
+	ErrBlockNotFound = apperrors.NotFound("block_not_found", "block not found")
+	ErrBlockKind     = apperrors.BadRequest("block_kind_invalid", "kind must be one of block, mute")
+)
+
+type userBlockService struct {
+	repo        interfaces.UserBlockRepository
+	connections interfaces.ConnectionRepository
+	userSvc     interfaces.UserService
+}
+
+func NewUserBlockService(
+	repo interfaces.UserBlockRepository,
+	connections interfaces.ConnectionRepository,
+	userSvc interfaces.UserService,
+) interfaces.UserBlockService {
+	return &userBlockService{
+		repo:        repo,
+		connections: connections,
+		userSvc:     userSvc,
+	}
+}
+
+func validBlockKind(kind models.BlockKind) bool {
+	return kind == models.BlockKindBlock || kind == models.BlockKindMute
+}
+
+// Add blocks or mutes targetID. Blocking also removes every follow and connection between the two users.
+func (s *userBlockService) Add(user models.User, targetID uuid.UUID, kind models.BlockKind) (*models.UserBlock, error) {
+	if !validBlockKind(kind) {
+		return nil, ErrBlockKind
+	}
+	if user.ID == targetID {
+		return nil, ErrBlockSelf
+	}
+	if _, err := s.userSvc.GetByID(targetID); err != nil {
+		return nil, ErrProfileNotFound
+	}
+
+	b := &models.UserBlock{
+		BlockerID: user.ID,
+		BlockedID: targetID,
+		Kind:      kind,
+	}
+	if err := s.repo.Create(b); err != nil {
+		return nil, err
+	}
+
+	if kind == models.BlockKindBlock {
+		if err := s.connections.DeleteBetween(user.ID, targetID); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+func (s *userBlockService) Remove(user models.User, targetID uuid.UUID, kind models.BlockKind) error {
+	if !validBlockKind(kind) {
+		return ErrBlockKind
+	}
+
+	removed, err := s.repo.Delete(user.ID, targetID, kind)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrBlockNotFound
+	}
+	return nil
+}
+
+func (s *userBlockService) List(userID uuid.UUID, kind models.BlockKind, p interfaces.Pagination) (*interfaces.ConnectionPage, error) {
+	if !validBlockKind(kind) {
+		return nil, ErrBlockKind
+	}
+
+	items, total, err := s.repo.List(userID, kind, p)
+	if err != nil {
+		return nil, err
+	}
+	return page(items, total, p), nil
+}
+
+func (s *userBlockService) BlockList(userID uuid.UUID) (interfaces.UserBlockList, error) {
+	out := interfaces.UserBlockList{UserID: userID}
+
+	var err error
+	if out.Blocked, err = s.repo.TargetIDs(userID, models.BlockKindBlock); err != nil {
+		return out, err
+	}
+	if out.BlockedBy, err = s.repo.BlockerIDs(userID, models.BlockKindBlock); err != nil {
+		return out, err
+	}
+	if out.Muted, err = s.repo.TargetIDs(userID, models.BlockKindMute); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+func (s *userBlockService) IsBlockedBetween(a, b uuid.UUID) (bool, error) {
+	if a == uuid.Nil || b == uuid.Nil || a == b {
+		return false, nil
+	}
+	return s.repo.BlockedBetween(a, b)
+}
+
+func (s *userBlockService) HasMuted(userID, otherID uuid.UUID) (bool, error) {
+	return s.repo.Exists(userID, otherID, models.BlockKindMute)
+}
+
+type blockingRelationshipResolver struct {
+	blocks interfaces.BlockChecker
+	next   interfaces.RelationshipResolver
+}
+
+// NewBlockingRelationshipResolver reports RelationshipBlocked when either user blocked the other,
+// and asks next otherwise.
+func NewBlockingRelationshipResolver(blocks interfaces.BlockChecker, next interfaces.RelationshipResolver) interfaces.RelationshipResolver {
+	return &blockingRelationshipResolver{blocks: blocks, next: next}
+}
+
+func (r *blockingRelationshipResolver) Relationship(viewerID, targetID uuid.UUID) (models.Relationship, error) {
+	blocked, err := r.blocks.IsBlockedBetween(viewerID, targetID)
+	if err != nil {
+		return "", err
+	}
+	if blocked {
+		return models.RelationshipBlocked, nil
+	}
+	return r.next.Relationship(viewerID, targetID)
+}
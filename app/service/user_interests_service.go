@@ -1,9 +1,13 @@
 package service
 
 import (
+	"fmt"
+
+	"group1-userservice/app/i18n"
 	"group1-userservice/app/interfaces"
 	"group1-userservice/app/models"
 	"group1-userservice/app/repository"
+	"group1-userservice/app/validation"
 )
 
 type userInterestsService struct {
@@ -14,8 +18,8 @@ func NewUserInterestsService(repo *repository.UserInterestsRepository) interface
 	return &userInterestsService{repo: repo}
 }
 
-func (s *userInterestsService) GetForUser(email string) ([]interfaces.UserInterestResponseItem, error) {
-	all, err := s.repo.ListAllInterests()
+func (s *userInterestsService) GetForUser(email string, locale i18n.Locale) ([]interfaces.UserInterestResponseItem, error) {
+	all, err := s.repo.ListActiveInterests()
 	if err != nil {
 		return nil, err
 	}
@@ -32,17 +36,40 @@ func (s *userInterestsService) GetForUser(email string) ([]interfaces.UserIntere
 
 	resp := make([]interfaces.UserInterestResponseItem, 0, len(all))
 	for _, i := range all {
+		t := i.Translation(string(locale))
 		resp = append(resp, interfaces.UserInterestResponseItem{
-			ID:    i.ID,
-			Key:   i.Key,
-			Value: values[i.ID],
+			ID:          i.ID,
+			Key:         i.Key,
+			Label:       t.Label,
+			Description: t.Description,
+			Value:       values[i.ID],
 		})
 	}
 
 	return resp, nil
 }
 
-func (s *userInterestsService) UpdateForUser(email string, input interfaces.UserInterestsUpdateInput) ([]interfaces.UserInterestResponseItem, error) {
+// UpdateForUser stores the selections; every ID must be an interest that is not archived
+func (s *userInterestsService) UpdateForUser(email string, input interfaces.UserInterestsUpdateInput, locale i18n.Locale) ([]interfaces.UserInterestResponseItem, error) {
+	active, err := s.repo.ListActiveInterests()
+	if err != nil {
+		return nil, err
+	}
+	selectable := map[uint]bool{}
+	for _, i := range active {
+		selectable[i.ID] = true
+	}
+
+	errs := validation.Errors{}
+	for n, item := range input.Interests {
+		if !selectable[item.ID] {
+			errs[fmt.Sprintf("interests.%d.id", n)] = validation.Violation{Key: "validation.interest_unknown"}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
 	for _, item := range input.Interests {
 		row := &models.UserInterest{
 			UserEmail:  email,
@@ -54,5 +81,5 @@ func (s *userInterestsService) UpdateForUser(email string, input interfaces.User
 		}
 	}
 
-	return s.GetForUser(email)
+	return s.GetForUser(email, locale)
 }
@@ -2,66 +2,83 @@ package service
 
 import (
 	"errors"
-	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"group1-userservice/app/apperrors"
 	"group1-userservice/app/interfaces"
-	"group1-userservice/app/keycloak"
+	"group1-userservice/app/metrics"
 	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+	"group1-userservice/app/validation"
 
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailTaken   = apperrors.Conflict("email_taken", "email already exists")
+	ErrUserNotFound = apperrors.NotFound("user_not_found", "user not found")
 )
 
 type userService struct {
 	repo interfaces.UserRepository
+	idp  interfaces.IdentityProvider
 }
 
-func NewUserService(repo interfaces.UserRepository) interfaces.UserService {
-	return &userService{repo}
+func NewUserService(repo interfaces.UserRepository, idp interfaces.IdentityProvider) interfaces.UserService {
+	return &userService{repo: repo, idp: idp}
 }
 
 func (s *userService) Register(user *models.User) error {
-	// Basic field validation
-	if user.Email == "" {
-		return errors.New("Email is required")
-	}
-	if user.FirstName == "" {
-		return errors.New("First name is required")
-	}
-	if user.LastName == "" {
-		return errors.New("Last name is required")
+	// Field validation and normalization; all violations are returned together
+	err := validation.Registration(user)
+	if err := resolveTerms(s.repo, err, &user.Sector, &user.JobFunction); err != nil {
+		return err
 	}
 
 	plainPassword := user.Password
 
 	if s.repo.ExistsByEmail(user.Email) {
-		return errors.New("email already exists")
+		return ErrEmailTaken
 	}
 
-	if len(user.Password) < 6 {
-		return errors.New("password must be at least 6 characters long")
-	}
+	// New accounts start unverified, whatever the request says
+	user.EmailVerifiedAt = nil
 
-	matched, _ := regexp.MatchString(`[0-9]`, user.Password)
-	if !matched {
-		return errors.New("password must contain at least one number")
+	// Use the requested handle, or generate one from the name
+	user.HandleChangedAt = nil
+	if strings.TrimSpace(user.Handle) != "" {
+		handle, err := s.claimableHandle(user.Handle, uuid.Nil)
+		if err != nil {
+			return err
+		}
+		user.Handle = handle
+	} else {
+		handle, err := s.generateHandle(user.FirstName, user.LastName)
+		if err != nil {
+			return err
+		}
+		user.Handle = handle
 	}
 
-	kcID, err := keycloak.CreateUserInKeycloakWithPassword(*user, plainPassword)
+	kcID, err := s.idp.CreateUser(interfaces.IdentityUser{
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}, plainPassword)
 	if err != nil {
-		if errors.Is(err, keycloak.ErrEmailAlreadyExists) {
-			return errors.New("email already exists")
+		if errors.Is(err, interfaces.ErrEmailAlreadyExists) {
+			return ErrEmailTaken
 		}
 		return err
 	}
 
-	hashed, err := HashPassword(user.Password)
-	if err != nil {
-		return errors.New("failed to hash password")
-	}
-
-	user.Password = hashed
+	// The password lives only in Keycloak
+	user.Password = ""
 	user.KeycloakID = kcID
 
 	if err := s.repo.Create(user); err != nil {
@@ -71,21 +88,69 @@ func (s *userService) Register(user *models.User) error {
 	return nil
 }
 
+// RegisterFromIdentity creates the local user for an account that already exists at the identity
+// provider, such as one made by a social login. The handle is generated from the name.
+func (s *userService) RegisterFromIdentity(user *models.User) error {
+	if user.KeycloakID == "" || user.Email == "" {
+		return errors.New("identity and email are required")
+	}
+	if s.repo.ExistsByEmail(user.Email) {
+		return ErrEmailTaken
+	}
+
+	handle, err := s.generateHandle(user.FirstName, user.LastName)
+	if err != nil {
+		return err
+	}
+	user.Handle = handle
+	user.HandleChangedAt = nil
+	user.Password = ""
+
+	return s.repo.Create(user)
+}
+
+// userNotFound reports a missing user as ErrUserNotFound; other errors are returned as is
+func userNotFound(user models.User, err error) (models.User, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, ErrUserNotFound
+	}
+	return user, err
+}
+
 func (s *userService) GetByEmail(email string) (models.User, error) {
-	return s.repo.FindByEmail(email)
+	return userNotFound(s.repo.FindByEmail(email))
 }
 
-func (s *userService) CheckPassword(hash string, raw string) bool {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(raw)) == nil
+func (s *userService) VerifyPassword(email string, password string) error {
+	_, err := s.idp.PasswordGrant(email, password)
+	return err
 }
 
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+func (s *userService) RetireLegacyPassword(user models.User, plainPassword string) error {
+	// Always empty once the column is dropped (AUTH_CREDENTIAL_MODE=keycloak)
+	if user.LegacyPasswordHash == "" {
+		return nil
+	}
+
+	// The hash is checked only to measure how far the two stores had drifted apart
+	outcome := "replaced"
+	if plainPassword != "" {
+		outcome = "drifted"
+		if bcrypt.CompareHashAndPassword([]byte(user.LegacyPasswordHash), []byte(plainPassword)) == nil {
+			outcome = "matched"
+		}
+	}
+
+	if err := s.repo.ClearLegacyPasswordHash(user.ID); err != nil {
+		return err
+	}
+
+	metrics.LegacyPasswordsRetiredTotal.WithLabelValues(outcome).Inc()
+	return nil
 }
 
 func (s *userService) GetByKeycloakID(sub string) (models.User, error) {
-	return s.repo.FindByKeycloakID(sub)
+	return userNotFound(s.repo.FindByKeycloakID(sub))
 }
 
 func (s *userService) GetPublicInfoByFirstLast(first, last string) (*models.UserPublicInfo, error) {
@@ -101,56 +166,95 @@ func (s *userService) GetPublicInfoByFirstLast(first, last string) (*models.User
 	}, nil
 }
 
-func (s *userService) UpdatePasswordByEmail(email string, newPlainPassword string) error {
-	if len(newPlainPassword) < 6 {
-		return errors.New("password must be at least 6 characters long")
-	}
-	matched, _ := regexp.MatchString(`[0-9]`, newPlainPassword)
-	if !matched {
-		return errors.New("password must contain at least one number")
+// UpdateByEmail changes the non-empty fields of input; empty fields are left alone
+func (s *userService) UpdateByEmail(email string, input *models.UserUpdateInput) (models.User, error) {
+	patch := models.UserProfilePatch{}
+	for _, f := range []struct {
+		dst   *models.PatchString
+		value string
+	}{
+		{&patch.FirstName, input.FirstName},
+		{&patch.LastName, input.LastName},
+		{&patch.PhoneNumber, input.PhoneNumber},
+		{&patch.Country, input.Country},
+		{&patch.JobFunction, input.JobFunction},
+		{&patch.Sector, input.Sector},
+		{&patch.Biography, input.Biography},
+		{&patch.ProfilePhotoURL, input.ProfilePhotoURL},
+		{&patch.PreferredLanguage, input.PreferredLanguage},
+	} {
+		if f.value != "" {
+			*f.dst = models.PatchString{Set: true, Value: f.value}
+		}
 	}
-
-	hashed, err := HashPassword(newPlainPassword)
-	if err != nil {
-		return errors.New("failed to hash password")
+	if input.PhoneNumberVisible != nil {
+		patch.PhoneNumberVisible = models.PatchBool{Set: true, Value: *input.PhoneNumberVisible}
 	}
 
-	return s.repo.UpdatePasswordHashByEmail(email, hashed)
+	return s.PatchByEmail(email, &patch, 0)
 }
 
-func (s *userService) UpdateByEmail(email string, input *models.UserUpdateInput) (models.User, error) {
-	fields := map[string]any{}
-
-	if input.FirstName != "" {
-		fields["first_name"] = input.FirstName
+// ErrVersionConflict is returned when an If-Match precondition no longer holds
+var ErrVersionConflict = repository.ErrVersionConflict
+
+// ReplaceByEmail overwrites the whole profile with input; omitted fields are cleared.
+// A non-zero ifMatch only applies the update while the user still has that version.
+func (s *userService) ReplaceByEmail(email string, input *models.UserUpdateInput, ifMatch int64) (models.User, error) {
+	profile := models.ProfileSnapshot{
+		FirstName:          input.FirstName,
+		LastName:           input.LastName,
+		PhoneNumber:        input.PhoneNumber,
+		PhoneNumberVisible: input.PhoneNumberVisible != nil && *input.PhoneNumberVisible,
+		Country:            input.Country,
+		JobFunction:        input.JobFunction,
+		Sector:             input.Sector,
+		Biography:          input.Biography,
+		ProfilePhotoURL:    input.ProfilePhotoURL,
+		PreferredLanguage:  input.PreferredLanguage,
 	}
-	if input.LastName != "" {
-		fields["last_name"] = input.LastName
-	}
-	if input.PhoneNumber != "" {
-		fields["phone_number"] = input.PhoneNumber
-	}
-	if input.PhoneNumberVisible != nil {
-		fields["phone_number_visible"] = *input.PhoneNumberVisible
+	err := validation.Profile(&profile)
+	if err := resolveTerms(s.repo, err, &profile.Sector, &profile.JobFunction); err != nil {
+		return models.User{}, err
 	}
 
-	if input.Country != "" {
-		fields["country"] = input.Country
+	return s.repo.UpdateFieldsByEmailIfVersion(email, profile.Fields(), ifMatch)
+}
+
+// PatchByEmail applies a merge patch: only fields present in the patch change, null clears a field
+func (s *userService) PatchByEmail(email string, patch *models.UserProfilePatch, ifMatch int64) (models.User, error) {
+	var sector, jobFunction *string
+	if patch.Sector.Set {
+		sector = &patch.Sector.Value
 	}
-	if input.JobFunction != "" {
-		fields["job_function"] = input.JobFunction
+	if patch.JobFunction.Set {
+		jobFunction = &patch.JobFunction.Value
 	}
-	if input.Sector != "" {
-		fields["sector"] = input.Sector
+	err := validation.ProfilePatch(patch)
+	if err := resolveTerms(s.repo, err, sector, jobFunction); err != nil {
+		return models.User{}, err
 	}
-	if input.Biography != "" {
-		fields["biography"] = input.Biography
+
+	fields := map[string]any{}
+	for column, value := range map[string]models.PatchString{
+		"first_name":         patch.FirstName,
+		"last_name":          patch.LastName,
+		"phone_number":       patch.PhoneNumber,
+		"country":            patch.Country,
+		"job_function":       patch.JobFunction,
+		"sector":             patch.Sector,
+		"biography":          patch.Biography,
+		"profile_photo_url":  patch.ProfilePhotoURL,
+		"preferred_language": patch.PreferredLanguage,
+	} {
+		if value.Set {
+			fields[column] = value.Value
+		}
 	}
-	if input.ProfilePhotoURL != "" {
-		fields["profile_photo_url"] = input.ProfilePhotoURL
+	if patch.PhoneNumberVisible.Set {
+		fields["phone_number_visible"] = patch.PhoneNumberVisible.Value
 	}
 
-	return s.repo.UpdateFieldsByEmail(email, fields)
+	return s.repo.UpdateFieldsByEmailIfVersion(email, fields, ifMatch)
 }
 
 func (s *userService) UpdateProfilePhotoURLByKeycloakID(keycloakID, url string) error {
@@ -171,3 +275,130 @@ func IsProfileComplete(u models.User) bool {
 func (s *userService) GetByID(id uuid.UUID) (models.User, error) {
 	return s.repo.GetByID(id)
 }
+
+// generateHandle derives a free handle from the user's name, adding a numeric suffix on collisions
+func (s *userService) generateHandle(first, last string) (string, error) {
+	base := handleBaseFromName(first, last)
+	if ValidateHandle(base) != nil {
+		base = "user"
+	}
+
+	for i := 1; i < 10000; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = base + strconv.Itoa(i)
+		}
+		if ValidateHandle(candidate) != nil {
+			continue
+		}
+
+		inUse, err := s.repo.HandleInUse(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !inUse {
+			return candidate, nil
+		}
+	}
+
+	return "", errors.New("could not generate a free handle")
+}
+
+// claimableHandle normalizes and validates a handle and checks that nobody else uses or reserved it
+func (s *userService) claimableHandle(raw string, owner uuid.UUID) (string, error) {
+	handle := NormalizeHandle(raw)
+	if err := ValidateHandle(handle); err != nil {
+		return handle, err
+	}
+
+	if existing, err := s.repo.FindByHandle(handle); err == nil {
+		if existing.ID == owner && owner != uuid.Nil {
+			return handle, nil
+		}
+		return handle, ErrHandleTaken
+	}
+
+	reservation, err := s.repo.FindActiveHandleReservation(handle)
+	if err == nil && (owner == uuid.Nil || reservation.UserID != owner) {
+		return handle, ErrHandleTaken
+	}
+
+	return handle, nil
+}
+
+// ResolveHandle finds the user for a handle. redirected is true when the handle is
+// an old, still reserved handle; the returned user then carries the current handle.
+func (s *userService) ResolveHandle(handle string) (models.User, bool, error) {
+	handle = NormalizeHandle(handle)
+
+	user, err := s.repo.FindByHandle(handle)
+	if err == nil {
+		return user, false, nil
+	}
+
+	reservation, err := s.repo.FindActiveHandleReservation(handle)
+	if err != nil {
+		return models.User{}, false, err
+	}
+
+	user, err = s.repo.GetByID(reservation.UserID)
+	if err != nil {
+		return models.User{}, false, err
+	}
+	return user, true, nil
+}
+
+func (s *userService) CheckHandleAvailability(raw string) (interfaces.HandleAvailability, error) {
+	handle, err := s.claimableHandle(raw, uuid.Nil)
+	out := interfaces.HandleAvailability{Handle: handle, Available: err == nil}
+
+	switch {
+	case err == nil:
+		return out, nil
+	case errors.Is(err, ErrHandleInvalid), errors.Is(err, ErrHandleReserved), errors.Is(err, ErrHandleTaken):
+		out.Reason = err.Error()
+		return out, nil
+	default:
+		return interfaces.HandleAvailability{}, err
+	}
+}
+
+func (s *userService) ChangeHandle(email string, raw string) (models.User, error) {
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	handle, err := s.claimableHandle(raw, user.ID)
+	if err != nil {
+		return models.User{}, err
+	}
+	if handle == user.Handle {
+		return user, nil
+	}
+
+	if user.HandleChangedAt != nil && time.Since(*user.HandleChangedAt) < handleChangeCooldown() {
+		return models.User{}, ErrHandleCooldown
+	}
+
+	return s.repo.ChangeHandle(user.ID, handle, time.Now().Add(handleReservationPeriod()))
+}
+
+// BackfillHandles gives every user that registered before handles existed a generated handle
+func (s *userService) BackfillHandles() error {
+	users, err := s.repo.FindWithoutHandle()
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		handle, err := s.generateHandle(u.FirstName, u.LastName)
+		if err != nil {
+			return err
+		}
+		if _, err := s.repo.UpdateFieldsByEmail(u.Email, map[string]any{"handle": handle}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"group1-userservice/app/models"
+)
+
+// Thresholds for mapping free-text profile values to terms
+const (
+	vocabularyAutoApplyScore = 0.8 // fuzzy matches from here on are applied
+	vocabularySuggestScore   = 0.5 // below this no suggestion is given
+)
+
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// termStopWords are left out when comparing, so "ICT & Media" equals "ICT en Media"
+var termStopWords = map[string]bool{"en": true, "and": true, "de": true, "het": true, "the": true, "of": true, "van": true}
+
+// termTokens lower cases, folds accents and splits on anything that is not a letter or digit
+func termTokens(s string) []string {
+	s = accentFolder.Replace(strings.ToLower(s))
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, w := range words {
+		if !termStopWords[w] {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// editSimilarity is 1 for equal strings and 0 for completely different ones
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// tokenSimilarity is the Dice coefficient of the word sets. A word with a typo counts partly,
+// by how close it is to the best matching word.
+func tokenSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0.0
+	for _, x := range a {
+		best := 0.0
+		for _, y := range b {
+			if s := editSimilarity(x, y); s >= vocabularyAutoApplyScore && s > best {
+				best = s
+			}
+		}
+		shared += best
+	}
+	return 2 * shared / float64(len(a)+len(b))
+}
+
+// termSimilarity compares a free-text value with a key or label
+func termSimilarity(value, candidate string) float64 {
+	a, b := termTokens(value), termTokens(candidate)
+	joinedA, joinedB := strings.Join(a, " "), strings.Join(b, " ")
+	if joinedA == joinedB {
+		return 1
+	}
+	return max(editSimilarity(joinedA, joinedB), tokenSimilarity(a, b))
+}
+
+// matchTerm finds the term closest to value by its key or either label
+func matchTerm(value string, terms []models.VocabularyTerm) (key string, score float64) {
+	for _, t := range terms {
+		for _, candidate := range []string{t.Key, t.LabelNL, t.LabelEN} {
+			if s := termSimilarity(value, candidate); s > score {
+				key, score = t.Key, s
+			}
+		}
+	}
+	return key, score
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"errors"
+	"log"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+	"group1-userservice/app/validation"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrVocabularyUnknown         = apperrors.NotFound("vocabulary_unknown", "unknown vocabulary")
+	ErrVocabularyTermNotFound    = apperrors.NotFound("vocabulary_term_not_found", "term not found")
+	ErrVocabularyTermExists      = apperrors.Conflict("vocabulary_term_exists", "term already exists")
+	ErrVocabularyTermInUse       = apperrors.Conflict("vocabulary_term_in_use", "term is used by profiles")
+	ErrVocabularyMappingNotFound = apperrors.NotFound("vocabulary_mapping_not_found", "mapping not found")
+)
+
+type vocabularyService struct {
+	repo interfaces.VocabularyRepository
+}
+
+func NewVocabularyService(repo interfaces.VocabularyRepository) interfaces.VocabularyService {
+	return &vocabularyService{repo: repo}
+}
+
+func checkVocabulary(v models.Vocabulary) error {
+	if v.ProfileField() == "" {
+		return ErrVocabularyUnknown
+	}
+	return nil
+}
+
+func (s *vocabularyService) List(v models.Vocabulary) ([]models.VocabularyTerm, error) {
+	if err := checkVocabulary(v); err != nil {
+		return nil, err
+	}
+	return s.repo.List(v)
+}
+
+func (s *vocabularyService) Create(v models.Vocabulary, input interfaces.VocabularyTermInput) (*models.VocabularyTerm, error) {
+	if err := checkVocabulary(v); err != nil {
+		return nil, err
+	}
+
+	term := models.VocabularyTerm{Key: input.Key, LabelNL: input.LabelNL, LabelEN: input.LabelEN, Position: input.Position}
+	if err := validation.VocabularyTerm(&term); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Find(v, term.Key); err == nil {
+		return nil, ErrVocabularyTermExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.repo.Create(v, &term); err != nil {
+		return nil, err
+	}
+	return &term, nil
+}
+
+// Update changes the labels and position; the key stays the same because profiles refer to it
+func (s *vocabularyService) Update(v models.Vocabulary, key string, input interfaces.VocabularyTermInput) (*models.VocabularyTerm, error) {
+	if err := checkVocabulary(v); err != nil {
+		return nil, err
+	}
+
+	term := models.VocabularyTerm{Key: key, LabelNL: input.LabelNL, LabelEN: input.LabelEN, Position: input.Position}
+	if err := validation.VocabularyTerm(&term); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(v, &term); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVocabularyTermNotFound
+		}
+		return nil, err
+	}
+	return s.repo.Find(v, key)
+}
+
+// Delete removes a term that no profile uses anymore
+func (s *vocabularyService) Delete(v models.Vocabulary, key string) error {
+	if err := checkVocabulary(v); err != nil {
+		return err
+	}
+
+	users, err := s.repo.UsersWithTerm(v, key)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return ErrVocabularyTermInUse
+	}
+
+	deleted, err := s.repo.Delete(v, key)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrVocabularyTermNotFound
+	}
+	return nil
+}
+
+// MigrateProfileValues maps the free-text sectors and job functions of existing profiles to term keys.
+// Exact and close matches are applied; every value is recorded in the migration report, so the
+// fuzzy and unmatched ones can be reviewed. Running it again only looks at values that are not a key yet.
+func (s *vocabularyService) MigrateProfileValues() error {
+	for _, v := range []models.Vocabulary{models.VocabularySectors, models.VocabularyJobFunctions} {
+		terms, err := s.repo.List(v)
+		if err != nil {
+			return err
+		}
+		values, err := s.repo.UnknownProfileValues(v)
+		if err != nil {
+			return err
+		}
+
+		for _, value := range values {
+			mapping := models.VocabularyMapping{Vocabulary: v, RawValue: value.Value, UserCount: value.Users}
+
+			key, score := matchTerm(value.Value, terms)
+			mapping.Score = score
+			if score >= vocabularySuggestScore {
+				mapping.SuggestedKey = key
+			}
+
+			switch {
+			case score == 1:
+				mapping.Status = models.VocabularyMappingExact
+			case score >= vocabularyAutoApplyScore:
+				mapping.Status = models.VocabularyMappingFuzzy
+			default:
+				mapping.Status = models.VocabularyMappingUnmatched
+			}
+
+			if mapping.Status != models.VocabularyMappingUnmatched {
+				if _, err := s.repo.ReplaceProfileValue(v, value.Value, key); err != nil {
+					return err
+				}
+				mapping.AppliedKey = key
+			}
+
+			if err := s.repo.SaveMapping(&mapping); err != nil {
+				return err
+			}
+			log.Printf("[vocabulary] %s %q -> %q (%s, score %.2f, %d users)\n",
+				v, value.Value, mapping.AppliedKey, mapping.Status, score, value.Users)
+		}
+	}
+	return nil
+}
+
+func (s *vocabularyService) MigrationReport(v models.Vocabulary, status models.VocabularyMappingStatus, p interfaces.Pagination) (*interfaces.VocabularyMappingPage, error) {
+	if err := checkVocabulary(v); err != nil {
+		return nil, err
+	}
+
+	items, total, err := s.repo.ListMappings(v, status, p)
+	if err != nil {
+		return nil, err
+	}
+	return &interfaces.VocabularyMappingPage{Items: items, Page: p.Page, PageSize: p.PageSize, Total: total}, nil
+}
+
+// ApplyMapping sets the chosen term on every profile that still has the reported free-text value
+func (s *vocabularyService) ApplyMapping(v models.Vocabulary, id uint, key string) (*models.VocabularyMapping, []uuid.UUID, error) {
+	if err := checkVocabulary(v); err != nil {
+		return nil, nil, err
+	}
+
+	mapping, err := s.repo.FindMapping(v, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrVocabularyMappingNotFound
+		}
+		return nil, nil, err
+	}
+	if _, err := s.repo.Find(v, key); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrVocabularyTermNotFound
+		}
+		return nil, nil, err
+	}
+
+	changed, err := s.repo.ReplaceProfileValue(v, mapping.RawValue, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mapping.Status = models.VocabularyMappingResolved
+	mapping.AppliedKey = key
+	if err := s.repo.SaveMapping(mapping); err != nil {
+		return nil, nil, err
+	}
+	return mapping, changed, nil
+}
+
+// vocabularyFields are the profile fields that must hold a term key
+var vocabularyFields = []struct {
+	vocabulary models.Vocabulary
+	field      string
+	violation  string
+}{
+	{models.VocabularySectors, "sector", "validation.sector_unknown"},
+	{models.VocabularyJobFunctions, "job_function", "validation.job_function_unknown"},
+}
+
+// resolveTerms replaces a sector and job function (key or label) by their term key in place; nil or empty
+// values are skipped. Unknown values are added to the errors of the field validation in validationErr,
+// so every problem is reported at once.
+func resolveTerms(lookup interfaces.VocabularyLookup, validationErr error, sector, jobFunction *string) error {
+	errs := validation.Errors{}
+	if validationErr != nil && !errors.As(validationErr, &errs) {
+		return validationErr
+	}
+
+	for i, value := range []*string{sector, jobFunction} {
+		f := vocabularyFields[i]
+		if _, invalid := errs[f.field]; value == nil || *value == "" || invalid {
+			continue
+		}
+
+		term, err := lookup.FindVocabularyTerm(f.vocabulary, *value)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errs[f.field] = validation.Violation{Key: f.violation}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		*value = term.Key
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -164,3 +164,22 @@ func (s *S3) PutMultipart(
 	)
 	return err
 }
+
+// KeyFromPublicURL returns the object key of a public URL created with PublicBaseURL
+func (s *S3) KeyFromPublicURL(publicURL string) (string, bool) {
+	base := strings.TrimRight(s.PublicBaseURL, "/") + "/"
+	if !strings.HasPrefix(publicURL, base) {
+		return "", false
+	}
+	return strings.TrimPrefix(publicURL, base), true
+}
+
+// Remove an object from the bucket; removing a missing object is not an error
+func (s *S3) RemoveObject(objectKey string) error {
+	return s.internal.RemoveObject(
+		context.Background(),
+		s.Bucket,
+		objectKey,
+		minio.RemoveObjectOptions{},
+	)
+}
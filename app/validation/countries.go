@@ -0,0 +1,65 @@
+package validation
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed countries.csv
+var countriesCSV string
+
+// Country is one ISO 3166-1 entry
+type Country struct {
+	Alpha2 string `json:"code"`
+	Alpha3 string `json:"alpha3"`
+	NameEN string `json:"name_en"`
+	NameNL string `json:"name_nl"`
+}
+
+var (
+	countries      []Country
+	countryByAlpha map[string]Country
+	countryLookup  map[string]string
+)
+
+func init() {
+	countryByAlpha = map[string]Country{}
+	countryLookup = map[string]string{}
+
+	for _, line := range strings.Split(countriesCSV, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		cols := strings.Split(line, ";")
+		c := Country{Alpha2: cols[0], Alpha3: cols[1], NameEN: cols[2], NameNL: cols[3]}
+		countries = append(countries, c)
+		countryByAlpha[c.Alpha2] = c
+
+		// codes, names and aliases all resolve to the alpha-2 code
+		for _, key := range cols {
+			countryLookup[strings.ToLower(key)] = c.Alpha2
+		}
+	}
+}
+
+// NormalizeCountry resolves an ISO alpha-2 or alpha-3 code, or an English or Dutch country name,
+// to the alpha-2 code we store.
+func NormalizeCountry(raw string) (string, bool) {
+	code, ok := countryLookup[strings.ToLower(strings.TrimSpace(raw))]
+	return code, ok
+}
+
+// CountryByCode returns the ISO entry of an alpha-2 code
+func CountryByCode(alpha2 string) (Country, bool) {
+	c, ok := countryByAlpha[strings.ToUpper(alpha2)]
+	return c, ok
+}
+
+// Countries lists all ISO 3166-1 countries in table order
+func Countries() []Country {
+	out := make([]Country, len(countries))
+	copy(out, countries)
+	return out
+}
@@ -0,0 +1,50 @@
+package validation
+
+import (
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/models"
+)
+
+const MaxInterestDescriptionLength = 1000
+
+// Interest normalizes an interest and its translations in place. The key is only checked for a new
+// interest, which also needs a label in every supported language; a change may send some languages only.
+// Fields are named after the request, e.g. "translations.nl.label".
+func Interest(i *models.Interest, isNew bool) error {
+	errs := Errors{}
+
+	if isNew {
+		i.Key = text(errs, "key", i.Key, MaxTermKeyLength, false)
+		if !termKey.MatchString(i.Key) {
+			errs.add("key", "validation.term_key_invalid")
+		}
+	}
+
+	labelled := map[string]bool{}
+	for n := range i.Translations {
+		t := &i.Translations[n]
+		field := "translations." + t.Locale
+
+		t.Locale = language(errs, field, t.Locale)
+		if t.Locale == "" {
+			errs.add(field, "validation.language_unsupported", supportedLanguages())
+		}
+
+		t.Label = text(errs, field+".label", t.Label, MaxTermLabelLength, false)
+		if t.Label == "" {
+			errs.add(field+".label", "validation.label_required")
+		}
+		t.Description = text(errs, field+".description", t.Description, MaxInterestDescriptionLength, true)
+		labelled[t.Locale] = true
+	}
+
+	if isNew {
+		for _, l := range i18n.Locales() {
+			if !labelled[string(l)] {
+				errs.add("translations."+string(l)+".label", "validation.label_required")
+			}
+		}
+	}
+
+	return errs.err()
+}
@@ -0,0 +1,329 @@
+// Package validation normalizes and checks user supplied profile data before it is stored.
+package validation
+
+import (
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"group1-userservice/app/apperrors"
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/models"
+)
+
+// Field limits, counted in characters after trimming
+const (
+	MaxNameLength        = 100
+	MaxJobFunctionLength = 100
+	MaxSectorLength      = 100
+	MaxBiographyLength   = 1000
+	MaxEmailLength       = 254
+	MaxPhotoURLLength    = 2048
+	MinPasswordLength    = 6
+	// MaxPasswordBytes keeps passwords usable with both identity providers: the default Keycloak policy has no upper
+	// limit, but the local provider stores bcrypt hashes and bcrypt rejects passwords over 72 bytes.
+	MaxPasswordBytes = 72
+)
+
+// Violation is what is wrong with a field: a key of the i18n catalog and the values of its placeholders
+type Violation struct {
+	Key  string
+	Args []any
+}
+
+// Message describes the violation in l
+func (v Violation) Message(l i18n.Locale) string {
+	return i18n.T(l, v.Key, v.Args...)
+}
+
+// Errors maps a field name to what is wrong with it; all violations are reported together
+type Errors map[string]Violation
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		for _, v := range e {
+			return v.Message(i18n.English)
+		}
+	}
+
+	fields := make([]string, 0, len(e))
+	for f := range e {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		parts = append(parts, f+": "+e[f].Message(i18n.English))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Messages describes what is wrong with each field in l
+func (e Errors) Messages(l i18n.Locale) map[string]string {
+	out := make(map[string]string, len(e))
+	for f, v := range e {
+		out[f] = v.Message(l)
+	}
+	return out
+}
+
+// Is makes errors.Is(err, apperrors.ErrValidation) match, so Errors render as a validation problem
+func (e Errors) Is(target error) bool {
+	return target == apperrors.ErrValidation
+}
+
+// add keeps the first violation of a field
+func (e Errors) add(field, key string, args ...any) {
+	if _, exists := e[field]; !exists {
+		e[field] = Violation{Key: key, Args: args}
+	}
+}
+
+// err returns nil when nothing was added, so callers can return it directly
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// phoneSeparators are stripped before a number is checked
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "/", "")
+
+// NormalizePhone turns a phone number into E.164 (+31612345678). "00" is read as "+", and a national
+// number with a leading 0 gets PHONE_DEFAULT_COUNTRY_CODE (default 31).
+func NormalizePhone(raw string) (string, bool) {
+	p := phoneSeparators.Replace(strings.TrimSpace(raw))
+
+	switch {
+	case strings.HasPrefix(p, "+"):
+	case strings.HasPrefix(p, "00"):
+		p = "+" + p[2:]
+	case strings.HasPrefix(p, "0"):
+		code := os.Getenv("PHONE_DEFAULT_COUNTRY_CODE")
+		if code == "" {
+			code = "31"
+		}
+		p = "+" + code + p[1:]
+	default:
+		return "", false
+	}
+
+	if !e164.MatchString(p) {
+		return "", false
+	}
+	return p, true
+}
+
+// isForbiddenRune reports control characters, including the bidi overrides used to disguise text
+func isForbiddenRune(r rune, multiline bool) bool {
+	if r == '\n' && multiline {
+		return false
+	}
+	if unicode.IsControl(r) {
+		return true
+	}
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
+
+// text trims value and checks it for control characters and its maximum length.
+// Only multiline fields may contain newlines; Windows line endings are normalized.
+func text(errs Errors, field, value string, max int, multiline bool) string {
+	value = strings.TrimSpace(value)
+	if multiline {
+		value = strings.ReplaceAll(value, "\r\n", "\n")
+	}
+
+	if !utf8.ValidString(value) {
+		errs.add(field, "validation.invalid_utf8")
+		return value
+	}
+	for _, r := range value {
+		if isForbiddenRune(r, multiline) {
+			errs.add(field, "validation.control_characters")
+			return value
+		}
+	}
+	if utf8.RuneCountInString(value) > max {
+		errs.add(field, "validation.too_long", max)
+	}
+	return value
+}
+
+func name(errs Errors, field, value, required string) string {
+	value = text(errs, field, value, MaxNameLength, false)
+	if value == "" {
+		errs.add(field, required)
+	}
+	return value
+}
+
+func phone(errs Errors, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	normalized, ok := NormalizePhone(value)
+	if !ok {
+		errs.add("phone_number", "validation.phone_invalid")
+		return value
+	}
+	return normalized
+}
+
+func country(errs Errors, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	code, ok := NormalizeCountry(value)
+	if !ok {
+		errs.add("country", "validation.country_unknown")
+		return value
+	}
+	return code
+}
+
+func photoURL(errs Errors, value string) string {
+	value = text(errs, "profile_photo_url", value, MaxPhotoURLLength, false)
+	if value == "" {
+		return ""
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.add("profile_photo_url", "validation.url_invalid")
+	}
+	return value
+}
+
+// supportedLanguages lists the supported locales for error texts, e.g. "nl, en"
+func supportedLanguages() string {
+	supported := []string{}
+	for _, s := range i18n.Locales() {
+		supported = append(supported, string(s))
+	}
+	return strings.Join(supported, ", ")
+}
+
+// language normalizes a language tag such as "NL" or "en-GB" to a supported locale
+func language(errs Errors, field, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	l, ok := i18n.Parse(value)
+	if !ok {
+		errs.add(field, "validation.language_unsupported", supportedLanguages())
+		return value
+	}
+	return string(l)
+}
+
+// profileFields checks and normalizes every profile field in place
+func profileFields(errs Errors, p *models.ProfileSnapshot) {
+	p.FirstName = name(errs, "first_name", p.FirstName, "validation.first_name_required")
+	p.LastName = name(errs, "last_name", p.LastName, "validation.last_name_required")
+	p.PhoneNumber = phone(errs, p.PhoneNumber)
+	p.Country = country(errs, p.Country)
+	p.JobFunction = text(errs, "job_function", p.JobFunction, MaxJobFunctionLength, false)
+	p.Sector = text(errs, "sector", p.Sector, MaxSectorLength, false)
+	p.Biography = text(errs, "biography", p.Biography, MaxBiographyLength, true)
+	p.ProfilePhotoURL = photoURL(errs, p.ProfilePhotoURL)
+	p.PreferredLanguage = language(errs, "preferred_language", p.PreferredLanguage)
+}
+
+// Profile normalizes a complete profile in place. It returns Errors when a field is invalid.
+func Profile(p *models.ProfileSnapshot) error {
+	errs := Errors{}
+	profileFields(errs, p)
+	return errs.err()
+}
+
+// ProfilePatch normalizes the fields present in a merge patch in place. Names cannot be cleared.
+func ProfilePatch(p *models.UserProfilePatch) error {
+	errs := Errors{}
+
+	if p.FirstName.Set {
+		p.FirstName.Value = name(errs, "first_name", p.FirstName.Value, "validation.first_name_required")
+	}
+	if p.LastName.Set {
+		p.LastName.Value = name(errs, "last_name", p.LastName.Value, "validation.last_name_required")
+	}
+	if p.PhoneNumber.Set {
+		p.PhoneNumber.Value = phone(errs, p.PhoneNumber.Value)
+	}
+	if p.Country.Set {
+		p.Country.Value = country(errs, p.Country.Value)
+	}
+	if p.JobFunction.Set {
+		p.JobFunction.Value = text(errs, "job_function", p.JobFunction.Value, MaxJobFunctionLength, false)
+	}
+	if p.Sector.Set {
+		p.Sector.Value = text(errs, "sector", p.Sector.Value, MaxSectorLength, false)
+	}
+	if p.Biography.Set {
+		p.Biography.Value = text(errs, "biography", p.Biography.Value, MaxBiographyLength, true)
+	}
+	if p.ProfilePhotoURL.Set {
+		p.ProfilePhotoURL.Value = photoURL(errs, p.ProfilePhotoURL.Value)
+	}
+	if p.PreferredLanguage.Set {
+		p.PreferredLanguage.Value = language(errs, "preferred_language", p.PreferredLanguage.Value)
+	}
+
+	return errs.err()
+}
+
+// Password checks the password rules; the password itself is never trimmed
+func Password(password string) error {
+	errs := Errors{}
+	checkPassword(errs, password)
+	return errs.err()
+}
+
+func checkPassword(errs Errors, password string) {
+	switch {
+	case len(password) < MinPasswordLength:
+		errs.add("password", "validation.password_too_short", MinPasswordLength)
+	case len(password) > MaxPasswordBytes:
+		errs.add("password", "validation.password_too_long", MaxPasswordBytes)
+	case !strings.ContainsAny(password, "0123456789"):
+		errs.add("password", "validation.password_no_digit")
+	}
+}
+
+// Registration normalizes a new user in place: email, password and the profile fields
+func Registration(u *models.User) error {
+	errs := Errors{}
+
+	u.Email = text(errs, "email", u.Email, MaxEmailLength, false)
+	if u.Email == "" {
+		errs.add("email", "validation.email_required")
+	} else if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+		errs.add("email", "validation.email_invalid")
+	}
+
+	profile := models.SnapshotOf(*u)
+	profileFields(errs, &profile)
+	u.FirstName = profile.FirstName
+	u.LastName = profile.LastName
+	u.PhoneNumber = profile.PhoneNumber
+	u.Country = profile.Country
+	u.JobFunction = profile.JobFunction
+	u.Sector = profile.Sector
+	u.Biography = profile.Biography
+	u.ProfilePhotoURL = profile.ProfilePhotoURL
+	u.PreferredLanguage = profile.PreferredLanguage
+
+	checkPassword(errs, u.Password)
+
+	return errs.err()
+}
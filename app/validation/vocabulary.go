@@ -0,0 +1,35 @@
+package validation
+
+import (
+	"regexp"
+
+	"group1-userservice/app/models"
+)
+
+const (
+	MaxTermKeyLength   = 100
+	MaxTermLabelLength = 200
+)
+
+var termKey = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// VocabularyTerm normalizes a sector or job function term in place. Keys are lower snake case.
+func VocabularyTerm(t *models.VocabularyTerm) error {
+	errs := Errors{}
+
+	t.Key = text(errs, "key", t.Key, MaxTermKeyLength, false)
+	if !termKey.MatchString(t.Key) {
+		errs.add("key", "validation.term_key_invalid")
+	}
+
+	t.LabelNL = text(errs, "label_nl", t.LabelNL, MaxTermLabelLength, false)
+	if t.LabelNL == "" {
+		errs.add("label_nl", "validation.label_nl_required")
+	}
+	t.LabelEN = text(errs, "label_en", t.LabelEN, MaxTermLabelLength, false)
+	if t.LabelEN == "" {
+		errs.add("label_en", "validation.label_en_required")
+	}
+
+	return errs.err()
+}
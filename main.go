@@ -8,6 +8,7 @@ import (
 	"group1-userservice/app/config"
 	controller "group1-userservice/app/controllers"
 	"group1-userservice/app/middleware"
+	"group1-userservice/app/notifications"
 	"group1-userservice/app/repository"
 	"group1-userservice/app/service"
 	"group1-userservice/app/storage"
@@ -29,8 +30,12 @@ func main() {
 		log.Println("No .env file found — using environment variables")
 	}
 
-	// Init Keycloak middleware
-	middleware.InitKeycloak()
+	// Init identity provider (Keycloak or local)
+	idp, err := config.IdentityProviderFromEnv()
+	if err != nil {
+		log.Fatalf("invalid identity provider config: %v", err)
+	}
+	middleware.InitIdentityProvider(idp)
 
 	// Connect DB
 	config.ConnectDatabase()
@@ -41,9 +46,24 @@ func main() {
 	// Seed Badges
 	config.SeedBadges()
 
+	// Seed sectors and job functions
+	config.SeedVocabularies()
+
 	// Services
 	userRepo := repository.NewUserRepository(config.DB)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, idp)
+
+	// Give users that registered before handles existed a generated handle
+	if err := userService.BackfillHandles(); err != nil {
+		log.Printf("handle backfill failed: %v", err)
+	}
+
+	// Map free-text sectors and job functions of existing profiles to the managed terms
+	vocabularyRepo := repository.NewVocabularyRepository(config.DB)
+	vocabularyService := service.NewVocabularyService(vocabularyRepo)
+	if err := vocabularyService.MigrateProfileValues(); err != nil {
+		log.Printf("vocabulary migration failed: %v", err)
+	}
 
 	notifRepo := repository.NewNotificationSettingsRepository()
 	notifService := service.NewNotificationSettingsService(notifRepo)
@@ -54,21 +74,96 @@ func main() {
 	userBadgeRepo := repository.NewUserBadgeRepository(config.DB)
 	userBadgeService := service.NewUserBadgeService(userBadgeRepo)
 
+	visibilityRepo := repository.NewProfileVisibilityRepository(config.DB)
+	visibilityService := service.NewProfileVisibilityService(visibilityRepo, userService)
+	notificationURL := os.Getenv("NOTIFICATION_SERVICE_URL")
+
+	connectionRepo := repository.NewConnectionRepository(config.DB)
+	blockRepo := repository.NewUserBlockRepository(config.DB)
+	blockService := service.NewUserBlockService(blockRepo, connectionRepo, userService)
+	connectionService := service.NewConnectionService(
+		connectionRepo,
+		userService,
+		notifService,
+		notifications.NewClient(notificationURL),
+		blockService,
+	)
+
+	profileViewService := service.NewProfileViewService(
+		userService,
+		userBadgeService,
+		visibilityService,
+		service.NewBlockingRelationshipResolver(blockService, connectionService),
+		connectionService,
+	)
+
+	verificationConfig, err := service.EmailVerificationConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid email verification config: %v", err)
+	}
+	verificationRepo := repository.NewEmailVerificationRepository(config.DB)
+	verificationService := service.NewEmailVerificationService(
+		verificationRepo,
+		userService,
+		notifications.NewClient(notificationURL),
+		idp,
+		verificationConfig,
+	)
+	requireVerified := middleware.RequireVerifiedEmail(verificationService, middleware.UnverifiedAllowedRoutes())
+
+	twoFactorConfig, err := service.TwoFactorConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid two-factor config: %v", err)
+	}
+	twoFactorRepo := repository.NewTwoFactorRepository(config.DB)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userService, twoFactorConfig)
+
 	// Controllers
-	registerController := controller.NewRegisterController(userService)
-	loginController := controller.NewLoginController(userService)
-	userController := controller.NewUserController(userService, userBadgeService)
+	registerController := controller.NewRegisterController(userService, verificationService)
+	verificationController := controller.NewEmailVerificationController(verificationService, userService)
+	loginController := controller.NewLoginController(userService, twoFactorService, idp)
+	twoFactorController := controller.NewTwoFactorController(twoFactorService, userService)
+	userController := controller.NewUserController(userService, userBadgeService, profileViewService)
+	privacyController := controller.NewProfileVisibilityController(visibilityService, userService)
 	notifController := controller.NewNotificationSettingsController(notifService, userService)
 	interestsController := controller.NewUserInterestsController(interestsService, userService)
 	prefsRepo := repository.NewDiscoveryPreferencesRepository(config.DB)
 	prefsService := service.NewDiscoveryPreferencesService(prefsRepo)
 	prefsController := controller.NewDiscoveryPreferencesController(prefsService, userService)
 	badgeController := controller.NewBadgeController(userBadgeService, userService)
+	connectionController := controller.NewConnectionController(connectionService, userService)
+	blockController := controller.NewUserBlockController(blockService, userService)
+
+	historyRepo := repository.NewProfileHistoryRepository(config.DB)
+	historyService := service.NewProfileHistoryService(historyRepo)
+	historyController := controller.NewProfileHistoryController(historyService, userService, userBadgeService)
+
+	completenessConfig, err := service.CompletenessConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid profile completeness config: %v", err)
+	}
+	completenessRepo := repository.NewProfileCompletenessRepository(config.DB)
+	completenessService := service.NewProfileCompletenessService(completenessRepo, completenessConfig)
+	completenessController := controller.NewProfileCompletenessController(completenessService, userService)
+
+	onboardingRepo := repository.NewOnboardingRepository(config.DB)
+	onboardingService := service.NewOnboardingService(onboardingRepo, completenessRepo, notifService)
+	onboardingController := controller.NewOnboardingController(onboardingService, userService)
 
 	resetRepo := repository.NewPasswordResetRepository(config.DB)
-	resetService := service.NewPasswordResetService(resetRepo, userService)
-	notificationURL := os.Getenv("NOTIFICATION_SERVICE_URL")
-	resetController := controller.NewPasswordResetController(resetService, notificationURL)
+	resetService := service.NewPasswordResetService(resetRepo, userService, idp)
+	resetController := controller.NewPasswordResetController(resetService, userService, notificationURL)
+
+	magicLinkRepo := repository.NewMagicLinkRepository(config.DB)
+	magicLinkService := service.NewMagicLinkService(magicLinkRepo, userService)
+	magicLinkController := controller.NewMagicLinkController(magicLinkService, userService, twoFactorService, idp, notificationURL)
+
+	socialConfig, err := service.SocialLoginConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid social login config: %v", err)
+	}
+	socialService := service.NewSocialLoginService(idp, userService, notifService, prefsService, socialConfig)
+	socialController := controller.NewSocialLoginController(socialService, twoFactorService, verificationService)
 
 	s3, err := storage.NewS3()
 	if err != nil {
@@ -84,37 +179,104 @@ func main() {
 		time.Sleep(2 * time.Second)
 	}
 
+	reportRepo := repository.NewUserReportRepository(config.DB)
+	moderationService := service.NewModerationService(reportRepo, userService, s3, idp)
+	moderationController := controller.NewModerationController(moderationService, userService)
+
+	moderatorRole := os.Getenv("MODERATOR_ROLE")
+	if moderatorRole == "" {
+		moderatorRole = "moderator"
+	}
+
+	adminRole := os.Getenv("ADMIN_ROLE")
+	if adminRole == "" {
+		adminRole = "admin"
+	}
+
+	auditRepo := repository.NewAuditRepository(config.DB)
+	auditService := service.NewAuditService(auditRepo)
+	auditController := controller.NewAuditController(auditService)
+	vocabularyController := controller.NewVocabularyController(vocabularyService)
+	interestCatalogController := controller.NewInterestCatalogController(service.NewInterestCatalogService(interestsRepo))
+
+	// Remove old audit events in the background
+	service.StartAuditRetention(auditService)
+
 	// Router
 	router := gin.Default()
 
+	// Render errors of all handlers and middleware as application/problem+json
+	router.Use(middleware.ProblemDetails())
+
+	// Language of error texts and messages: preferred_language of the user, then Accept-Language
+	router.Use(middleware.Locale(userService))
+
 	// Prometheus metrics
 	router.Use(middleware.PrometheusMiddleware())
 
+	// Audit log recorder for all handlers
+	router.Use(middleware.AuditContext(auditService, adminRole))
+
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Public routes
 	router.POST("/users/register", registerController.Handle)
 	router.POST("/auth/login", loginController.Handle)
+	router.POST("/auth/login/2fa", loginController.HandleTwoFactor)
 	router.POST("/auth/refresh", loginController.Refresh)
 
-	router.POST("/auth/forgot-password", resetController.Forgot)
+	// Endpoints that send an email are limited per client IP
+	router.POST("/auth/forgot-password", middleware.AuthEmailRateLimit(), resetController.Forgot)
 	router.POST("/auth/reset-password", resetController.Reset)
 
+	router.POST("/auth/magic-link", middleware.AuthEmailRateLimit(), magicLinkController.Request)
+	router.POST("/auth/magic-link/redeem", magicLinkController.Redeem)
+
+	router.POST("/auth/social", socialController.Handle)
+
+	router.POST("/auth/verify-email", verificationController.Verify)
+	router.POST("/auth/verify-email/resend", verificationController.Resend)
+
+	router.GET("/vocabularies/:vocabulary", vocabularyController.List)
+
 	// public info by first and last name
 	usersProtected := router.Group("/users")
-	usersProtected.Use(middleware.AuthMiddleware())
+	usersProtected.Use(middleware.AuthMiddleware(), requireVerified)
 
 	usersProtected.GET("/:firstname/:lastname", userController.GetByFirstLast)
+	usersProtected.GET("/@:handle", userController.GetByHandle)
+	usersProtected.GET("/handle-availability", userController.CheckHandleAvailability)
 	usersProtected.GET("/id/:id/badges", userController.GetBadgesByUserID)
+	usersProtected.GET("/id/:id/profile", userController.GetPublicProfileByID)
+	usersProtected.POST("/id/:id/report", moderationController.Report)
 
-	// User routes
-	router.GET("/users/keycloak/:sub", userController.GetByKeycloakSub)
+	// User routes (own sub with Bearer token, or any sub with X-Service-Token)
+	router.GET("/users/keycloak/:sub", middleware.AuthOrServiceMiddleware(), userController.GetByKeycloakSub)
 
 	// Protected
 	protected := router.Group("/users/me")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(), requireVerified)
+	protected.GET("/notification-settings", notifController.GetForMe)
 	protected.PUT("/notification-settings", notifController.UpdateForMe)
+	protected.GET("", userController.GetMe)
 	protected.PUT("", userController.UpdateMe)
+	protected.PATCH("", userController.PatchMe)
+	protected.PUT("/handle", userController.UpdateMyHandle)
+	protected.POST("/verify-email/resend", verificationController.ResendForMe)
+
+	protected.GET("/2fa", twoFactorController.GetForMe)
+	protected.POST("/2fa/enroll", twoFactorController.Enroll)
+	protected.POST("/2fa/confirm", twoFactorController.Confirm)
+	protected.POST("/2fa/disable", twoFactorController.Disable)
+	protected.POST("/2fa/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+
+	protected.GET("/profile/history", historyController.ListForMe)
+	protected.POST("/profile/history/:version/restore", historyController.RestoreForMe)
+	protected.GET("/profile/completeness", completenessController.GetForMe)
+
+	protected.GET("/onboarding", onboardingController.GetForMe)
+	protected.POST("/onboarding/steps/:step/complete", onboardingController.CompleteStep)
+	protected.POST("/onboarding/steps/:step/skip", onboardingController.SkipStep)
 
 	protected.GET("/interests", interestsController.GetForMe)
 	protected.PUT("/interests", interestsController.UpdateForMe)
@@ -127,6 +289,51 @@ func main() {
 
 	protected.GET("/badges", userController.GetMyBadges)
 
+	protected.GET("/privacy", privacyController.GetForMe)
+	protected.PUT("/privacy", privacyController.UpdateForMe)
+
+	protected.GET("/followers", connectionController.ListFollowers)
+	protected.GET("/following", connectionController.ListFollowing)
+	protected.POST("/following/:id", connectionController.Follow)
+	protected.DELETE("/following/:id", connectionController.Unfollow)
+	protected.GET("/connections", connectionController.ListConnections)
+	protected.POST("/connections/:id", connectionController.RequestConnection)
+	protected.DELETE("/connections/:id", connectionController.RemoveConnection)
+	protected.GET("/connection-requests", connectionController.ListIncomingRequests)
+	protected.POST("/connection-requests/:requestId/accept", connectionController.AcceptRequest)
+	protected.POST("/connection-requests/:requestId/decline", connectionController.DeclineRequest)
+
+	protected.GET("/blocks", blockController.ListBlocks)
+	protected.POST("/blocks/:id", blockController.Block)
+	protected.DELETE("/blocks/:id", blockController.Unblock)
+	protected.GET("/mutes", blockController.ListMutes)
+	protected.POST("/mutes/:id", blockController.Mute)
+	protected.DELETE("/mutes/:id", blockController.Unmute)
+
+	// Moderation (Keycloak realm role)
+	moderation := router.Group("/moderation")
+	moderation.Use(middleware.AuthMiddleware(), middleware.RequireRealmRole(moderatorRole))
+	moderation.GET("/reports", moderationController.List)
+	moderation.GET("/reports/:reportId", moderationController.Get)
+	moderation.POST("/reports/:reportId/claim", moderationController.Claim)
+	moderation.POST("/reports/:reportId/resolve", moderationController.Resolve)
+
+	// Admin (Keycloak realm role)
+	admin := router.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.RequireRealmRole(adminRole))
+	admin.GET("/audit-events", auditController.Query)
+	admin.POST("/vocabularies/:vocabulary", vocabularyController.Create)
+	admin.PUT("/vocabularies/:vocabulary/:key", vocabularyController.Update)
+	admin.DELETE("/vocabularies/:vocabulary/:key", vocabularyController.Delete)
+	admin.GET("/vocabularies/:vocabulary/migration", vocabularyController.MigrationReport)
+	admin.POST("/vocabularies/:vocabulary/migration/:mappingId/apply", vocabularyController.ApplyMapping)
+	admin.GET("/interests", interestCatalogController.List)
+	admin.POST("/interests", interestCatalogController.Create)
+	admin.PUT("/interests/order", interestCatalogController.Reorder)
+	admin.PUT("/interests/:id", interestCatalogController.Update)
+	admin.POST("/interests/:id/archive", interestCatalogController.Archive)
+	admin.POST("/interests/:id/merge", interestCatalogController.Merge)
+
 	// Internal service-to-service endpoints
 	internal := router.Group("/internal")
 	internal.Use(middleware.ServiceAuthMiddleware())
@@ -134,6 +341,9 @@ func main() {
 	internal.GET("/users/:email/notification-settings", notifController.GetByEmailInternal)
 	internal.GET("/users/:email/interests", interestsController.GetForUserInternal)
 	internal.GET("/users/:email/discovery-preferences", prefsController.GetByEmailInternal)
+	internal.GET("/users/id/:id/connections", connectionController.GetConnectionIDsInternal)
+	internal.GET("/users/id/:id/blocks", blockController.GetBlockListInternal)
+	internal.GET("/users/id/:id/profile-completeness", completenessController.GetByIDInternal)
 	internal.POST("/badges/award", badgeController.Award)
 
 	// Port
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingAuditRecorder keeps recorded events in memory
+type recordingAuditRecorder struct {
+	events []models.AuditEvent
+}
+
+func (r *recordingAuditRecorder) Record(e models.AuditEvent) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func TestDiffFields_OnlyChangedFieldsWithoutSecrets(t *testing.T) {
+	before := models.User{FirstName: "Jane", LastName: "Doe", Password: "hash-1", Sector: "ICT"}
+	after := before
+	after.LastName = "Smith"
+	after.Password = "hash-2"
+
+	changes := service.DiffFields(before, after)
+
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "Doe", changes["last_name"].Before)
+	assert.Equal(t, "Smith", changes["last_name"].After)
+	_, hasPassword := changes["password"]
+	assert.False(t, hasPassword)
+}
+
+func TestDiffFields_FromNothing(t *testing.T) {
+	changes := service.DiffFields(nil, map[string]int{"radius_km": 25})
+
+	assert.Nil(t, changes["radius_km"].Before)
+	assert.Equal(t, float64(25), changes["radius_km"].After)
+}
+
+func setupAuditRouter(recorder *recordingAuditRecorder, setup gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(middleware.ProblemDetails())
+	r.Use(middleware.AuditContext(recorder, "admin"))
+	r.Use(setup)
+	r.POST("/action", func(c *gin.Context) {
+		middleware.RecordAudit(c, models.AuditEvent{Action: "test.action", TargetType: "user", TargetID: "42"})
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func TestRecordAudit_ResolvesActor(t *testing.T) {
+	cases := []struct {
+		name      string
+		setup     gin.HandlerFunc
+		header    map[string]string
+		wantType  models.AuditActorType
+		wantActor string
+	}{
+		{
+			name:     "anonymous",
+			setup:    func(c *gin.Context) { c.Next() },
+			wantType: models.AuditActorAnonymous,
+		},
+		{
+			name:      "user",
+			setup:     func(c *gin.Context) { c.Set("user_id", "kc-123"); c.Next() },
+			wantType:  models.AuditActorUser,
+			wantActor: "kc-123",
+		},
+		{
+			name: "admin",
+			setup: func(c *gin.Context) {
+				c.Set("user_id", "kc-admin")
+				c.Set("realm_roles", []string{"admin"})
+				c.Next()
+			},
+			wantType:  models.AuditActorAdmin,
+			wantActor: "kc-admin",
+		},
+		{
+			name:      "service",
+			setup:     func(c *gin.Context) { c.Set("service_auth", true); c.Next() },
+			header:    map[string]string{"X-Service-Name": "gamification-service"},
+			wantType:  models.AuditActorService,
+			wantActor: "gamification-service",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := &recordingAuditRecorder{}
+			router := setupAuditRouter(recorder, tc.setup)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/action", nil)
+			req.RemoteAddr = "203.0.113.7:5555"
+			req.Header.Set("User-Agent", "audit-test")
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Len(t, recorder.events, 1)
+
+			e := recorder.events[0]
+			assert.Equal(t, tc.wantType, e.ActorType)
+			assert.Equal(t, tc.wantActor, e.ActorID)
+			assert.Equal(t, "audit-test", e.UserAgent)
+			assert.Equal(t, "203.0.113.7", e.IP)
+		})
+	}
+}
+
+func TestRecordAudit_WithoutRecorderIsNoop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(middleware.ProblemDetails())
+	r.POST("/action", func(c *gin.Context) {
+		middleware.RecordAudit(c, models.AuditEvent{Action: "test.action"})
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/action", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestFieldChanges_RoundTrip(t *testing.T) {
+	in := models.FieldChanges{"handle": {Before: "old", After: "new"}}
+
+	v, err := in.Value()
+	assert.NoError(t, err)
+
+	var out models.FieldChanges
+	assert.NoError(t, out.Scan(v))
+	assert.Equal(t, "old", out["handle"].Before)
+	assert.Equal(t, "new", out["handle"].After)
+}
+
+func TestStartAuditRetention_DisabledWithZero(t *testing.T) {
+	os.Setenv("AUDIT_RETENTION_DAYS", "0")
+	defer os.Unsetenv("AUDIT_RETENTION_DAYS")
+
+	// with retention disabled no purge may run, so a nil service is never touched
+	service.StartAuditRetention(nil)
+}
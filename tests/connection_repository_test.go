@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"testing"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+
+	"gorm.io/gorm"
+)
+
+func setupConnectionRepoTest(t *testing.T) (*gorm.DB, interfaces.ConnectionRepository) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	if err := db.AutoMigrate(&models.User{}, &models.Connection{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return db, repository.NewConnectionRepository(db)
+}
+
+func createConnectionUser(t *testing.T, db *gorm.DB, email, first string) models.User {
+	t.Helper()
+
+	u := models.User{Email: email, FirstName: first, LastName: "Test", KeycloakID: "kc-" + email}
+	if err := db.Create(&u).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return u
+}
+
+func TestConnectionRepository_ListsAndCounts(t *testing.T) {
+	db, repo := setupConnectionRepoTest(t)
+
+	alice := createConnectionUser(t, db, "alice@example.com", "Alice")
+	bob := createConnectionUser(t, db, "bob@example.com", "Bob")
+	carol := createConnectionUser(t, db, "carol@example.com", "Carol")
+
+	rows := []models.Connection{
+		{RequesterID: bob.ID, AddresseeID: alice.ID, Type: models.ConnectionTypeFollow, Status: models.ConnectionStatusAccepted},
+		{RequesterID: carol.ID, AddresseeID: alice.ID, Type: models.ConnectionTypeFollow, Status: models.ConnectionStatusAccepted},
+		{RequesterID: alice.ID, AddresseeID: bob.ID, Type: models.ConnectionTypeMutual, Status: models.ConnectionStatusAccepted},
+		{RequesterID: carol.ID, AddresseeID: alice.ID, Type: models.ConnectionTypeMutual, Status: models.ConnectionStatusPending},
+	}
+	for i := range rows {
+		if err := repo.Save(&rows[i]); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	followers, total, err := repo.ListFollowers(alice.ID, interfaces.Pagination{Page: 1, PageSize: 1})
+	if err != nil {
+		t.Fatalf("list followers: %v", err)
+	}
+	if total != 2 || len(followers) != 1 {
+		t.Fatalf("expected 1 of 2 followers, got %d of %d", len(followers), total)
+	}
+
+	connections, _, err := repo.ListMutual(bob.ID, interfaces.Pagination{Page: 1, PageSize: 20})
+	if err != nil {
+		t.Fatalf("list connections: %v", err)
+	}
+	if len(connections) != 1 || connections[0].ID != alice.ID {
+		t.Fatalf("expected alice as bob's connection, got %+v", connections)
+	}
+
+	requests, _, err := repo.ListIncomingRequests(alice.ID, interfaces.Pagination{Page: 1, PageSize: 20})
+	if err != nil {
+		t.Fatalf("list requests: %v", err)
+	}
+	if len(requests) != 1 || requests[0].ID != carol.ID || requests[0].RequestID != rows[3].ID {
+		t.Fatalf("unexpected requests: %+v", requests)
+	}
+
+	counts, err := repo.Counts(alice.ID)
+	if err != nil {
+		t.Fatalf("counts: %v", err)
+	}
+	if counts.Followers != 2 || counts.Following != 0 || counts.Connections != 1 {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+
+	ids, err := repo.IDs(alice.ID)
+	if err != nil {
+		t.Fatalf("ids: %v", err)
+	}
+	if len(ids.Followers) != 2 || len(ids.Connections) != 1 || ids.Connections[0] != bob.ID {
+		t.Fatalf("unexpected ids: %+v", ids)
+	}
+}
@@ -0,0 +1,341 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// directoryUserService is a fakeUserService that knows a fixed set of users by ID
+type directoryUserService struct {
+	fakeUserService
+	users map[uuid.UUID]models.User
+}
+
+func (d *directoryUserService) GetByID(id uuid.UUID) (models.User, error) {
+	u, ok := d.users[id]
+	if !ok {
+		return models.User{}, errors.New("not found")
+	}
+	return u, nil
+}
+
+// fakeConnectionRepo keeps connections in memory
+type fakeConnectionRepo struct {
+	rows   map[uint]*models.Connection
+	nextID uint
+}
+
+func newFakeConnectionRepo() *fakeConnectionRepo {
+	return &fakeConnectionRepo{rows: map[uint]*models.Connection{}}
+}
+
+func (f *fakeConnectionRepo) Find(requesterID, addresseeID uuid.UUID, t models.ConnectionType) (*models.Connection, error) {
+	for _, c := range f.rows {
+		if c.RequesterID == requesterID && c.AddresseeID == addresseeID && c.Type == t {
+			return c, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeConnectionRepo) FindByID(id uint) (*models.Connection, error) {
+	c, ok := f.rows[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return c, nil
+}
+
+func (f *fakeConnectionRepo) Save(c *models.Connection) error {
+	if c.ID == 0 {
+		f.nextID++
+		c.ID = f.nextID
+	}
+	f.rows[c.ID] = c
+	return nil
+}
+
+func (f *fakeConnectionRepo) Delete(id uint) error {
+	delete(f.rows, id)
+	return nil
+}
+
+func (f *fakeConnectionRepo) DeleteBetween(a, b uuid.UUID) error {
+	for id, c := range f.rows {
+		if (c.RequesterID == a && c.AddresseeID == b) || (c.RequesterID == b && c.AddresseeID == a) {
+			delete(f.rows, id)
+		}
+	}
+	return nil
+}
+
+func (f *fakeConnectionRepo) FindAcceptedMutual(a, b uuid.UUID) (*models.Connection, error) {
+	for _, c := range f.rows {
+		if c.Type != models.ConnectionTypeMutual || c.Status != models.ConnectionStatusAccepted {
+			continue
+		}
+		if (c.RequesterID == a && c.AddresseeID == b) || (c.RequesterID == b && c.AddresseeID == a) {
+			return c, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeConnectionRepo) ListFollowers(uuid.UUID, interfaces.Pagination) ([]models.ConnectionUser, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeConnectionRepo) ListFollowing(uuid.UUID, interfaces.Pagination) ([]models.ConnectionUser, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeConnectionRepo) ListMutual(uuid.UUID, interfaces.Pagination) ([]models.ConnectionUser, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeConnectionRepo) ListIncomingRequests(uuid.UUID, interfaces.Pagination) ([]models.ConnectionUser, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeConnectionRepo) Counts(uuid.UUID) (models.ConnectionCounts, error) {
+	return models.ConnectionCounts{}, nil
+}
+
+func (f *fakeConnectionRepo) IDs(userID uuid.UUID) (interfaces.UserConnectionIDs, error) {
+	return interfaces.UserConnectionIDs{UserID: userID}, nil
+}
+
+// fakeNotificationSettingsService returns the same settings for every email
+type fakeNotificationSettingsService struct {
+	settings models.NotificationSettings
+	saved    *models.NotificationSettings
+}
+
+func (f *fakeNotificationSettingsService) GetByEmail(email string) (*models.NotificationSettings, error) {
+	s := f.settings
+	s.UserEmail = email
+	return &s, nil
+}
+
+func (f *fakeNotificationSettingsService) UpdateForEmail(email string, input interfaces.NotificationSettingsInput) (*models.NotificationSettings, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeNotificationSettingsService) Upsert(settings *models.NotificationSettings) (*models.NotificationSettings, error) {
+	f.saved = settings
+	return settings, nil
+}
+
+func (f *fakeNotificationSettingsService) UpdateIfVersion(settings *models.NotificationSettings, version int64) (*models.NotificationSettings, error) {
+	return settings, nil
+}
+
+// recordingSender remembers every notification instead of sending it
+type recordingSender struct {
+	sent []models.Notification
+}
+
+func (r *recordingSender) Send(n models.Notification) error {
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+type connectionTestEnv struct {
+	svc    interfaces.ConnectionService
+	blocks interfaces.UserBlockService
+	repo   *fakeConnectionRepo
+	sender *recordingSender
+	alice  models.User
+	bob    models.User
+}
+
+func newConnectionTestEnv(settings models.NotificationSettings) connectionTestEnv {
+	alice := models.User{ID: uuid.New(), Email: "alice@example.com", FirstName: "Alice", LastName: "A", Handle: "alice"}
+	bob := models.User{ID: uuid.New(), Email: "bob@example.com", FirstName: "Bob", LastName: "B", Handle: "bob"}
+
+	users := &directoryUserService{users: map[uuid.UUID]models.User{alice.ID: alice, bob.ID: bob}}
+	repo := newFakeConnectionRepo()
+	sender := &recordingSender{}
+
+	blocks := service.NewUserBlockService(newFakeUserBlockRepo(), repo, users)
+
+	svc := service.NewConnectionService(repo, users, &fakeNotificationSettingsService{settings: settings}, sender, blocks)
+	return connectionTestEnv{svc: svc, blocks: blocks, repo: repo, sender: sender, alice: alice, bob: bob}
+}
+
+func allConnectionChannels() models.NotificationSettings {
+	return models.NotificationSettings{ConnectionEmail: true, ConnectionPush: true}
+}
+
+func TestConnectionService_FollowIsIdempotentAndNotifies(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+
+	first, err := env.svc.Follow(env.alice, env.bob.ID)
+	if err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	second, err := env.svc.Follow(env.alice, env.bob.ID)
+	if err != nil {
+		t.Fatalf("second follow: %v", err)
+	}
+
+	if first.ID != second.ID || len(env.repo.rows) != 1 {
+		t.Fatalf("expected a single follow edge, got %d", len(env.repo.rows))
+	}
+	if first.Status != models.ConnectionStatusAccepted {
+		t.Fatalf("follow should be accepted immediately, got %s", first.Status)
+	}
+	if len(env.sender.sent) != 1 || env.sender.sent[0].Email != env.bob.Email {
+		t.Fatalf("expected one notification to bob, got %+v", env.sender.sent)
+	}
+}
+
+func TestConnectionService_CannotConnectWithSelf(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+
+	if _, err := env.svc.Follow(env.alice, env.alice.ID); !errors.Is(err, service.ErrConnectionSelf) {
+		t.Fatalf("expected ErrConnectionSelf, got %v", err)
+	}
+	if _, err := env.svc.RequestConnection(env.alice, env.alice.ID); !errors.Is(err, service.ErrConnectionSelf) {
+		t.Fatalf("expected ErrConnectionSelf, got %v", err)
+	}
+}
+
+func TestConnectionService_RequestAcceptFlow(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+
+	req, err := env.svc.RequestConnection(env.alice, env.bob.ID)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if req.Status != models.ConnectionStatusPending {
+		t.Fatalf("expected pending, got %s", req.Status)
+	}
+
+	if _, err := env.svc.RequestConnection(env.alice, env.bob.ID); !errors.Is(err, service.ErrConnectionRequestPending) {
+		t.Fatalf("expected ErrConnectionRequestPending, got %v", err)
+	}
+
+	// only the addressee may respond
+	if _, err := env.svc.RespondToRequest(env.alice, req.ID, true); !errors.Is(err, service.ErrConnectionRequestNotFound) {
+		t.Fatalf("expected ErrConnectionRequestNotFound for requester, got %v", err)
+	}
+
+	accepted, err := env.svc.RespondToRequest(env.bob, req.ID, true)
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if accepted.Status != models.ConnectionStatusAccepted || accepted.RespondedAt == nil {
+		t.Fatalf("expected accepted with responded_at, got %+v", accepted)
+	}
+
+	rel, err := env.svc.Relationship(env.bob.ID, env.alice.ID)
+	if err != nil || rel != models.RelationshipConnection {
+		t.Fatalf("expected connection relationship, got %s (%v)", rel, err)
+	}
+
+	if _, err := env.svc.RequestConnection(env.bob, env.alice.ID); !errors.Is(err, service.ErrConnectionExists) {
+		t.Fatalf("expected ErrConnectionExists, got %v", err)
+	}
+
+	// request to bob + acceptance to alice
+	if len(env.sender.sent) != 2 || env.sender.sent[1].Type != "connection_accepted" {
+		t.Fatalf("unexpected notifications: %+v", env.sender.sent)
+	}
+}
+
+func TestConnectionService_ReverseRequestAutoAccepts(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+
+	if _, err := env.svc.RequestConnection(env.alice, env.bob.ID); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+
+	c, err := env.svc.RequestConnection(env.bob, env.alice.ID)
+	if err != nil {
+		t.Fatalf("reverse request: %v", err)
+	}
+	if c.Status != models.ConnectionStatusAccepted || c.RequesterID != env.alice.ID {
+		t.Fatalf("expected alice's request to be accepted, got %+v", c)
+	}
+	if len(env.repo.rows) != 1 {
+		t.Fatalf("expected a single connection row, got %d", len(env.repo.rows))
+	}
+}
+
+func TestConnectionService_DeclinedRequestCanBeResent(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+
+	req, _ := env.svc.RequestConnection(env.alice, env.bob.ID)
+	if _, err := env.svc.RespondToRequest(env.bob, req.ID, false); err != nil {
+		t.Fatalf("decline: %v", err)
+	}
+
+	if rel, _ := env.svc.Relationship(env.alice.ID, env.bob.ID); rel != models.RelationshipPublic {
+		t.Fatalf("declined request must not connect, got %s", rel)
+	}
+
+	again, err := env.svc.RequestConnection(env.alice, env.bob.ID)
+	if err != nil {
+		t.Fatalf("resend: %v", err)
+	}
+	if again.ID != req.ID || again.Status != models.ConnectionStatusPending || again.RespondedAt != nil {
+		t.Fatalf("expected the declined request to be pending again, got %+v", again)
+	}
+}
+
+func TestConnectionService_RemoveConnection(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+
+	req, _ := env.svc.RequestConnection(env.alice, env.bob.ID)
+	_, _ = env.svc.RespondToRequest(env.bob, req.ID, true)
+
+	if err := env.svc.RemoveConnection(env.bob, env.alice.ID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := env.svc.RemoveConnection(env.bob, env.alice.ID); !errors.Is(err, service.ErrConnectionNotFound) {
+		t.Fatalf("expected ErrConnectionNotFound, got %v", err)
+	}
+}
+
+func TestConnectionService_RespectsNotificationSettings(t *testing.T) {
+	env := newConnectionTestEnv(models.NotificationSettings{ConnectionEmail: false, ConnectionPush: false})
+
+	if _, err := env.svc.Follow(env.alice, env.bob.ID); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if len(env.sender.sent) != 0 {
+		t.Fatalf("expected no notifications, got %+v", env.sender.sent)
+	}
+
+	env = newConnectionTestEnv(models.NotificationSettings{ConnectionEmail: false, ConnectionPush: true})
+	if _, err := env.svc.Follow(env.alice, env.bob.ID); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if len(env.sender.sent) != 1 {
+		t.Fatalf("expected one notification, got %d", len(env.sender.sent))
+	}
+	ch := env.sender.sent[0].Channels
+	if ch["email"] != false || ch["push"] != true {
+		t.Fatalf("unexpected channels: %+v", ch)
+	}
+}
+
+func TestNormalizePagination(t *testing.T) {
+	p := service.NormalizePagination(0, 0)
+	if p.Page != 1 || p.PageSize != service.DefaultConnectionPageSize {
+		t.Fatalf("unexpected defaults: %+v", p)
+	}
+
+	p = service.NormalizePagination(3, 1000)
+	if p.PageSize != service.MaxConnectionPageSize || p.Offset() != 2*service.MaxConnectionPageSize {
+		t.Fatalf("unexpected clamp: %+v", p)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"group1-userservice/app/config"
 	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/middleware"
 	"group1-userservice/app/models"
 	"group1-userservice/app/repository"
 	"group1-userservice/app/service"
@@ -27,6 +28,7 @@ func setupDiscoveryPrefsTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 
 	if err := config.DB.AutoMigrate(
 		&models.User{},
+		&models.ProfileVersion{},
 		&models.DiscoveryPreferences{},
 	); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
@@ -34,7 +36,7 @@ func setupDiscoveryPrefsTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 
 	// Real services
 	userRepo := repository.NewUserRepository(config.DB)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, newTestIdentityProvider(t))
 
 	prefsRepo := repository.NewDiscoveryPreferencesRepository(config.DB)
 	prefsService := service.NewDiscoveryPreferencesService(prefsRepo)
@@ -42,6 +44,7 @@ func setupDiscoveryPrefsTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 	prefsController := controller.NewDiscoveryPreferencesController(prefsService, userService)
 
 	router := gin.Default()
+	router.Use(middleware.ProblemDetails())
 
 	// Test-only middleware stub: mimics AuthMiddleware by setting "sub"/"user_id" in context
 	router.Use(func(c *gin.Context) {
@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVerificationRepo marks users verified in the user directory it shares with the user service
+type fakeVerificationRepo struct {
+	users *accountUserService
+	sends map[uuid.UUID]models.EmailVerificationSend
+}
+
+func (f *fakeVerificationRepo) MarkVerified(userID uuid.UUID, at time.Time) error {
+	u := f.users.users[userID]
+	u.EmailVerifiedAt = &at
+	f.users.users[userID] = u
+	return nil
+}
+
+func (f *fakeVerificationRepo) FindSend(userID uuid.UUID) (*models.EmailVerificationSend, error) {
+	send, ok := f.sends[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &send, nil
+}
+
+func (f *fakeVerificationRepo) SaveSend(send *models.EmailVerificationSend) error {
+	f.sends[send.UserID] = *send
+	return nil
+}
+
+// accountUserService finds users by ID, email and Keycloak sub
+type accountUserService struct {
+	directoryUserService
+}
+
+func (a *accountUserService) GetByEmail(email string) (models.User, error) {
+	for _, u := range a.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return models.User{}, service.ErrUserNotFound
+}
+
+func (a *accountUserService) GetByKeycloakID(sub string) (models.User, error) {
+	for _, u := range a.users {
+		if u.KeycloakID == sub {
+			return u, nil
+		}
+	}
+	return models.User{}, service.ErrUserNotFound
+}
+
+// recordingMarker remembers which Keycloak users were marked verified
+type recordingMarker struct {
+	marked []string
+}
+
+func (r *recordingMarker) MarkEmailVerified(keycloakID string) error {
+	r.marked = append(r.marked, keycloakID)
+	return nil
+}
+
+type verificationTestEnv struct {
+	svc    interfaces.EmailVerificationService
+	users  *accountUserService
+	sender *recordingSender
+	marker *recordingMarker
+	user   models.User
+}
+
+func newVerificationTestEnv(cfg service.EmailVerificationConfig) verificationTestEnv {
+	user := models.User{ID: uuid.New(), KeycloakID: "kc-123", Email: "john@example.com"}
+	users := &accountUserService{directoryUserService{users: map[uuid.UUID]models.User{user.ID: user}}}
+	env := verificationTestEnv{
+		users:  users,
+		sender: &recordingSender{},
+		marker: &recordingMarker{},
+		user:   user,
+	}
+	repo := &fakeVerificationRepo{users: users, sends: map[uuid.UUID]models.EmailVerificationSend{}}
+	env.svc = service.NewEmailVerificationService(repo, users, env.sender, env.marker, cfg)
+	return env
+}
+
+func verificationConfig() service.EmailVerificationConfig {
+	return service.EmailVerificationConfig{
+		Secret:         []byte("test-secret"),
+		TTL:            time.Hour,
+		ResendCooldown: 0,
+		MaxSendsPerDay: 5,
+	}
+}
+
+func TestEmailVerification_SendAndVerify(t *testing.T) {
+	env := newVerificationTestEnv(verificationConfig())
+
+	verified, err := env.svc.IsEmailVerified("kc-123")
+	assert.NoError(t, err)
+	assert.False(t, verified)
+
+	assert.NoError(t, env.svc.Send(env.user))
+	assert.Len(t, env.sender.sent, 1)
+	assert.Equal(t, "john@example.com", env.sender.sent[0].Email)
+	token := env.sender.sent[0].Token
+
+	user, err := env.svc.Verify(token)
+	assert.NoError(t, err)
+	assert.NotNil(t, user.EmailVerifiedAt)
+	assert.Equal(t, []string{"kc-123"}, env.marker.marked)
+
+	verified, err = env.svc.IsEmailVerified("kc-123")
+	assert.NoError(t, err)
+	assert.True(t, verified)
+
+	// the link can be followed again, Keycloak is only updated once
+	_, err = env.svc.Verify(token)
+	assert.NoError(t, err)
+	assert.Len(t, env.marker.marked, 1)
+
+	assert.True(t, errors.Is(env.svc.Send(env.users.users[env.user.ID]), service.ErrEmailAlreadyVerified))
+}
+
+func TestEmailVerification_RejectsBadTokens(t *testing.T) {
+	env := newVerificationTestEnv(verificationConfig())
+	assert.NoError(t, env.svc.Send(env.user))
+	token := env.sender.sent[0].Token
+
+	for _, bad := range []string{"", "abc", token + "x", "x" + token, strings.Replace(token, ".", ".A", 1)} {
+		_, err := env.svc.Verify(bad)
+		assert.True(t, errors.Is(err, service.ErrVerificationTokenInvalid), bad)
+	}
+
+	// a token signed with another secret is not accepted
+	other := newVerificationTestEnv(service.EmailVerificationConfig{Secret: []byte("other"), TTL: time.Hour, MaxSendsPerDay: 1})
+	other.users.users = env.users.users
+	_, err := other.svc.Verify(token)
+	assert.True(t, errors.Is(err, service.ErrVerificationTokenInvalid))
+
+	// changing the email address invalidates links sent to the old one
+	u := env.users.users[env.user.ID]
+	u.Email = "someone@example.com"
+	env.users.users[env.user.ID] = u
+	_, err = env.svc.Verify(token)
+	assert.True(t, errors.Is(err, service.ErrVerificationTokenInvalid))
+	assert.Empty(t, env.marker.marked)
+}
+
+func TestEmailVerification_ExpiredToken(t *testing.T) {
+	cfg := verificationConfig()
+	cfg.TTL = -time.Minute
+	env := newVerificationTestEnv(cfg)
+
+	assert.NoError(t, env.svc.Send(env.user))
+	_, err := env.svc.Verify(env.sender.sent[0].Token)
+	assert.True(t, errors.Is(err, service.ErrVerificationTokenExpired))
+}
+
+func TestEmailVerification_ResendThrottle(t *testing.T) {
+	cfg := verificationConfig()
+	cfg.ResendCooldown = time.Minute
+	env := newVerificationTestEnv(cfg)
+
+	assert.NoError(t, env.svc.Send(env.user))
+	assert.True(t, errors.Is(env.svc.Send(env.user), service.ErrVerificationThrottled))
+	assert.Len(t, env.sender.sent, 1)
+
+	// without cooldown the daily maximum still applies
+	cfg = verificationConfig()
+	cfg.MaxSendsPerDay = 2
+	env = newVerificationTestEnv(cfg)
+	assert.NoError(t, env.svc.Resend("john@example.com"))
+	assert.NoError(t, env.svc.Resend("john@example.com"))
+	assert.True(t, errors.Is(env.svc.Resend("john@example.com"), service.ErrVerificationThrottled))
+	assert.Len(t, env.sender.sent, 2)
+
+	// unknown addresses are ignored without an error
+	assert.NoError(t, env.svc.Resend("nobody@example.com"))
+	assert.Len(t, env.sender.sent, 2)
+}
+
+func TestEmailVerificationConfigFromEnv(t *testing.T) {
+	t.Setenv("EMAIL_VERIFICATION_SECRET", "")
+	_, err := service.EmailVerificationConfigFromEnv()
+	assert.True(t, errors.Is(err, service.ErrVerificationSecretRequired))
+
+	t.Setenv("EMAIL_VERIFICATION_SECRET", "s3cret")
+	t.Setenv("EMAIL_VERIFICATION_TTL_HOURS", "48")
+	t.Setenv("EMAIL_VERIFICATION_RESEND_COOLDOWN_SECONDS", "30")
+	cfg, err := service.EmailVerificationConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, cfg.TTL)
+	assert.Equal(t, 30*time.Second, cfg.ResendCooldown)
+	assert.Equal(t, 5, cfg.MaxSendsPerDay)
+
+	t.Setenv("EMAIL_VERIFICATION_MAX_SENDS_PER_DAY", "0")
+	_, err = service.EmailVerificationConfigFromEnv()
+	assert.Error(t, err)
+}
+
+func TestRequireVerifiedEmail_AllowedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("UNVERIFIED_ALLOWED_ROUTES", "GET /users/me, * /users/me/onboarding/*")
+
+	env := newVerificationTestEnv(verificationConfig())
+
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	group := router.Group("/users/me")
+	group.Use(
+		func(c *gin.Context) {
+			c.Set("user_id", "kc-123")
+			c.Set("email_verified", c.GetHeader("X-TEST-VERIFIED-CLAIM") == "true")
+			c.Next()
+		},
+		middleware.RequireVerifiedEmail(env.svc, middleware.UnverifiedAllowedRoutes()),
+	)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	group.GET("", ok)
+	group.PUT("", ok)
+	group.GET("/onboarding", ok)
+	group.POST("/onboarding/steps/:step/skip", ok)
+	group.GET("/followers", ok)
+
+	do := func(method, path string, claim bool) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		if claim {
+			req.Header.Set("X-TEST-VERIFIED-CLAIM", "true")
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/users/me", false))
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPut, "/users/me", false))
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/users/me/onboarding", false))
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/users/me/onboarding/steps/photo/skip", false))
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/users/me/followers", false))
+
+	// a token with email_verified passes, and so does an older token once the account is verified
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/users/me/followers", true))
+	assert.NoError(t, env.svc.Send(env.user))
+	_, err := env.svc.Verify(env.sender.sent[0].Token)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/users/me/followers", false))
+}
+
+func TestEmailVerificationRepository_MarkVerifiedBumpsVersion(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	users := repository.NewUserRepository(db)
+	user := models.User{Email: "verify-version@example.com", KeycloakID: "kc-verify-version", FirstName: "Vera", LastName: "Fied"}
+	assert.NoError(t, users.Create(&user))
+
+	verifications := repository.NewEmailVerificationRepository(db)
+	assert.NoError(t, verifications.MarkVerified(user.ID, time.Now()))
+	// a second verification changes nothing
+	assert.NoError(t, verifications.MarkVerified(user.ID, time.Now()))
+
+	verified, err := users.FindByEmail(user.Email)
+	assert.NoError(t, err)
+	assert.NotNil(t, verified.EmailVerifiedAt)
+	assert.Equal(t, int64(2), verified.Version)
+}
@@ -7,6 +7,7 @@ import (
 
 	"group1-userservice/app/config"
 	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/middleware"
 	"group1-userservice/app/models"
 	"group1-userservice/app/repository"
 	"group1-userservice/app/service"
@@ -24,7 +25,7 @@ func TestEndToEnd_GetUserByEmail_Success(t *testing.T) {
 	config.DB = db
 
 	// migrate
-	if err := config.DB.AutoMigrate(&models.User{}); err != nil {
+	if err := config.DB.AutoMigrate(&models.User{}, &models.ProfileVersion{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 
@@ -42,12 +43,15 @@ func TestEndToEnd_GetUserByEmail_Success(t *testing.T) {
 
 	// real service stack
 	userRepo := repository.NewUserRepository(config.DB)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, newTestIdentityProvider(t))
 	fakeBadges := &fakeBadgeService{}
-	userController := controller.NewUserController(userService, fakeBadges)
+	visibilityService := service.NewProfileVisibilityService(repository.NewProfileVisibilityRepository(config.DB), userService)
+	profileViews := service.NewProfileViewService(userService, fakeBadges, visibilityService, service.NewSelfRelationshipResolver(), nil)
+	userController := controller.NewUserController(userService, fakeBadges, profileViews)
 
 	// real HTTP router + route
 	router := gin.Default()
+	router.Use(middleware.ProblemDetails())
 	router.GET("/users/:email", userController.GetByEmail)
 
 	// perform HTTP request
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"group1-userservice/app/config"
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// versionedUserService serves one user at a fixed version and remembers the If-Match version it got
+type versionedUserService struct {
+	fakeUserService
+	user     models.User
+	gotMatch int64
+	conflict bool
+}
+
+func (v *versionedUserService) GetByKeycloakID(sub string) (models.User, error) {
+	return v.user, nil
+}
+
+func (v *versionedUserService) ReplaceByEmail(email string, input *models.UserUpdateInput, ifMatch int64) (models.User, error) {
+	v.gotMatch = ifMatch
+	if v.conflict {
+		return models.User{}, service.ErrVersionConflict
+	}
+	updated := v.user
+	updated.FirstName = input.FirstName
+	updated.LastName = input.LastName
+	updated.Version++
+	return updated, nil
+}
+
+func setupETagRouter(us *versionedUserService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	uc := controller.NewUserController(us, &fakeBadgeService{}, nil)
+
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.Use(func(c *gin.Context) { c.Set("user_id", "kc-etag") })
+	router.GET("/users/me", uc.GetMe)
+	router.PUT("/users/me", uc.UpdateMe)
+	return router
+}
+
+func TestGetMe_ETagAndIfNoneMatch(t *testing.T) {
+	us := &versionedUserService{user: models.User{ID: uuid.New(), FirstName: "Jane", Password: "hash", Version: 3}}
+	router := setupETagRouter(us)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/me", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.NotContains(t, w.Body.String(), "hash")
+
+	for header, want := range map[string]int{
+		`"3"`:        http.StatusNotModified,
+		`W/"3"`:      http.StatusNotModified,
+		`"1", "3"`:   http.StatusNotModified,
+		`*`:          http.StatusNotModified,
+		`"2"`:        http.StatusOK,
+		`"garbage"`:  http.StatusOK,
+		`W/"2", "4"`: http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/users/me", nil)
+		req.Header.Set("If-None-Match", header)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, header)
+	}
+}
+
+func TestUpdateMe_IfMatch(t *testing.T) {
+	body := `{"first_name": "Jane", "last_name": "Doe"}`
+
+	t.Run("stale etag is rejected before updating", func(t *testing.T) {
+		us := &versionedUserService{user: models.User{Version: 3}, gotMatch: -1}
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/users/me", bytes.NewBufferString(body))
+		req.Header.Set("If-Match", `"2"`)
+		setupETagRouter(us).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Equal(t, int64(-1), us.gotMatch)
+	})
+
+	t.Run("weak etags never match", func(t *testing.T) {
+		us := &versionedUserService{user: models.User{Version: 3}}
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/users/me", bytes.NewBufferString(body))
+		req.Header.Set("If-Match", `W/"3"`)
+		setupETagRouter(us).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("matching etag is passed on and the new one returned", func(t *testing.T) {
+		us := &versionedUserService{user: models.User{Version: 3}}
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/users/me", bytes.NewBufferString(body))
+		req.Header.Set("If-Match", `"3"`)
+		setupETagRouter(us).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(3), us.gotMatch)
+		assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("without If-Match the update is unconditional", func(t *testing.T) {
+		us := &versionedUserService{user: models.User{Version: 3}, gotMatch: -1}
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/users/me", bytes.NewBufferString(body))
+		setupETagRouter(us).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(0), us.gotMatch)
+	})
+
+	t.Run("a concurrent write between check and update is a 412", func(t *testing.T) {
+		us := &versionedUserService{user: models.User{Version: 3}, conflict: true}
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/users/me", bytes.NewBufferString(body))
+		req.Header.Set("If-Match", `"3"`)
+		setupETagRouter(us).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+func TestConditionalUpdates_CheckVersion(t *testing.T) {
+	db := openTestDB(t)
+	config.DB = db
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.ProfileVersion{},
+		&models.NotificationSettings{},
+		&models.DiscoveryPreferences{},
+	); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	users := repository.NewUserRepository(db)
+	user := models.User{Email: "etag@example.com", KeycloakID: "kc-etag", FirstName: "Jane", LastName: "Doe"}
+	assert.NoError(t, users.Create(&user))
+
+	updated, err := users.UpdateFieldsByEmailIfVersion(user.Email, map[string]any{"biography": "one"}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+
+	_, err = users.UpdateFieldsByEmailIfVersion(user.Email, map[string]any{"biography": "two"}, 1)
+	assert.True(t, errors.Is(err, repository.ErrVersionConflict))
+
+	fromDB, _ := users.FindByEmail(user.Email)
+	assert.Equal(t, "one", fromDB.Biography)
+
+	notif := repository.NewNotificationSettingsRepository()
+	settings, err := notif.Upsert(&models.NotificationSettings{UserEmail: user.Email, LikeEmail: true})
+	assert.NoError(t, err)
+	saved, err := notif.UpdateIfVersion(&models.NotificationSettings{UserEmail: user.Email}, settings.Version)
+	assert.NoError(t, err)
+	assert.Equal(t, settings.Version+1, saved.Version)
+	_, err = notif.UpdateIfVersion(&models.NotificationSettings{UserEmail: user.Email}, settings.Version)
+	assert.True(t, errors.Is(err, repository.ErrVersionConflict))
+
+	prefs := repository.NewDiscoveryPreferencesRepository(db)
+	p, err := prefs.Upsert(&models.DiscoveryPreferences{Email: user.Email, RadiusKm: 10})
+	assert.NoError(t, err)
+	p2, err := prefs.Upsert(&models.DiscoveryPreferences{Email: user.Email, RadiusKm: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, p.Version+1, p2.Version)
+	_, err = prefs.UpdateIfVersion(&models.DiscoveryPreferences{Email: user.Email, RadiusKm: 30}, p.Version)
+	assert.True(t, errors.Is(err, repository.ErrVersionConflict))
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"group1-userservice/app/interfaces"
 	"group1-userservice/app/models"
 
 	"github.com/google/uuid"
@@ -23,12 +24,20 @@ func (f *fakeUserService) Register(user *models.User) error {
 	return nil
 }
 
+func (f *fakeUserService) RegisterFromIdentity(user *models.User) error {
+	return nil
+}
+
 func (f *fakeUserService) GetByEmail(email string) (models.User, error) {
 	return models.User{}, errors.New("not implemented")
 }
 
-func (f *fakeUserService) CheckPassword(hash string, raw string) bool {
-	return true
+func (f *fakeUserService) VerifyPassword(email string, password string) error {
+	return nil
+}
+
+func (f *fakeUserService) RetireLegacyPassword(user models.User, plainPassword string) error {
+	return nil
 }
 
 func (f *fakeUserService) GetByKeycloakID(sub string) (models.User, error) {
@@ -39,8 +48,25 @@ func (f *fakeUserService) GetPublicInfoByFirstLast(firstName, lastName string) (
 	return nil, errors.New("not implemented")
 }
 
-func (f *fakeUserService) UpdatePasswordByEmail(email string, newPlainPassword string) error {
-	return nil
+// stubIdentityProvider answers token requests from its funcs when set and passes everything else on
+type stubIdentityProvider struct {
+	interfaces.IdentityProvider
+	grant       func(email, password string) (*interfaces.TokenResponse, error)
+	impersonate func(subject string) (*interfaces.TokenResponse, error)
+}
+
+func (s *stubIdentityProvider) PasswordGrant(email, password string) (*interfaces.TokenResponse, error) {
+	if s.grant != nil {
+		return s.grant(email, password)
+	}
+	return s.IdentityProvider.PasswordGrant(email, password)
+}
+
+func (s *stubIdentityProvider) Impersonate(subject string) (*interfaces.TokenResponse, error) {
+	if s.impersonate != nil {
+		return s.impersonate(subject)
+	}
+	return s.IdentityProvider.Impersonate(subject)
 }
 
 // fakeResetRepo is a minimal in-memory reset repository for service tests
@@ -81,6 +107,14 @@ func (f *fakeUserService) UpdateByEmail(email string, input *models.UserUpdateIn
 	return u, nil
 }
 
+func (f *fakeUserService) ReplaceByEmail(email string, input *models.UserUpdateInput, ifMatch int64) (models.User, error) {
+	return f.UpdateByEmail(email, input)
+}
+
+func (f *fakeUserService) PatchByEmail(email string, patch *models.UserProfilePatch, ifMatch int64) (models.User, error) {
+	return models.User{Email: email, FirstName: patch.FirstName.Value, LastName: patch.LastName.Value}, nil
+}
+
 func (f *fakeUserService) UpdateProfilePhotoURLByKeycloakID(keycloakID, url string) error {
 	return nil
 }
@@ -96,6 +130,45 @@ func (f *fakeBadgeService) AwardBadge(userID uuid.UUID, badgeKey string) (bool,
 	return false, nil
 }
 
+func (f *fakeBadgeService) RevokeBadge(userID uuid.UUID, badgeKey string) (bool, error) {
+	return false, nil
+}
+
 func (f *fakeBadgeService) GetBadgesForUser(userID uuid.UUID) ([]models.UserBadge, error) {
 	return []models.UserBadge{}, nil
 }
+
+func (f *fakeUserService) ResolveHandle(handle string) (models.User, bool, error) {
+	return models.User{}, false, errors.New("not implemented")
+}
+
+func (f *fakeUserService) CheckHandleAvailability(handle string) (interfaces.HandleAvailability, error) {
+	return interfaces.HandleAvailability{Handle: handle, Available: true}, nil
+}
+
+func (f *fakeUserService) ChangeHandle(email string, handle string) (models.User, error) {
+	return models.User{Email: email, Handle: handle}, nil
+}
+
+func (f *fakeUserService) BackfillHandles() error {
+	return nil
+}
+
+// fakeEmailVerification sends nothing and treats every account as verified
+type fakeEmailVerification struct{}
+
+func (f *fakeEmailVerification) Send(user models.User) error {
+	return nil
+}
+
+func (f *fakeEmailVerification) Resend(email string) error {
+	return nil
+}
+
+func (f *fakeEmailVerification) Verify(token string) (models.User, error) {
+	return models.User{}, errors.New("not implemented")
+}
+
+func (f *fakeEmailVerification) IsEmailVerified(keycloakID string) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"group1-userservice/app/config"
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// Handle validation (no database needed)
+
+func TestNormalizeHandle_TrimsAtAndLowercases(t *testing.T) {
+	assert.Equal(t, "jane.doe", service.NormalizeHandle("  @Jane.Doe "))
+}
+
+func TestValidateHandle(t *testing.T) {
+	cases := map[string]error{
+		"jane.doe":    nil,
+		"jd_2025":     nil,
+		"ab":          service.ErrHandleInvalid,
+		".jane":       service.ErrHandleInvalid,
+		"jane..doe":   service.ErrHandleInvalid,
+		"jane doe":    service.ErrHandleInvalid,
+		"admin":       service.ErrHandleReserved,
+		"me":          service.ErrHandleInvalid,
+		"kankerlijer": service.ErrHandleReserved,
+		"jane.kut":    service.ErrHandleReserved,
+		"lul_2000":    service.ErrHandleReserved,
+		// real names that merely contain a blocked word
+		"lulu":           nil,
+		"kutlu.yilmaz":   nil,
+		"ashton.kutcher": nil,
+		"piet.catering":  nil,
+		"pietering":      nil,
+		"pornchai":       nil,
+		"scunthorpe":     nil,
+	}
+
+	for handle, want := range cases {
+		err := service.ValidateHandle(handle)
+		if want == nil {
+			assert.NoError(t, err, handle)
+			continue
+		}
+		assert.True(t, errors.Is(err, want), "%s: got %v, want %v", handle, err, want)
+	}
+}
+
+// Handle persistence (Postgres)
+
+func setupHandleTest(t *testing.T) (*gin.Engine, *gorm.DB) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db := openTestDB(t)
+	config.DB = db
+
+	if err := config.DB.AutoMigrate(
+		&models.User{},
+		&models.ProfileVersion{},
+		&models.HandleReservation{},
+		&models.ProfileVisibility{},
+		&models.Badge{},
+		&models.UserBadge{},
+	); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	userService := service.NewUserService(repository.NewUserRepository(config.DB), newTestIdentityProvider(t))
+	badgeService := service.NewUserBadgeService(repository.NewUserBadgeRepository(config.DB))
+	visibilityService := service.NewProfileVisibilityService(repository.NewProfileVisibilityRepository(config.DB), userService)
+	profileViews := service.NewProfileViewService(userService, badgeService, visibilityService, service.NewSelfRelationshipResolver(), nil)
+	userController := controller.NewUserController(userService, badgeService, profileViews)
+
+	router := gin.Default()
+	router.Use(middleware.ProblemDetails())
+	router.GET("/users/@:handle", userController.GetByHandle)
+	router.GET("/users/handle-availability", userController.CheckHandleAvailability)
+
+	return router, db
+}
+
+func createHandleTestUser(t *testing.T, db *gorm.DB, email, kcID, handle string) *models.User {
+	t.Helper()
+
+	user := createUserTestUser(t, db, email, kcID, "hash")
+	db.Model(user).Update("handle", handle)
+	user.Handle = handle
+	return user
+}
+
+func TestChangeHandle_ReservesOldHandleAndRedirects(t *testing.T) {
+	router, db := setupHandleTest(t)
+	userService := service.NewUserService(repository.NewUserRepository(db), newTestIdentityProvider(t))
+
+	createHandleTestUser(t, db, "rename@example.com", "kc-rename", "old.name")
+
+	updated, err := userService.ChangeHandle("rename@example.com", "New.Name")
+	assert.NoError(t, err)
+	assert.Equal(t, "new.name", updated.Handle)
+
+	availability, err := userService.CheckHandleAvailability("old.name")
+	assert.NoError(t, err)
+	assert.False(t, availability.Available)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/@old.name", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/users/@new.name", w.Header().Get("Location"))
+}
+
+// blockedRelationships reports every viewer as blocked
+type blockedRelationships struct{}
+
+func (blockedRelationships) Relationship(viewerID, targetID uuid.UUID) (models.Relationship, error) {
+	return models.RelationshipBlocked, nil
+}
+
+func TestGetByHandle_OldHandleDoesNotRedirectBlockedViewer(t *testing.T) {
+	_, db := setupHandleTest(t)
+	userService := service.NewUserService(repository.NewUserRepository(db), newTestIdentityProvider(t))
+	badgeService := service.NewUserBadgeService(repository.NewUserBadgeRepository(db))
+	visibilityService := service.NewProfileVisibilityService(repository.NewProfileVisibilityRepository(db), userService)
+	profileViews := service.NewProfileViewService(userService, badgeService, visibilityService, blockedRelationships{}, nil)
+	userController := controller.NewUserController(userService, badgeService, profileViews)
+
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.GET("/users/@:handle", userController.GetByHandle)
+
+	createHandleTestUser(t, db, "hidden@example.com", "kc-hidden", "old.hidden")
+	_, err := userService.ChangeHandle("hidden@example.com", "new.hidden")
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/@old.hidden", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "user_not_found")
+	assert.Empty(t, w.Header().Get("Location"))
+}
+
+func TestChangeHandle_CooldownActive(t *testing.T) {
+	_, db := setupHandleTest(t)
+	userService := service.NewUserService(repository.NewUserRepository(db), newTestIdentityProvider(t))
+
+	user := createHandleTestUser(t, db, "cool@example.com", "kc-cool", "cool.one")
+	db.Model(user).Update("handle_changed_at", time.Now().Add(-time.Hour))
+
+	_, err := userService.ChangeHandle("cool@example.com", "cool.two")
+	assert.ErrorIs(t, err, service.ErrHandleCooldown)
+}
+
+func TestChangeHandle_TakenCaseInsensitive(t *testing.T) {
+	_, db := setupHandleTest(t)
+	userService := service.NewUserService(repository.NewUserRepository(db), newTestIdentityProvider(t))
+
+	createHandleTestUser(t, db, "first@example.com", "kc-first", "taken")
+	createHandleTestUser(t, db, "second@example.com", "kc-second", "second")
+
+	_, err := userService.ChangeHandle("second@example.com", "TAKEN")
+	assert.ErrorIs(t, err, service.ErrHandleTaken)
+}
+
+func TestBackfillHandles_GeneratesSuffixOnCollision(t *testing.T) {
+	_, db := setupHandleTest(t)
+	userService := service.NewUserService(repository.NewUserRepository(db), newTestIdentityProvider(t))
+
+	createHandleTestUser(t, db, "a@example.com", "kc-a", "test.user")
+	createUserTestUser(t, db, "b@example.com", "kc-b", "hash")
+
+	assert.NoError(t, userService.BackfillHandles())
+
+	var b models.User
+	assert.NoError(t, db.First(&b, "email = ?", "b@example.com").Error)
+	assert.Equal(t, "test.user2", b.Handle)
+}
+
+func TestBackfillHandles_KeepsNamesContainingBlockedWords(t *testing.T) {
+	_, db := setupHandleTest(t)
+	userService := service.NewUserService(repository.NewUserRepository(db), newTestIdentityProvider(t))
+
+	user := createUserTestUser(t, db, "lulu@example.com", "kc-lulu", "hash")
+	db.Model(user).Updates(map[string]any{"first_name": "Lulu", "last_name": "Kutcher"})
+
+	assert.NoError(t, userService.BackfillHandles())
+
+	var got models.User
+	assert.NoError(t, db.First(&got, "email = ?", "lulu@example.com").Error)
+	assert.Equal(t, "lulu.kutcher", got.Handle)
+}
+
+func TestGetByHandle_ReturnsProfile(t *testing.T) {
+	router, db := setupHandleTest(t)
+	createHandleTestUser(t, db, "profile@example.com", "kc-profile", "jane.doe")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/@Jane.Doe", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"handle":"jane.doe"`)
+}
@@ -0,0 +1,281 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+	"group1-userservice/app/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestI18n_DutchCatalogIsComplete(t *testing.T) {
+	assert.Empty(t, i18n.Missing(i18n.Dutch))
+}
+
+func TestI18n_FromAcceptLanguage(t *testing.T) {
+	for header, want := range map[string]i18n.Locale{
+		"nl":                      i18n.Dutch,
+		"nl-BE,nl;q=0.9":          i18n.Dutch,
+		"de-DE,de;q=0.9,en;q=0.8": i18n.English,
+		"en;q=0.5,nl-NL;q=0.8":    i18n.Dutch,
+		"fr, NL;q=0.3, en;q=0.2":  i18n.Dutch,
+		"EN-gb":                   i18n.English,
+	} {
+		got, ok := i18n.FromAcceptLanguage(header)
+		assert.True(t, ok, header)
+		assert.Equal(t, want, got, header)
+	}
+
+	for _, header := range []string{"", "*", "de, fr", "nl;q=0"} {
+		_, ok := i18n.FromAcceptLanguage(header)
+		assert.False(t, ok, header)
+	}
+}
+
+func TestI18n_DefaultLanguage(t *testing.T) {
+	t.Setenv("DEFAULT_LANGUAGE", "")
+	assert.Equal(t, i18n.English, i18n.Default())
+
+	t.Setenv("DEFAULT_LANGUAGE", "nl")
+	assert.Equal(t, i18n.Dutch, i18n.Default())
+}
+
+func TestI18n_ValidationMessagesAreTranslated(t *testing.T) {
+	p := models.ProfileSnapshot{FirstName: "Jane"}
+	err := validation.Profile(&p)
+
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	assert.Equal(t, "Achternaam is verplicht", errs.Messages(i18n.Dutch)["last_name"])
+	assert.Equal(t, "Last name is required", errs.Messages(i18n.English)["last_name"])
+	assert.Contains(t, err.Error(), "Last name is required")
+}
+
+func TestI18n_PreferredLanguageIsNormalized(t *testing.T) {
+	p := models.ProfileSnapshot{FirstName: "Jane", LastName: "Doe", PreferredLanguage: " NL-be "}
+	assert.NoError(t, validation.Profile(&p))
+	assert.Equal(t, "nl", p.PreferredLanguage)
+
+	p.PreferredLanguage = "de"
+	err := validation.Profile(&p)
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	assert.Equal(t, "must be one of nl, en", errs.Messages(i18n.English)["preferred_language"])
+}
+
+func serveLocalized(users *accountUserService, sub string, header string, handler gin.HandlerFunc) (*httptest.ResponseRecorder, middleware.Problem) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.Use(middleware.Locale(users))
+	router.Use(func(c *gin.Context) {
+		if sub != "" {
+			c.Set("user_id", sub)
+		}
+	})
+	router.GET("/things", handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/things", nil)
+	if header != "" {
+		req.Header.Set("Accept-Language", header)
+	}
+	router.ServeHTTP(w, req)
+
+	var problem middleware.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &problem)
+	return w, problem
+}
+
+func TestI18n_ProblemFollowsAcceptLanguage(t *testing.T) {
+	users := &accountUserService{directoryUserService{users: map[uuid.UUID]models.User{}}}
+	notFound := func(c *gin.Context) { c.Error(service.ErrUserNotFound) }
+
+	w, problem := serveLocalized(users, "", "nl-NL,nl;q=0.9,en;q=0.8", notFound)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "nl", w.Header().Get("Content-Language"))
+	assert.Equal(t, "user_not_found", problem.Code)
+	assert.Equal(t, "gebruiker niet gevonden", problem.Detail)
+
+	w, problem = serveLocalized(users, "", "", notFound)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+	assert.Equal(t, "user not found", problem.Detail)
+}
+
+func TestI18n_PreferredLanguageWinsOverAcceptLanguage(t *testing.T) {
+	user := models.User{ID: uuid.New(), KeycloakID: "kc-lang", Email: "lang@example.com", PreferredLanguage: "nl"}
+	users := &accountUserService{directoryUserService{users: map[uuid.UUID]models.User{user.ID: user}}}
+
+	_, problem := serveLocalized(users, user.KeycloakID, "en", func(c *gin.Context) {
+		c.Error(service.ErrHandleInvalid.Because("handle_invalid.length", 3, 30))
+	})
+	assert.Equal(t, "handle_invalid", problem.Code)
+	assert.Equal(t, "ongeldige handle: moet 3 tot 30 tekens lang zijn", problem.Detail)
+
+	_, problem = serveLocalized(users, user.KeycloakID, "en", func(c *gin.Context) {
+		c.Error(validation.Errors{"first_name": {Key: "validation.too_long", Args: []any{100}}})
+	})
+	assert.Equal(t, "niet alle velden zijn geldig", problem.Detail)
+	assert.Equal(t, "mag maximaal 100 tekens zijn", problem.Fields["first_name"])
+}
+
+// listedBadgeService returns a fixed list of badges
+type listedBadgeService struct {
+	fakeBadgeService
+	badges []models.UserBadge
+}
+
+func (l *listedBadgeService) GetBadgesForUser(userID uuid.UUID) ([]models.UserBadge, error) {
+	return l.badges, nil
+}
+
+func TestI18n_BadgesAreTranslated(t *testing.T) {
+	user := models.User{ID: uuid.New(), KeycloakID: "kc-badges", Email: "badges@example.com"}
+	users := &accountUserService{directoryUserService{users: map[uuid.UUID]models.User{user.ID: user}}}
+	badges := &listedBadgeService{badges: []models.UserBadge{
+		{BadgeKey: service.BadgeKeyProfileComplete, Badge: models.Badge{Key: service.BadgeKeyProfileComplete, Name: "Complete Profile"}},
+		{BadgeKey: "streak_30_days", Badge: models.Badge{Key: "streak_30_days", Name: "30 day streak"}},
+	}}
+	uc := controller.NewUserController(users, badges, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.Use(func(c *gin.Context) { c.Set("user_id", user.KeycloakID) })
+	router.GET("/users/me/badges", uc.GetMyBadges)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/me/badges", nil)
+	req.Header.Set("Accept-Language", "nl")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []models.UserBadge
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got, 2)
+	assert.Equal(t, "Volledig profiel", got[0].Badge.Name)
+	assert.Equal(t, "Heeft alle verplichte profielgegevens ingevuld", got[0].Badge.Description)
+	assert.Equal(t, "30 day streak", got[1].Badge.Name)
+}
+
+// channelSender hands every notification to the test, which waits for the asynchronous send
+type channelSender chan models.Notification
+
+func (s channelSender) Send(n models.Notification) error {
+	s <- n
+	return nil
+}
+
+func TestI18n_PasswordResetAlertUsesPreferredLanguage(t *testing.T) {
+	user := models.User{ID: uuid.New(), KeycloakID: "kc-reset", Email: "reset@example.com", PreferredLanguage: "nl"}
+	users := &accountUserService{directoryUserService{users: map[uuid.UUID]models.User{user.ID: user}}}
+	sent := make(channelSender, 1)
+
+	pc := controller.NewPasswordResetController(&fakePasswordResetService{}, users, "")
+	pc.Notifier = sent
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.POST("/auth/forgot-password", pc.Forgot)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/auth/forgot-password", bytes.NewBufferString(`{"email": "reset@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "en")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "if the email exists, a reset link will be sent")
+
+	select {
+	case n := <-sent:
+		assert.Equal(t, "Wachtwoord resetten aangevraagd", n.Title)
+		assert.Contains(t, n.Message, "wachtwoord van je account")
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no password reset alert was sent")
+	}
+}
+
+func TestI18n_UserFacingTextsHaveBothLanguages(t *testing.T) {
+	keys := []string{
+		"notification.magic_link.title", "notification.magic_link.message",
+		"notification.email_verification.title", "notification.email_verification.message",
+		"completeness.reason.missing", "completeness.reason.biography_short",
+		"onboarding_step_incomplete.fields", "onboarding_step_incomplete.notification_consent",
+	}
+	for _, event := range []string{"connection_follow", "connection_request", "connection_accepted"} {
+		keys = append(keys, "notification."+event+".title", "notification."+event+".message")
+	}
+	for _, field := range []string{
+		"first_name", "last_name", "profile_photo_url", "biography", "job_function",
+		"sector", "country", "phone_number", "interests", "discovery_preferences",
+	} {
+		keys = append(keys, "completeness.step."+field)
+	}
+
+	for _, key := range keys {
+		en, ok := i18n.Lookup(i18n.English, key)
+		assert.True(t, ok, key)
+		nl, _ := i18n.Lookup(i18n.Dutch, key)
+		assert.NotEqual(t, en, nl, key)
+	}
+}
+
+func TestI18n_ConnectionNotificationUsesRecipientLanguage(t *testing.T) {
+	alice := models.User{ID: uuid.New(), Email: "alice@example.com", FirstName: "Alice", LastName: "A"}
+	bob := models.User{ID: uuid.New(), Email: "bob@example.com", FirstName: "Bob", LastName: "B", PreferredLanguage: "nl"}
+	users := &directoryUserService{users: map[uuid.UUID]models.User{alice.ID: alice, bob.ID: bob}}
+	repo := newFakeConnectionRepo()
+	sender := &recordingSender{}
+	settings := &fakeNotificationSettingsService{settings: models.NotificationSettings{ConnectionEmail: true}}
+	svc := service.NewConnectionService(repo, users, settings, sender, service.NewUserBlockService(newFakeUserBlockRepo(), repo, users))
+
+	_, err := svc.Follow(alice, bob.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, sender.sent, 1) {
+		assert.Equal(t, "Nieuwe volger", sender.sent[0].Title)
+		assert.Equal(t, "Alice A volgt je nu.", sender.sent[0].Message)
+	}
+}
+
+func TestI18n_CompletenessAndOnboardingTextsAreTranslated(t *testing.T) {
+	u := models.User{ID: uuid.New(), FirstName: "Jan", LastName: "Jansen", Biography: "Hoi", EmailVerifiedAt: verifiedNow()}
+
+	completeness := service.NewProfileCompletenessService(&fakeCompletenessRepo{}, service.DefaultCompletenessConfig())
+	result, err := completeness.ForUser(u, i18n.Dutch)
+	assert.NoError(t, err)
+	reasons := map[string]string{}
+	for _, item := range result.Missing {
+		reasons[item.Field] = item.Reason
+	}
+	assert.Equal(t, "niet ingevuld", reasons["country"])
+	assert.Equal(t, "biografie heeft 3 tekens, minstens 50 aanbevolen", reasons["biography"])
+	if assert.NotEmpty(t, result.NextSteps) {
+		assert.Equal(t, "Upload een profielfoto", result.NextSteps[0].Message)
+	}
+
+	onboarding := service.NewOnboardingService(&fakeOnboardingRepo{}, &fakeCompletenessRepo{}, &fakeNotificationSettingsService{})
+	_, err = onboarding.Complete(u, models.OnboardingBasicProfile, interfaces.OnboardingStepInput{})
+	users := &accountUserService{directoryUserService{users: map[uuid.UUID]models.User{}}}
+	_, problem := serveLocalized(users, "", "nl", func(c *gin.Context) { c.Error(err) })
+	assert.Equal(t, "onboarding_step_incomplete", problem.Code)
+	assert.Contains(t, problem.Detail, "vul country in")
+}
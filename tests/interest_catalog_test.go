@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+	"group1-userservice/app/service"
+	"group1-userservice/app/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingCatalogService records which admin action a request reached
+type recordingCatalogService struct {
+	called string
+}
+
+func (f *recordingCatalogService) List() ([]models.Interest, error) {
+	f.called = "list"
+	return []models.Interest{}, nil
+}
+
+func (f *recordingCatalogService) Create(input interfaces.InterestInput) (*models.Interest, error) {
+	f.called = "create"
+	return &models.Interest{ID: 1, Key: input.Key}, nil
+}
+
+func (f *recordingCatalogService) Update(id uint, input interfaces.InterestInput) (*models.Interest, error) {
+	f.called = "update"
+	return &models.Interest{ID: id}, nil
+}
+
+func (f *recordingCatalogService) Reorder(ids []uint) ([]models.Interest, error) {
+	f.called = "reorder"
+	return []models.Interest{}, nil
+}
+
+func (f *recordingCatalogService) Archive(id uint) (*models.Interest, error) {
+	f.called = "archive"
+	return &models.Interest{ID: id}, nil
+}
+
+func (f *recordingCatalogService) Merge(from, into uint) (*interfaces.InterestMergeResult, error) {
+	f.called = "merge"
+	if from == into {
+		return nil, service.ErrInterestMergeSelf
+	}
+	return &interfaces.InterestMergeResult{From: models.Interest{ID: from}, Into: models.Interest{ID: into}}, nil
+}
+
+func TestInterestValidation(t *testing.T) {
+	created := models.Interest{Key: "Duurzaamheid", Translations: []models.InterestTranslation{
+		{Locale: "NL", Label: "  Duurzaamheid "},
+		{Locale: "de", Label: "Nachhaltigkeit"},
+	}}
+	err := validation.Interest(&created, true)
+
+	var fields validation.Errors
+	if !assert.ErrorAs(t, err, &fields) {
+		return
+	}
+	assert.Contains(t, fields, "key")
+	assert.Contains(t, fields, "translations.de")
+	assert.Contains(t, fields, "translations.en.label")
+	assert.Equal(t, "nl", created.Translations[0].Locale)
+	assert.Equal(t, "Duurzaamheid", created.Translations[0].Label)
+
+	// a rename may send one language only, but not an empty label
+	renamed := models.Interest{ID: 1, Key: "ict", Translations: []models.InterestTranslation{{Locale: "en", Label: "IT"}}}
+	assert.NoError(t, validation.Interest(&renamed, false))
+
+	renamed.Translations[0].Label = " "
+	assert.ErrorAs(t, validation.Interest(&renamed, false), &fields)
+	assert.Contains(t, fields, "translations.en.label")
+}
+
+func TestInterestCatalogController_Routes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake := &recordingCatalogService{}
+	ic := controller.NewInterestCatalogController(fake)
+
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.GET("/admin/interests", ic.List)
+	router.POST("/admin/interests", ic.Create)
+	router.PUT("/admin/interests/order", ic.Reorder)
+	router.PUT("/admin/interests/:id", ic.Update)
+	router.POST("/admin/interests/:id/archive", ic.Archive)
+	router.POST("/admin/interests/:id/merge", ic.Merge)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		fake.called = ""
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPut, "/admin/interests/order", `{"ids": [3, 1]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "reorder", fake.called)
+
+	w = serve(http.MethodPut, "/admin/interests/3", `{"translations": {"en": {"label": "IT"}}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "update", fake.called)
+
+	w = serve(http.MethodPost, "/admin/interests/abc/archive", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_interest_id")
+	assert.Empty(t, fake.called)
+
+	w = serve(http.MethodPost, "/admin/interests/3/merge", `{"into": 3}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "interest_merge_self")
+
+	w = serve(http.MethodPost, "/admin/interests", `{"key": "ict", "labels": {}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, fake.called)
+}
+
+func TestInterestCatalog_CreateRenameReorder(t *testing.T) {
+	_, _, repo := setupUserInterestsServiceTest(t)
+	catalog := service.NewInterestCatalogService(repo)
+
+	created, err := catalog.Create(interfaces.InterestInput{
+		Key: "sustainability",
+		Translations: map[string]interfaces.InterestLabelInput{
+			"nl": {Label: "Duurzaamheid"},
+			"en": {Label: "Sustainability", Description: "Energy, climate and circular economy"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	assert.Equal(t, 13, created.Position)
+	assert.Equal(t, "Duurzaamheid", created.Translation("nl").Label)
+	assert.Equal(t, "Energy, climate and circular economy", created.Translation("en").Description)
+
+	_, err = catalog.Create(interfaces.InterestInput{
+		Key:          "sustainability",
+		Translations: map[string]interfaces.InterestLabelInput{"nl": {Label: "Duurzaam"}, "en": {Label: "Sustainable"}},
+	})
+	assert.ErrorIs(t, err, service.ErrInterestExists)
+
+	renamed, err := catalog.Update(created.ID, interfaces.InterestInput{
+		Translations: map[string]interfaces.InterestLabelInput{"nl": {Label: "Duurzaamheid en Klimaat"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "sustainability", renamed.Key)
+	assert.Equal(t, "Duurzaamheid en Klimaat", renamed.Translation("nl").Label)
+	assert.Equal(t, "Sustainability", renamed.Translation("en").Label)
+
+	_, err = catalog.Update(99999, interfaces.InterestInput{})
+	assert.ErrorIs(t, err, service.ErrInterestNotFound)
+
+	ordered, err := catalog.Reorder([]uint{created.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, ordered, 13) {
+		assert.Equal(t, "sustainability", ordered[0].Key)
+		assert.Equal(t, 1, ordered[0].Position)
+		assert.Equal(t, "healthcare_welfare", ordered[1].Key)
+	}
+
+	_, err = catalog.Reorder([]uint{99999})
+	assert.ErrorIs(t, err, service.ErrInterestNotFound)
+}
+
+func TestInterestCatalog_ArchivedInterestsAreHiddenAndRejected(t *testing.T) {
+	svc, db, repo := setupUserInterestsServiceTest(t)
+	catalog := service.NewInterestCatalogService(repo)
+
+	email := "user31@example.com"
+	ictID := getInterestIDByKey(t, db, "ict")
+	assert.NoError(t, db.Create(&models.UserInterest{UserEmail: email, InterestID: ictID, Value: true}).Error)
+
+	archived, err := catalog.Archive(ictID)
+	assert.NoError(t, err)
+	assert.NotNil(t, archived.ArchivedAt)
+
+	again, err := catalog.Archive(ictID)
+	assert.NoError(t, err)
+	assert.Equal(t, archived.ArchivedAt.Unix(), again.ArchivedAt.Unix())
+
+	items, err := svc.GetForUser(email, i18n.English)
+	assert.NoError(t, err)
+	assert.Len(t, items, 11)
+	for _, item := range items {
+		assert.NotEqual(t, "ict", item.Key)
+	}
+
+	// the selection made before archiving is kept
+	var kept models.UserInterest
+	assert.NoError(t, db.First(&kept, "user_email = ? AND interest_id = ?", email, ictID).Error)
+	assert.True(t, kept.Value)
+
+	_, err = svc.UpdateForUser(email, interfaces.UserInterestsUpdateInput{
+		Interests: []interfaces.UserInterestItemInput{
+			{ID: getInterestIDByKey(t, db, "media_communication"), Value: true},
+			{ID: ictID, Value: false},
+			{ID: 99999, Value: true},
+		},
+	}, i18n.English)
+	var fields validation.Errors
+	if assert.ErrorAs(t, err, &fields) {
+		assert.NotContains(t, fields, "interests.0.id")
+		assert.Contains(t, fields, "interests.1.id")
+		assert.Contains(t, fields, "interests.2.id")
+	}
+
+	var count int64
+	db.Model(&models.UserInterest{}).Where("user_email = ?", email).Count(&count)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestInterestCatalog_MergeMovesSelections(t *testing.T) {
+	_, db, repo := setupUserInterestsServiceTest(t)
+	catalog := service.NewInterestCatalogService(repo)
+
+	mediaID := getInterestIDByKey(t, db, "media_communication")
+	tradeID := getInterestIDByKey(t, db, "trade_services")
+
+	rows := []models.UserInterest{
+		{UserEmail: "only-media@example.com", InterestID: mediaID, Value: true},
+		{UserEmail: "both@example.com", InterestID: mediaID, Value: true},
+		{UserEmail: "both@example.com", InterestID: tradeID, Value: false},
+		{UserEmail: "only-trade@example.com", InterestID: tradeID, Value: true},
+	}
+	assert.NoError(t, db.Create(&rows).Error)
+
+	result, err := catalog.Merge(mediaID, tradeID)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	assert.EqualValues(t, 2, result.Moved)
+	assert.NotNil(t, result.From.ArchivedAt)
+	assert.Equal(t, "trade_services", result.Into.Key)
+
+	values := map[string]bool{}
+	var merged []models.UserInterest
+	assert.NoError(t, db.Where("interest_id = ?", tradeID).Find(&merged).Error)
+	for _, r := range merged {
+		values[r.UserEmail] = r.Value
+	}
+	assert.Equal(t, map[string]bool{"only-media@example.com": true, "both@example.com": true, "only-trade@example.com": true}, values)
+
+	var left int64
+	db.Model(&models.UserInterest{}).Where("interest_id = ?", mediaID).Count(&left)
+	assert.Zero(t, left)
+
+	_, err = catalog.Merge(tradeID, mediaID)
+	assert.ErrorIs(t, err, service.ErrInterestArchived)
+	_, err = catalog.Merge(tradeID, tradeID)
+	assert.ErrorIs(t, err, service.ErrInterestMergeSelf)
+	_, err = catalog.Merge(99999, tradeID)
+	assert.ErrorIs(t, err, service.ErrInterestNotFound)
+}
+
+func TestProfileCompleteness_IgnoresArchivedInterests(t *testing.T) {
+	_, db, repo := setupUserInterestsServiceTest(t)
+
+	email := "user32@example.com"
+	ictID := getInterestIDByKey(t, db, "ict")
+	assert.NoError(t, db.Create(&models.UserInterest{UserEmail: email, InterestID: ictID, Value: true}).Error)
+
+	completeness := repository.NewProfileCompletenessRepository(db)
+	count, err := completeness.CountSelectedInterests(email)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	_, err = service.NewInterestCatalogService(repo).Archive(ictID)
+	assert.NoError(t, err)
+
+	count, err = completeness.CountSelectedInterests(email)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
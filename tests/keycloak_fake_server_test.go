@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"group1-userservice/app/config"
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/keycloak"
+	"group1-userservice/app/keycloak/keycloaktest"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// The real HTTP code of the Keycloak provider against the fake server
+func TestKeycloakProvider_FakeServer(t *testing.T) {
+	srv := keycloaktest.NewServer()
+	defer srv.Close()
+	kc := keycloak.NewProvider(srv.Config())
+
+	subject, err := kc.CreateUser(interfaces.IdentityUser{Email: "founder@example.com", FirstName: "Ada"}, "Welkom1234")
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	_, err = kc.CreateUser(interfaces.IdentityUser{Email: "founder@example.com"}, "Welkom1234")
+	assert.ErrorIs(t, err, interfaces.ErrEmailAlreadyExists)
+
+	_, err = kc.PasswordGrant("founder@example.com", "wrong")
+	assert.ErrorIs(t, err, interfaces.ErrInvalidCredentials)
+
+	token, err := kc.PasswordGrant("founder@example.com", "Welkom1234")
+	if err != nil {
+		t.Fatalf("password grant: %v", err)
+	}
+
+	// Verified against the JWKS of the fake realm
+	claims, err := kc.VerifyToken(token.AccessToken)
+	if err != nil {
+		t.Fatalf("verify token: %v", err)
+	}
+	assert.Equal(t, subject, claims.Subject)
+	assert.False(t, claims.EmailVerified)
+
+	assert.NoError(t, kc.MarkEmailVerified(subject))
+	refreshed, err := kc.Refresh(token.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	claims, _ = kc.VerifyToken(refreshed.AccessToken)
+	assert.True(t, claims.EmailVerified)
+
+	// A logged out session can no longer be refreshed
+	resp, err := http.PostForm(srv.URL+"/realms/test/protocol/openid-connect/logout", url.Values{
+		"client_id":     {srv.ClientID},
+		"client_secret": {srv.ClientSecret},
+		"refresh_token": {refreshed.RefreshToken},
+	})
+	if err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	_, err = kc.Refresh(refreshed.RefreshToken)
+	assert.ErrorIs(t, err, interfaces.ErrInvalidToken)
+
+	assert.NoError(t, kc.SetPassword(subject, "Nieuw12345"))
+	_, err = kc.PasswordGrant("founder@example.com", "Welkom1234")
+	assert.ErrorIs(t, err, interfaces.ErrInvalidCredentials)
+
+	assert.NoError(t, kc.DisableAccount(subject))
+	_, err = kc.PasswordGrant("founder@example.com", "Nieuw12345")
+	assert.ErrorIs(t, err, interfaces.ErrAccountDisabled)
+
+	assert.ErrorIs(t, kc.SetPassword("unknown", "Nieuw12345"), interfaces.ErrIdentityNotFound)
+	assert.NoError(t, kc.DeleteUser(subject))
+	assert.NoError(t, kc.DeleteUser(subject))
+	_, found := srv.User("founder@example.com")
+	assert.False(t, found)
+}
+
+func TestKeycloakProvider_FakeServer_InjectedFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	srv := keycloaktest.NewServer()
+	defer srv.Close()
+	cfg := srv.Config()
+	cfg.Timeout = 200 * time.Millisecond
+	kc := keycloak.NewProvider(cfg)
+
+	subject := srv.AddUser(keycloaktest.User{Email: "founder@example.com", Password: "Welkom1234", Enabled: true})
+
+	// 409 from the Admin API
+	srv.Inject(keycloaktest.Fault{Method: http.MethodPost, Path: "/users", Status: http.StatusConflict, Times: 1})
+	_, err := kc.CreateUser(interfaces.IdentityUser{Email: "investor@example.com"}, "Welkom1234")
+	assert.ErrorIs(t, err, interfaces.ErrEmailAlreadyExists)
+
+	// Outage of the token endpoint reaches the client as 503
+	user := models.User{ID: uuid.New(), KeycloakID: subject, Email: "founder@example.com"}
+	users := &accountUserService{directoryUserService{users: map[uuid.UUID]models.User{user.ID: user}}}
+	lc := controller.NewLoginController(users, service.NewTwoFactorService(newFakeTwoFactorRepo(), users, twoFactorConfig()), kc)
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.POST("/auth/login", lc.Handle)
+
+	login := func() int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"founder@example.com","password":"Welkom1234"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	srv.Inject(keycloaktest.Fault{Path: "/openid-connect/token", Status: http.StatusBadGateway})
+	assert.Equal(t, http.StatusServiceUnavailable, login())
+	srv.ClearFaults()
+
+	// A hanging Keycloak runs into the client timeout
+	srv.Inject(keycloaktest.Fault{Path: "/openid-connect/token", Delay: time.Second, Times: 1})
+	assert.Equal(t, http.StatusServiceUnavailable, login())
+	assert.Equal(t, http.StatusOK, login())
+}
+
+// Register → login → refresh → reset through the controllers and services, with only the
+// identity provider faked
+func TestAuthFlow_AgainstFakeKeycloak(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := openTestDB(t)
+	config.DB = db
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.ProfileVersion{},
+		&models.HandleReservation{},
+		&models.NotificationSettings{},
+		&models.Interest{},
+		&models.InterestTranslation{},
+		&models.UserInterest{},
+		&models.TwoFactor{},
+		&models.LoginChallenge{},
+		&models.PasswordResetToken{},
+	); err != nil {
+		t.Fatalf("failed to migrate test db: %v", err)
+	}
+
+	srv := keycloaktest.NewServer()
+	defer srv.Close()
+	kc := keycloak.NewProvider(srv.Config())
+
+	userService := service.NewUserService(repository.NewUserRepository(db), kc)
+	twoFactorService := service.NewTwoFactorService(repository.NewTwoFactorRepository(db), userService, twoFactorConfig())
+	resetService := service.NewPasswordResetService(repository.NewPasswordResetRepository(db), userService, kc)
+
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.POST("/users/register", controller.NewRegisterController(userService, &fakeEmailVerification{}).Handle)
+	loginController := controller.NewLoginController(userService, twoFactorService, kc)
+	router.POST("/auth/login", loginController.Handle)
+	router.POST("/auth/refresh", loginController.Refresh)
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/users/register", `{"email":"founder@example.com","password":"Welkom1234","first_name":"Ada","last_name":"Lovelace"}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	kcUser, found := srv.User("founder@example.com")
+	assert.True(t, found)
+	assert.Equal(t, "Ada", kcUser.FirstName)
+
+	w = post("/auth/login", `{"email":"founder@example.com","password":"Welkom1234"}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var token interfaces.TokenResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &token)
+
+	w = post("/auth/refresh", `{"refresh_token":"`+token.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	raw, err := resetService.RequestReset("founder@example.com")
+	if err != nil {
+		t.Fatalf("request reset: %v", err)
+	}
+	_, err = resetService.ResetPassword(raw, "Nieuw12345")
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, post("/auth/login", `{"email":"founder@example.com","password":"Welkom1234"}`).Code)
+	assert.Equal(t, http.StatusOK, post("/auth/login", `{"email":"founder@example.com","password":"Nieuw12345"}`).Code)
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/localidp"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalIdentityProvider_Lifecycle(t *testing.T) {
+	idp, err := localidp.New(localidp.Config{
+		Secret:     []byte("test-secret"),
+		Issuer:     "test",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+		Roles:      map[string][]string{"founder@example.com": {"moderator"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	subject, err := idp.CreateUser(interfaces.IdentityUser{Email: "Founder@example.com", FirstName: "Ada"}, "Welkom1234")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = idp.CreateUser(interfaces.IdentityUser{Email: "founder@example.com"}, "Welkom1234")
+	assert.ErrorIs(t, err, interfaces.ErrEmailAlreadyExists)
+
+	_, err = idp.PasswordGrant("founder@example.com", "wrong")
+	assert.ErrorIs(t, err, interfaces.ErrInvalidCredentials)
+
+	token, err := idp.PasswordGrant("founder@example.com", "Welkom1234")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, "Bearer", token.TokenType)
+
+	claims, err := idp.VerifyToken(token.AccessToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, subject, claims.Subject)
+	assert.False(t, claims.EmailVerified)
+	assert.Equal(t, []string{"moderator"}, claims.RealmRoles)
+
+	// A refresh token is not an access token and the other way around
+	_, err = idp.VerifyToken(token.RefreshToken)
+	assert.ErrorIs(t, err, interfaces.ErrInvalidToken)
+	_, err = idp.Refresh(token.AccessToken)
+	assert.ErrorIs(t, err, interfaces.ErrInvalidToken)
+
+	if err := idp.MarkEmailVerified(subject); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	refreshed, err := idp.Refresh(token.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claims, err = idp.VerifyToken(refreshed.AccessToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.True(t, claims.EmailVerified)
+
+	impersonated, err := idp.Impersonate(subject)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.NotEmpty(t, impersonated.RefreshToken)
+
+	if err := idp.DisableAccount(subject); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = idp.PasswordGrant("founder@example.com", "Welkom1234")
+	assert.ErrorIs(t, err, interfaces.ErrAccountDisabled)
+	_, err = idp.Refresh(token.RefreshToken)
+	assert.ErrorIs(t, err, interfaces.ErrInvalidToken)
+
+	assert.ErrorIs(t, idp.SetPassword("unknown", "Welkom1234"), interfaces.ErrIdentityNotFound)
+}
+
+func TestLocalIdentityProvider_StoreSurvivesRestart(t *testing.T) {
+	cfg := localidp.Config{
+		Secret:     []byte("test-secret"),
+		Issuer:     "test",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+		StorePath:  filepath.Join(t.TempDir(), "accounts.json"),
+	}
+
+	first, err := localidp.New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = first.CreateUser(interfaces.IdentityUser{Email: "founder@example.com"}, "Welkom1234")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := localidp.New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = second.PasswordGrant("founder@example.com", "Welkom1234")
+	assert.NoError(t, err)
+}
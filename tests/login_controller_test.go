@@ -2,17 +2,23 @@ package tests
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"group1-userservice/app/config"
 	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/keycloak"
+	"group1-userservice/app/middleware"
 	"group1-userservice/app/models"
 	"group1-userservice/app/repository"
 	"group1-userservice/app/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 )
@@ -31,9 +37,13 @@ func setupAuthTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 	// Migrate required tables
 	if err := config.DB.AutoMigrate(
 		&models.User{},
+		&models.ProfileVersion{},
 		&models.NotificationSettings{},
 		&models.Interest{},
+		&models.InterestTranslation{},
 		&models.UserInterest{},
+		&models.TwoFactor{},
+		&models.LoginChallenge{},
 	); err != nil {
 		t.Fatalf("failed to migrate test db: %v", err)
 	}
@@ -43,11 +53,14 @@ func setupAuthTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 
 	// Initialize repository and service
 	userRepo := repository.NewUserRepository(config.DB)
-	userService := service.NewUserService(userRepo)
-	loginController := controller.NewLoginController(userService)
+	idp := newTestIdentityProvider(t)
+	userService := service.NewUserService(userRepo, idp)
+	twoFactorService := service.NewTwoFactorService(repository.NewTwoFactorRepository(config.DB), userService, twoFactorConfig())
+	loginController := controller.NewLoginController(userService, twoFactorService, idp)
 
 	// Create Gin router with authentication routes
 	router := gin.Default()
+	router.Use(middleware.ProblemDetails())
 	router.POST("/auth/login", loginController.Handle)
 	router.POST("/auth/refresh", loginController.Refresh)
 
@@ -88,9 +101,9 @@ func TestLogin_UserNotFound_Returns401(t *testing.T) {
 func TestLogin_WrongPassword_Returns401(t *testing.T) {
 	router, db := setupAuthTestRouter(t)
 
+	// The user only exists locally, so the identity provider rejects any password
 	const email = "test@example.com"
-	const correctPassword = "correct-password"
-	createTestUser(t, db, email, correctPassword)
+	createTestUser(t, db, email)
 
 	body := []byte(`{"email":"` + email + `","password":"wrong-password"}`)
 
@@ -103,6 +116,72 @@ func TestLogin_WrongPassword_Returns401(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+// The identity provider's reason for rejecting the grant decides the answer; the local store has no say
+func TestLogin_IdentityProviderRejections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	idp := &stubIdentityProvider{IdentityProvider: newTestIdentityProvider(t)}
+	user := models.User{ID: uuid.New(), KeycloakID: "kc-login", Email: "founder@example.com"}
+	users := &accountUserService{directoryUserService{users: map[uuid.UUID]models.User{user.ID: user}}}
+	lc := controller.NewLoginController(users, service.NewTwoFactorService(newFakeTwoFactorRepo(), users, twoFactorConfig()), idp)
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.POST("/auth/login", lc.Handle)
+
+	for grantErr, want := range map[error]int{
+		interfaces.ErrInvalidCredentials: http.StatusUnauthorized,
+		interfaces.ErrAccountDisabled:    http.StatusForbidden,
+		interfaces.ErrAccountNotVerified: http.StatusForbidden,
+		interfaces.ErrAccountLocked:      http.StatusTooManyRequests,
+		errors.New("connection refused"): http.StatusServiceUnavailable,
+	} {
+		idp.grant = func(email, password string) (*interfaces.TokenResponse, error) {
+			return nil, grantErr
+		}
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"founder@example.com","password":"secret1"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, want, w.Code, grantErr.Error())
+	}
+
+	idp.grant = func(email, password string) (*interfaces.TokenResponse, error) {
+		return &interfaces.TokenResponse{AccessToken: "access"}, nil
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"founder@example.com","password":"secret1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestKeycloakPasswordGrant_MapsErrors(t *testing.T) {
+	var description string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "p&ss=word1", r.PostForm.Get("password"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": description})
+	}))
+	defer server.Close()
+	kc := keycloak.NewProvider(keycloak.Config{URL: server.URL, Realm: "test", ClientID: "user-service"})
+
+	for desc, want := range map[string]error{
+		"Invalid user credentials":     interfaces.ErrInvalidCredentials,
+		"Account disabled":             interfaces.ErrAccountDisabled,
+		"Account is not fully set up":  interfaces.ErrAccountNotVerified,
+		"Account temporarily disabled": interfaces.ErrAccountLocked,
+	} {
+		description = desc
+		_, err := kc.PasswordGrant("founder@example.com", "p&ss=word1")
+		assert.True(t, errors.Is(err, want), desc)
+	}
+}
+
 // Tests for POST /auth/refresh
 
 // Missing refresh_token should return 400
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMagicLinkRepo keeps login links in memory
+type fakeMagicLinkRepo struct {
+	rows   map[uint]*models.MagicLinkToken
+	nextID uint
+}
+
+func (f *fakeMagicLinkRepo) Create(email, hash string, exp time.Time) error {
+	f.nextID++
+	f.rows[f.nextID] = &models.MagicLinkToken{ID: f.nextID, Email: email, TokenHash: hash, ExpiresAt: exp}
+	return nil
+}
+
+func (f *fakeMagicLinkRepo) FindValidByTokenHash(hash string) (*models.MagicLinkToken, error) {
+	for _, row := range f.rows {
+		if row.TokenHash == hash && !row.Used && row.ExpiresAt.After(time.Now()) {
+			copied := *row
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeMagicLinkRepo) MarkUsed(id uint) (bool, error) {
+	row, ok := f.rows[id]
+	if !ok || row.Used {
+		return false, nil
+	}
+	row.Used = true
+	return true, nil
+}
+
+func newMagicLinkTestEnv(users ...models.User) (*fakeMagicLinkRepo, *accountUserService) {
+	byID := map[uuid.UUID]models.User{}
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	return &fakeMagicLinkRepo{rows: map[uint]*models.MagicLinkToken{}},
+		&accountUserService{directoryUserService{users: byID}}
+}
+
+func TestMagicLinkService_SingleUse(t *testing.T) {
+	user := models.User{ID: uuid.New(), KeycloakID: "kc-1", Email: "founder@example.com"}
+	repo, users := newMagicLinkTestEnv(user)
+	svc := service.NewMagicLinkService(repo, users)
+
+	token, err := svc.RequestLink("founder@example.com")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.NotEqual(t, token, repo.rows[1].TokenHash, "only the hash is stored")
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), repo.rows[1].ExpiresAt, time.Minute)
+
+	redeemed, err := svc.Redeem(token)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, redeemed.ID)
+
+	_, err = svc.Redeem(token)
+	assert.True(t, errors.Is(err, service.ErrMagicLinkInvalid))
+}
+
+func TestMagicLinkService_NoTokenForUnknownOrBlocked(t *testing.T) {
+	blocked := models.User{ID: uuid.New(), Email: "blocked@example.com", IsBlocked: true}
+	repo, users := newMagicLinkTestEnv(blocked)
+	svc := service.NewMagicLinkService(repo, users)
+
+	token, err := svc.RequestLink("nobody@example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, token)
+
+	token, err = svc.RequestLink("blocked@example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, token)
+	assert.Empty(t, repo.rows)
+}
+
+func TestMagicLinkService_ExpiredAndBlockedAfterRequest(t *testing.T) {
+	user := models.User{ID: uuid.New(), Email: "founder@example.com"}
+	repo, users := newMagicLinkTestEnv(user)
+	svc := service.NewMagicLinkService(repo, users)
+
+	token, _ := svc.RequestLink("founder@example.com")
+	repo.rows[1].ExpiresAt = time.Now().Add(-time.Second)
+	_, err := svc.Redeem(token)
+	assert.True(t, errors.Is(err, service.ErrMagicLinkInvalid))
+
+	token, _ = svc.RequestLink("founder@example.com")
+	user.IsBlocked = true
+	users.users[user.ID] = user
+	_, err = svc.Redeem(token)
+	assert.True(t, errors.Is(err, service.ErrAccountBlocked))
+}
+
+func TestMagicLink_Endpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var impersonated []string
+	idp := &stubIdentityProvider{IdentityProvider: newTestIdentityProvider(t)}
+	idp.impersonate = func(subject string) (*interfaces.TokenResponse, error) {
+		impersonated = append(impersonated, subject)
+		return &interfaces.TokenResponse{AccessToken: "access", RefreshToken: "refresh"}, nil
+	}
+
+	env := newTwoFactorTestEnv()
+	repo, users := newMagicLinkTestEnv(env.user)
+	svc := service.NewMagicLinkService(repo, users)
+	sender := &recordingSender{}
+
+	mc := controller.NewMagicLinkController(svc, users, env.svc, idp, "")
+	mc.Notifier = sender
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.POST("/auth/magic-link", mc.Request)
+	router.POST("/auth/magic-link/redeem", mc.Redeem)
+
+	do := func(path string, body any) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(raw))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Unknown and known emails get the same answer
+	unknown := do("/auth/magic-link", map[string]string{"email": "nobody@example.com"})
+	known := do("/auth/magic-link", map[string]string{"email": env.user.Email})
+	assert.Equal(t, http.StatusOK, unknown.Code)
+	assert.Equal(t, unknown.Body.String(), known.Body.String())
+	assert.Equal(t, http.StatusBadRequest, do("/auth/magic-link", map[string]string{}).Code)
+
+	token, _ := svc.RequestLink(env.user.Email)
+	w := do("/auth/magic-link/redeem", map[string]string{"token": token})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{env.user.KeycloakID}, impersonated)
+
+	assert.Equal(t, http.StatusUnauthorized, do("/auth/magic-link/redeem", map[string]string{"token": token}).Code)
+	assert.Equal(t, http.StatusBadRequest, do("/auth/magic-link/redeem", map[string]string{}).Code)
+
+	// With 2FA enabled the link only replaces the password step
+	env.enable(t)
+	token, _ = svc.RequestLink(env.user.Email)
+	w = do("/auth/magic-link/redeem", map[string]string{"token": token})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var challenge controller.LoginChallengeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &challenge))
+	assert.True(t, challenge.TwoFactorRequired)
+	assert.NotEmpty(t, challenge.ChallengeToken)
+}
+
+func TestRateLimit_PerClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.POST("/auth/forgot-password", middleware.RateLimit(2, time.Minute), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func(ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/auth/forgot-password", nil)
+		req.RemoteAddr = ip + ":1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, do("10.0.0.1").Code)
+	assert.Equal(t, http.StatusOK, do("10.0.0.1").Code)
+
+	w := do("10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, do("10.0.0.2").Code)
+}
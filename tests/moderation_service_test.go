@@ -0,0 +1,333 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeReportRepo keeps reports and actions in memory and records user updates
+type fakeReportRepo struct {
+	reports     map[uint]*models.UserReport
+	actions     []models.ModerationAction
+	userUpdates map[string]interface{}
+}
+
+func newFakeReportRepo() *fakeReportRepo {
+	return &fakeReportRepo{reports: map[uint]*models.UserReport{}}
+}
+
+func (f *fakeReportRepo) Create(r *models.UserReport) error {
+	r.ID = uint(len(f.reports) + 1)
+	f.reports[r.ID] = r
+	return nil
+}
+
+func (f *fakeReportRepo) FindByID(id uint) (*models.UserReport, error) {
+	r, ok := f.reports[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r
+	return &copied, nil
+}
+
+func (f *fakeReportRepo) HasOpenReport(reporterID, reportedID uuid.UUID) (bool, error) {
+	for _, r := range f.reports {
+		if r.ReporterID == reporterID && r.ReportedID == reportedID &&
+			(r.Status == models.ReportStatusOpen || r.Status == models.ReportStatusInReview) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeReportRepo) List(status models.ReportStatus, p interfaces.Pagination) ([]models.UserReport, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeReportRepo) Claim(id uint, moderatorID string, at time.Time) (bool, error) {
+	r, ok := f.reports[id]
+	if !ok {
+		return false, nil
+	}
+	if r.Status == models.ReportStatusOpen || (r.Status == models.ReportStatusInReview && r.ClaimedBy == moderatorID) {
+		r.Status = models.ReportStatusInReview
+		r.ClaimedBy = moderatorID
+		r.ClaimedAt = &at
+		return true, nil
+	}
+	return false, nil
+}
+
+func (f *fakeReportRepo) Resolve(r *models.UserReport, actions []models.ModerationAction, userUpdates map[string]interface{}) (bool, error) {
+	current, ok := f.reports[r.ID]
+	if !ok || !(current.Status == models.ReportStatusOpen || current.Status == models.ReportStatusInReview) ||
+		(current.ClaimedBy != "" && current.ClaimedBy != r.ResolvedBy) {
+		return false, nil
+	}
+	f.reports[r.ID] = r
+	f.actions = append(f.actions, actions...)
+	f.userUpdates = userUpdates
+	return true, nil
+}
+
+func (f *fakeReportRepo) AddAction(a *models.ModerationAction) error {
+	f.actions = append(f.actions, *a)
+	return nil
+}
+
+func (f *fakeReportRepo) Actions(reportID uint) ([]models.ModerationAction, error) {
+	out := []models.ModerationAction{}
+	for _, a := range f.actions {
+		if a.ReportID == reportID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// fakeStorage records removed object keys
+type fakeStorage struct {
+	removed []string
+}
+
+func (f *fakeStorage) KeyFromPublicURL(publicURL string) (string, bool) {
+	const base = "http://cdn.test/"
+	if len(publicURL) <= len(base) || publicURL[:len(base)] != base {
+		return "", false
+	}
+	return publicURL[len(base):], true
+}
+
+func (f *fakeStorage) RemoveObject(key string) error {
+	f.removed = append(f.removed, key)
+	return nil
+}
+
+// fakeDisabler records disabled Keycloak accounts
+type fakeDisabler struct {
+	disabled []string
+	err      error
+}
+
+func (f *fakeDisabler) DisableAccount(keycloakID string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.disabled = append(f.disabled, keycloakID)
+	return nil
+}
+
+type moderationTestEnv struct {
+	svc      interfaces.ModerationService
+	repo     *fakeReportRepo
+	storage  *fakeStorage
+	disabler *fakeDisabler
+	reporter models.User
+	reported models.User
+}
+
+func newModerationTestEnv() moderationTestEnv {
+	reporter := models.User{ID: uuid.New(), Email: "reporter@example.com"}
+	reported := models.User{
+		ID:              uuid.New(),
+		Email:           "fake@example.com",
+		KeycloakID:      "kc-fake",
+		ProfilePhotoURL: "http://cdn.test/users/kc-fake/profile.jpg",
+	}
+
+	users := &directoryUserService{users: map[uuid.UUID]models.User{reporter.ID: reporter, reported.ID: reported}}
+	repo := newFakeReportRepo()
+	storage := &fakeStorage{}
+	disabler := &fakeDisabler{}
+
+	return moderationTestEnv{
+		svc:      service.NewModerationService(repo, users, storage, disabler),
+		repo:     repo,
+		storage:  storage,
+		disabler: disabler,
+		reporter: reporter,
+		reported: reported,
+	}
+}
+
+func TestModerationService_ReportValidation(t *testing.T) {
+	env := newModerationTestEnv()
+
+	cases := []struct {
+		target uuid.UUID
+		input  interfaces.ReportInput
+		want   error
+	}{
+		{env.reporter.ID, interfaces.ReportInput{Reason: models.ReportReasonSpam}, service.ErrReportSelf},
+		{env.reported.ID, interfaces.ReportInput{Reason: "boring"}, service.ErrReportReason},
+		{uuid.New(), interfaces.ReportInput{Reason: models.ReportReasonSpam}, service.ErrProfileNotFound},
+	}
+	for _, tc := range cases {
+		if _, err := env.svc.Report(env.reporter, tc.target, tc.input); !errors.Is(err, tc.want) {
+			t.Fatalf("expected %v, got %v", tc.want, err)
+		}
+	}
+
+	report, err := env.svc.Report(env.reporter, env.reported.ID, interfaces.ReportInput{
+		Reason:  models.ReportReasonFakeProfile,
+		Details: "  claims to be an investor  ",
+	})
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if report.Status != models.ReportStatusOpen || report.Details != "claims to be an investor" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	_, err = env.svc.Report(env.reporter, env.reported.ID, interfaces.ReportInput{Reason: models.ReportReasonSpam})
+	if !errors.Is(err, service.ErrReportDuplicate) {
+		t.Fatalf("expected ErrReportDuplicate, got %v", err)
+	}
+}
+
+func TestModerationService_ClaimIsExclusive(t *testing.T) {
+	env := newModerationTestEnv()
+	report, _ := env.svc.Report(env.reporter, env.reported.ID, interfaces.ReportInput{Reason: models.ReportReasonSpam})
+
+	if _, err := env.svc.Claim(report.ID, "mod-1"); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := env.svc.Claim(report.ID, "mod-1"); err != nil {
+		t.Fatalf("re-claim by same moderator: %v", err)
+	}
+	if _, err := env.svc.Claim(report.ID, "mod-2"); !errors.Is(err, service.ErrReportClaimed) {
+		t.Fatalf("expected ErrReportClaimed, got %v", err)
+	}
+	if _, err := env.svc.Resolve(report.ID, "mod-2", interfaces.ResolveReportInput{}); !errors.Is(err, service.ErrReportClaimed) {
+		t.Fatalf("expected ErrReportClaimed on resolve, got %v", err)
+	}
+	if _, err := env.svc.Claim(999, "mod-1"); !errors.Is(err, service.ErrReportNotFound) {
+		t.Fatalf("expected ErrReportNotFound, got %v", err)
+	}
+}
+
+func TestModerationService_ResolveAppliesAndAuditsActions(t *testing.T) {
+	env := newModerationTestEnv()
+	report, _ := env.svc.Report(env.reporter, env.reported.ID, interfaces.ReportInput{Reason: models.ReportReasonInappropriatePhoto})
+	_, _ = env.svc.Claim(report.ID, "mod-1")
+
+	detail, err := env.svc.Resolve(report.ID, "mod-1", interfaces.ResolveReportInput{
+		Actions: []models.ModerationActionType{
+			models.ModerationActionRemovePhoto,
+			models.ModerationActionHideBio,
+			models.ModerationActionBlockAccount,
+		},
+		Note: "fake investor",
+	})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	if detail.Report.Status != models.ReportStatusResolved || detail.Report.ResolvedBy != "mod-1" {
+		t.Fatalf("unexpected report: %+v", detail.Report)
+	}
+	// claim + three actions
+	if len(detail.Actions) != 4 {
+		t.Fatalf("expected 4 audit rows, got %+v", detail.Actions)
+	}
+
+	if len(env.storage.removed) != 1 || env.storage.removed[0] != "users/kc-fake/profile.jpg" {
+		t.Fatalf("expected photo to be removed, got %v", env.storage.removed)
+	}
+	if len(env.disabler.disabled) != 1 || env.disabler.disabled[0] != "kc-fake" {
+		t.Fatalf("expected keycloak account to be disabled, got %v", env.disabler.disabled)
+	}
+
+	want := map[string]interface{}{"is_blocked": true, "profile_photo_url": "", "biography_hidden": true}
+	for k, v := range want {
+		if env.repo.userUpdates[k] != v {
+			t.Fatalf("expected %s=%v, got %v", k, v, env.repo.userUpdates[k])
+		}
+	}
+
+	if _, err := env.svc.Resolve(report.ID, "mod-1", interfaces.ResolveReportInput{}); !errors.Is(err, service.ErrReportClosed) {
+		t.Fatalf("expected ErrReportClosed, got %v", err)
+	}
+}
+
+// racingReportRepo lets another moderator close the report between the read and the write of Resolve.
+type racingReportRepo struct {
+	*fakeReportRepo
+}
+
+func (r racingReportRepo) Resolve(report *models.UserReport, actions []models.ModerationAction, userUpdates map[string]interface{}) (bool, error) {
+	r.reports[report.ID].Status = models.ReportStatusDismissed
+	r.reports[report.ID].ResolvedBy = "mod-2"
+	return r.fakeReportRepo.Resolve(report, actions, userUpdates)
+}
+
+func TestModerationService_ConcurrentResolveReturnsClosed(t *testing.T) {
+	env := newModerationTestEnv()
+	report, _ := env.svc.Report(env.reporter, env.reported.ID, interfaces.ReportInput{Reason: models.ReportReasonSpam})
+
+	users := &directoryUserService{users: map[uuid.UUID]models.User{env.reported.ID: env.reported}}
+	svc := service.NewModerationService(racingReportRepo{env.repo}, users, env.storage, env.disabler)
+
+	_, err := svc.Resolve(report.ID, "mod-1", interfaces.ResolveReportInput{Actions: []models.ModerationActionType{models.ModerationActionHideBio}})
+	if !errors.Is(err, service.ErrReportClosed) {
+		t.Fatalf("expected ErrReportClosed, got %v", err)
+	}
+	if env.repo.reports[report.ID].ResolvedBy != "mod-2" || len(env.repo.userUpdates) != 0 {
+		t.Fatalf("losing resolve must not overwrite the report or the user, got %+v %v", env.repo.reports[report.ID], env.repo.userUpdates)
+	}
+}
+
+func TestModerationService_ResolveWithoutActionsDismisses(t *testing.T) {
+	env := newModerationTestEnv()
+	report, _ := env.svc.Report(env.reporter, env.reported.ID, interfaces.ReportInput{Reason: models.ReportReasonOther})
+
+	detail, err := env.svc.Resolve(report.ID, "mod-1", interfaces.ResolveReportInput{Note: "nothing wrong"})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if detail.Report.Status != models.ReportStatusDismissed || detail.Report.ClaimedBy != "mod-1" {
+		t.Fatalf("unexpected report: %+v", detail.Report)
+	}
+	if len(detail.Actions) != 1 || detail.Actions[0].Action != models.ModerationActionDismiss {
+		t.Fatalf("expected a dismiss audit row, got %+v", detail.Actions)
+	}
+}
+
+func TestModerationService_KeycloakFailureKeepsReportOpen(t *testing.T) {
+	env := newModerationTestEnv()
+	env.disabler.err = errors.New("keycloak down")
+	report, _ := env.svc.Report(env.reporter, env.reported.ID, interfaces.ReportInput{Reason: models.ReportReasonFakeProfile})
+
+	_, err := env.svc.Resolve(report.ID, "mod-1", interfaces.ResolveReportInput{
+		Actions: []models.ModerationActionType{models.ModerationActionBlockAccount},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	stored, _ := env.repo.FindByID(report.ID)
+	if stored.Status != models.ReportStatusOpen || len(env.repo.actions) != 0 {
+		t.Fatalf("report must stay open without audit rows, got %+v", stored)
+	}
+}
+
+func TestModerationService_RejectsUnknownAction(t *testing.T) {
+	env := newModerationTestEnv()
+	report, _ := env.svc.Report(env.reporter, env.reported.ID, interfaces.ReportInput{Reason: models.ReportReasonSpam})
+
+	_, err := env.svc.Resolve(report.ID, "mod-1", interfaces.ResolveReportInput{
+		Actions: []models.ModerationActionType{"delete_everything"},
+	})
+	if !errors.Is(err, service.ErrReportAction) {
+		t.Fatalf("expected ErrReportAction, got %v", err)
+	}
+}
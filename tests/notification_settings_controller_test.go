@@ -8,6 +8,7 @@ import (
 
 	"group1-userservice/app/config"
 	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/middleware"
 	"group1-userservice/app/models"
 	"group1-userservice/app/repository"
 	"group1-userservice/app/service"
@@ -27,8 +28,10 @@ func setupNotificationTestRouter(t *testing.T) (*gin.Engine, *gorm.DB, *controll
 
 	if err := config.DB.AutoMigrate(
 		&models.User{},
+		&models.ProfileVersion{},
 		&models.NotificationSettings{},
 		&models.Interest{},
+		&models.InterestTranslation{},
 		&models.UserInterest{},
 	); err != nil {
 		t.Fatalf("failed to migrate test db: %v", err)
@@ -37,7 +40,7 @@ func setupNotificationTestRouter(t *testing.T) (*gin.Engine, *gorm.DB, *controll
 	config.SeedInterests()
 
 	userRepo := repository.NewUserRepository(config.DB)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, newTestIdentityProvider(t))
 
 	notifRepo := repository.NewNotificationSettingsRepository()
 	notifService := service.NewNotificationSettingsService(notifRepo)
@@ -45,6 +48,7 @@ func setupNotificationTestRouter(t *testing.T) (*gin.Engine, *gorm.DB, *controll
 	notifController := controller.NewNotificationSettingsController(notifService, userService)
 
 	router := gin.Default()
+	router.Use(middleware.ProblemDetails())
 
 	router.GET("/internal/users/email/:email/notification-settings", notifController.GetByEmailInternal)
 
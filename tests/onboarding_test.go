@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOnboardingRepo keeps the states in memory, copying them like a database round trip would
+type fakeOnboardingRepo struct {
+	states map[uuid.UUID]models.OnboardingState
+}
+
+func copyOnboardingState(s models.OnboardingState) *models.OnboardingState {
+	s.Steps = append([]models.OnboardingStepState(nil), s.Steps...)
+	return &s
+}
+
+func (f *fakeOnboardingRepo) FindOrCreate(userID uuid.UUID, fresh *models.OnboardingState) (*models.OnboardingState, bool, error) {
+	if f.states == nil {
+		f.states = map[uuid.UUID]models.OnboardingState{}
+	}
+	if s, ok := f.states[userID]; ok {
+		return copyOnboardingState(s), false, nil
+	}
+	f.states[userID] = *copyOnboardingState(*fresh)
+	return copyOnboardingState(*fresh), true, nil
+}
+
+func (f *fakeOnboardingRepo) Update(state *models.OnboardingState) error {
+	f.states[state.UserID] = *copyOnboardingState(*state)
+	return nil
+}
+
+func verifiedNow() *time.Time {
+	now := time.Now()
+	return &now
+}
+
+func onboardingStatus(state *models.OnboardingState) map[models.OnboardingStep]models.OnboardingStepStatus {
+	status := map[models.OnboardingStep]models.OnboardingStepStatus{}
+	for _, s := range state.Steps {
+		status[s.Step] = s.Status
+	}
+	return status
+}
+
+func TestOnboarding_WaitsForEmailVerification(t *testing.T) {
+	svc := service.NewOnboardingService(&fakeOnboardingRepo{}, &fakeCompletenessRepo{}, &fakeNotificationSettingsService{})
+	u := models.User{ID: uuid.New()}
+
+	state, err := svc.Get(u)
+	assert.NoError(t, err)
+	assert.Len(t, state.Steps, len(models.OnboardingSteps))
+	assert.Equal(t, models.OnboardingVerifyEmail, state.CurrentStep)
+	assert.False(t, state.Steps[0].Skippable)
+	assert.True(t, state.Steps[2].Skippable)
+
+	// email verification is a system step: it cannot be completed by hand and completes by itself
+	_, err = svc.Complete(u, models.OnboardingVerifyEmail, interfaces.OnboardingStepInput{})
+	assert.True(t, errors.Is(err, service.ErrOnboardingSystemStep))
+
+	u.EmailVerifiedAt = verifiedNow()
+	state, err = svc.Get(u)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OnboardingStepCompleted, state.Steps[0].Status)
+	assert.NotNil(t, state.Steps[0].FinishedAt)
+	assert.Equal(t, models.OnboardingBasicProfile, state.CurrentStep)
+	assert.Nil(t, state.CompletedAt)
+}
+
+func TestOnboarding_FullFlowIsResumable(t *testing.T) {
+	repo := &fakeOnboardingRepo{}
+	profile := &fakeCompletenessRepo{}
+	notifications := &fakeNotificationSettingsService{settings: models.NotificationSettings{
+		ChatEmail: true, ChatPush: true, ConnectionEmail: true, ConnectionPush: true, SystemEmail: true,
+	}}
+	svc := service.NewOnboardingService(repo, profile, notifications)
+	u := models.User{ID: uuid.New(), Email: "john@example.com", EmailVerifiedAt: verifiedNow()}
+
+	_, err := svc.Complete(u, models.OnboardingBasicProfile, interfaces.OnboardingStepInput{})
+	assert.True(t, errors.Is(err, service.ErrOnboardingStepIncomplete))
+	assert.Contains(t, err.Error(), "first_name, last_name, country")
+
+	u.FirstName, u.LastName, u.Country = "John", "Doe", "NL"
+	_, err = svc.Complete(u, models.OnboardingBasicProfile, interfaces.OnboardingStepInput{})
+	assert.NoError(t, err)
+
+	// steps further ahead cannot be taken yet
+	_, err = svc.Complete(u, models.OnboardingInterests, interfaces.OnboardingStepInput{})
+	assert.True(t, errors.Is(err, service.ErrOnboardingStepNotCurrent))
+
+	_, err = svc.Skip(u, models.OnboardingPhoto)
+	assert.NoError(t, err)
+
+	// a new service instance picks up where the user left off
+	svc = service.NewOnboardingService(repo, profile, notifications)
+	state, err := svc.Get(u)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OnboardingInterests, state.CurrentStep)
+
+	profile.interests = 2
+	_, err = svc.Complete(u, models.OnboardingInterests, interfaces.OnboardingStepInput{})
+	assert.NoError(t, err)
+	_, err = svc.Skip(u, models.OnboardingDiscoveryRadius)
+	assert.NoError(t, err)
+
+	_, err = svc.Complete(u, models.OnboardingNotificationConsent, interfaces.OnboardingStepInput{})
+	assert.True(t, errors.Is(err, service.ErrOnboardingStepIncomplete))
+
+	granted := false
+	state, err = svc.Complete(u, models.OnboardingNotificationConsent, interfaces.OnboardingStepInput{Granted: &granted})
+	assert.NoError(t, err)
+	assert.NotNil(t, state.CompletedAt)
+	assert.Empty(t, state.CurrentStep)
+	assert.Equal(t, &granted, state.NotificationConsent)
+	// declining turns the optional notifications off
+	if assert.NotNil(t, notifications.saved) {
+		assert.Equal(t, "john@example.com", notifications.saved.UserEmail)
+		assert.False(t, notifications.saved.ChatPush)
+		assert.False(t, notifications.saved.ConnectionEmail)
+		assert.False(t, notifications.saved.ConnectionPush)
+		assert.True(t, notifications.saved.SystemEmail)
+	}
+	assert.Equal(t, map[models.OnboardingStep]models.OnboardingStepStatus{
+		models.OnboardingVerifyEmail:         models.OnboardingStepCompleted,
+		models.OnboardingBasicProfile:        models.OnboardingStepCompleted,
+		models.OnboardingPhoto:               models.OnboardingStepSkipped,
+		models.OnboardingInterests:           models.OnboardingStepCompleted,
+		models.OnboardingDiscoveryRadius:     models.OnboardingStepSkipped,
+		models.OnboardingNotificationConsent: models.OnboardingStepCompleted,
+	}, onboardingStatus(state))
+}
+
+func TestOnboarding_GrantedConsentKeepsNotificationSettings(t *testing.T) {
+	repo := &fakeOnboardingRepo{}
+	notifications := &fakeNotificationSettingsService{}
+	svc := service.NewOnboardingService(repo, &fakeCompletenessRepo{}, notifications)
+	u := models.User{ID: uuid.New(), FirstName: "John", LastName: "Doe", Country: "NL", EmailVerifiedAt: verifiedNow()}
+
+	_, err := svc.Complete(u, models.OnboardingBasicProfile, interfaces.OnboardingStepInput{})
+	assert.NoError(t, err)
+	for _, step := range []models.OnboardingStep{models.OnboardingPhoto, models.OnboardingInterests, models.OnboardingDiscoveryRadius} {
+		_, err = svc.Skip(u, step)
+		assert.NoError(t, err)
+	}
+
+	granted := true
+	state, err := svc.Complete(u, models.OnboardingNotificationConsent, interfaces.OnboardingStepInput{Granted: &granted})
+	assert.NoError(t, err)
+	assert.NotNil(t, state.CompletedAt)
+	assert.Nil(t, notifications.saved)
+}
+
+func TestOnboarding_SkipRules(t *testing.T) {
+	svc := service.NewOnboardingService(&fakeOnboardingRepo{}, &fakeCompletenessRepo{}, &fakeNotificationSettingsService{})
+	u := models.User{ID: uuid.New(), FirstName: "John", LastName: "Doe", Country: "NL", EmailVerifiedAt: verifiedNow()}
+
+	_, err := svc.Skip(u, models.OnboardingBasicProfile)
+	assert.True(t, errors.Is(err, service.ErrOnboardingStepNotSkippable))
+
+	_, err = svc.Skip(u, models.OnboardingVerifyEmail)
+	assert.True(t, errors.Is(err, service.ErrOnboardingSystemStep))
+
+	_, err = svc.Complete(u, "shoe_size", interfaces.OnboardingStepInput{})
+	assert.True(t, errors.Is(err, service.ErrOnboardingUnknownStep))
+
+	// completing twice is harmless, but a completed step cannot be skipped afterwards
+	_, err = svc.Complete(u, models.OnboardingBasicProfile, interfaces.OnboardingStepInput{})
+	assert.NoError(t, err)
+	state, err := svc.Complete(u, models.OnboardingBasicProfile, interfaces.OnboardingStepInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.OnboardingPhoto, state.CurrentStep)
+	_, err = svc.Skip(u, models.OnboardingBasicProfile)
+	assert.True(t, errors.Is(err, service.ErrOnboardingStepNotSkippable))
+}
+
+func TestOnboardingController_Steps(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	u := models.User{ID: uuid.New(), FirstName: "John", LastName: "Doe", Country: "NL", EmailVerifiedAt: verifiedNow()}
+	oc := controller.NewOnboardingController(
+		service.NewOnboardingService(&fakeOnboardingRepo{}, &fakeCompletenessRepo{}, &fakeNotificationSettingsService{}),
+		&versionedUserService{user: u},
+	)
+
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.Use(func(c *gin.Context) { c.Set("user_id", "kc-123"); c.Next() })
+	router.GET("/users/me/onboarding", oc.GetForMe)
+	router.POST("/users/me/onboarding/steps/:step/complete", oc.CompleteStep)
+	router.POST("/users/me/onboarding/steps/:step/skip", oc.SkipStep)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/users/me/onboarding", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var state models.OnboardingState
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &state))
+	assert.Equal(t, models.OnboardingBasicProfile, state.CurrentStep)
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/users/me/onboarding/steps/nope/complete", "").Code)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/users/me/onboarding/steps/photo/skip", "").Code)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/users/me/onboarding/steps/basic_profile/skip", "").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/users/me/onboarding/steps/basic_profile/complete", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/users/me/onboarding/steps/photo/complete", `{"shoe":1}`).Code)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/users/me/onboarding/steps/photo/complete", "").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/users/me/onboarding/steps/photo/skip", "").Code)
+}
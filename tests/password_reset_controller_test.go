@@ -9,6 +9,7 @@ import (
 
 	controller "group1-userservice/app/controllers"
 	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -27,19 +28,20 @@ func (f *fakePasswordResetService) RequestReset(email string) (string, error) {
 	return "dummy-token", nil
 }
 
-func (f *fakePasswordResetService) ResetPassword(token string, newPassword string) error {
+func (f *fakePasswordResetService) ResetPassword(token string, newPassword string) (string, error) {
 	if f.resetFn != nil {
-		return f.resetFn(token, newPassword)
+		return "", f.resetFn(token, newPassword)
 	}
-	return nil
+	return "", nil
 }
 
 func setupPasswordResetRouter(t *testing.T, svc interfaces.PasswordResetService) *gin.Engine {
 	t.Helper()
 	gin.SetMode(gin.TestMode)
 
-	pc := controller.NewPasswordResetController(svc, "")
+	pc := controller.NewPasswordResetController(svc, &fakeUserService{}, "")
 	r := gin.Default()
+	r.Use(middleware.ProblemDetails())
 	r.POST("/auth/forgot-password", pc.Forgot)
 	r.POST("/auth/reset-password", pc.Reset)
 	return r
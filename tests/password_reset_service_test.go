@@ -4,20 +4,20 @@ import (
 	"testing"
 
 	"group1-userservice/app/interfaces"
-	"group1-userservice/app/keycloak"
 	"group1-userservice/app/models"
 	"group1-userservice/app/service"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestPasswordResetService_ResetPassword_Success(t *testing.T) {
-	orig := keycloak.ResetPasswordByEmail
-	defer func() { keycloak.ResetPasswordByEmail = orig }()
+	idp := newTestIdentityProvider(t)
+	subject, err := idp.CreateUser(interfaces.IdentityUser{Email: "test@example.com"}, "Oud12345")
+	assert.NoError(t, err)
 
-	keycloak.ResetPasswordByEmail = func(email, pw string) error {
-		return nil
-	}
+	user := models.User{ID: uuid.New(), KeycloakID: subject, Email: "test@example.com"}
+	users := &accountUserService{directoryUserService{users: map[uuid.UUID]models.User{user.ID: user}}}
 
 	repo := &fakeResetRepo{
 		row: &models.PasswordResetToken{
@@ -28,8 +28,15 @@ func TestPasswordResetService_ResetPassword_Success(t *testing.T) {
 
 	var _ interfaces.PasswordResetRepository = repo
 
-	svc := service.NewPasswordResetService(repo, &fakeUserService{})
+	svc := service.NewPasswordResetService(repo, users, idp)
+
+	email, err := svc.ResetPassword("raw-token", "Welkom1234")
+	assert.NoError(t, err)
+	assert.Equal(t, "test@example.com", email)
 
-	err := svc.ResetPassword("raw-token", "Welkom1234")
+	// The new password now works at the identity provider, the old one does not
+	_, err = idp.PasswordGrant("test@example.com", "Welkom1234")
 	assert.NoError(t, err)
+	_, err = idp.PasswordGrant("test@example.com", "Oud12345")
+	assert.ErrorIs(t, err, interfaces.ErrInvalidCredentials)
 }
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"group1-userservice/app/apperrors"
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/service"
+	"group1-userservice/app/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveProblem(t *testing.T, handlers ...gin.HandlerFunc) (*httptest.ResponseRecorder, middleware.Problem) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.GET("/things/1", handlers...)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/things/1", nil)
+	router.ServeHTTP(w, req)
+
+	var problem middleware.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("body is not a problem: %v: %s", err, w.Body.String())
+	}
+	return w, problem
+}
+
+func TestProblem_TypedErrorMapsToStatusAndCode(t *testing.T) {
+	w, problem := serveProblem(t, func(c *gin.Context) {
+		c.Error(service.ErrUserNotFound)
+	})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "user_not_found", problem.Code)
+	assert.Equal(t, "user not found", problem.Detail)
+	assert.Equal(t, "/things/1", problem.Instance)
+}
+
+func TestProblem_DetailKeepsCodeAndKind(t *testing.T) {
+	base := apperrors.Conflict("thing_taken", "thing is taken")
+	detailed := base.Detailf("by %s", "someone")
+	assert.True(t, errors.Is(detailed, base))
+	assert.True(t, errors.Is(detailed, apperrors.ErrConflict))
+
+	w, problem := serveProblem(t, func(c *gin.Context) {
+		c.Error(detailed)
+	})
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "thing_taken", problem.Code)
+	assert.Equal(t, "thing is taken: by someone", problem.Detail)
+}
+
+func TestProblem_ValidationErrorsListFields(t *testing.T) {
+	w, problem := serveProblem(t, func(c *gin.Context) {
+		c.Error(validation.Errors{"email": {Key: "validation.email_invalid"}})
+	})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "validation_failed", problem.Code)
+	assert.Equal(t, "must be a valid email address", problem.Fields["email"])
+}
+
+func TestProblem_UntypedErrorDoesNotLeak(t *testing.T) {
+	w, problem := serveProblem(t, func(c *gin.Context) {
+		c.Error(errors.New("pq: connection refused to 10.0.0.7"))
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.NotContains(t, w.Body.String(), "10.0.0.7")
+}
+
+func TestProblem_MiddlewareAbortIsRendered(t *testing.T) {
+	called := false
+	w, problem := serveProblem(t,
+		func(c *gin.Context) {
+			middleware.AbortWithError(c, apperrors.Forbidden("role_required", "insufficient permissions"))
+		},
+		func(c *gin.Context) { called = true },
+	)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "role_required", problem.Code)
+}
+
+func TestProblem_InvalidJSONDoesNotLeakGoTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mc := controller.NewModerationController(nil, nil)
+
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.POST("/admin/reports/:reportId/resolve", func(c *gin.Context) {
+		c.Set("user_id", "mod-1")
+		mc.Resolve(c)
+	})
+
+	cases := map[string]string{
+		`{"note": 5}`:    "note has the wrong type",
+		`{"notes": ""}`:  "unknown field notes",
+		`{"note": "x"`:   "invalid request body",
+		`{"note": "x",}`: "the body is not valid JSON",
+	}
+	for body, detail := range cases {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/admin/reports/1/resolve", bytes.NewBufferString(body))
+		router.ServeHTTP(w, req)
+
+		var problem middleware.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("body is not a problem: %v: %s", err, w.Body.String())
+		}
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Equal(t, "invalid_input", problem.Code, body)
+		assert.Contains(t, problem.Detail, detail, body)
+		assert.NotContains(t, problem.Detail, "Go value", body)
+		assert.NotContains(t, problem.Detail, "string", body)
+	}
+}
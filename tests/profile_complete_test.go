@@ -5,6 +5,8 @@ import (
 
 	"group1-userservice/app/models"
 	"group1-userservice/app/service"
+
+	"github.com/google/uuid"
 )
 
 func TestIsProfileComplete(t *testing.T) {
@@ -31,3 +33,43 @@ func TestIsProfileComplete(t *testing.T) {
 		t.Error("profile should NOT be complete")
 	}
 }
+
+// recordingBadgeService remembers which badges are held
+type recordingBadgeService struct {
+	fakeBadgeService
+	held map[string]bool
+}
+
+func (r *recordingBadgeService) AwardBadge(userID uuid.UUID, badgeKey string) (bool, error) {
+	r.held[badgeKey] = true
+	return true, nil
+}
+
+func (r *recordingBadgeService) RevokeBadge(userID uuid.UUID, badgeKey string) (bool, error) {
+	delete(r.held, badgeKey)
+	return true, nil
+}
+
+func TestSyncProfileCompleteBadge_AwardsAndRevokes(t *testing.T) {
+	badges := &recordingBadgeService{held: map[string]bool{}}
+	u := models.User{
+		ID:              uuid.New(),
+		FirstName:       "John",
+		LastName:        "Doe",
+		PhoneNumber:     "123",
+		Country:         "NL",
+		JobFunction:     "Developer",
+		Sector:          "IT",
+		Biography:       "Hello, I am John",
+		ProfilePhotoURL: "http://example.com/p.jpg",
+	}
+
+	if err := service.SyncProfileCompleteBadge(badges, u); err != nil || !badges.held[service.BadgeKeyProfileComplete] {
+		t.Fatalf("expected profile_complete to be awarded, err=%v", err)
+	}
+
+	u.Biography = ""
+	if err := service.SyncProfileCompleteBadge(badges, u); err != nil || badges.held[service.BadgeKeyProfileComplete] {
+		t.Fatalf("expected profile_complete to be revoked, err=%v", err)
+	}
+}
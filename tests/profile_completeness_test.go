@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCompletenessRepo struct {
+	interests int64
+	prefs     bool
+}
+
+func (f *fakeCompletenessRepo) CountSelectedInterests(email string) (int64, error) {
+	return f.interests, nil
+}
+
+func (f *fakeCompletenessRepo) HasDiscoveryPreferences(email string) (bool, error) {
+	return f.prefs, nil
+}
+
+// userByIDService returns the same user for every ID
+type userByIDService struct {
+	fakeUserService
+	user models.User
+}
+
+func (u *userByIDService) GetByID(id uuid.UUID) (models.User, error) {
+	return u.user, nil
+}
+
+func completeUser() models.User {
+	return models.User{
+		ID:              uuid.New(),
+		FirstName:       "John",
+		LastName:        "Doe",
+		Email:           "john@example.com",
+		PhoneNumber:     "+31612345678",
+		Country:         "NL",
+		JobFunction:     "software_development",
+		Sector:          "ict",
+		Biography:       strings.Repeat("a", 60),
+		ProfilePhotoURL: "http://example.com/p.jpg",
+	}
+}
+
+func TestProfileCompleteness_FullProfile(t *testing.T) {
+	svc := service.NewProfileCompletenessService(&fakeCompletenessRepo{interests: 2, prefs: true}, service.DefaultCompletenessConfig())
+
+	result, err := svc.ForUser(completeUser(), i18n.English)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, result.Percentage)
+	assert.True(t, result.Complete)
+	assert.Empty(t, result.Missing)
+	assert.Empty(t, result.NextSteps)
+}
+
+func TestProfileCompleteness_WeightsAndNextSteps(t *testing.T) {
+	svc := service.NewProfileCompletenessService(&fakeCompletenessRepo{}, service.DefaultCompletenessConfig())
+
+	u := completeUser()
+	u.Biography = "Hi there"
+	u.PhoneNumber = ""
+
+	result, err := svc.ForUser(u, i18n.English)
+	assert.NoError(t, err)
+	// missing: phone 5, interests 15, discovery 5; weak biography loses half of 15
+	assert.Equal(t, 68, result.Percentage)
+	assert.False(t, result.Complete)
+
+	status := map[string]models.CompletenessStatus{}
+	for _, item := range result.Missing {
+		status[item.Field] = item.Status
+	}
+	assert.Equal(t, map[string]models.CompletenessStatus{
+		"biography":             models.CompletenessWeak,
+		"phone_number":          models.CompletenessMissing,
+		"interests":             models.CompletenessMissing,
+		"discovery_preferences": models.CompletenessMissing,
+	}, status)
+
+	// required and missing first, then the weak required field, then the heaviest optional one
+	assert.Len(t, result.NextSteps, 3)
+	assert.Equal(t, "phone_number", result.NextSteps[0].Field)
+	assert.Equal(t, "biography", result.NextSteps[1].Field)
+	assert.Equal(t, "interests", result.NextSteps[2].Field)
+	assert.Equal(t, "PUT /users/me/interests", result.NextSteps[2].Endpoint)
+}
+
+func TestCompletenessConfigFromEnv(t *testing.T) {
+	t.Setenv("PROFILE_COMPLETENESS_WEIGHTS", "interests=0, biography=30")
+	t.Setenv("PROFILE_REQUIRED_FIELDS", "first_name,last_name,interests")
+	t.Setenv("PROFILE_MIN_BIOGRAPHY_LENGTH", "10")
+
+	cfg, err := service.CompletenessConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cfg.Weights["interests"])
+	assert.Equal(t, 30, cfg.Weights["biography"])
+	assert.Equal(t, map[string]bool{"first_name": true, "last_name": true, "interests": true}, cfg.Required)
+
+	// a required field without weight still decides whether the profile is complete
+	svc := service.NewProfileCompletenessService(&fakeCompletenessRepo{prefs: true}, cfg)
+	result, err := svc.ForUser(completeUser(), i18n.English)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, result.Percentage)
+	assert.False(t, result.Complete)
+
+	t.Setenv("PROFILE_COMPLETENESS_WEIGHTS", "shoe_size=5")
+	_, err = service.CompletenessConfigFromEnv()
+	assert.Error(t, err)
+}
+
+func TestProfileCompleteness_InternalEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := service.NewProfileCompletenessService(&fakeCompletenessRepo{interests: 1, prefs: true}, service.DefaultCompletenessConfig())
+	pc := controller.NewProfileCompletenessController(svc, &userByIDService{user: completeUser()})
+
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.GET("/internal/users/id/:id/profile-completeness", pc.GetByIDInternal)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/internal/users/id/not-a-uuid/profile-completeness", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/internal/users/id/"+uuid.NewString()+"/profile-completeness", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result models.ProfileCompleteness
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 100, result.Percentage)
+	assert.True(t, result.Complete)
+}
@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"group1-userservice/app/config"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+	"group1-userservice/app/service"
+	"group1-userservice/app/validation"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeProfileHistoryRepo keeps versions of a single user in memory, oldest first
+type fakeProfileHistoryRepo struct {
+	versions []models.ProfileVersion
+	restored *models.ProfileVersion
+}
+
+func (f *fakeProfileHistoryRepo) List(userID uuid.UUID, offset, limit int) ([]models.ProfileVersion, int64, error) {
+	out := []models.ProfileVersion{}
+	for i := len(f.versions) - 1 - offset; i >= 0 && len(out) < limit; i-- {
+		out = append(out, f.versions[i])
+	}
+	return out, int64(len(f.versions)), nil
+}
+
+// FindVocabularyTerm only knows the ICT sector
+func (f *fakeProfileHistoryRepo) FindVocabularyTerm(v models.Vocabulary, value string) (*models.VocabularyTerm, error) {
+	if v == models.VocabularySectors && strings.EqualFold(value, "ict") {
+		return &models.VocabularyTerm{Key: "ict", LabelNL: "ICT", LabelEN: "ICT"}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeProfileHistoryRepo) Find(userID uuid.UUID, version int) (*models.ProfileVersion, error) {
+	for _, v := range f.versions {
+		if v.Version == version {
+			return &v, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeProfileHistoryRepo) Restore(userID uuid.UUID, v models.ProfileVersion) (models.User, error) {
+	f.restored = &v
+	s := v.Snapshot
+	return models.User{ID: userID, FirstName: s.FirstName, LastName: s.LastName, Biography: s.Biography}, nil
+}
+
+func newFakeHistory() *fakeProfileHistoryRepo {
+	return &fakeProfileHistoryRepo{versions: []models.ProfileVersion{
+		{Version: 1, Reason: models.ProfileVersionInitial, Snapshot: models.ProfileSnapshot{FirstName: "Jane", LastName: "Doe"}},
+		{Version: 2, Reason: models.ProfileVersionUpdate, Snapshot: models.ProfileSnapshot{FirstName: "Jane", LastName: "Doe", Biography: "Hello"}},
+		{Version: 3, Reason: models.ProfileVersionUpdate, Snapshot: models.ProfileSnapshot{FirstName: "Jane", LastName: "Smith", Biography: "Hello"}},
+	}}
+}
+
+func TestProfileHistory_ListsNewestFirstWithDiffs(t *testing.T) {
+	svc := service.NewProfileHistoryService(newFakeHistory())
+
+	page, err := svc.History(uuid.New(), interfaces.Pagination{Page: 1, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Len(t, page.Items, 2)
+
+	assert.Equal(t, 3, page.Items[0].Version)
+	assert.Len(t, page.Items[0].Changes, 1)
+	assert.Equal(t, "Doe", page.Items[0].Changes["last_name"].Before)
+	assert.Equal(t, "Smith", page.Items[0].Changes["last_name"].After)
+
+	// the oldest item on the page is still compared with its predecessor
+	assert.Equal(t, 2, page.Items[1].Version)
+	assert.Len(t, page.Items[1].Changes, 1)
+	assert.Equal(t, "Hello", page.Items[1].Changes["biography"].After)
+}
+
+func TestProfileHistory_Restore(t *testing.T) {
+	repo := newFakeHistory()
+	svc := service.NewProfileHistoryService(repo)
+	user := models.User{ID: uuid.New(), FirstName: "Jane", LastName: "Smith"}
+
+	restored, err := svc.Restore(user, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Doe", restored.LastName)
+	assert.Equal(t, 2, repo.restored.Version)
+
+	_, err = svc.Restore(user, 9)
+	assert.ErrorIs(t, err, service.ErrProfileVersionNotFound)
+}
+
+func TestProfileHistory_RestoreRejectsNamelessSnapshot(t *testing.T) {
+	repo := &fakeProfileHistoryRepo{versions: []models.ProfileVersion{
+		{Version: 1, Reason: models.ProfileVersionInitial, Snapshot: models.ProfileSnapshot{FirstName: "Jane"}},
+	}}
+	svc := service.NewProfileHistoryService(repo)
+
+	_, err := svc.Restore(models.User{ID: uuid.New()}, 1)
+	var fields validation.Errors
+	assert.ErrorAs(t, err, &fields)
+	assert.Contains(t, fields, "last_name")
+	assert.Nil(t, repo.restored)
+}
+
+func TestProfileHistory_RestoreChecksVocabularies(t *testing.T) {
+	repo := &fakeProfileHistoryRepo{versions: []models.ProfileVersion{
+		{Version: 1, Snapshot: models.ProfileSnapshot{FirstName: "Jane", LastName: "Doe", Sector: "ICT"}},
+		{Version: 2, Snapshot: models.ProfileSnapshot{FirstName: "Jane", LastName: "Doe", Sector: "Software"}},
+	}}
+	svc := service.NewProfileHistoryService(repo)
+
+	_, err := svc.Restore(models.User{ID: uuid.New()}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "ict", repo.restored.Snapshot.Sector)
+
+	repo.restored = nil
+	_, err = svc.Restore(models.User{ID: uuid.New()}, 2)
+	var fields validation.Errors
+	assert.ErrorAs(t, err, &fields)
+	assert.Contains(t, fields, "sector")
+	assert.Nil(t, repo.restored)
+}
+
+func TestProfileHistoryRepository_RecordsUpdatesAndRestores(t *testing.T) {
+	db := openTestDB(t)
+	config.DB = db
+	if err := db.AutoMigrate(&models.User{}, &models.ProfileVersion{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	users := repository.NewUserRepository(db)
+	history := repository.NewProfileHistoryRepository(db)
+
+	user := models.User{Email: "history@example.com", KeycloakID: "kc-history", FirstName: "Jane", LastName: "Doe"}
+	assert.NoError(t, users.Create(&user))
+
+	_, err := users.UpdateFieldsByEmail(user.Email, map[string]any{"biography": "Hello"})
+	assert.NoError(t, err)
+	// unchanged values do not add a version
+	_, err = users.UpdateFieldsByEmail(user.Email, map[string]any{"biography": "Hello"})
+	assert.NoError(t, err)
+	assert.NoError(t, users.UpdateProfilePhotoURLByKeycloakID(user.KeycloakID, "http://cdn/photo.jpg"))
+
+	versions, total, err := history.List(user.ID, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, models.ProfileVersionPhoto, versions[0].Reason)
+	assert.Equal(t, models.ProfileVersionUpdate, versions[1].Reason)
+	assert.Equal(t, models.ProfileVersionInitial, versions[2].Reason)
+	assert.Equal(t, "", versions[2].Snapshot.Biography)
+
+	initial, err := history.Find(user.ID, 1)
+	assert.NoError(t, err)
+
+	restored, err := history.Restore(user.ID, *initial)
+	assert.NoError(t, err)
+	assert.Equal(t, "", restored.Biography)
+	assert.Equal(t, "", restored.ProfilePhotoURL)
+
+	latest, err := history.Find(user.ID, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ProfileVersionRestore, latest.Reason)
+	assert.Equal(t, 1, *latest.RestoredFrom)
+}
+
+func TestProfileHistoryRepository_RestoreKeepsModeratedPhotoRemoved(t *testing.T) {
+	db := openTestDB(t)
+	config.DB = db
+	if err := db.AutoMigrate(&models.User{}, &models.ProfileVersion{}, &models.UserReport{}, &models.ModerationAction{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	users := repository.NewUserRepository(db)
+	history := repository.NewProfileHistoryRepository(db)
+
+	user := models.User{Email: "moderated@example.com", KeycloakID: "kc-moderated", FirstName: "Jane", LastName: "Doe"}
+	assert.NoError(t, users.Create(&user))
+	assert.NoError(t, users.UpdateProfilePhotoURLByKeycloakID(user.KeycloakID, "http://cdn/photo.jpg"))
+
+	report := models.UserReport{ReporterID: uuid.New(), ReportedID: user.ID, Reason: models.ReportReasonInappropriatePhoto, Status: models.ReportStatusOpen}
+	assert.NoError(t, db.Create(&report).Error)
+	report.Status = models.ReportStatusResolved
+	report.ResolvedBy = "mod-1"
+	resolved, err := repository.NewUserReportRepository(db).Resolve(&report, nil, map[string]interface{}{"profile_photo_url": ""})
+	assert.NoError(t, err)
+	assert.True(t, resolved)
+
+	versions, _, err := history.List(user.ID, 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 3) {
+		assert.Equal(t, models.ProfileVersionModeration, versions[0].Reason)
+	}
+
+	withPhoto, err := history.Find(user.ID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://cdn/photo.jpg", withPhoto.Snapshot.ProfilePhotoURL)
+
+	restored, err := history.Restore(user.ID, *withPhoto)
+	assert.NoError(t, err)
+	assert.Equal(t, "", restored.ProfilePhotoURL)
+	assert.Equal(t, "Doe", restored.LastName)
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"group1-userservice/app/config"
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupPrivacyTestRouter configures a Postgres test DB and initializes the ProfileVisibilityController
+func setupPrivacyTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db := openTestDB(t)
+	config.DB = db
+
+	if err := config.DB.AutoMigrate(
+		&models.User{},
+		&models.ProfileVersion{},
+		&models.ProfileVisibility{},
+	); err != nil {
+		t.Fatalf("failed to migrate tables: %v", err)
+	}
+
+	userService := service.NewUserService(repository.NewUserRepository(config.DB), newTestIdentityProvider(t))
+	visibilityService := service.NewProfileVisibilityService(
+		repository.NewProfileVisibilityRepository(config.DB),
+		userService,
+	)
+	privacyController := controller.NewProfileVisibilityController(visibilityService, userService)
+
+	router := gin.Default()
+	router.Use(middleware.ProblemDetails())
+	router.GET("/users/me/privacy", func(c *gin.Context) {
+		c.Set("user_id", "kc-privacy-1")
+		privacyController.GetForMe(c)
+	})
+	router.PUT("/users/me/privacy", func(c *gin.Context) {
+		c.Set("user_id", "kc-privacy-1")
+		privacyController.UpdateForMe(c)
+	})
+
+	return router, db
+}
+
+func TestPrivacy_Get_ReturnsDefaults(t *testing.T) {
+	router, db := setupPrivacyTestRouter(t)
+	createUserTestUser(t, db, "privacy@example.com", "kc-privacy-1", "hash")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/me/privacy", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `"email":"public"`)
+	assert.Contains(t, body, `"phone_number":"private"`)
+}
+
+func TestPrivacy_Update_PersistsAndSyncsPhoneFlag(t *testing.T) {
+	router, db := setupPrivacyTestRouter(t)
+	user := createUserTestUser(t, db, "privacy@example.com", "kc-privacy-1", "hash")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/users/me/privacy",
+		bytes.NewBufferString(`{"phone_number":"connections","biography":"private"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var saved models.ProfileVisibility
+	assert.NoError(t, db.First(&saved, "user_id = ?", user.ID).Error)
+	assert.Equal(t, models.VisibilityConnections, saved.PhoneNumber)
+	assert.Equal(t, models.VisibilityPrivate, saved.Biography)
+	assert.Equal(t, models.VisibilityPublic, saved.Email)
+
+	var fromDB models.User
+	assert.NoError(t, db.First(&fromDB, "id = ?", user.ID).Error)
+	assert.True(t, fromDB.PhoneNumberVisible)
+}
+
+func TestPrivacy_Update_InvalidValue_Returns400(t *testing.T) {
+	router, db := setupPrivacyTestRouter(t)
+	createUserTestUser(t, db, "privacy@example.com", "kc-privacy-1", "hash")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/users/me/privacy", bytes.NewBufferString(`{"email":"friends"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "email must be one of")
+}
+
+func TestPrivacy_Update_UnknownField_Returns400(t *testing.T) {
+	router, db := setupPrivacyTestRouter(t)
+	createUserTestUser(t, db, "privacy@example.com", "kc-privacy-1", "hash")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/users/me/privacy", bytes.NewBufferString(`{"first_name":"private"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newProfileTestUser() models.User {
+	return models.User{
+		ID:              uuid.New(),
+		KeycloakID:      "kc-projection",
+		Email:           "jane@example.com",
+		Password:        "hash",
+		FirstName:       "Jane",
+		LastName:        "Doe",
+		PhoneNumber:     "+31612345678",
+		Country:         "NL",
+		JobFunction:     "Founder",
+		Sector:          "ICT",
+		Biography:       "Building things",
+		IsBlocked:       true,
+		ProfilePhotoURL: "http://example.com/p.jpg",
+	}
+}
+
+func TestProjectProfile_DefaultsHidePhoneNumber(t *testing.T) {
+	u := newProfileTestUser()
+
+	p := service.ProjectProfile(u, service.DefaultProfileVisibility(u), models.RelationshipPublic, nil)
+
+	assert.Equal(t, u.ID, p.ID)
+	assert.Equal(t, "Jane", p.FirstName)
+	assert.Equal(t, "jane@example.com", p.Email)
+	assert.Equal(t, "Building things", p.Biography)
+	assert.Equal(t, "ICT", p.Sector)
+	assert.Equal(t, "", p.PhoneNumber)
+	assert.NotNil(t, p.Badges)
+}
+
+func TestProjectProfile_ShowsPhoneNumberWhenVisible(t *testing.T) {
+	u := newProfileTestUser()
+	u.PhoneNumberVisible = true
+
+	p := service.ProjectProfile(u, service.DefaultProfileVisibility(u), models.RelationshipPublic, nil)
+
+	assert.Equal(t, "+31612345678", p.PhoneNumber)
+}
+
+func TestProjectProfile_MapsBadges(t *testing.T) {
+	u := newProfileTestUser()
+	earned := time.Now()
+
+	p := service.ProjectProfile(u, service.DefaultProfileVisibility(u), models.RelationshipPublic, []models.UserBadge{
+		{
+			UserID:   u.ID,
+			BadgeKey: service.BadgeKeyProfileComplete,
+			EarnedAt: earned,
+			Badge:    models.Badge{Key: service.BadgeKeyProfileComplete, Name: "Complete Profile"},
+		},
+	})
+
+	assert.Len(t, p.Badges, 1)
+	assert.Equal(t, service.BadgeKeyProfileComplete, p.Badges[0].Key)
+	assert.Equal(t, "Complete Profile", p.Badges[0].Name)
+	assert.Equal(t, earned, p.Badges[0].EarnedAt)
+}
+
+func TestProjectProfile_AppliesVisibilityPerRelationship(t *testing.T) {
+	u := newProfileTestUser()
+	u.PhoneNumberVisible = true
+
+	settings := service.DefaultProfileVisibility(u)
+	settings.Email = models.VisibilityPrivate
+	settings.PhoneNumber = models.VisibilityConnections
+	settings.Biography = models.VisibilityConnections
+	settings.Badges = models.VisibilityPrivate
+
+	badges := []models.UserBadge{{UserID: u.ID, BadgeKey: service.BadgeKeyProfileComplete}}
+
+	public := service.ProjectProfile(u, settings, models.RelationshipPublic, badges)
+	assert.Equal(t, "", public.Email)
+	assert.Equal(t, "", public.PhoneNumber)
+	assert.Equal(t, "", public.Biography)
+	assert.Empty(t, public.Badges)
+	assert.Equal(t, "NL", public.Country)
+
+	connection := service.ProjectProfile(u, settings, models.RelationshipConnection, badges)
+	assert.Equal(t, "", connection.Email)
+	assert.Equal(t, "+31612345678", connection.PhoneNumber)
+	assert.Equal(t, "Building things", connection.Biography)
+	assert.Empty(t, connection.Badges)
+
+	self := service.ProjectProfile(u, settings, models.RelationshipSelf, badges)
+	assert.Equal(t, "jane@example.com", self.Email)
+	assert.Len(t, self.Badges, 1)
+}
+
+// erroringVisibilityRepository fails every lookup with err
+type erroringVisibilityRepository struct {
+	err error
+}
+
+func (r erroringVisibilityRepository) FindByUserID(userID uuid.UUID) (*models.ProfileVisibility, error) {
+	return nil, r.err
+}
+
+func (r erroringVisibilityRepository) Upsert(v *models.ProfileVisibility) (*models.ProfileVisibility, error) {
+	return nil, r.err
+}
+
+func TestProfileVisibility_GetForUser_DefaultsOnlyWhenNotSaved(t *testing.T) {
+	u := newProfileTestUser()
+
+	missing := service.NewProfileVisibilityService(erroringVisibilityRepository{err: gorm.ErrRecordNotFound}, nil)
+	settings, err := missing.GetForUser(u)
+	assert.NoError(t, err)
+	assert.Equal(t, service.DefaultProfileVisibility(u), *settings)
+
+	// a failing database must not silently fall back to the (more open) defaults
+	broken := service.NewProfileVisibilityService(erroringVisibilityRepository{err: errors.New("connection refused")}, nil)
+	_, err = broken.GetForUser(u)
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestProjectProfile_PhoneNumberVisibleIsMasterSwitch(t *testing.T) {
+	u := newProfileTestUser()
+	u.PhoneNumberVisible = false
+
+	settings := service.DefaultProfileVisibility(u)
+	settings.PhoneNumber = models.VisibilityPublic
+
+	p := service.ProjectProfile(u, settings, models.RelationshipPublic, nil)
+	assert.Equal(t, "", p.PhoneNumber)
+
+	self := service.ProjectProfile(u, settings, models.RelationshipSelf, nil)
+	assert.Equal(t, "+31612345678", self.PhoneNumber)
+}
+
+func TestProjectProfile_HidesModeratedBiography(t *testing.T) {
+	u := newProfileTestUser()
+	u.BiographyHidden = true
+
+	settings := service.DefaultProfileVisibility(u)
+
+	p := service.ProjectProfile(u, settings, models.RelationshipConnection, nil)
+	assert.Equal(t, "", p.Biography)
+
+	self := service.ProjectProfile(u, settings, models.RelationshipSelf, nil)
+	assert.Equal(t, u.Biography, self.Biography)
+}
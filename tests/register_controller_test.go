@@ -9,6 +9,7 @@ import (
 
 	"group1-userservice/app/config"
 	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/middleware"
 	"group1-userservice/app/models"
 	"group1-userservice/app/repository"
 	"group1-userservice/app/service"
@@ -28,8 +29,10 @@ func setupRegisterTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 
 	if err := config.DB.AutoMigrate(
 		&models.User{},
+		&models.ProfileVersion{},
 		&models.NotificationSettings{},
 		&models.Interest{},
+		&models.InterestTranslation{},
 		&models.UserInterest{},
 	); err != nil {
 		t.Fatalf("failed to migrate tables: %v", err)
@@ -38,10 +41,11 @@ func setupRegisterTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 	config.SeedInterests()
 
 	userRepo := repository.NewUserRepository(config.DB)
-	userService := service.NewUserService(userRepo)
-	registerController := controller.NewRegisterController(userService)
+	userService := service.NewUserService(userRepo, newTestIdentityProvider(t))
+	registerController := controller.NewRegisterController(userService, &fakeEmailVerification{})
 
 	router := gin.Default()
+	router.Use(middleware.ProblemDetails())
 	router.POST("/users/register", registerController.Handle)
 
 	return router, db
@@ -53,9 +57,10 @@ func setupRegisterSuccessRouter(t *testing.T) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 
 	fakeSvc := &fakeUserService{}
-	registerController := controller.NewRegisterController(fakeSvc)
+	registerController := controller.NewRegisterController(fakeSvc, &fakeEmailVerification{})
 
 	router := gin.Default()
+	router.Use(middleware.ProblemDetails())
 	router.POST("/users/register", registerController.Handle)
 
 	return router
@@ -80,7 +85,6 @@ func TestRegister_EmailAlreadyExists_Returns409(t *testing.T) {
 
 	existing := models.User{
 		Email:      "test@example.com",
-		Password:   hashPassword(t, "secret"),
 		FirstName:  "Existing",
 		LastName:   "User",
 		KeycloakID: "kc-register-controller-exists",
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"group1-userservice/app/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRoleTestRouter(roles []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(middleware.ProblemDetails())
+	r.Use(func(c *gin.Context) {
+		if roles != nil {
+			c.Set("realm_roles", roles)
+		}
+		c.Next()
+	})
+	r.Use(middleware.RequireRealmRole("moderator"))
+
+	r.GET("/moderation", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	return r
+}
+
+func TestRequireRealmRole_AllowsRole(t *testing.T) {
+	router := setupRoleTestRouter([]string{"offline_access", "moderator"})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/moderation", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireRealmRole_RejectsMissingRole(t *testing.T) {
+	for _, roles := range [][]string{nil, {"offline_access"}} {
+		router := setupRoleTestRouter(roles)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/moderation", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	}
+}
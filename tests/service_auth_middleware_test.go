@@ -16,6 +16,7 @@ func setupAuthMiddlewareTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 
 	r := gin.Default()
+	r.Use(middleware.ProblemDetails())
 	r.Use(middleware.AuthMiddleware())
 
 	// This handler only runs if middleware succeeds
@@ -82,3 +83,57 @@ func TestGetUserID_NotSet_ReturnsFalse(t *testing.T) {
 	assert.False(t, ok)
 	assert.Equal(t, "", id)
 }
+
+// AuthOrServiceMiddleware tests
+
+func setupAuthOrServiceTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("USER_SERVICE_TOKEN", "svc-secret")
+
+	r := gin.Default()
+	r.Use(middleware.ProblemDetails())
+	r.GET("/protected", middleware.AuthOrServiceMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"service": middleware.IsServiceRequest(c)})
+	})
+
+	return r
+}
+
+func TestAuthOrServiceMiddleware_ValidServiceToken_Returns200(t *testing.T) {
+	router := setupAuthOrServiceTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("X-Service-Token", "svc-secret")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"service":true`)
+}
+
+func TestAuthOrServiceMiddleware_WrongServiceToken_Returns401(t *testing.T) {
+	router := setupAuthOrServiceTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("X-Service-Token", "wrong")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid service token")
+}
+
+func TestAuthOrServiceMiddleware_NoCredentials_Returns401(t *testing.T) {
+	router := setupAuthOrServiceTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "missing authorization header")
+}
@@ -0,0 +1,256 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/keycloak"
+	"group1-userservice/app/keycloak/keycloaktest"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// socialUserService also creates users, like RegisterFromIdentity does
+type socialUserService struct {
+	accountUserService
+}
+
+func (s *socialUserService) RegisterFromIdentity(user *models.User) error {
+	if _, err := s.GetByEmail(user.Email); err == nil {
+		return service.ErrEmailTaken
+	}
+	user.ID = uuid.New()
+	s.users[user.ID] = *user
+	return nil
+}
+
+// recordingNotificationSettings remembers for which emails the settings were read
+type recordingNotificationSettings struct {
+	fakeNotificationSettingsService
+	emails []string
+}
+
+func (r *recordingNotificationSettings) GetByEmail(email string) (*models.NotificationSettings, error) {
+	r.emails = append(r.emails, email)
+	return r.fakeNotificationSettingsService.GetByEmail(email)
+}
+
+// recordingDiscoveryPreferences remembers for which emails the preferences were read
+type recordingDiscoveryPreferences struct {
+	emails []string
+}
+
+func (r *recordingDiscoveryPreferences) GetForEmail(email string) (*models.DiscoveryPreferences, error) {
+	r.emails = append(r.emails, email)
+	return &models.DiscoveryPreferences{Email: email, RadiusKm: 50}, nil
+}
+
+func (r *recordingDiscoveryPreferences) UpdateForEmail(email string, input interfaces.DiscoveryPreferencesInput) (*models.DiscoveryPreferences, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *recordingDiscoveryPreferences) UpdateForEmailIfVersion(email string, input interfaces.DiscoveryPreferencesInput, version int64) (*models.DiscoveryPreferences, error) {
+	return nil, errors.New("not implemented")
+}
+
+type socialTestEnv struct {
+	srv       *keycloaktest.Server
+	users     *socialUserService
+	notif     *recordingNotificationSettings
+	discovery *recordingDiscoveryPreferences
+	svc       interfaces.SocialLoginService
+}
+
+func newSocialTestEnv(idp interfaces.IdentityProvider, srv *keycloaktest.Server, users ...models.User) socialTestEnv {
+	env := socialTestEnv{
+		srv:       srv,
+		users:     &socialUserService{accountUserService{directoryUserService{users: map[uuid.UUID]models.User{}}}},
+		notif:     &recordingNotificationSettings{},
+		discovery: &recordingDiscoveryPreferences{},
+	}
+	for _, u := range users {
+		env.users.users[u.ID] = u
+	}
+	env.svc = service.NewSocialLoginService(idp, env.users, env.notif, env.discovery, service.SocialLoginConfig{Providers: []string{"google", "apple"}})
+	return env
+}
+
+func (env socialTestEnv) router() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	twoFactor := service.NewTwoFactorService(newFakeTwoFactorRepo(), env.users, twoFactorConfig())
+	sc := controller.NewSocialLoginController(env.svc, twoFactor, &fakeEmailVerification{})
+
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.POST("/auth/social", sc.Handle)
+	return router
+}
+
+func postSocial(router *gin.Engine, provider, idToken string) *httptest.ResponseRecorder {
+	raw, _ := json.Marshal(controller.SocialLoginRequest{Provider: provider, IDToken: idToken})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/auth/social", bytes.NewBuffer(raw))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSocialLogin_FirstLoginCreatesUserWithDefaults(t *testing.T) {
+	srv := keycloaktest.NewServer()
+	defer srv.Close()
+	env := newSocialTestEnv(keycloak.NewProvider(srv.Config()), srv)
+	router := env.router()
+
+	srv.AddSocialToken("google", "google-id-token", keycloaktest.SocialAccount{
+		UserID: "google-1", Email: "founder@example.com", EmailVerified: true, FirstName: "Ada", LastName: "Lovelace",
+	})
+
+	w := postSocial(router, "google", "google-id-token")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var token interfaces.TokenResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &token)
+	assert.NotEmpty(t, token.AccessToken)
+
+	kcUser, _ := srv.User("founder@example.com")
+	user, err := env.users.GetByKeycloakID(kcUser.ID)
+	if err != nil {
+		t.Fatalf("local user not created: %v", err)
+	}
+	assert.Equal(t, "Ada", user.FirstName)
+	assert.Equal(t, "Lovelace", user.LastName)
+	assert.NotNil(t, user.EmailVerifiedAt)
+	assert.Equal(t, []string{"founder@example.com"}, env.notif.emails)
+	assert.Equal(t, []string{"founder@example.com"}, env.discovery.emails)
+
+	// The next login finds the same user and creates nothing
+	assert.Equal(t, http.StatusOK, postSocial(router, "google", "google-id-token").Code)
+	assert.Len(t, env.users.users, 1)
+	assert.Len(t, env.notif.emails, 1)
+}
+
+func TestSocialLogin_Rejections(t *testing.T) {
+	srv := keycloaktest.NewServer()
+	defer srv.Close()
+	env := newSocialTestEnv(keycloak.NewProvider(srv.Config()), srv)
+	router := env.router()
+
+	srv.AddUser(keycloaktest.User{Email: "founder@example.com", Password: "Welkom1234", Enabled: true})
+	srv.AddSocialToken("google", "google-id-token", keycloaktest.SocialAccount{UserID: "google-1", Email: "founder@example.com", EmailVerified: true})
+
+	assert.Equal(t, http.StatusBadRequest, postSocial(router, "facebook", "google-id-token").Code)
+	assert.Equal(t, http.StatusBadRequest, postSocial(router, "google", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, postSocial(router, "google", "forged").Code)
+
+	// Keycloak has a user with this email and its first broker login flow does not link
+	assert.Equal(t, http.StatusConflict, postSocial(router, "google", "google-id-token").Code)
+
+	// The local identity provider cannot do social logins
+	local := newSocialTestEnv(newTestIdentityProvider(t), nil)
+	assert.Equal(t, http.StatusNotImplemented, postSocial(local.router(), "google", "google-id-token").Code)
+}
+
+func TestSocialLogin_KeycloakLinkedAccount(t *testing.T) {
+	srv := keycloaktest.NewServer()
+	defer srv.Close()
+	srv.LinkSocialByEmail = true
+
+	subject := srv.AddUser(keycloaktest.User{Email: "founder@example.com", Password: "Welkom1234", Enabled: true})
+	user := models.User{ID: uuid.New(), KeycloakID: subject, Email: "founder@example.com"}
+	env := newSocialTestEnv(keycloak.NewProvider(srv.Config()), srv, user)
+
+	srv.AddSocialToken("apple", "apple-id-token", keycloaktest.SocialAccount{UserID: "apple-1", Email: "founder@example.com", EmailVerified: true})
+
+	result, err := env.svc.Login("apple", "apple-id-token")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, result.User.ID)
+	assert.False(t, result.Created)
+	assert.Empty(t, env.notif.emails)
+
+	// Blocked accounts may not log in this way either
+	user.IsBlocked = true
+	env.users.users[user.ID] = user
+	assert.Equal(t, http.StatusForbidden, postSocial(env.router(), "apple", "apple-id-token").Code)
+}
+
+func TestSocialLogin_LinksToLocalAccountWithVerifiedEmail(t *testing.T) {
+	srv := keycloaktest.NewServer()
+	defer srv.Close()
+
+	// The Keycloak user still has an old email, so Keycloak creates a new brokered user
+	subject := srv.AddUser(keycloaktest.User{Email: "founder@old.example.com", Password: "Welkom1234", Enabled: true})
+	verified := time.Now()
+	user := models.User{ID: uuid.New(), KeycloakID: subject, Email: "founder@example.com", EmailVerifiedAt: &verified}
+	env := newSocialTestEnv(keycloak.NewProvider(srv.Config()), srv, user)
+
+	srv.AddSocialToken("google", "verified-token", keycloaktest.SocialAccount{UserID: "google-1", Email: "founder@example.com", EmailVerified: true})
+	result, err := env.svc.Login("google", "verified-token")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	assert.True(t, result.Linked)
+	assert.Equal(t, user.ID, result.User.ID)
+
+	claims, err := keycloak.NewProvider(srv.Config()).VerifyToken(result.Token.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, subject, claims.Subject)
+
+	// The brokered duplicate is gone and the Google account belongs to the existing user
+	kcUser, _ := srv.User("founder@old.example.com")
+	assert.Equal(t, []keycloaktest.FederatedIdentity{{Provider: "google", UserID: "google-1", UserName: "founder@example.com"}}, kcUser.FederatedIdentities)
+	_, duplicate := srv.User("founder@example.com")
+	assert.False(t, duplicate)
+
+	// An email the provider did not verify is no proof of owning the account
+	srv.AddSocialToken("google", "unverified-token", keycloaktest.SocialAccount{UserID: "google-2", Email: "founder@example.com"})
+	_, err = env.svc.Login("google", "unverified-token")
+	assert.ErrorIs(t, err, service.ErrSocialEmailUnverified)
+}
+
+func TestSocialLogin_DoesNotLinkToUnverifiedLocalAccount(t *testing.T) {
+	srv := keycloaktest.NewServer()
+	defer srv.Close()
+
+	// Someone registered the victim's email with a password of their own and never verified it
+	subject := srv.AddUser(keycloaktest.User{Email: "squatter@example.com", Password: "Welkom1234", Enabled: true})
+	squatter := models.User{ID: uuid.New(), KeycloakID: subject, Email: "victim@example.com"}
+	env := newSocialTestEnv(keycloak.NewProvider(srv.Config()), srv, squatter)
+
+	srv.AddSocialToken("google", "victim-token", keycloaktest.SocialAccount{UserID: "google-9", Email: "victim@example.com", EmailVerified: true})
+	w := postSocial(env.router(), "google", "victim-token")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "social_account_unverified")
+
+	kcUser, _ := srv.User("squatter@example.com")
+	assert.Empty(t, kcUser.FederatedIdentities)
+}
+
+// failingUserService fails every lookup, like a database that is down
+type failingUserService struct {
+	fakeUserService
+}
+
+func (*failingUserService) GetByKeycloakID(sub string) (models.User, error) {
+	return models.User{}, errors.New("connection refused")
+}
+
+func TestSocialLogin_LookupErrorDoesNotCreateUser(t *testing.T) {
+	srv := keycloaktest.NewServer()
+	defer srv.Close()
+	srv.AddSocialToken("google", "google-id-token", keycloaktest.SocialAccount{UserID: "google-1", Email: "founder@example.com", EmailVerified: true})
+
+	svc := service.NewSocialLoginService(keycloak.NewProvider(srv.Config()), &failingUserService{}, &recordingNotificationSettings{}, &recordingDiscoveryPreferences{}, service.SocialLoginConfig{Providers: []string{"google"}})
+	_, err := svc.Login("google", "google-id-token")
+	assert.EqualError(t, err, "connection refused")
+}
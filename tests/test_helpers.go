@@ -5,11 +5,12 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
+	"group1-userservice/app/localidp"
 	"group1-userservice/app/models"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -19,26 +20,14 @@ func intToString(v int) string {
 	return strconv.Itoa(v)
 }
 
-// Hashes a plaintext password for use in tests
-func hashPassword(t *testing.T, plain string) string {
-	t.Helper()
-
-	hashed, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
-	if err != nil {
-		t.Fatalf("error hashing password: %v", err)
-	}
-	return string(hashed)
-}
-
-// Inserts a user with a hashed password into the test database
-func createTestUser(t *testing.T, db *gorm.DB, email, plainPassword string) {
+// Inserts a user into the test database; the password itself only exists in Keycloak
+func createTestUser(t *testing.T, db *gorm.DB, email string) {
 	t.Helper()
 
 	user := models.User{
 		KeycloakID: "test-kc-" + uuid.NewString(),
 
-		Email:    email,
-		Password: hashPassword(t, plainPassword),
+		Email: email,
 
 		FirstName: "Test",
 		LastName:  "User",
@@ -49,6 +38,22 @@ func createTestUser(t *testing.T, db *gorm.DB, email, plainPassword string) {
 	}
 }
 
+// Returns an in-memory identity provider, so tests need no Keycloak container
+func newTestIdentityProvider(t *testing.T) *localidp.Provider {
+	t.Helper()
+
+	idp, err := localidp.New(localidp.Config{
+		Secret:     []byte("test-secret"),
+		Issuer:     "test",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("failed to create identity provider: %v", err)
+	}
+	return idp
+}
+
 func truncateIfExists(db *gorm.DB, table string) {
 	// Truncate only if table exists to avoid "relation does not exist" noise
 	db.Exec(fmt.Sprintf(`
@@ -87,6 +92,24 @@ func openTestDB(t *testing.T) *gorm.DB {
 	truncateIfExists(db, "interests")
 	truncateIfExists(db, "discovery_preferences")
 	truncateIfExists(db, "password_reset_tokens")
+	truncateIfExists(db, "magic_link_tokens")
+	truncateIfExists(db, "profile_visibilities")
+	truncateIfExists(db, "handle_reservations")
+	truncateIfExists(db, "connections")
+	truncateIfExists(db, "user_blocks")
+	truncateIfExists(db, "user_reports")
+	truncateIfExists(db, "moderation_actions")
+	truncateIfExists(db, "audit_events")
+	truncateIfExists(db, "profile_versions")
+	truncateIfExists(db, "sectors")
+	truncateIfExists(db, "job_functions")
+	truncateIfExists(db, "vocabulary_mappings")
+	truncateIfExists(db, "onboarding_states")
+	truncateIfExists(db, "onboarding_step_states")
+	truncateIfExists(db, "email_verification_sends")
+	truncateIfExists(db, "two_factors")
+	truncateIfExists(db, "recovery_codes")
+	truncateIfExists(db, "login_challenges")
 
 	return db
 }
@@ -113,18 +136,18 @@ func seedInterests(t *testing.T, db *gorm.DB) []models.Interest {
 	t.Helper()
 
 	keys := []string{
-		"Gezondheidszorg en Welzijn",
-		"Handel en Dienstverlening",
-		"ICT",
-		"Justitie, Veiligheid en Openbaar Bestuur",
-		"Milieu en Agrarische Sector",
-		"Media en Communicatie",
-		"Onderwijs, Cultuur en Wetenschap",
-		"Techniek, Productie en Bouw",
-		"Toerisme, Recreatie en Horeca",
-		"Transport en Logistiek",
-		"Behoefte aan Investering",
-		"Interesse om te Investeren",
+		"healthcare_welfare",
+		"trade_services",
+		"ict",
+		"justice_security_public_administration",
+		"environment_agriculture",
+		"media_communication",
+		"education_culture_science",
+		"engineering_production_construction",
+		"tourism_recreation_hospitality",
+		"transport_logistics",
+		"investment_needed",
+		"investment_offered",
 	}
 
 	out := make([]models.Interest, 0, len(keys))
@@ -0,0 +1,339 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTwoFactorRepo keeps 2FA setups, recovery codes and challenges in memory
+type fakeTwoFactorRepo struct {
+	setups     map[uuid.UUID]models.TwoFactor
+	codes      map[uuid.UUID]map[string]bool // hash -> used
+	challenges map[uint]models.LoginChallenge
+	nextID     uint
+}
+
+func newFakeTwoFactorRepo() *fakeTwoFactorRepo {
+	return &fakeTwoFactorRepo{
+		setups:     map[uuid.UUID]models.TwoFactor{},
+		codes:      map[uuid.UUID]map[string]bool{},
+		challenges: map[uint]models.LoginChallenge{},
+	}
+}
+
+func (f *fakeTwoFactorRepo) Find(userID uuid.UUID) (*models.TwoFactor, error) {
+	tf, ok := f.setups[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &tf, nil
+}
+
+func (f *fakeTwoFactorRepo) Save(tf *models.TwoFactor) error {
+	f.setups[tf.UserID] = *tf
+	return nil
+}
+
+func (f *fakeTwoFactorRepo) Delete(userID uuid.UUID) error {
+	delete(f.setups, userID)
+	delete(f.codes, userID)
+	for id, ch := range f.challenges {
+		if ch.UserID == userID {
+			delete(f.challenges, id)
+		}
+	}
+	return nil
+}
+
+func (f *fakeTwoFactorRepo) UseStep(userID uuid.UUID, step int64) (bool, error) {
+	tf := f.setups[userID]
+	if tf.LastUsedStep >= step {
+		return false, nil
+	}
+	tf.LastUsedStep = step
+	f.setups[userID] = tf
+	return true, nil
+}
+
+func (f *fakeTwoFactorRepo) ReplaceRecoveryCodes(userID uuid.UUID, hashes []string) error {
+	f.codes[userID] = map[string]bool{}
+	for _, h := range hashes {
+		f.codes[userID][h] = false
+	}
+	return nil
+}
+
+func (f *fakeTwoFactorRepo) CountRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var n int64
+	for _, used := range f.codes[userID] {
+		if !used {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeTwoFactorRepo) UseRecoveryCode(userID uuid.UUID, hash string) (bool, error) {
+	used, ok := f.codes[userID][hash]
+	if !ok || used {
+		return false, nil
+	}
+	f.codes[userID][hash] = true
+	return true, nil
+}
+
+func (f *fakeTwoFactorRepo) CreateChallenge(ch *models.LoginChallenge) error {
+	f.nextID++
+	ch.ID = f.nextID
+	f.challenges[ch.ID] = *ch
+	return nil
+}
+
+func (f *fakeTwoFactorRepo) FindChallenge(tokenHash string) (*models.LoginChallenge, error) {
+	for _, ch := range f.challenges {
+		if ch.TokenHash == tokenHash && ch.ExpiresAt.After(time.Now()) {
+			return &ch, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeTwoFactorRepo) AddChallengeAttempt(id uint) error {
+	ch := f.challenges[id]
+	ch.Attempts++
+	f.challenges[id] = ch
+	return nil
+}
+
+func (f *fakeTwoFactorRepo) DeleteChallenge(id uint) error {
+	delete(f.challenges, id)
+	return nil
+}
+
+// passwordUserService stands in for the identity provider: a password is right when it equals the user's Password
+type passwordUserService struct {
+	accountUserService
+}
+
+func (p *passwordUserService) VerifyPassword(email string, password string) error {
+	u, err := p.GetByEmail(email)
+	if err != nil || u.Password != password {
+		return interfaces.ErrInvalidCredentials
+	}
+	return nil
+}
+
+func twoFactorConfig() service.TwoFactorConfig {
+	return service.TwoFactorConfig{Key: bytes.Repeat([]byte{7}, 32), Issuer: "Group1", ChallengeTTL: time.Minute}
+}
+
+type twoFactorTestEnv struct {
+	svc  interfaces.TwoFactorService
+	repo *fakeTwoFactorRepo
+	user models.User
+}
+
+func newTwoFactorTestEnv() twoFactorTestEnv {
+	user := models.User{ID: uuid.New(), KeycloakID: "kc-123", Email: "investor@example.com", Password: "secret1"}
+	users := &passwordUserService{accountUserService{directoryUserService{users: map[uuid.UUID]models.User{user.ID: user}}}}
+	repo := newFakeTwoFactorRepo()
+	return twoFactorTestEnv{svc: service.NewTwoFactorService(repo, users, twoFactorConfig()), repo: repo, user: user}
+}
+
+// enable enrolls and confirms 2FA and returns the secret and recovery codes; the confirming code
+// belongs to the previous time step so the current one is still unused
+func (env twoFactorTestEnv) enable(t *testing.T) (string, []string) {
+	t.Helper()
+	enrollment, err := env.svc.Enroll(env.user)
+	assert.NoError(t, err)
+
+	code, _ := service.TOTPCode(enrollment.Secret, time.Now().Add(-30*time.Second))
+	codes, err := env.svc.Confirm(env.user, code)
+	assert.NoError(t, err)
+	return enrollment.Secret, codes
+}
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1 seed "12345678901234567890", last six digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"} {
+		code, err := service.TOTPCode(secret, time.Unix(unix, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, want, code)
+	}
+}
+
+func TestTwoFactor_EnrollAndConfirm(t *testing.T) {
+	env := newTwoFactorTestEnv()
+
+	_, err := env.svc.Confirm(env.user, "123456")
+	assert.True(t, errors.Is(err, service.ErrTwoFactorNotEnrolled))
+
+	enrollment, err := env.svc.Enroll(env.user)
+	assert.NoError(t, err)
+	uri, err := url.Parse(enrollment.ProvisioningURI)
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Group1:investor@example.com", uri.Path)
+	assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+
+	// the secret is stored encrypted
+	assert.NotContains(t, env.repo.setups[env.user.ID].SecretEnc, enrollment.Secret)
+
+	enabled, _ := env.svc.Enabled(env.user.ID)
+	assert.False(t, enabled, "pending until confirmed")
+
+	_, err = env.svc.Confirm(env.user, "000000")
+	assert.True(t, errors.Is(err, service.ErrTwoFactorInvalidCode))
+
+	code, _ := service.TOTPCode(enrollment.Secret, time.Now())
+	codes, err := env.svc.Confirm(env.user, code)
+	assert.NoError(t, err)
+	assert.Len(t, codes, 10)
+
+	status, err := env.svc.Status(env.user)
+	assert.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.EqualValues(t, 10, status.RecoveryCodesRemaining)
+
+	// only hashes of the recovery codes are stored
+	for hash := range env.repo.codes[env.user.ID] {
+		for _, c := range codes {
+			assert.NotContains(t, hash, strings.ReplaceAll(c, "-", ""))
+		}
+	}
+
+	_, err = env.svc.Enroll(env.user)
+	assert.True(t, errors.Is(err, service.ErrTwoFactorAlreadyEnabled))
+}
+
+func TestTwoFactor_LoginChallenge(t *testing.T) {
+	env := newTwoFactorTestEnv()
+	secret, codes := env.enable(t)
+
+	challenge, expiresIn, err := env.svc.StartLogin(env.user, "refresh-token")
+	assert.NoError(t, err)
+	assert.Equal(t, 60, expiresIn)
+
+	// the refresh token is not stored in the clear
+	for _, ch := range env.repo.challenges {
+		assert.NotContains(t, ch.RefreshTokenEnc, "refresh-token")
+	}
+
+	user, _, err := env.svc.FinishLogin(interfaces.LoginChallengeInput{ChallengeToken: challenge, Code: "000000"})
+	assert.True(t, errors.Is(err, service.ErrTwoFactorInvalidCode))
+	assert.Equal(t, env.user.ID, user.ID)
+
+	code, _ := service.TOTPCode(secret, time.Now())
+	user, refresh, err := env.svc.FinishLogin(interfaces.LoginChallengeInput{ChallengeToken: challenge, Code: code})
+	assert.NoError(t, err)
+	assert.Equal(t, env.user.ID, user.ID)
+	assert.Equal(t, "refresh-token", refresh)
+
+	// a challenge is used once, and a code cannot be replayed on the next login
+	_, _, err = env.svc.FinishLogin(interfaces.LoginChallengeInput{ChallengeToken: challenge, Code: code})
+	assert.True(t, errors.Is(err, service.ErrLoginChallengeInvalid))
+
+	challenge, _, _ = env.svc.StartLogin(env.user, "refresh-token")
+	_, _, err = env.svc.FinishLogin(interfaces.LoginChallengeInput{ChallengeToken: challenge, Code: code})
+	assert.True(t, errors.Is(err, service.ErrTwoFactorInvalidCode))
+
+	// recovery codes work once, in any format
+	recovery := strings.ToUpper(strings.ReplaceAll(codes[0], "-", " "))
+	_, _, err = env.svc.FinishLogin(interfaces.LoginChallengeInput{ChallengeToken: challenge, Code: recovery})
+	assert.NoError(t, err)
+
+	challenge, _, _ = env.svc.StartLogin(env.user, "refresh-token")
+	_, _, err = env.svc.FinishLogin(interfaces.LoginChallengeInput{ChallengeToken: challenge, Code: codes[0]})
+	assert.True(t, errors.Is(err, service.ErrTwoFactorInvalidCode))
+
+	status, _ := env.svc.Status(env.user)
+	assert.EqualValues(t, 9, status.RecoveryCodesRemaining)
+}
+
+func TestTwoFactor_ChallengeLocksAfterFiveInvalidCodes(t *testing.T) {
+	env := newTwoFactorTestEnv()
+	env.enable(t)
+
+	challenge, _, _ := env.svc.StartLogin(env.user, "refresh-token")
+	for i := 0; i < 4; i++ {
+		_, _, err := env.svc.FinishLogin(interfaces.LoginChallengeInput{ChallengeToken: challenge, Code: "000000"})
+		assert.True(t, errors.Is(err, service.ErrTwoFactorInvalidCode))
+	}
+	_, _, err := env.svc.FinishLogin(interfaces.LoginChallengeInput{ChallengeToken: challenge, Code: "000000"})
+	assert.True(t, errors.Is(err, service.ErrLoginChallengeLocked))
+	assert.Empty(t, env.repo.challenges)
+}
+
+func TestTwoFactor_DisableAndRegenerateNeedReauth(t *testing.T) {
+	env := newTwoFactorTestEnv()
+	secret, codes := env.enable(t)
+	code, _ := service.TOTPCode(secret, time.Now())
+
+	_, err := env.svc.RegenerateRecoveryCodes(env.user, interfaces.TwoFactorReauthInput{Password: "wrong", Code: code})
+	assert.True(t, errors.Is(err, service.ErrTwoFactorInvalidPassword))
+	_, err = env.svc.RegenerateRecoveryCodes(env.user, interfaces.TwoFactorReauthInput{Password: "secret1", Code: "000000"})
+	assert.True(t, errors.Is(err, service.ErrTwoFactorInvalidCode))
+
+	fresh, err := env.svc.RegenerateRecoveryCodes(env.user, interfaces.TwoFactorReauthInput{Password: "secret1", Code: code})
+	assert.NoError(t, err)
+	assert.Len(t, fresh, 10)
+	assert.NotEqual(t, codes, fresh)
+
+	// old recovery codes are gone; a new one re-authenticates
+	err = env.svc.Disable(env.user, interfaces.TwoFactorReauthInput{Password: "secret1", Code: codes[1]})
+	assert.True(t, errors.Is(err, service.ErrTwoFactorInvalidCode))
+	assert.NoError(t, env.svc.Disable(env.user, interfaces.TwoFactorReauthInput{Password: "secret1", Code: fresh[0]}))
+
+	enabled, _ := env.svc.Enabled(env.user.ID)
+	assert.False(t, enabled)
+	err = env.svc.Disable(env.user, interfaces.TwoFactorReauthInput{Password: "secret1", Code: fresh[1]})
+	assert.True(t, errors.Is(err, service.ErrTwoFactorNotEnabled))
+}
+
+func TestLoginTwoFactor_Endpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := newTwoFactorTestEnv()
+	env.enable(t)
+
+	lc := controller.NewLoginController(&fakeUserService{}, env.svc, newTestIdentityProvider(t))
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.POST("/auth/login/2fa", lc.HandleTwoFactor)
+
+	do := func(body any) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/auth/login/2fa", bytes.NewBuffer(raw))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do(map[string]string{"challenge_token": "abc"}).Code)
+	assert.Equal(t, http.StatusUnauthorized, do(map[string]string{"challenge_token": "abc", "code": "123456"}).Code)
+
+	challenge, _, _ := env.svc.StartLogin(env.user, "refresh-token")
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, http.StatusUnauthorized, do(map[string]string{"challenge_token": challenge, "code": "000000"}).Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, do(map[string]string{"challenge_token": challenge, "code": "000000"}).Code)
+}
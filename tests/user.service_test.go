@@ -10,6 +10,7 @@ import (
 	"group1-userservice/app/service"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -20,12 +21,12 @@ func setupUserServiceTest(t *testing.T) (interfaces.UserService, *gorm.DB) {
 	db := openTestDB(t)
 	config.DB = db
 
-	if err := config.DB.AutoMigrate(&models.User{}); err != nil {
+	if err := config.DB.AutoMigrate(&models.User{}, &models.ProfileVersion{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 
 	userRepo := repository.NewUserRepository(config.DB)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, newTestIdentityProvider(t))
 
 	return userService, db
 }
@@ -190,35 +191,32 @@ func TestGetByKeycloakID_NotFound(t *testing.T) {
 	assert.Error(t, err)
 }
 
-// HashPassword / CheckPassword
+// RetireLegacyPassword
 
-func TestHashPassword_ProducesValidHash(t *testing.T) {
-	raw := "mysecret123"
+func TestRetireLegacyPassword_ClearsHash(t *testing.T) {
+	userService, db := setupUserServiceTest(t)
 
-	hashed, err := service.HashPassword(raw)
+	// The column is not part of the model anymore; databases from before still have it
+	assert.NoError(t, db.Exec("ALTER TABLE users ADD COLUMN IF NOT EXISTS password text").Error)
 
-	assert.NoError(t, err)
-	assert.NotEqual(t, raw, hashed)
-	assert.NotEmpty(t, hashed)
-}
-
-func TestCheckPassword_CorrectPassword(t *testing.T) {
-	userService, _ := setupUserServiceTest(t)
+	user := models.User{Email: "legacy@example.com", KeycloakID: "kc-legacy", FirstName: "Old", LastName: "Timer"}
+	assert.NoError(t, db.Create(&user).Error)
 
-	raw := "mysecret123"
-	hashed, _ := service.HashPassword(raw)
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret1"), bcrypt.MinCost)
+	assert.NoError(t, db.Exec("UPDATE users SET password = ? WHERE id = ?", string(hashed), user.ID).Error)
 
-	match := userService.CheckPassword(hashed, raw)
-	assert.True(t, match)
-}
+	loaded, err := userService.GetByEmail("legacy@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, string(hashed), loaded.LegacyPasswordHash)
 
-func TestCheckPassword_InvalidPassword(t *testing.T) {
-	userService, _ := setupUserServiceTest(t)
+	assert.NoError(t, userService.RetireLegacyPassword(loaded, "secret1"))
 
-	hashed, _ := service.HashPassword("correct")
+	loaded, err = userService.GetByEmail("legacy@example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, loaded.LegacyPasswordHash)
 
-	match := userService.CheckPassword(hashed, "wrong")
-	assert.False(t, match)
+	// Nothing to do without a hash
+	assert.NoError(t, userService.RetireLegacyPassword(loaded, "secret1"))
 }
 
 func TestGetPublicInfoByFirstLast_CaseInsensitive(t *testing.T) {
@@ -338,7 +336,6 @@ func TestUpdateByEmail_DoesNotChangeRestrictedFields(t *testing.T) {
 
 	assert.Equal(t, "restricted@example.com", u2.Email)
 	assert.Equal(t, "kc-original", u2.KeycloakID)
-	assert.Equal(t, "original-hash", u2.Password)
 	assert.Equal(t, "Changed", u2.FirstName)
 }
 
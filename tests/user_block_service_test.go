@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+
+	"github.com/google/uuid"
+)
+
+// fakeUserBlockRepo keeps blocks and mutes in memory
+type fakeUserBlockRepo struct {
+	rows []models.UserBlock
+}
+
+func newFakeUserBlockRepo() *fakeUserBlockRepo {
+	return &fakeUserBlockRepo{}
+}
+
+func (f *fakeUserBlockRepo) Create(b *models.UserBlock) error {
+	if ok, _ := f.Exists(b.BlockerID, b.BlockedID, b.Kind); ok {
+		return nil
+	}
+	b.ID = uint(len(f.rows) + 1)
+	f.rows = append(f.rows, *b)
+	return nil
+}
+
+func (f *fakeUserBlockRepo) Delete(blockerID, blockedID uuid.UUID, kind models.BlockKind) (bool, error) {
+	for i, b := range f.rows {
+		if b.BlockerID == blockerID && b.BlockedID == blockedID && b.Kind == kind {
+			f.rows = append(f.rows[:i], f.rows[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeUserBlockRepo) Exists(blockerID, blockedID uuid.UUID, kind models.BlockKind) (bool, error) {
+	for _, b := range f.rows {
+		if b.BlockerID == blockerID && b.BlockedID == blockedID && b.Kind == kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeUserBlockRepo) BlockedBetween(a, b uuid.UUID) (bool, error) {
+	ab, _ := f.Exists(a, b, models.BlockKindBlock)
+	ba, _ := f.Exists(b, a, models.BlockKindBlock)
+	return ab || ba, nil
+}
+
+func (f *fakeUserBlockRepo) List(uuid.UUID, models.BlockKind, interfaces.Pagination) ([]models.ConnectionUser, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeUserBlockRepo) TargetIDs(blockerID uuid.UUID, kind models.BlockKind) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	for _, b := range f.rows {
+		if b.BlockerID == blockerID && b.Kind == kind {
+			ids = append(ids, b.BlockedID)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeUserBlockRepo) BlockerIDs(blockedID uuid.UUID, kind models.BlockKind) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	for _, b := range f.rows {
+		if b.BlockedID == blockedID && b.Kind == kind {
+			ids = append(ids, b.BlockerID)
+		}
+	}
+	return ids, nil
+}
+
+// defaultVisibilityService always returns the default privacy settings
+type defaultVisibilityService struct{}
+
+func (defaultVisibilityService) GetForUser(user models.User) (*models.ProfileVisibility, error) {
+	v := service.DefaultProfileVisibility(user)
+	return &v, nil
+}
+
+func (defaultVisibilityService) UpdateForUser(user models.User, input interfaces.ProfileVisibilityPatchInput) (*models.ProfileVisibility, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestUserBlockService_BlockRemovesConnectionsAndPreventsConnecting(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+
+	if _, err := env.svc.Follow(env.alice, env.bob.ID); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	req, _ := env.svc.RequestConnection(env.bob, env.alice.ID)
+	if _, err := env.svc.RespondToRequest(env.alice, req.ID, true); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+
+	if _, err := env.blocks.Add(env.bob, env.alice.ID, models.BlockKindBlock); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	if len(env.repo.rows) != 0 {
+		t.Fatalf("expected all edges to be removed, got %d", len(env.repo.rows))
+	}
+
+	// both directions are blocked, and the block is reported as not found
+	if _, err := env.svc.Follow(env.alice, env.bob.ID); !errors.Is(err, service.ErrConnectionTargetNotFound) {
+		t.Fatalf("expected ErrConnectionTargetNotFound, got %v", err)
+	}
+	if _, err := env.svc.RequestConnection(env.bob, env.alice.ID); !errors.Is(err, service.ErrConnectionTargetNotFound) {
+		t.Fatalf("expected ErrConnectionTargetNotFound, got %v", err)
+	}
+
+	list, err := env.blocks.BlockList(env.alice.ID)
+	if err != nil {
+		t.Fatalf("block list: %v", err)
+	}
+	if len(list.BlockedBy) != 1 || list.BlockedBy[0] != env.bob.ID || len(list.Blocked) != 0 {
+		t.Fatalf("unexpected block list: %+v", list)
+	}
+
+	if err := env.blocks.Remove(env.bob, env.alice.ID, models.BlockKindBlock); err != nil {
+		t.Fatalf("unblock: %v", err)
+	}
+	if _, err := env.svc.Follow(env.alice, env.bob.ID); err != nil {
+		t.Fatalf("follow after unblock: %v", err)
+	}
+}
+
+func TestUserBlockService_Validation(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+
+	if _, err := env.blocks.Add(env.alice, env.alice.ID, models.BlockKindBlock); !errors.Is(err, service.ErrBlockSelf) {
+		t.Fatalf("expected ErrBlockSelf, got %v", err)
+	}
+	if _, err := env.blocks.Add(env.alice, uuid.New(), models.BlockKindBlock); !errors.Is(err, service.ErrProfileNotFound) {
+		t.Fatalf("expected ErrProfileNotFound, got %v", err)
+	}
+	if _, err := env.blocks.Add(env.alice, env.bob.ID, models.BlockKind("ban")); !errors.Is(err, service.ErrBlockKind) {
+		t.Fatalf("expected ErrBlockKind, got %v", err)
+	}
+	if err := env.blocks.Remove(env.alice, env.bob.ID, models.BlockKindMute); !errors.Is(err, service.ErrBlockNotFound) {
+		t.Fatalf("expected ErrBlockNotFound, got %v", err)
+	}
+}
+
+func TestUserBlockService_MuteSuppressesNotificationsOnly(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+
+	if _, err := env.blocks.Add(env.bob, env.alice.ID, models.BlockKindMute); err != nil {
+		t.Fatalf("mute: %v", err)
+	}
+
+	if _, err := env.svc.Follow(env.alice, env.bob.ID); err != nil {
+		t.Fatalf("muted users can still follow: %v", err)
+	}
+	if len(env.sender.sent) != 0 {
+		t.Fatalf("expected no notification from a muted user, got %+v", env.sender.sent)
+	}
+}
+
+func TestProfileView_HidesBlockedUsers(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+
+	users := &directoryUserService{users: map[uuid.UUID]models.User{env.alice.ID: env.alice, env.bob.ID: env.bob}}
+	views := service.NewProfileViewService(
+		&viewerUserService{directoryUserService: users, viewer: env.alice},
+		&fakeBadgeService{},
+		defaultVisibilityService{},
+		service.NewBlockingRelationshipResolver(env.blocks, env.svc),
+		nil,
+	)
+
+	if _, err := views.PublicProfile("kc-alice", env.bob.ID); err != nil {
+		t.Fatalf("profile before block: %v", err)
+	}
+
+	if _, err := env.blocks.Add(env.bob, env.alice.ID, models.BlockKindBlock); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+
+	if _, err := views.PublicProfile("kc-alice", env.bob.ID); !errors.Is(err, service.ErrProfileNotFound) {
+		t.Fatalf("expected ErrProfileNotFound for profile, got %v", err)
+	}
+	if _, err := views.BadgesForUser("kc-alice", env.bob.ID); !errors.Is(err, service.ErrProfileNotFound) {
+		t.Fatalf("expected ErrProfileNotFound for badges, got %v", err)
+	}
+}
+
+// unreachableViewerService fails to look up any viewer, like a database that is down
+type unreachableViewerService struct {
+	*directoryUserService
+}
+
+func (u *unreachableViewerService) GetByKeycloakID(sub string) (models.User, error) {
+	return models.User{}, errors.New("connection refused")
+}
+
+func TestProfileView_ViewerLookupErrorDoesNotSkipBlockCheck(t *testing.T) {
+	env := newConnectionTestEnv(allConnectionChannels())
+	if _, err := env.blocks.Add(env.bob, env.alice.ID, models.BlockKindBlock); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+
+	users := &directoryUserService{users: map[uuid.UUID]models.User{env.alice.ID: env.alice, env.bob.ID: env.bob}}
+	views := service.NewProfileViewService(
+		&unreachableViewerService{directoryUserService: users},
+		&fakeBadgeService{},
+		defaultVisibilityService{},
+		service.NewBlockingRelationshipResolver(env.blocks, env.svc),
+		nil,
+	)
+
+	profile, err := views.PublicProfile("kc-alice", env.bob.ID)
+	if err == nil || profile != nil {
+		t.Fatalf("expected the lookup error, got profile %+v", profile)
+	}
+	if err := views.CanView("kc-alice", env.bob.ID); err == nil {
+		t.Fatalf("expected the lookup error from CanView")
+	}
+}
+
+// viewerUserService resolves every Keycloak sub to the same viewer
+type viewerUserService struct {
+	*directoryUserService
+	viewer models.User
+}
+
+func (v *viewerUserService) GetByKeycloakID(sub string) (models.User, error) {
+	return v.viewer, nil
+}
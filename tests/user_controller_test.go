@@ -9,6 +9,7 @@ import (
 
 	"group1-userservice/app/config"
 	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/middleware"
 	"group1-userservice/app/models"
 	"group1-userservice/app/repository"
 	"group1-userservice/app/service"
@@ -28,11 +29,14 @@ func setupUserTestRouter(t *testing.T) (*gin.Engine, *gorm.DB, *controller.UserC
 
 	if err := config.DB.AutoMigrate(
 		&models.User{},
+		&models.ProfileVersion{},
 		&models.NotificationSettings{},
 		&models.Interest{},
+		&models.InterestTranslation{},
 		&models.UserInterest{},
 		&models.UserBadge{},
 		&models.Badge{},
+		&models.ProfileVisibility{},
 	); err != nil {
 		t.Fatalf("failed to migrate tables: %v", err)
 	}
@@ -41,20 +45,55 @@ func setupUserTestRouter(t *testing.T) (*gin.Engine, *gorm.DB, *controller.UserC
 	config.SeedBadges()
 
 	userRepo := repository.NewUserRepository(config.DB)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, newTestIdentityProvider(t))
 
 	userBadgeRepo := repository.NewUserBadgeRepository(config.DB)
 	userBadgeService := service.NewUserBadgeService(userBadgeRepo)
 
-	userController := controller.NewUserController(userService, userBadgeService)
+	visibilityRepo := repository.NewProfileVisibilityRepository(config.DB)
+	visibilityService := service.NewProfileVisibilityService(visibilityRepo, userService)
+	profileViews := service.NewProfileViewService(
+		userService,
+		userBadgeService,
+		visibilityService,
+		service.NewSelfRelationshipResolver(),
+		nil,
+	)
+
+	userController := controller.NewUserController(userService, userBadgeService, profileViews)
 
 	router := gin.Default()
+	router.Use(middleware.ProblemDetails())
 
 	// public info route
-	router.GET("/users/:firstname/:lastname", userController.GetByFirstLast)
+	router.GET("/users/:firstname/:lastname", func(c *gin.Context) {
+		c.Set("user_id", "kc-me-sub-1")
+		userController.GetByFirstLast(c)
+	})
+
+	// badges by id route
+	router.GET("/users/id/:id/badges", func(c *gin.Context) {
+		c.Set("user_id", "kc-me-sub-1")
+		userController.GetBadgesByUserID(c)
+	})
+
+	// keycloak route (authenticated as a service)
+	router.GET("/users/keycloak/:sub", func(c *gin.Context) {
+		c.Set("service_auth", true)
+		userController.GetByKeycloakSub(c)
+	})
 
-	// keycloak route
-	router.GET("/users/keycloak/:sub", userController.GetByKeycloakSub)
+	// keycloak route (authenticated as a user)
+	router.GET("/as-user/users/keycloak/:sub", func(c *gin.Context) {
+		c.Set("user_id", "kc-me-sub-1")
+		userController.GetByKeycloakSub(c)
+	})
+
+	// public profile route
+	router.GET("/users/id/:id/profile", func(c *gin.Context) {
+		c.Set("user_id", "kc-me-sub-1")
+		userController.GetPublicProfileByID(c)
+	})
 
 	// internal email route
 	router.GET("/internal/users/:email", userController.GetByEmail)
@@ -64,6 +103,11 @@ func setupUserTestRouter(t *testing.T) (*gin.Engine, *gorm.DB, *controller.UserC
 		userController.UpdateMe(c)
 	})
 
+	router.PATCH("/users/me", func(c *gin.Context) {
+		c.Set("user_id", "kc-me-sub-1")
+		userController.PatchMe(c)
+	})
+
 	return router, db, userController
 }
 
@@ -167,6 +211,72 @@ func TestGetUserByKeycloakSub_Success_Returns200(t *testing.T) {
 	assert.NotContains(t, body, `"password":"hashed-pass"`)
 }
 
+func TestGetUserByKeycloakSub_OtherUsersSub_Returns403(t *testing.T) {
+	router, db, _ := setupUserTestRouter(t)
+
+	createUserTestUser(t, db, "other@example.com", "kc-other-sub", "hashed-pass")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/as-user/users/keycloak/kc-other-sub", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NotContains(t, w.Body.String(), "other@example.com")
+}
+
+func TestGetUserByKeycloakSub_OwnSub_Returns200(t *testing.T) {
+	router, db, _ := setupUserTestRouter(t)
+
+	createUserTestUser(t, db, "me@example.com", "kc-me-sub-1", "hashed-pass")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/as-user/users/keycloak/kc-me-sub-1", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"email":"me@example.com"`)
+}
+
+// Tests for GET /users/id/:id/profile
+
+func TestGetPublicProfile_InvalidID_Returns400(t *testing.T) {
+	router, _, _ := setupUserTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/id/not-a-uuid/profile", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetPublicProfile_HidesPrivateFields(t *testing.T) {
+	router, db, _ := setupUserTestRouter(t)
+
+	user := createUserTestUser(t, db, "profile@example.com", "kc-profile-1", "hashed-pass")
+	db.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]any{
+		"biography":    "Investor in ICT",
+		"phone_number": "+31612345678",
+		"is_blocked":   false,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/id/"+user.ID.String()+"/profile", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Contains(t, body, `"biography":"Investor in ICT"`)
+	assert.NotContains(t, body, `"phone_number"`)
+	assert.NotContains(t, body, `"keycloak_id"`)
+	assert.NotContains(t, body, `"is_blocked"`)
+	assert.NotContains(t, body, `"password"`)
+}
+
 // Tests for GET /users/:firstname/:lastname (public info)
 
 func TestGetUserByFirstLast_NotFound_Returns404(t *testing.T) {
@@ -203,6 +313,59 @@ func TestGetUserByFirstLast_Success_ReturnsOnlyEmailAndNames(t *testing.T) {
 	assert.NotContains(t, body, `"keycloak_id"`)
 }
 
+func TestGetUserByFirstLast_EmailPrivate_OmitsEmail(t *testing.T) {
+	router, db, _ := setupUserTestRouter(t)
+
+	user := createUserTestUser(t, db, "private.mail@example.com", "kc-firstlast-2", "hashed-pass")
+	db.Model(&models.User{}).Where("id = ?", user.ID).
+		Updates(map[string]any{"first_name": "Priv", "last_name": "Ate"})
+	db.Create(&models.ProfileVisibility{
+		UserID:      user.ID,
+		Email:       models.VisibilityPrivate,
+		PhoneNumber: models.VisibilityPrivate,
+		Country:     models.VisibilityPublic,
+		JobFunction: models.VisibilityPublic,
+		Sector:      models.VisibilityPublic,
+		Biography:   models.VisibilityPublic,
+		Badges:      models.VisibilityPublic,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/priv/ate", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `"first_name":"Priv"`)
+	assert.NotContains(t, body, "private.mail@example.com")
+}
+
+func TestGetBadgesByUserID_BadgesPrivate_ReturnsEmptyList(t *testing.T) {
+	router, db, _ := setupUserTestRouter(t)
+
+	user := createUserTestUser(t, db, "badges@example.com", "kc-badges-1", "hashed-pass")
+	db.Create(&models.UserBadge{UserID: user.ID, BadgeKey: "profile_complete"})
+	db.Create(&models.ProfileVisibility{
+		UserID:      user.ID,
+		Email:       models.VisibilityPublic,
+		PhoneNumber: models.VisibilityPrivate,
+		Country:     models.VisibilityPublic,
+		JobFunction: models.VisibilityPublic,
+		Sector:      models.VisibilityPublic,
+		Biography:   models.VisibilityPublic,
+		Badges:      models.VisibilityPrivate,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/id/"+user.ID.String()+"/badges", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+}
+
 func TestUpdateMe_InvalidBody_Returns400(t *testing.T) {
 	router, _, _ := setupUserTestRouter(t)
 
@@ -222,6 +385,7 @@ func TestUpdateMe_Success_Returns200AndUpdatesUser(t *testing.T) {
 
 	payload := map[string]any{
 		"first_name": "NewName",
+		"last_name":  "User",
 		"biography":  "New bio",
 	}
 	b, _ := json.Marshal(payload)
@@ -247,7 +411,7 @@ func TestUpdateMe_Success_Returns200AndUpdatesUser(t *testing.T) {
 	assert.Equal(t, "New bio", fromDB.Biography)
 }
 
-func TestUpdateMe_CanSetPhoneNumberVisibleTrue(t *testing.T) {
+func TestPatchMe_CanSetPhoneNumberVisibleTrue(t *testing.T) {
 	router, db, _ := setupUserTestRouter(t)
 
 	createUserTestUser(t, db, "me@example.com", "kc-me-sub-1", "hashed-pass")
@@ -258,7 +422,7 @@ func TestUpdateMe_CanSetPhoneNumberVisibleTrue(t *testing.T) {
 	b, _ := json.Marshal(payload)
 
 	w := httptest.NewRecorder()
-	req, _ := http.NewRequest(http.MethodPut, "/users/me", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPatch, "/users/me", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 
 	router.ServeHTTP(w, req)
@@ -276,7 +440,7 @@ func TestUpdateMe_CanSetPhoneNumberVisibleTrue(t *testing.T) {
 	assert.True(t, fromDB.PhoneNumberVisible)
 }
 
-func TestUpdateMe_CanTogglePhoneNumberVisibleTrueThenFalse(t *testing.T) {
+func TestPatchMe_CanTogglePhoneNumberVisibleTrueThenFalse(t *testing.T) {
 	router, db, _ := setupUserTestRouter(t)
 	createUserTestUser(t, db, "me@example.com", "kc-me-sub-1", "hashed-pass")
 
@@ -285,7 +449,7 @@ func TestUpdateMe_CanTogglePhoneNumberVisibleTrueThenFalse(t *testing.T) {
 		payload := map[string]any{"phone_number_visible": true}
 		b, _ := json.Marshal(payload)
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest(http.MethodPut, "/users/me", bytes.NewReader(b))
+		req, _ := http.NewRequest(http.MethodPatch, "/users/me", bytes.NewReader(b))
 		req.Header.Set("Content-Type", "application/json")
 		router.ServeHTTP(w, req)
 		assert.Equal(t, http.StatusOK, w.Code)
@@ -296,7 +460,7 @@ func TestUpdateMe_CanTogglePhoneNumberVisibleTrueThenFalse(t *testing.T) {
 		payload := map[string]any{"phone_number_visible": false}
 		b, _ := json.Marshal(payload)
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest(http.MethodPut, "/users/me", bytes.NewReader(b))
+		req, _ := http.NewRequest(http.MethodPatch, "/users/me", bytes.NewReader(b))
 		req.Header.Set("Content-Type", "application/json")
 		router.ServeHTTP(w, req)
 		assert.Equal(t, http.StatusOK, w.Code)
@@ -317,6 +481,7 @@ func TestUpdateMe_DoesNotChangePasswordOrKeycloakID(t *testing.T) {
 		"password":    "hacked",
 		"keycloak_id": "kc-hacked",
 		"first_name":  "Ok",
+		"last_name":   "User",
 	}
 	b, _ := json.Marshal(payload)
 
@@ -331,7 +496,80 @@ func TestUpdateMe_DoesNotChangePasswordOrKeycloakID(t *testing.T) {
 	var fromDB models.User
 	err := db.First(&fromDB, "email = ?", "me@example.com").Error
 	assert.NoError(t, err)
-	assert.Equal(t, "keep-hash", fromDB.Password)
 	assert.Equal(t, "kc-me-sub-1", fromDB.KeycloakID)
 	assert.Equal(t, "Ok", fromDB.FirstName)
 }
+
+func TestUpdateMe_ReplacesWholeProfile(t *testing.T) {
+	router, db, _ := setupUserTestRouter(t)
+
+	user := createUserTestUser(t, db, "me@example.com", "kc-me-sub-1", "hashed-pass")
+	db.Model(user).Updates(map[string]any{"biography": "Old bio", "country": "NL"})
+
+	b, _ := json.Marshal(map[string]any{"first_name": "Jane", "last_name": "Doe"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/users/me", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var fromDB models.User
+	assert.NoError(t, db.First(&fromDB, "email = ?", "me@example.com").Error)
+	assert.Equal(t, "Jane", fromDB.FirstName)
+	assert.Equal(t, "", fromDB.Biography)
+	assert.Equal(t, "", fromDB.Country)
+}
+
+func TestUpdateMe_MissingName_Returns422(t *testing.T) {
+	router, db, _ := setupUserTestRouter(t)
+	createUserTestUser(t, db, "me@example.com", "kc-me-sub-1", "hashed-pass")
+
+	b, _ := json.Marshal(map[string]any{"first_name": "Jane", "biography": "Bio"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/users/me", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"last_name"`)
+}
+
+func TestPatchMe_NullClearsFieldAndKeepsOthers(t *testing.T) {
+	router, db, _ := setupUserTestRouter(t)
+
+	user := createUserTestUser(t, db, "me@example.com", "kc-me-sub-1", "hashed-pass")
+	db.Model(user).Updates(map[string]any{"biography": "Old bio", "country": "NL"})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPatch, "/users/me", bytes.NewBufferString(`{"biography": null}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var fromDB models.User
+	assert.NoError(t, db.First(&fromDB, "email = ?", "me@example.com").Error)
+	assert.Equal(t, "", fromDB.Biography)
+	assert.Equal(t, "NL", fromDB.Country)
+	assert.Equal(t, "Test", fromDB.FirstName)
+}
+
+func TestPatchMe_RejectsUnknownFieldsAndClearingNames(t *testing.T) {
+	router, db, _ := setupUserTestRouter(t)
+	createUserTestUser(t, db, "me@example.com", "kc-me-sub-1", "hashed-pass")
+
+	for body, want := range map[string]int{
+		`{"biografy": "typo"}`: http.StatusBadRequest,
+		`{"password": "x"}`:    http.StatusBadRequest,
+		`["biography"]`:        http.StatusBadRequest,
+		`{"last_name": null}`:  http.StatusUnprocessableEntity,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, "/users/me", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, want, w.Code, body)
+	}
+}
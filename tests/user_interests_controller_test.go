@@ -11,6 +11,7 @@ import (
 
 	"group1-userservice/app/config"
 	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/middleware"
 	"group1-userservice/app/models"
 	"group1-userservice/app/repository"
 	"group1-userservice/app/service"
@@ -30,7 +31,9 @@ func setupInterestsTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 
 	if err := config.DB.AutoMigrate(
 		&models.User{},
+		&models.ProfileVersion{},
 		&models.Interest{},
+		&models.InterestTranslation{},
 		&models.UserInterest{},
 	); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
@@ -39,7 +42,7 @@ func setupInterestsTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 	config.SeedInterests()
 
 	userRepo := repository.NewUserRepository(config.DB)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, newTestIdentityProvider(t))
 
 	interestsRepo := repository.NewUserInterestsRepository()
 	interestsService := service.NewUserInterestsService(interestsRepo)
@@ -47,6 +50,7 @@ func setupInterestsTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 	interestsController := controller.NewUserInterestsController(interestsService, userService)
 
 	router := gin.Default()
+	router.Use(middleware.ProblemDetails())
 	router.Use(func(c *gin.Context) {
 		if sub := c.GetHeader("X-TEST-USER-SUB"); sub != "" {
 			// Must match middleware.GetUserID key ("user_id" / "sub" depending on your middleware)
@@ -135,10 +139,26 @@ func TestGetInterests_Success_ReturnsDefaults(t *testing.T) {
 	assert.Contains(t, body, `"email":"`+user.Email+`"`)
 
 	// Defaults: value false for all (since user has no rows yet)
-	assert.Contains(t, body, `"key":"ICT"`)
+	assert.Contains(t, body, `"key":"ict"`)
 	assert.Contains(t, body, `"value":false`)
-	assert.Contains(t, body, `"key":"Media en Communicatie"`)
+	assert.Contains(t, body, `"key":"media_communication"`)
+	assert.Contains(t, body, `"label":"Media and Communication"`)
+}
+
+func TestGetInterests_LabelsFollowAcceptLanguage(t *testing.T) {
+	router, db := setupInterestsTestRouter(t)
 
+	user := createInterestsTestUser(t, db, "dutch@example.com", "kc-nl")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/me/interests", nil)
+	req.Header.Set("X-TEST-USER-SUB", user.KeycloakID)
+	req.Header.Set("Accept-Language", "nl-NL,nl;q=0.9,en;q=0.5")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"key":"media_communication","label":"Media en Communicatie"`)
 }
 
 // Tests for PUT /users/me/interests
@@ -190,8 +210,8 @@ func TestUpdateInterests_Success(t *testing.T) {
 
 	user := createInterestsTestUser(t, db, "update@example.com", "kc-update")
 
-	ictID := getInterestIDByKey(t, db, "ICT")
-	mediaID := getInterestIDByKey(t, db, "Media en Communicatie")
+	ictID := getInterestIDByKey(t, db, "ict")
+	mediaID := getInterestIDByKey(t, db, "media_communication")
 
 	body := map[string]interface{}{
 		"interests": []map[string]interface{}{
@@ -217,8 +237,8 @@ func TestUpdateInterests_Success(t *testing.T) {
 	assert.Contains(t, resp, `"email":"`+user.Email+`"`)
 
 	// Check returned items contain keys and correct values
-	assert.Contains(t, resp, `"key":"ICT"`)
-	assert.Contains(t, resp, `"key":"Media en Communicatie"`)
+	assert.Contains(t, resp, `"key":"ict"`)
+	assert.Contains(t, resp, `"key":"media_communication"`)
 
 	assert.Contains(t, resp, `"value":false`)
 	assert.Contains(t, resp, `"value":true`)
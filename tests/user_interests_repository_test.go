@@ -17,10 +17,11 @@ func setupUserInterestsRepositoryTest(t *testing.T) (*repository.UserInterestsRe
 	db := openTestDB(t)
 	config.DB = db
 
-	_ = config.DB.Migrator().DropTable(&models.UserInterest{}, &models.Interest{})
+	_ = config.DB.Migrator().DropTable(&models.UserInterest{}, &models.InterestTranslation{}, &models.Interest{})
 
 	if err := config.DB.AutoMigrate(
 		&models.Interest{},
+		&models.InterestTranslation{},
 		&models.UserInterest{},
 	); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
@@ -42,8 +43,8 @@ func TestUserInterestsRepository_CreateInterests_ThenAnyInterestsExist_True(t *t
 	repo, _ := setupUserInterestsRepositoryTest(t)
 
 	err := repo.CreateInterests([]string{
-		"ICT",
-		"Onderwijs, Cultuur en Wetenschap",
+		"ict",
+		"education_culture_science",
 	})
 	assert.NoError(t, err)
 
@@ -65,9 +66,9 @@ func TestUserInterestsRepository_ListAllInterests_ReturnsOrdered(t *testing.T) {
 		keys[it.Key] = true
 	}
 
-	assert.True(t, keys["ICT"])
-	assert.True(t, keys["Onderwijs, Cultuur en Wetenschap"])
-	assert.True(t, keys["Interesse om te Investeren"])
+	assert.True(t, keys["ict"])
+	assert.True(t, keys["education_culture_science"])
+	assert.True(t, keys["investment_offered"])
 }
 
 func TestUserInterestsRepository_GetUserInterests_EmptyWhenNoRows(t *testing.T) {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"group1-userservice/app/config"
+	"group1-userservice/app/i18n"
 	"group1-userservice/app/interfaces"
 	"group1-userservice/app/models"
 	"group1-userservice/app/repository"
@@ -19,10 +20,11 @@ func setupUserInterestsServiceTest(t *testing.T) (interfaces.UserInterestsServic
 	db := openTestDB(t)
 	config.DB = db
 
-	_ = config.DB.Migrator().DropTable(&models.UserInterest{}, &models.Interest{})
+	_ = config.DB.Migrator().DropTable(&models.UserInterest{}, &models.InterestTranslation{}, &models.Interest{})
 
 	if err := config.DB.AutoMigrate(
 		&models.Interest{},
+		&models.InterestTranslation{},
 		&models.UserInterest{},
 	); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
@@ -41,7 +43,7 @@ func TestUserInterests_GetForUser_ReturnsDefaultsWhenNoRows(t *testing.T) {
 
 	email := "user55@example.com"
 
-	result, err := svc.GetForUser(email)
+	result, err := svc.GetForUser(email, i18n.English)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -51,12 +53,12 @@ func TestUserInterests_GetForUser_ReturnsDefaultsWhenNoRows(t *testing.T) {
 	foundMedia := false
 
 	for _, item := range result {
-		if item.Key == "ICT" {
+		if item.Key == "ict" {
 			foundICT = true
 			assert.False(t, item.Value)
 			assert.NotZero(t, item.ID)
 		}
-		if item.Key == "Media en Communicatie" {
+		if item.Key == "media_communication" {
 			foundMedia = true
 			assert.False(t, item.Value)
 			assert.NotZero(t, item.ID)
@@ -70,8 +72,8 @@ func TestUserInterests_GetForUser_ReturnsDefaultsWhenNoRows(t *testing.T) {
 func TestUserInterests_GetForUser_ReturnsExistingValues(t *testing.T) {
 	svc, db, _ := setupUserInterestsServiceTest(t)
 
-	ictID := getInterestIDByKey(t, db, "ICT")
-	mediaID := getInterestIDByKey(t, db, "Media en Communicatie")
+	ictID := getInterestIDByKey(t, db, "ict")
+	mediaID := getInterestIDByKey(t, db, "media_communication")
 
 	email := "user10@example.com"
 
@@ -87,7 +89,7 @@ func TestUserInterests_GetForUser_ReturnsExistingValues(t *testing.T) {
 		Value:      false,
 	}).Error)
 
-	result, err := svc.GetForUser(email)
+	result, err := svc.GetForUser(email, i18n.English)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -97,11 +99,11 @@ func TestUserInterests_GetForUser_ReturnsExistingValues(t *testing.T) {
 	var mediaVal *bool
 
 	for _, item := range result {
-		if item.Key == "ICT" {
+		if item.Key == "ict" {
 			v := item.Value
 			ictVal = &v
 		}
-		if item.Key == "Media en Communicatie" {
+		if item.Key == "media_communication" {
 			v := item.Value
 			mediaVal = &v
 		}
@@ -118,9 +120,9 @@ func TestUserInterests_GetForUser_ReturnsExistingValues(t *testing.T) {
 func TestUserInterests_UpdateForUser_UpdatesAndReturnsMergedList(t *testing.T) {
 	svc, db, _ := setupUserInterestsServiceTest(t)
 
-	ictID := getInterestIDByKey(t, db, "ICT")
-	eduID := getInterestIDByKey(t, db, "Onderwijs, Cultuur en Wetenschap")
-	mediaID := getInterestIDByKey(t, db, "Media en Communicatie")
+	ictID := getInterestIDByKey(t, db, "ict")
+	eduID := getInterestIDByKey(t, db, "education_culture_science")
+	mediaID := getInterestIDByKey(t, db, "media_communication")
 
 	input := interfaces.UserInterestsUpdateInput{
 		Interests: []interfaces.UserInterestItemInput{
@@ -132,7 +134,7 @@ func TestUserInterests_UpdateForUser_UpdatesAndReturnsMergedList(t *testing.T) {
 
 	email := "user99@example.com"
 
-	result, err := svc.UpdateForUser(email, input)
+	result, err := svc.UpdateForUser(email, input, i18n.English)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -146,17 +148,17 @@ func TestUserInterests_UpdateForUser_UpdatesAndReturnsMergedList(t *testing.T) {
 		if item.ID == ictID {
 			v := item.Value
 			ictVal = &v
-			assert.Equal(t, "ICT", item.Key)
+			assert.Equal(t, "ict", item.Key)
 		}
 		if item.ID == eduID {
 			v := item.Value
 			eduVal = &v
-			assert.Equal(t, "Onderwijs, Cultuur en Wetenschap", item.Key)
+			assert.Equal(t, "education_culture_science", item.Key)
 		}
 		if item.ID == mediaID {
 			v := item.Value
 			mediaVal = &v
-			assert.Equal(t, "Media en Communicatie", item.Key)
+			assert.Equal(t, "media_communication", item.Key)
 		}
 	}
 
@@ -185,3 +187,73 @@ func TestUserInterests_UpdateForUser_UpdatesAndReturnsMergedList(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, savedMedia.Value)
 }
+
+func TestUserInterests_GetForUser_ReturnsLabelsInLocale(t *testing.T) {
+	svc, _, _ := setupUserInterestsServiceTest(t)
+
+	labels := func(locale i18n.Locale) map[string]string {
+		result, err := svc.GetForUser("user12@example.com", locale)
+		if err != nil {
+			t.Fatalf("GetForUser: %v", err)
+		}
+		out := map[string]string{}
+		for _, item := range result {
+			out[item.Key] = item.Label
+		}
+		return out
+	}
+
+	nl := labels(i18n.Dutch)
+	en := labels(i18n.English)
+
+	assert.Equal(t, "Media en Communicatie", nl["media_communication"])
+	assert.Equal(t, "Media and Communication", en["media_communication"])
+	assert.Equal(t, "Interesse om te Investeren", nl["investment_offered"])
+	assert.Equal(t, "Interested in Investing", en["investment_offered"])
+}
+
+func TestSeedInterests_MigratesLegacyKeysAndKeepsSelections(t *testing.T) {
+	db := openTestDB(t)
+	config.DB = db
+
+	_ = db.Migrator().DropTable(&models.UserInterest{}, &models.InterestTranslation{}, &models.Interest{})
+	if err := db.AutoMigrate(&models.Interest{}, &models.InterestTranslation{}, &models.UserInterest{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	// an interest as it was seeded before slugs, selected by a user
+	legacy := models.Interest{Key: "Media en Communicatie"}
+	assert.NoError(t, db.Create(&legacy).Error)
+	assert.NoError(t, db.Create(&models.UserInterest{UserEmail: "user7@example.com", InterestID: legacy.ID, Value: true}).Error)
+
+	config.SeedInterests()
+	config.SeedInterests()
+
+	var migrated models.Interest
+	assert.NoError(t, db.Preload("Translations").First(&migrated, legacy.ID).Error)
+	assert.Equal(t, "media_communication", migrated.Key)
+	assert.Equal(t, "Media en Communicatie", migrated.Translation("nl").Label)
+	assert.Equal(t, "Media and Communication", migrated.Translation("en").Label)
+
+	var count int64
+	db.Model(&models.Interest{}).Where("key = ?", "Media en Communicatie").Count(&count)
+	assert.Zero(t, count)
+	db.Model(&models.Interest{}).Count(&count)
+	assert.EqualValues(t, 12, count)
+
+	var selection models.UserInterest
+	assert.NoError(t, db.First(&selection, "user_email = ? AND interest_id = ?", "user7@example.com", legacy.ID).Error)
+	assert.True(t, selection.Value)
+}
+
+func TestInterest_Translation_FallsBackToEnglishThenKey(t *testing.T) {
+	interest := models.Interest{ID: 1, Key: "ict", Translations: []models.InterestTranslation{
+		{InterestID: 1, Locale: "nl", Label: "ICT-sector"},
+		{InterestID: 1, Locale: "en", Label: "ICT sector", Description: "Software and IT"},
+	}}
+
+	assert.Equal(t, "ICT-sector", interest.Translation("nl").Label)
+	assert.Equal(t, "ICT sector", interest.Translation("de").Label)
+	assert.Equal(t, "Software and IT", interest.Translation("de").Description)
+	assert.Equal(t, "ict", models.Interest{Key: "ict"}.Translation("nl").Label)
+}
@@ -19,7 +19,7 @@ func setupUserRepositoryTest(t *testing.T) (interfaces.UserRepository, *gorm.DB)
 	db := openTestDB(t)
 	config.DB = db
 
-	if err := config.DB.AutoMigrate(&models.User{}); err != nil {
+	if err := config.DB.AutoMigrate(&models.User{}, &models.ProfileVersion{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 
@@ -233,24 +233,26 @@ func TestUserRepository_UpdateFieldsByEmail_UserNotFound(t *testing.T) {
 	assert.Error(t, err)
 }
 
-func TestUserRepository_UpdatePasswordHashByEmail_UpdatesPassword(t *testing.T) {
+func TestUserRepository_ClearLegacyPasswordHash_EmptiesColumn(t *testing.T) {
 	repo, db := setupUserRepositoryTest(t)
+	assert.NoError(t, db.Exec("ALTER TABLE users ADD COLUMN IF NOT EXISTS password text").Error)
 
-	db.Create(&models.User{
-		Email:      "pwupdate@example.com",
-		Password:   "oldhash",
+	user := models.User{
+		Email:      "pwclear@example.com",
 		KeycloakID: "kc-pw",
 		FirstName:  "Test",
 		LastName:   "User",
-	})
+	}
+	db.Create(&user)
+	db.Exec("UPDATE users SET password = 'oldhash' WHERE id = ?", user.ID)
 
-	err := repo.UpdatePasswordHashByEmail("pwupdate@example.com", "newhash")
+	err := repo.ClearLegacyPasswordHash(user.ID)
 	assert.NoError(t, err)
 
 	var fromDB models.User
-	err = db.First(&fromDB, "email = ?", "pwupdate@example.com").Error
+	err = db.First(&fromDB, "email = ?", "pwclear@example.com").Error
 	assert.NoError(t, err)
-	assert.Equal(t, "newhash", fromDB.Password)
+	assert.Empty(t, fromDB.LegacyPasswordHash)
 }
 
 func TestUserRepository_UpdateProfilePhotoURLByKeycloakID_UpdatesField(t *testing.T) {
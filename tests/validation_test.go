@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/i18n"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/service"
+	"group1-userservice/app/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	t.Setenv("PHONE_DEFAULT_COUNTRY_CODE", "")
+
+	for raw, want := range map[string]string{
+		"+31612345678":      "+31612345678",
+		"06 12345678":       "+31612345678",
+		"06-1234 5678":      "+31612345678",
+		"0031 6 12345678":   "+31612345678",
+		"+1 (555) 123-4567": "+15551234567",
+		"12345678":          "",
+		"+3161234abcd":      "",
+		"+0612345678":       "",
+		"+3161234567890123": "",
+	} {
+		got, ok := validation.NormalizePhone(raw)
+		assert.Equal(t, want != "", ok, raw)
+		if ok {
+			assert.Equal(t, want, got, raw)
+		}
+	}
+}
+
+func TestNormalizePhone_DefaultCountryCode(t *testing.T) {
+	t.Setenv("PHONE_DEFAULT_COUNTRY_CODE", "32")
+
+	got, ok := validation.NormalizePhone("0470 12 34 56")
+	assert.True(t, ok)
+	assert.Equal(t, "+32470123456", got)
+}
+
+func TestNormalizeCountry(t *testing.T) {
+	for _, raw := range []string{"NL", "nl", "NLD", "Netherlands", "nederland", " Nederland "} {
+		code, ok := validation.NormalizeCountry(raw)
+		assert.True(t, ok, raw)
+		assert.Equal(t, "NL", code, raw)
+	}
+
+	code, ok := validation.NormalizeCountry("België")
+	assert.True(t, ok)
+	assert.Equal(t, "BE", code)
+
+	_, ok = validation.NormalizeCountry("Atlantis")
+	assert.False(t, ok)
+
+	nl, ok := validation.CountryByCode("nl")
+	assert.True(t, ok)
+	assert.Equal(t, "NLD", nl.Alpha3)
+	assert.Equal(t, "Nederland", nl.NameNL)
+	assert.Len(t, validation.Countries(), 249)
+}
+
+func TestProfile_NormalizesInPlace(t *testing.T) {
+	p := models.ProfileSnapshot{
+		FirstName:   "  Jane ",
+		LastName:    "Doe",
+		PhoneNumber: "06 12345678",
+		Country:     "nederland",
+		Biography:   "Hello\r\nworld",
+	}
+
+	assert.NoError(t, validation.Profile(&p))
+	assert.Equal(t, "Jane", p.FirstName)
+	assert.Equal(t, "+31612345678", p.PhoneNumber)
+	assert.Equal(t, "NL", p.Country)
+	assert.Equal(t, "Hello\nworld", p.Biography)
+}
+
+func TestProfile_ReportsEveryInvalidField(t *testing.T) {
+	p := models.ProfileSnapshot{
+		FirstName:       "Jane\u202e",
+		LastName:        "",
+		PhoneNumber:     "123",
+		Country:         "Atlantis",
+		JobFunction:     "Dev\nOps",
+		Biography:       strings.Repeat("a", validation.MaxBiographyLength+1),
+		ProfilePhotoURL: "javascript:alert(1)",
+	}
+
+	err := validation.Profile(&p)
+	errs, ok := err.(validation.Errors)
+	assert.True(t, ok)
+	fields := errs.Messages(i18n.English)
+	assert.Equal(t, "must not contain control characters", fields["first_name"])
+	assert.Equal(t, "Last name is required", fields["last_name"])
+	assert.Contains(t, fields, "phone_number")
+	assert.Contains(t, fields, "country")
+	assert.Equal(t, "must not contain control characters", fields["job_function"])
+	assert.Equal(t, "must be at most 1000 characters", fields["biography"])
+	assert.Contains(t, fields, "profile_photo_url")
+	assert.Len(t, fields, 7)
+}
+
+func TestProfile_LimitsCountCharactersNotBytes(t *testing.T) {
+	p := models.ProfileSnapshot{FirstName: strings.Repeat("é", validation.MaxNameLength), LastName: "Doe"}
+	assert.NoError(t, validation.Profile(&p))
+
+	p.FirstName += "é"
+	assert.Error(t, validation.Profile(&p))
+}
+
+func TestPassword(t *testing.T) {
+	assert.NoError(t, validation.Password("secret1"))
+	assert.EqualError(t, validation.Password("abc1"), "password must be at least 6 characters long")
+	assert.EqualError(t, validation.Password("secretpassword"), "password must contain at least one number")
+	assert.Error(t, validation.Password(strings.Repeat("a1", 37)))
+}
+
+func TestRegister_InvalidFields_Returns422(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// validation runs before the repository is used
+	rc := controller.NewRegisterController(service.NewUserService(nil, newTestIdentityProvider(t)), &fakeEmailVerification{})
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.POST("/users/register", rc.Handle)
+
+	body := `{"email": "not-an-email", "password": "short", "first_name": "Alice", "last_name": "", "country": "Atlantis"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/users/register", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+
+	var resp middleware.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "validation_failed", resp.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Status)
+	assert.Equal(t, "must be a valid email address", resp.Fields["email"])
+	assert.Equal(t, "Last name is required", resp.Fields["last_name"])
+	assert.Contains(t, resp.Fields, "password")
+	assert.Contains(t, resp.Fields, "country")
+	assert.NotContains(t, resp.Fields, "first_name")
+}
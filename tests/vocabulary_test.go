@@ -0,0 +1,294 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"group1-userservice/app/config"
+	controller "group1-userservice/app/controllers"
+	"group1-userservice/app/interfaces"
+	"group1-userservice/app/middleware"
+	"group1-userservice/app/models"
+	"group1-userservice/app/repository"
+	"group1-userservice/app/service"
+	"group1-userservice/app/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeVocabularyRepo keeps one list of sectors and the free-text sector values of existing profiles
+type fakeVocabularyRepo struct {
+	terms    []models.VocabularyTerm
+	values   []interfaces.VocabularyValueCount
+	replaced map[string]string
+	mappings []models.VocabularyMapping
+	inUse    int64
+}
+
+func (f *fakeVocabularyRepo) FindVocabularyTerm(v models.Vocabulary, value string) (*models.VocabularyTerm, error) {
+	for _, t := range f.terms {
+		if strings.EqualFold(t.Key, value) || strings.EqualFold(t.LabelNL, value) || strings.EqualFold(t.LabelEN, value) {
+			return &t, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeVocabularyRepo) List(v models.Vocabulary) ([]models.VocabularyTerm, error) {
+	if v != models.VocabularySectors {
+		return nil, nil
+	}
+	return f.terms, nil
+}
+
+func (f *fakeVocabularyRepo) Find(v models.Vocabulary, key string) (*models.VocabularyTerm, error) {
+	for _, t := range f.terms {
+		if t.Key == key {
+			return &t, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeVocabularyRepo) Create(v models.Vocabulary, t *models.VocabularyTerm) error {
+	f.terms = append(f.terms, *t)
+	return nil
+}
+
+func (f *fakeVocabularyRepo) Update(v models.Vocabulary, t *models.VocabularyTerm) error {
+	for i := range f.terms {
+		if f.terms[i].Key == t.Key {
+			f.terms[i] = *t
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (f *fakeVocabularyRepo) Delete(v models.Vocabulary, key string) (bool, error) {
+	for i := range f.terms {
+		if f.terms[i].Key == key {
+			f.terms = append(f.terms[:i], f.terms[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeVocabularyRepo) UsersWithTerm(v models.Vocabulary, key string) (int64, error) {
+	return f.inUse, nil
+}
+
+func (f *fakeVocabularyRepo) UnknownProfileValues(v models.Vocabulary) ([]interfaces.VocabularyValueCount, error) {
+	if v != models.VocabularySectors {
+		return nil, nil
+	}
+	return f.values, nil
+}
+
+func (f *fakeVocabularyRepo) ReplaceProfileValue(v models.Vocabulary, raw, key string) ([]uuid.UUID, error) {
+	if f.replaced == nil {
+		f.replaced = map[string]string{}
+	}
+	f.replaced[raw] = key
+	return []uuid.UUID{uuid.New()}, nil
+}
+
+func (f *fakeVocabularyRepo) SaveMapping(m *models.VocabularyMapping) error {
+	if m.ID == 0 {
+		m.ID = uint(len(f.mappings) + 1)
+		f.mappings = append(f.mappings, *m)
+		return nil
+	}
+	f.mappings[m.ID-1] = *m
+	return nil
+}
+
+func (f *fakeVocabularyRepo) ListMappings(v models.Vocabulary, status models.VocabularyMappingStatus, p interfaces.Pagination) ([]models.VocabularyMapping, int64, error) {
+	return f.mappings, int64(len(f.mappings)), nil
+}
+
+func (f *fakeVocabularyRepo) FindMapping(v models.Vocabulary, id uint) (*models.VocabularyMapping, error) {
+	if id == 0 || int(id) > len(f.mappings) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	m := f.mappings[id-1]
+	return &m, nil
+}
+
+func newFakeSectors() *fakeVocabularyRepo {
+	return &fakeVocabularyRepo{terms: []models.VocabularyTerm{
+		{Key: "ict", LabelNL: "ICT", LabelEN: "ICT"},
+		{Key: "healthcare_welfare", LabelNL: "Gezondheidszorg en Welzijn", LabelEN: "Healthcare and Welfare"},
+		{Key: "transport_logistics", LabelNL: "Transport en Logistiek", LabelEN: "Transport and Logistics"},
+	}}
+}
+
+func TestVocabularyMigration_MapsFreeTextValues(t *testing.T) {
+	repo := newFakeSectors()
+	repo.values = []interfaces.VocabularyValueCount{
+		{Value: "Gezondheidszorg & welzijn", Users: 4},
+		{Value: "Transport en Logistek", Users: 2},
+		{Value: "Healthcare", Users: 1},
+		{Value: "Bakkerij", Users: 1},
+	}
+
+	assert.NoError(t, service.NewVocabularyService(repo).MigrateProfileValues())
+
+	byValue := map[string]models.VocabularyMapping{}
+	for _, m := range repo.mappings {
+		byValue[m.RawValue] = m
+	}
+
+	assert.Equal(t, models.VocabularyMappingExact, byValue["Gezondheidszorg & welzijn"].Status)
+	assert.Equal(t, int64(4), byValue["Gezondheidszorg & welzijn"].UserCount)
+	assert.Equal(t, "healthcare_welfare", repo.replaced["Gezondheidszorg & welzijn"])
+
+	// a typo is close enough to apply, but stays visible in the report
+	assert.Equal(t, models.VocabularyMappingFuzzy, byValue["Transport en Logistek"].Status)
+	assert.Equal(t, "transport_logistics", repo.replaced["Transport en Logistek"])
+
+	// half a label is only a suggestion
+	assert.Equal(t, models.VocabularyMappingUnmatched, byValue["Healthcare"].Status)
+	assert.Equal(t, "healthcare_welfare", byValue["Healthcare"].SuggestedKey)
+	assert.NotContains(t, repo.replaced, "Healthcare")
+
+	assert.Equal(t, models.VocabularyMappingUnmatched, byValue["Bakkerij"].Status)
+	assert.Equal(t, "", byValue["Bakkerij"].SuggestedKey)
+}
+
+func TestVocabularyService_ApplyMapping(t *testing.T) {
+	repo := newFakeSectors()
+	repo.values = []interfaces.VocabularyValueCount{{Value: "Healthcare", Users: 1}}
+	svc := service.NewVocabularyService(repo)
+	assert.NoError(t, svc.MigrateProfileValues())
+
+	_, _, err := svc.ApplyMapping(models.VocabularySectors, 1, "bakery")
+	assert.ErrorIs(t, err, service.ErrVocabularyTermNotFound)
+
+	mapping, changed, err := svc.ApplyMapping(models.VocabularySectors, 1, "healthcare_welfare")
+	assert.NoError(t, err)
+	assert.Len(t, changed, 1)
+	assert.Equal(t, models.VocabularyMappingResolved, mapping.Status)
+	assert.Equal(t, "healthcare_welfare", repo.replaced["Healthcare"])
+}
+
+func TestVocabularyService_CreateAndDelete(t *testing.T) {
+	repo := newFakeSectors()
+	svc := service.NewVocabularyService(repo)
+
+	_, err := svc.Create(models.VocabularySectors, interfaces.VocabularyTermInput{Key: "ICT", LabelNL: "ICT", LabelEN: "ICT"})
+	var fields validation.Errors
+	assert.ErrorAs(t, err, &fields)
+	assert.Contains(t, fields, "key")
+
+	_, err = svc.Create(models.VocabularySectors, interfaces.VocabularyTermInput{Key: "ict", LabelNL: "ICT", LabelEN: "ICT"})
+	assert.ErrorIs(t, err, service.ErrVocabularyTermExists)
+
+	term, err := svc.Create(models.VocabularySectors, interfaces.VocabularyTermInput{Key: "retail", LabelNL: " Detailhandel ", LabelEN: "Retail"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Detailhandel", term.LabelNL)
+
+	repo.inUse = 2
+	assert.ErrorIs(t, svc.Delete(models.VocabularySectors, "retail"), service.ErrVocabularyTermInUse)
+
+	repo.inUse = 0
+	assert.NoError(t, svc.Delete(models.VocabularySectors, "retail"))
+	assert.ErrorIs(t, svc.Delete(models.VocabularySectors, "retail"), service.ErrVocabularyTermNotFound)
+}
+
+func TestVocabularyController_UnknownVocabulary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	vc := controller.NewVocabularyController(service.NewVocabularyService(newFakeSectors()))
+	router := gin.New()
+	router.Use(middleware.ProblemDetails())
+	router.GET("/vocabularies/:vocabulary", vc.List)
+	router.POST("/admin/vocabularies/:vocabulary", vc.Create)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/vocabularies/sectors", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"label_en":"Healthcare and Welfare"`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/vocabularies/hobbies", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/admin/vocabularies/sectors",
+		bytes.NewBufferString(`{"key": "retail", "label_nl": "Detailhandel"}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "label_en")
+}
+
+func TestVocabularyRepository_ProfilesUseTermKeys(t *testing.T) {
+	db := openTestDB(t)
+	config.DB = db
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.ProfileVersion{},
+		&models.Sector{},
+		&models.JobFunction{},
+		&models.VocabularyMapping{},
+	); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	config.SeedVocabularies()
+
+	users := repository.NewUserRepository(db)
+	vocabularies := repository.NewVocabularyRepository(db)
+
+	legacy := models.User{Email: "legacy@example.com", KeycloakID: "kc-legacy", FirstName: "Old", LastName: "Timer", Sector: "Transport & Logistiek"}
+	assert.NoError(t, users.Create(&legacy))
+
+	values, err := vocabularies.UnknownProfileValues(models.VocabularySectors)
+	assert.NoError(t, err)
+	assert.Equal(t, []interfaces.VocabularyValueCount{{Value: "Transport & Logistiek", Users: 1}}, values)
+
+	assert.NoError(t, service.NewVocabularyService(vocabularies).MigrateProfileValues())
+	migrated, _ := users.FindByEmail(legacy.Email)
+	assert.Equal(t, "transport_logistics", migrated.Sector)
+	assert.Equal(t, int64(2), migrated.Version)
+
+	// the migration is a version in the history, and the version before it can still be restored
+	history := repository.NewProfileHistoryRepository(db)
+	versions, _, err := history.List(legacy.ID, 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 2) {
+		assert.Equal(t, models.ProfileVersionVocabulary, versions[0].Reason)
+		assert.Equal(t, "Transport & Logistiek", versions[1].Snapshot.Sector)
+	}
+	restored, err := service.NewProfileHistoryService(history).Restore(migrated, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "transport_logistics", restored.Sector)
+
+	// labels are accepted and stored as keys
+	userService := service.NewUserService(users, newTestIdentityProvider(t))
+	updated, err := userService.PatchByEmail(legacy.Email, &models.UserProfilePatch{
+		Sector:      models.PatchString{Set: true, Value: "ict"},
+		JobFunction: models.PatchString{Set: true, Value: "Softwareontwikkeling"},
+	}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "ict", updated.Sector)
+	assert.Equal(t, "software_development", updated.JobFunction)
+
+	_, err = userService.PatchByEmail(legacy.Email, &models.UserProfilePatch{
+		Sector: models.PatchString{Set: true, Value: "Bakkerij"},
+	}, 0)
+	var fields validation.Errors
+	assert.ErrorAs(t, err, &fields)
+	assert.Contains(t, fields, "sector")
+
+	inUse, err := vocabularies.UsersWithTerm(models.VocabularySectors, "ict")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), inUse)
+}